@@ -0,0 +1,395 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/LEVI-Tempest/Candle/pkg/datasource"
+	"github.com/LEVI-Tempest/Candle/pkg/research"
+	"github.com/LEVI-Tempest/Candle/pkg/signal"
+	"github.com/LEVI-Tempest/Candle/pkg/synthetic"
+)
+
+const usage = `usage: research <command> [flags]
+
+commands:
+  train       learn evidence/score weights from pattern outcomes in a candle history
+  calibrate   fit FinalScore -> hit probability / expected return tables per pattern and horizon
+  walkforward search a parameter grid over rolling train/test folds and write the winning config
+  significance test pattern edges against random entry with BH / Reality Check / SPA corrections
+  synth       generate synthetic candle files with injected patterns and their ground truth
+  label       write label file templates (and charts) from detector output for analysts to review
+  evaluate    compare detector output with reviewed labels: precision, recall and confusion per pattern
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		_, _ = fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	var err error
+	switch os.Args[1] {
+	case "train":
+		err = runTrain(os.Args[2:])
+	case "calibrate":
+		err = runCalibrate(os.Args[2:])
+	case "walkforward":
+		err = runWalkForward(os.Args[2:])
+	case "significance":
+		err = runSignificance(os.Args[2:])
+	case "synth":
+		err = runSynth(os.Args[2:])
+	case "label":
+		err = runLabel(os.Args[2:])
+	case "evaluate":
+		err = runEvaluate(os.Args[2:])
+	default:
+		_, _ = fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		exitf("%s failed: %v", os.Args[1], err)
+	}
+}
+
+func runTrain(args []string) error {
+	fs := flag.NewFlagSet("train", flag.ExitOnError)
+	history := fs.String("history", "", "Comma-separated candle JSON files or directories of *.json to learn from.")
+	configPath := fs.String("config", "", "Signal config JSON the samples are built with and the learned weights are written into.")
+	horizon := fs.Int("horizon", 5, "Forward return horizon in bars that defines a hit.")
+	lambda := fs.Float64("lambda", research.DefaultTrainConfig().Lambda, "L2 penalty on coefficients.")
+	testFraction := fs.Float64("test-fraction", research.DefaultTrainConfig().TestFraction, "Latest share of samples held out for out-of-sample AUC.")
+	family := fs.String("family", research.FamilyDirection, "Pattern family grouping: direction | type")
+	minSamples := fs.Int("min-samples", research.DefaultTrainConfig().MinSamples, "Fewest training samples a family needs to be fitted.")
+	outputPath := fs.String("output", "", "Write the learned signal config JSON here (consumable by --config).")
+	reportPath := fs.String("report", "", "Write the training report JSON here. Empty prints to stdout.")
+	_ = fs.Parse(args)
+
+	if *family != research.FamilyDirection && *family != research.FamilyType {
+		return fmt.Errorf("--family must be direction or type")
+	}
+	cfg, err := signal.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	samples, err := loadSamples(*history, cfg, *horizon)
+	if err != nil {
+		return err
+	}
+	res, err := research.TrainWeights(samples, research.TrainConfig{
+		Lambda:       *lambda,
+		TestFraction: *testFraction,
+		Family:       *family,
+		MinSamples:   *minSamples,
+	})
+	if err != nil {
+		return err
+	}
+	if *outputPath != "" {
+		if err := writeJSON(*outputPath, res.Apply(cfg)); err != nil {
+			return err
+		}
+	}
+	return writeJSON(*reportPath, res)
+}
+
+func runCalibrate(args []string) error {
+	def := research.DefaultCalibrationConfig()
+	fs := flag.NewFlagSet("calibrate", flag.ExitOnError)
+	history := fs.String("history", "", "Comma-separated candle JSON files or directories of *.json to calibrate on.")
+	configPath := fs.String("config", "", "Signal config JSON the samples are built with.")
+	horizons := fs.String("horizons", "3,5,10", "Comma-separated forward return horizons in bars.")
+	method := fs.String("method", def.Method, "Calibration method: isotonic | platt")
+	bins := fs.Int("bins", def.Bins, "Equal-count score bins for expected returns.")
+	interval := fs.Float64("interval", def.Interval, "Central coverage of the expected return interval.")
+	minSamples := fs.Int("min-samples", def.MinSamples, "Fewest samples a pattern/horizon table needs.")
+	outputPath := fs.String("output", "", "Write the calibration JSON here (for signal --calibration). Empty prints to stdout.")
+	_ = fs.Parse(args)
+
+	if *method != signal.CalibrationIsotonic && *method != signal.CalibrationPlatt {
+		return fmt.Errorf("--method must be isotonic or platt")
+	}
+	cfg, err := signal.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	byHorizon := make(map[int][]signal.TrainingSample)
+	for _, part := range strings.Split(*horizons, ",") {
+		h, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || h < 1 {
+			return fmt.Errorf("invalid horizon %q", part)
+		}
+		if byHorizon[h], err = loadSamples(*history, cfg, h); err != nil {
+			return err
+		}
+	}
+	cal, err := research.FitCalibration(byHorizon, research.CalibrationConfig{
+		Method:     *method,
+		Bins:       *bins,
+		Interval:   *interval,
+		MinSamples: *minSamples,
+	})
+	if err != nil {
+		return err
+	}
+	return writeJSON(*outputPath, cal)
+}
+
+func runWalkForward(args []string) error {
+	fs := flag.NewFlagSet("walkforward", flag.ExitOnError)
+	history := fs.String("history", "", "Comma-separated candle JSON files or directories of *.json to optimize across.")
+	configPath := fs.String("config", "", "Baseline signal config JSON; parameters are applied on top of it.")
+	gridPath := fs.String("grid", "", "Walk-forward JSON: params (dotted config path -> values), search, objective, fold sizes.")
+	outputPath := fs.String("output", "", "Write the winning signal config JSON here.")
+	force := fs.Bool("force", false, "Write --output even when the winner does not beat the baseline on the holdout.")
+	reportPath := fs.String("report", "", "Write the walk-forward report JSON here. Empty prints to stdout.")
+	_ = fs.Parse(args)
+
+	if *gridPath == "" {
+		return fmt.Errorf("--grid is required")
+	}
+	raw, err := os.ReadFile(*gridPath)
+	if err != nil {
+		return err
+	}
+	wf := research.DefaultWalkForwardConfig()
+	if err := json.Unmarshal(raw, &wf); err != nil {
+		return fmt.Errorf("%s: %w", *gridPath, err)
+	}
+	cfg, err := signal.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if *history == "" {
+		return fmt.Errorf("--history is required")
+	}
+	series, err := datasource.LoadCandleFiles(*history)
+	if err != nil {
+		return err
+	}
+	res, err := research.WalkForward(cfg, series, wf)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(*reportPath, res); err != nil {
+		return err
+	}
+	if *outputPath == "" {
+		return nil
+	}
+	if !res.Accepted && !*force {
+		return fmt.Errorf("winner did not beat the baseline on the holdout; not writing %s (use --force)", *outputPath)
+	}
+	return writeJSON(*outputPath, res.Config)
+}
+
+func runSignificance(args []string) error {
+	def := research.DefaultSignificanceConfig()
+	fs := flag.NewFlagSet("significance", flag.ExitOnError)
+	history := fs.String("history", "", "Comma-separated candle JSON files or directories of *.json; random entries are drawn from every bar.")
+	logPath := fs.String("log", "data/signal_log.csv", "Signal log CSV used when --history is empty; random entries are drawn from every logged return.")
+	configPath := fs.String("config", "", "Signal config JSON patterns are detected with (--history only).")
+	horizon := fs.Int("horizon", 5, "Forward return horizon in bars (3, 5 or 10 with --log).")
+	bootstrap := fs.Int("bootstrap", def.Bootstrap, "Bootstrap resamples.")
+	alpha := fs.Float64("alpha", def.Alpha, "False discovery rate and family-wise level.")
+	seed := fs.Int64("seed", def.Seed, "Bootstrap random seed.")
+	minSamples := fs.Int("min-samples", def.MinSamples, "Fewest returns a pattern needs to be tested.")
+	reportPath := fs.String("report", "", "Write the significance report JSON here. Empty prints to stdout.")
+	_ = fs.Parse(args)
+
+	var patterns []research.PatternReturns
+	var entries []float64
+	if *history == "" {
+		rows, err := signal.LoadSignalLogCSV(*logPath)
+		if err != nil {
+			return err
+		}
+		patterns, entries = research.LogReturns(rows, *horizon)
+	} else {
+		cfg, err := signal.LoadConfig(*configPath)
+		if err != nil {
+			return err
+		}
+		series, err := datasource.LoadCandleFiles(*history)
+		if err != nil {
+			return err
+		}
+		patterns, entries = research.HistoryReturns(series, cfg, *horizon)
+	}
+	res, err := research.AssessSignificance(patterns, entries, research.SignificanceConfig{
+		Bootstrap:  *bootstrap,
+		Alpha:      *alpha,
+		Seed:       *seed,
+		MinSamples: *minSamples,
+	})
+	if err != nil {
+		return err
+	}
+	return writeJSON(*reportPath, res)
+}
+
+func runSynth(args []string) error {
+	fs := flag.NewFlagSet("synth", flag.ExitOnError)
+	configPath := fs.String("config", "", "Synthetic generator config JSON (model, regimes, injections). Empty uses the defaults.")
+	count := fs.Int("count", 1, "Number of series; series k uses seed+k.")
+	seed := fs.Int64("seed", 0, "Random seed overriding the config's.")
+	outDir := fs.String("out-dir", "data/synthetic", "Directory for the candle files, usable as --history.")
+	truthPath := fs.String("truth", "data/synthetic_truth.json", "Write the injected patterns per symbol here. Keep it outside --out-dir.")
+	_ = fs.Parse(args)
+
+	cfg := synthetic.DefaultConfig()
+	if *configPath != "" {
+		raw, err := os.ReadFile(*configPath)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(raw, &cfg); err != nil {
+			return fmt.Errorf("parse %s: %w", *configPath, err)
+		}
+	}
+	if *seed != 0 {
+		cfg.Seed = *seed
+	}
+	series, err := synthetic.GenerateMany(cfg, *count)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		return err
+	}
+	truth := make(map[string][]synthetic.Truth, len(series))
+	for k, s := range series {
+		symbol := fmt.Sprintf("SYN%03d", k)
+		file := datasource.CandleFile{Symbol: symbol, Source: "synthetic", Data: s.Candles}
+		if err := writeJSON(filepath.Join(*outDir, symbol+".json"), file); err != nil {
+			return err
+		}
+		truth[symbol] = s.Truth
+	}
+	return writeJSON(*truthPath, truth)
+}
+
+func runLabel(args []string) error {
+	fs := flag.NewFlagSet("label", flag.ExitOnError)
+	history := fs.String("history", "", "Comma-separated candle JSON files or directories of *.json to label.")
+	configPath := fs.String("config", "", "Signal config JSON the candidate patterns are detected with.")
+	from := fs.String("from", "", "First bar (YYYY-MM-DD) to review. Empty means the first candle.")
+	to := fs.String("to", "", "Last bar (YYYY-MM-DD) to review. Empty means the last candle.")
+	patterns := fs.String("patterns", "", "Comma-separated pattern names to review. Empty reviews every pattern.")
+	outDir := fs.String("out-dir", "data/labels", "Directory for the label templates, one <symbol>.json each.")
+	chart := fs.Bool("chart", true, "Also write <symbol>.html, a chart with the detected patterns marked.")
+	_ = fs.Parse(args)
+
+	if *history == "" {
+		return fmt.Errorf("--history is required")
+	}
+	cfg, err := signal.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	series, err := datasource.LoadCandleFiles(*history)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, p := range strings.Split(*patterns, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			names = append(names, p)
+		}
+	}
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		return err
+	}
+	for symbol, candles := range series {
+		file := strings.ReplaceAll(symbol, ":", "_")
+		tmpl := research.LabelTemplate(symbol, candles, cfg, *from, *to, names)
+		if err := writeJSON(filepath.Join(*outDir, file+".json"), tmpl); err != nil {
+			return err
+		}
+		if !*chart {
+			continue
+		}
+		ek := research.LabelChart(candles, cfg, *from, *to, names)
+		ek.CreateChart(fmt.Sprintf("%s - label review", symbol))
+		if err := ek.RenderToFile(filepath.Join(*outDir, file+".html")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runEvaluate(args []string) error {
+	fs := flag.NewFlagSet("evaluate", flag.ExitOnError)
+	history := fs.String("history", "", "Comma-separated candle JSON files or directories of *.json the labels refer to.")
+	labels := fs.String("labels", "data/labels", "Comma-separated reviewed label JSON files or directories of *.json.")
+	configPath := fs.String("config", "", "Signal config JSON the detector is run with.")
+	tolerance := fs.Int("tolerance", 0, "Bars a detection may be off its label and still match.")
+	reportPath := fs.String("report", "", "Write the evaluation report JSON here. Empty prints to stdout.")
+	_ = fs.Parse(args)
+
+	if *history == "" {
+		return fmt.Errorf("--history is required")
+	}
+	cfg, err := signal.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	series, err := datasource.LoadCandleFiles(*history)
+	if err != nil {
+		return err
+	}
+	files, err := research.LoadLabelFiles(*labels)
+	if err != nil {
+		return err
+	}
+	res, err := research.EvaluateLabels(series, files, cfg, *tolerance)
+	if err != nil {
+		return err
+	}
+	return writeJSON(*reportPath, res)
+}
+
+// loadSamples builds training samples from every series in the history.
+func loadSamples(history string, cfg signal.Config, horizon int) ([]signal.TrainingSample, error) {
+	if history == "" {
+		return nil, fmt.Errorf("--history is required")
+	}
+	series, err := datasource.LoadCandleFiles(history)
+	if err != nil {
+		return nil, err
+	}
+	symbols := make([]string, 0, len(series))
+	for s := range series {
+		symbols = append(symbols, s)
+	}
+	sort.Strings(symbols)
+	samples := make([]signal.TrainingSample, 0)
+	for _, s := range symbols {
+		samples = append(samples, signal.BuildTrainingSamples(s, series[s], cfg, horizon)...)
+	}
+	return samples, nil
+}
+
+// writeJSON writes v indented to path, or to stdout when path is empty.
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func exitf(format string, args ...any) {
+	_, _ = fmt.Fprintf(os.Stderr, "research: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -5,19 +5,15 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/LEVI-Tempest/Candle/pkg/datasource"
+	"github.com/LEVI-Tempest/Candle/pkg/identify"
 	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
 	"github.com/LEVI-Tempest/Candle/pkg/signal"
 )
 
-type candleInputEnvelope struct {
-	Symbol string            `json:"symbol"`
-	Source string            `json:"source"`
-	Data   []*v1.Candlestick `json:"data"`
-}
-
 func main() {
 	inputPath := flag.String("input", "", "Input JSON file path. Supports []Candlestick or {symbol,source,data}.")
 	outputPath := flag.String("output", "", "Output JSON file path. Empty prints to stdout.")
@@ -34,6 +30,19 @@ func main() {
 	ticker := flag.String("ticker", "300059", "Ticker code")
 	token := flag.String("token", "demo", "Tsanghi API token")
 	limit := flag.Int("limit", 120, "Number of candles to fetch")
+
+	diagnose := flag.String("diagnose", "", "Print detector diagnostics instead of a report: pattern names (comma separated) or \"all\" for near-misses.")
+	diagnoseFrom := flag.String("diagnose-from", "", "First date (YYYY-MM-DD) to diagnose. Empty means the first candle.")
+	diagnoseTo := flag.String("diagnose-to", "", "Last date (YYYY-MM-DD) to diagnose. Empty means the last candle.")
+	benchmarkPath := flag.String("benchmark", "", "Benchmark index JSON (e.g. CSI 300) for relative strength; same formats as --input.")
+	benchmarkSymbol := flag.String("benchmark-symbol", "", "Benchmark symbol for reporting. Empty uses the file's symbol or name.")
+	watchlist := flag.String("watchlist", "", "Comma-separated candle JSON files to rank relative strength against (needs --benchmark).")
+	historyPath := flag.String("history", "", "Comma-separated candle JSON files or directories of *.json to search for historical analogs of the latest window.")
+	calibrationPath := flag.String("calibration", "", "Calibration JSON from `research calibrate`; adds calibrated hit probabilities and expected returns to patterns.")
+	ticksPath := flag.String("ticks", "", "Trade ticks CSV or JSONL (timestamp, price, size) to aggregate into bars instead of --input.")
+	bars := flag.String("bars", "time:1m", "Bars built from --ticks: time:<duration> | tick:<n> | volume:<n> | dollar:<n>; <n>/day sets the threshold from the daily average.")
+	barsOutput := flag.String("bars-output", "", "Also write the bars built from --ticks as a candle JSON file (usable as --input or --history).")
+	heikinAshi := flag.Bool("heikin-ashi", false, "Detect on Heikin-Ashi bars and add HA flip/shadowless signals (recorded in report metadata).")
 	flag.Parse()
 
 	cfg, err := signal.LoadConfig(*configPath)
@@ -43,8 +52,17 @@ func main() {
 	if *logCSVPath != "" {
 		cfg.LogCSVPath = *logCSVPath
 	}
+	if *heikinAshi {
+		cfg.HeikinAshi = true
+	}
 
-	candles, source, detectedSymbol, err := loadCandles(*inputPath, *fetch, *exchange, *ticker, *token, *limit)
+	var candles []*v1.Candlestick
+	var source, detectedSymbol string
+	if *ticksPath != "" {
+		candles, source, err = loadTickBars(*ticksPath, *bars, *symbol, *barsOutput)
+	} else {
+		candles, source, detectedSymbol, err = loadCandles(*inputPath, *fetch, *exchange, *ticker, *token, *limit)
+	}
 	if err != nil {
 		exitf("load candles failed: %v", err)
 	}
@@ -55,7 +73,34 @@ func main() {
 		exitf("no candles available")
 	}
 
-	report := signal.BuildReport(*symbol, *asOf, source, candles, cfg)
+	if *diagnose != "" {
+		entries, err := diagnoseRange(candles, cfg.Detector, *diagnose, *diagnoseFrom, *diagnoseTo)
+		if err != nil {
+			exitf("diagnose failed: %v", err)
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			exitf("marshal diagnostics failed: %v", err)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	bench, err := loadBenchmark(*benchmarkPath, *benchmarkSymbol, *watchlist)
+	if err != nil {
+		exitf("load benchmark failed: %v", err)
+	}
+	history, err := datasource.LoadCandleFiles(*historyPath)
+	if err != nil {
+		exitf("load history failed: %v", err)
+	}
+	in := signal.ReportInputs{Benchmark: bench, History: history}
+	if *calibrationPath != "" {
+		if in.Calibration, err = signal.LoadCalibration(*calibrationPath); err != nil {
+			exitf("load calibration failed: %v", err)
+		}
+	}
+	report := signal.BuildReportWithInputs(*symbol, *asOf, source, candles, in, cfg)
 	if *validateSchema {
 		if err := signal.ValidateReportSchema(report, *schemaPath); err != nil {
 			exitf("schema validation failed: %v", err)
@@ -98,26 +143,123 @@ func loadCandles(
 	if inputPath == "" {
 		return nil, "", "", fmt.Errorf("either --input or --fetch is required")
 	}
+	return datasource.LoadCandleFile(inputPath)
+}
+
+// loadTickBars aggregates the trades in path into bars per spec and, when
+// output is set, writes them there as a candle file.
+// loadTickBars 按 spec 将 path 中的成交明细聚合为K线；指定 output 时另存为K线文件。
+func loadTickBars(path, spec, symbol, output string) ([]*v1.Candlestick, string, error) {
+	cfg, err := datasource.ParseBarSpec(spec)
+	if err != nil {
+		return nil, "", err
+	}
+	ticks, err := datasource.LoadTicks(path)
+	if err != nil {
+		return nil, "", err
+	}
+	candles, err := datasource.AggregateTicks(ticks, cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	source := "ticks:" + cfg.Type
+	if output != "" {
+		data, err := json.MarshalIndent(datasource.CandleFile{Symbol: symbol, Source: source, Data: candles}, "", "  ")
+		if err != nil {
+			return nil, "", err
+		}
+		if err := os.WriteFile(output, data, 0o644); err != nil {
+			return nil, "", err
+		}
+	}
+	return candles, source, nil
+}
 
-	raw, err := os.ReadFile(inputPath)
+// loadBenchmark reads the benchmark and watchlist files; it returns nil when
+// no benchmark is given. File symbols default to the base file name.
+// loadBenchmark 读取基准与观察列表文件；未指定基准时返回 nil。文件未带 symbol 时使用文件名。
+func loadBenchmark(path, symbol, watchlist string) (*signal.Benchmark, error) {
+	if path == "" {
+		if watchlist != "" {
+			return nil, fmt.Errorf("--watchlist requires --benchmark")
+		}
+		return nil, nil
+	}
+	candles, sym, err := datasource.LoadNamedCandleFile(path)
 	if err != nil {
-		return nil, "", "", err
+		return nil, err
 	}
+	if symbol == "" {
+		symbol = sym
+	}
+	bench := &signal.Benchmark{Symbol: symbol, Candles: candles}
+	for _, p := range strings.Split(watchlist, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		wc, ws, err := datasource.LoadNamedCandleFile(p)
+		if err != nil {
+			return nil, err
+		}
+		if bench.Watchlist == nil {
+			bench.Watchlist = make(map[string][]*v1.Candlestick)
+		}
+		bench.Watchlist[ws] = wc
+	}
+	return bench, nil
+}
+
+type diagnosticEntry struct {
+	Time     string `json:"time"`
+	Position int    `json:"position"`
+	identify.PatternDiagnosis
+}
 
-	var envelope candleInputEnvelope
-	if err := json.Unmarshal(raw, &envelope); err == nil && len(envelope.Data) > 0 {
-		src := envelope.Source
-		if src == "" {
-			src = "file"
+// diagnoseRange explains detector decisions for every candle between from and
+// to (inclusive dates). With "all", only hits and near-misses (at most one
+// failed condition) are kept.
+func diagnoseRange(candles []*v1.Candlestick, cfg identify.DetectorConfig, patterns, from, to string) ([]diagnosticEntry, error) {
+	names := identify.DiagnosablePatterns()
+	nearMissOnly := strings.EqualFold(patterns, "all")
+	if !nearMissOnly {
+		known := make(map[string]bool, len(names))
+		for _, name := range names {
+			known[name] = true
+		}
+		names = strings.Split(patterns, ",")
+		for i := range names {
+			names[i] = strings.TrimSpace(names[i])
+			if !known[names[i]] {
+				return nil, fmt.Errorf("unknown pattern %q", names[i])
+			}
 		}
-		return envelope.Data, src, envelope.Symbol, nil
 	}
 
-	var candles []*v1.Candlestick
-	if err := json.Unmarshal(raw, &candles); err != nil {
-		return nil, "", "", fmt.Errorf("input must be []Candlestick or {symbol,source,data}: %w", err)
+	cs := make([]identify.CandlestickWrapper, len(candles))
+	for i, c := range candles {
+		cs[i] = identify.NewCandlestickWrapper(c)
+	}
+	detector := identify.NewDetector(cfg)
+	window := identify.PatternSpan("Rising Three Methods") + detector.Config().ReferencePeriod
+
+	entries := make([]diagnosticEntry, 0)
+	for i, c := range cs {
+		day := time.Unix(c.Timestamp, 0).Format("2006-01-02")
+		if (from != "" && day < from) || (to != "" && day > to) {
+			continue
+		}
+		for _, name := range names {
+			diag, err := detector.Diagnose(name, identify.WindowAt(cs, i, window))
+			if err != nil {
+				continue // not enough candles before this position
+			}
+			if nearMissOnly && len(diag.Failed) > 1 {
+				continue
+			}
+			entries = append(entries, diagnosticEntry{Time: day, Position: i, PatternDiagnosis: diag})
+		}
 	}
-	return candles, "file", "", nil
+	return entries, nil
 }
 
 func exitf(format string, args ...any) {
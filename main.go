@@ -15,34 +15,62 @@ import (
 
 	"github.com/LEVI-Tempest/Candle/pkg/charting"
 	"github.com/LEVI-Tempest/Candle/pkg/datasource"
+	"github.com/LEVI-Tempest/Candle/pkg/identify"
 	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+	"github.com/LEVI-Tempest/Candle/pkg/synthetic"
 )
 
 func main() {
 	// CLI flags | 命令行参数
-	example := flag.String("example", "chart", "Demo: chart | fetch")
+	example := flag.String("example", "chart", "Demo: chart | fetch | synthetic | renko | kagi | linebreak | pnf")
 	output := flag.String("output", "candle_chart.html", "Output HTML filename")
 	// fetch 专用
 	exchange := flag.String("exchange", "XSHE", "Exchange: XSHE(深圳) | XSHG(上海)")
 	ticker := flag.String("ticker", "300059", "Stock ticker code")
 	token := flag.String("token", "demo", "Tsanghi API token")
 	limit := flag.Int("limit", 60, "Number of days to fetch")
+	chartConfig := flag.String("chart-config", "", "Chart config JSON: price overlays and indicator panels")
+	// price-only charts | 仅价格图表
+	box := flag.Float64("box", 0, "Renko/P&F box or Kagi reversal size; 0 uses ATR")
+	atrMultiple := flag.Float64("atr-multiple", 1, "ATR multiple for the box when -box is 0")
+	reversal := flag.Int("reversal", 0, "Reversal boxes (Renko 2, P&F 3) or line count (line break 3); 0 uses the default")
+	// synthetic 专用
+	seed := flag.Int64("seed", 1, "Random seed of the synthetic series")
+	heikinAshi := flag.String("heikin-ashi", "", "Heikin-Ashi mode: display | detect | both (overrides the chart config)")
 	flag.Parse()
 
+	cfg, err := charting.LoadChartConfig(*chartConfig)
+	if err != nil {
+		log.Fatalf("❌ Load chart config failed: %v", err)
+	}
+	if *heikinAshi != "" {
+		cfg.HeikinAshi = charting.HeikinAshiMode(*heikinAshi)
+		if err := charting.ValidateChartConfig(cfg); err != nil {
+			log.Fatalf("❌ Invalid -heikin-ashi: %v", err)
+		}
+	}
+
 	switch *example {
 	case "chart":
-		runChartDemo(*output)
+		runChartDemo(*output, cfg)
 	case "fetch":
-		runFetchDemo(*output, *exchange, *ticker, *token, *limit)
+		runFetchDemo(*output, *exchange, *ticker, *token, *limit, cfg)
+	case "synthetic":
+		runSyntheticDemo(*output, *seed, cfg)
+	case charting.PriceChartRenko, charting.PriceChartKagi, charting.PriceChartLineBreak, charting.PriceChartPointFigure:
+		runPriceChartDemo(*example, *output, charting.PriceChartOptions{
+			Box:      identify.BoxConfig{Size: *box, ATRPeriod: 14, ATRMultiple: *atrMultiple},
+			Reversal: *reversal,
+		})
 	default:
-		fmt.Fprintf(os.Stderr, "Unknown example: %s. Use: chart | fetch\n", *example)
+		fmt.Fprintf(os.Stderr, "Unknown example: %s. Use: chart | fetch | synthetic | renko | kagi | linebreak | pnf\n", *example)
 		os.Exit(1)
 	}
 }
 
 // runFetchDemo fetches data from Tsanghi API and generates chart
 // runFetchDemo 从 Tsanghi API 拉取数据并生成图表
-func runFetchDemo(outputFile, exchange, ticker, token string, limit int) {
+func runFetchDemo(outputFile, exchange, ticker, token string, limit int, chartConfig charting.ChartConfig) {
 	fmt.Println("🕯️  Candle - Fetch & Chart Demo")
 	fmt.Println("=================================")
 	fmt.Printf("Fetching %s %s (%d days)...\n", exchange, ticker, limit)
@@ -62,6 +90,7 @@ func runFetchDemo(outputFile, exchange, ticker, token string, limit int) {
 	fmt.Printf("📊 Fetched %d candlesticks\n", len(candles))
 
 	ek := charting.NewEnhancedKline()
+	ek.ChartConfig = chartConfig
 	ek.LoadData(candles)
 	ek.AutoDetectPatterns()
 	fmt.Printf("🔍 Detected %d patterns\n\n", len(ek.Patterns))
@@ -75,7 +104,7 @@ func runFetchDemo(outputFile, exchange, ticker, token string, limit int) {
 
 // runChartDemo creates a candlestick chart with pattern markers
 // 运行图表示例：生成带形态标记的蜡烛图
-func runChartDemo(outputFile string) {
+func runChartDemo(outputFile string, chartConfig charting.ChartConfig) {
 	fmt.Println("🕯️  Candle - Japanese Candlestick Chart Demo")
 	fmt.Println("=============================================")
 	fmt.Println()
@@ -86,6 +115,7 @@ func runChartDemo(outputFile string) {
 
 	// 2. Create and load enhanced kline chart | 创建并加载增强K线图
 	ek := charting.NewEnhancedKline()
+	ek.ChartConfig = chartConfig
 	ek.LoadData(candleData)
 
 	// 3. Auto-detect patterns | 自动检测形态
@@ -114,6 +144,87 @@ func runChartDemo(outputFile string) {
 	fmt.Println("📖 Usage: Open the HTML file in your browser to view the interactive chart.")
 }
 
+// runSyntheticDemo charts a generated GARCH series with regime switching and
+// checks each injected pattern against the detector
+// 运行合成数据示例：生成含状态切换的 GARCH 序列，并核对每个注入形态是否被识别
+func runSyntheticDemo(outputFile string, seed int64, chartConfig charting.ChartConfig) {
+	fmt.Println("🕯️  Candle - Synthetic Data Demo")
+	fmt.Println("=================================")
+
+	sc := synthetic.DefaultConfig()
+	sc.Bars = 160
+	sc.Model = synthetic.ModelGARCH
+	sc.Seed = seed
+	sc.Regimes = []synthetic.Regime{
+		{Name: "bull", Drift: 0.002, Volatility: 0.012},
+		{Name: "bear", Drift: -0.002, Volatility: 0.025, VolumeMultiple: 1.5},
+	}
+	sc.Injections = []synthetic.Injection{
+		{Position: 25, Pattern: "Hammer", VolumeMultiple: 2.5},
+		{Position: 50, Pattern: "Bearish Engulfing"},
+		{Position: 75, Pattern: "Morning Star", VolumeMultiple: 3},
+		{Position: 100, Pattern: "Shooting Star"},
+		{Position: 125, Pattern: "Three Black Crows"},
+		{Position: 150, Pattern: "Rising Three Methods"},
+	}
+	series, err := synthetic.Generate(sc)
+	if err != nil {
+		log.Fatalf("❌ Generate failed: %v", err)
+	}
+	fmt.Printf("📊 Generated %d candlesticks (seed %d)\n", len(series.Candles), seed)
+
+	ek := charting.NewEnhancedKline()
+	ek.ChartConfig = chartConfig
+	ek.LoadData(series.Candles)
+	ek.AutoDetectPatterns()
+	fmt.Printf("🔍 Detected %d patterns\n\n", len(ek.Patterns))
+
+	fmt.Println("🎯 Injected Patterns:")
+	fmt.Println("--------------------")
+	for _, t := range series.Truth {
+		mark := "❌ missed"
+		for _, p := range ek.Patterns {
+			if p.Type == t.Pattern && p.Position == t.Position {
+				mark = "✅ detected"
+				break
+			}
+		}
+		fmt.Printf("%s | Pos:%d | %s | regime:%s | %s\n", t.Pattern, t.Position, t.Time, series.Regimes[t.Position], mark)
+	}
+	fmt.Println()
+
+	ek.CreateChart("🕯️ Synthetic Series with Injected Patterns")
+	if err := ek.RenderToFile(outputFile); err != nil {
+		log.Fatalf("❌ Render failed: %v", err)
+	}
+	fmt.Printf("✅ Chart saved: %s\n", outputFile)
+}
+
+// runPriceChartDemo renders a Renko, Kagi, line-break or point-and-figure
+// chart of the demo data and prints its signals
+// runPriceChartDemo 基于示例数据绘制砖形图、卡吉图、新价线或点数图，并输出信号
+func runPriceChartDemo(kind, outputFile string, o charting.PriceChartOptions) {
+	candleData := createDemoData()
+	chart, signals, err := charting.BuildPriceChart(kind, candleData, o, fmt.Sprintf("🕯️ %s", kind))
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	fmt.Printf("🔍 %d %s signals\n", len(signals), kind)
+	for i, s := range signals {
+		fmt.Printf("%d. %s | Pos:%d | Price:%.2f | %s\n", i+1, s.Type, s.Position, s.Price, s.Time)
+	}
+
+	f, err := os.Create(outputFile)
+	if err != nil {
+		log.Fatalf("❌ Failed to create %s: %v", outputFile, err)
+	}
+	defer f.Close()
+	if err := chart.Render(f); err != nil {
+		log.Fatalf("❌ Failed to render chart: %v", err)
+	}
+	fmt.Printf("✅ Chart saved: %s\n", outputFile)
+}
+
 // createDemoData returns sample candlestick data with clear patterns
 // 创建包含明确形态的示例蜡烛数据
 func createDemoData() []*v1.Candlestick {
@@ -3,6 +3,7 @@ package charting
 import (
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"sort"
 	"strings"
@@ -384,8 +385,18 @@ type EnhancedKline struct {
 	VolumeSignals     []identify.VolumePriceSignal  // Volume-price signals (量价信号)
 	Evidences         []identify.PatternEvidence    // Structured pattern evidences (结构化证据)
 	Indicators        map[string][]float64          // Technical indicators (技术指标)
+	IndicatorSpecs    []identify.IndicatorSpec      // Indicators to compute (需计算的指标)
+	ChartConfig       ChartConfig                   // Overlays and indicator panels to draw (主图叠加与指标副图)
+	Profile           *identify.VolumeProfile       // Volume profile drawn by the last CreateChart (最近一次绘制的成交量分布)
+	Benchmark         []identify.CandlestickWrapper // Benchmark index for relative strength (相对强弱的基准指数)
 	TrendLines        []TrendLine                   // Trend lines (趋势线)
 	SupportResistance []Level                       // Support and resistance levels (支撑阻力位)
+	Pivots            []identify.SwingPoint         // Confirmed swing points (已确认摆动点)
+	PivotConfig       identify.PivotConfig          // Swing point detection config (摆动点识别配置)
+	LevelConfig       identify.LevelConfig          // Support/resistance detection config (支撑阻力识别配置)
+	TrendLineConfig   identify.TrendLineConfig      // Trendline fitting config (趋势线拟合配置)
+	DetectorConfig    identify.DetectorConfig       // Pattern detector thresholds (形态识别阈值)
+	EvidenceConfig    identify.EvidenceConfig       // Evidence and beiliang scoring config (证据与倍量评分配置)
 	TimeFrame         TimeFrame                     // Current time frame (当前时间周期)
 	Data              []identify.CandlestickWrapper // Candlestick data (蜡烛图数据)
 }
@@ -407,6 +418,8 @@ type TrendLine struct {
 	StartPoint Point     // Starting point (起始点)
 	EndPoint   Point     // Ending point (结束点)
 	Type       TrendType // Trend type: up/down (上升/下降)
+	Touches    int       // Number of touches (触及次数)
+	Broken     bool      // Whether a close went through the line (是否已被收盘突破)
 }
 
 // Level represents a support or resistance level
@@ -415,6 +428,8 @@ type Level struct {
 	Price    float64   // Price level (价格水平)
 	Type     LevelType // Level type: support/resistance (支撑/阻力)
 	Strength float64   // Level strength (强度)
+	Touches  int       // Number of touch episodes (触及次数)
+	Sources  []string  // Candidate sources: pivot/volume/gap (来源)
 }
 
 // NewKline creates a new Kline instance
@@ -436,8 +451,16 @@ func NewEnhancedKline() *EnhancedKline {
 		VolumeSignals:     make([]identify.VolumePriceSignal, 0),
 		Evidences:         make([]identify.PatternEvidence, 0),
 		Indicators:        make(map[string][]float64),
+		IndicatorSpecs:    identify.DefaultIndicatorSpecs(),
+		ChartConfig:       DefaultChartConfig(),
 		TrendLines:        make([]TrendLine, 0),
 		SupportResistance: make([]Level, 0),
+		Pivots:            make([]identify.SwingPoint, 0),
+		PivotConfig:       identify.DefaultPivotConfig(),
+		LevelConfig:       identify.DefaultLevelConfig(),
+		TrendLineConfig:   identify.DefaultTrendLineConfig(),
+		DetectorConfig:    identify.DefaultDetectorConfig(),
+		EvidenceConfig:    identify.DefaultEvidenceConfig(),
 		TimeFrame:         TimeFrame1Day,
 		Data:              make([]identify.CandlestickWrapper, 0),
 	}
@@ -452,384 +475,110 @@ func (ek *EnhancedKline) LoadData(candles []*v1.Candlestick) {
 	}
 }
 
+// LoadBenchmark loads a benchmark index (e.g. CSI 300); AutoDetectPatterns
+// then adds its RS line to Indicators.
+// LoadBenchmark 加载基准指数（如沪深300）；之后 AutoDetectPatterns 会将 RS 线写入 Indicators。
+func (ek *EnhancedKline) LoadBenchmark(candles []*v1.Candlestick) {
+	ek.Benchmark = make([]identify.CandlestickWrapper, len(candles))
+	for i, candle := range candles {
+		ek.Benchmark[i] = identify.NewCandlestickWrapper(candle)
+	}
+}
+
 // AutoDetectPatterns automatically detects candlestick patterns in the data
 // 自动识别K线形态
 func (ek *EnhancedKline) AutoDetectPatterns() {
-	patterns := make([]Pattern, 0)
-
-	// Single candlestick patterns (单根K线形态)
-	for i := 0; i < len(ek.Data); i++ {
-		candle := []identify.CandlestickWrapper{ek.Data[i]}
-		timestamp := time.Unix(ek.Data[i].Timestamp, 0).Format("2006-01-02 15:04:05")
-
-		// Check single candle patterns
-		if identify.Doji(candle) {
-			patterns = append(patterns, Pattern{
-				Type:     "Doji",
-				Position: i,
-				Strength: 0.7,
-				Risk:     0.5,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
+	detector := identify.NewDetector(ek.DetectorConfig)
+	scorer := identify.NewPatternScorer(identify.DefaultPatternConfig()).WithDetectorConfig(ek.DetectorConfig)
 
-		if identify.LongLeggedDoji(candle) {
-			patterns = append(patterns, Pattern{
-				Type:     "Long-Legged Doji",
-				Position: i,
-				Strength: 0.85,
-				Risk:     0.5,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-
-		if identify.Hammer(candle) {
-			patterns = append(patterns, Pattern{
-				Type:     "Hammer",
-				Position: i,
-				Strength: 0.8,
-				Risk:     0.3,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-
-		if identify.HangingMan(candle) {
-			patterns = append(patterns, Pattern{
-				Type:     "Hanging Man",
-				Position: i,
-				Strength: 0.8,
-				Risk:     0.7,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
+	// Detection runs on Heikin-Ashi bars in detect/both mode (detect/both 模式下基于平均K线识别)
+	data := ek.DetectionData()
 
-		if identify.InvertedHammer(candle) {
-			patterns = append(patterns, Pattern{
-				Type:     "Inverted Hammer",
-				Position: i,
-				Strength: 0.7,
-				Risk:     0.4,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-
-		if identify.ShootingStar(candle) {
-			patterns = append(patterns, Pattern{
-				Type:     "Shooting Star",
-				Position: i,
-				Strength: 0.8,
-				Risk:     0.6,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-
-		if identify.Marubozu(candle) {
-			patterns = append(patterns, Pattern{
-				Type:     "Marubozu",
-				Position: i,
-				Strength: 0.9,
-				Risk:     0.2,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-		if identify.WhiteMarubozu(candle) {
-			patterns = append(patterns, Pattern{
-				Type:     "White Marubozu",
-				Position: i,
-				Strength: 0.95,
-				Risk:     0.2,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-		if identify.BlackMarubozu(candle) {
-			patterns = append(patterns, Pattern{
-				Type:     "Black Marubozu",
-				Position: i,
-				Strength: 0.95,
-				Risk:     0.2,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-
-		if identify.SpinningTop(candle) {
-			patterns = append(patterns, Pattern{
-				Type:     "Spinning Top",
-				Position: i,
-				Strength: 0.5,
-				Risk:     0.8,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-
-		if identify.Umbrella(candle) {
-			patterns = append(patterns, Pattern{
-				Type:     "Umbrella",
-				Position: i,
-				Strength: 0.7,
-				Risk:     0.4,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-
-		if identify.DragonflyDoji(candle) {
-			patterns = append(patterns, Pattern{
-				Type:     "Dragonfly Doji",
-				Position: i,
-				Strength: 0.8,
-				Risk:     0.4,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-
-		if identify.GravestoneDoji(candle) {
-			patterns = append(patterns, Pattern{
-				Type:     "Gravestone Doji",
-				Position: i,
-				Strength: 0.8,
-				Risk:     0.5,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
+	// Single, double, triple and five candlestick patterns in turn (依次识别单根、双根、三根及五根K线形态)
+	signals := identify.ScanPatterns(data, detector, scorer)
+	if ek.ChartConfig.HeikinAshi != HeikinAshiOff {
+		signals = append(signals, identify.HeikinAshiSignals(identify.HeikinAshiWrappers(ek.Data), identify.DefaultHeikinAshiConfig())...)
 	}
-
-	// Two candlestick patterns (双根K线形态)
-	for i := 1; i < len(ek.Data); i++ {
-		candles := []identify.CandlestickWrapper{ek.Data[i], ek.Data[i-1]}
-		timestamp := time.Unix(ek.Data[i].Timestamp, 0).Format("2006-01-02 15:04:05")
-
-		if identify.BullishEngulfing(candles) {
-			patterns = append(patterns, Pattern{
-				Type:     "Bullish Engulfing",
-				Position: i,
-				Strength: 0.9,
-				Risk:     0.2,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-
-		if identify.BearishEngulfing(candles) {
-			patterns = append(patterns, Pattern{
-				Type:     "Bearish Engulfing",
-				Position: i,
-				Strength: 0.9,
-				Risk:     0.2,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-
-		if identify.PiercingLine(candles) {
-			patterns = append(patterns, Pattern{
-				Type:     "Piercing Line",
-				Position: i,
-				Strength: 0.8,
-				Risk:     0.3,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-
-		if identify.DarkCloudCover(candles) {
-			patterns = append(patterns, Pattern{
-				Type:     "Dark Cloud Cover",
-				Position: i,
-				Strength: 0.8,
-				Risk:     0.3,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-
-		if identify.TweezerBottoms(candles) {
-			patterns = append(patterns, Pattern{
-				Type:     "Tweezer Bottoms",
-				Position: i,
-				Strength: 0.7,
-				Risk:     0.4,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-
-		if identify.TweezerTops(candles) {
-			patterns = append(patterns, Pattern{
-				Type:     "Tweezer Tops",
-				Position: i,
-				Strength: 0.7,
-				Risk:     0.4,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-
-		if identify.FallingWindow(candles) {
-			patterns = append(patterns, Pattern{
-				Type:     "Falling Window",
-				Position: i,
-				Strength: 0.6,
-				Risk:     0.5,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-
-		if identify.RisingWindow(candles) {
-			patterns = append(patterns, Pattern{
-				Type:     "Rising Window",
-				Position: i,
-				Strength: 0.6,
-				Risk:     0.5,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-
-		if identify.BullishHarami(candles) {
-			patterns = append(patterns, Pattern{
-				Type:     "Bullish Harami",
-				Position: i,
-				Strength: 0.8,
-				Risk:     0.3,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-
-		if identify.BearishHarami(candles) {
-			patterns = append(patterns, Pattern{
-				Type:     "Bearish Harami",
-				Position: i,
-				Strength: 0.8,
-				Risk:     0.3,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
+	patterns := make([]Pattern, 0, len(signals))
+	for _, s := range signals {
+		patterns = append(patterns, Pattern{
+			Type:     s.Type,
+			Position: s.Position,
+			Strength: s.Strength,
+			Risk:     s.Risk,
+			Price:    s.Price,
+			Time:     s.Time,
+		})
 	}
 
-	// Three candlestick patterns (三根K线形态)
-	for i := 2; i < len(ek.Data); i++ {
-		candles := []identify.CandlestickWrapper{ek.Data[i], ek.Data[i-1], ek.Data[i-2]}
-		timestamp := time.Unix(ek.Data[i].Timestamp, 0).Format("2006-01-02 15:04:05")
-
-		if identify.MorningStar(candles) {
-			patterns = append(patterns, Pattern{
-				Type:     "Morning Star",
-				Position: i,
-				Strength: 0.9,
-				Risk:     0.1,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-
-		if identify.EveningStar(candles) {
-			patterns = append(patterns, Pattern{
-				Type:     "Evening Star",
-				Position: i,
-				Strength: 0.9,
-				Risk:     0.1,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-		if identify.MorningDojiStar(candles) {
-			patterns = append(patterns, Pattern{
-				Type:     "Morning Doji Star",
-				Position: i,
-				Strength: 0.98,
-				Risk:     0.1,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
-		if identify.EveningDojiStar(candles) {
-			patterns = append(patterns, Pattern{
-				Type:     "Evening Doji Star",
-				Position: i,
-				Strength: 0.98,
-				Risk:     0.1,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
-		}
+	ek.Patterns = patterns
+	// Swing points feed the ZigZag overlay and level/trendline detection
+	// 摆动点用于之字形叠加层及支撑阻力/趋势线识别
+	ek.Pivots = identify.DetectPivots(data, ek.PivotConfig)
+	ek.SupportResistance = toLevels(identify.DetectLevels(data, ek.LevelConfig))
+	ek.TrendLines = toTrendLines(identify.DetectTrendLines(data, ek.TrendLineConfig), len(data))
+	// Analyze volume-price signals after pattern detection
+	// 形态识别后补充量价信号分析
+	ek.VolumeSignals = identify.AnalyzeVolumePriceSignals(data, 5)
+	ek.VolumeSignals = append(ek.VolumeSignals, identify.BeiliangVolumeSignals(data,
+		identify.AnalyzeBeiliang(data, ek.EvidenceConfig))...)
+	ek.Indicators = identify.ComputeIndicators(data, ek.IndicatorSpecs)
+	if len(ek.Benchmark) > 0 {
+		ek.Indicators[identify.RelativeStrengthKey] = identify.RelativeStrengthLine(data, identify.AlignBenchmark(data, ek.Benchmark))
+	}
+	ek.Evidences = identify.BuildPatternEvidenceWithIndicators(
+		toPatternSignals(ek.Patterns),
+		data,
+		ek.EvidenceConfig,
+		ek.Indicators,
+	)
+}
 
-		if identify.ThreeWhiteSoldiers(candles) {
-			patterns = append(patterns, Pattern{
-				Type:     "Three White Soldiers",
-				Position: i,
-				Strength: 0.95,
-				Risk:     0.1,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
+func toLevels(levels []identify.PriceLevel) []Level {
+	out := make([]Level, 0, len(levels))
+	for _, l := range levels {
+		levelType := LevelTypeResistance
+		if l.Type == identify.LevelSupport {
+			levelType = LevelTypeSupport
 		}
-
-		if identify.ThreeBlackCrows(candles) {
-			patterns = append(patterns, Pattern{
-				Type:     "Three Black Crows",
-				Position: i,
-				Strength: 0.95,
-				Risk:     0.1,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
+		sources := make([]string, 0, len(l.Sources))
+		for _, src := range l.Sources {
+			sources = append(sources, string(src))
 		}
+		out = append(out, Level{
+			Price:    l.Price,
+			Type:     levelType,
+			Strength: l.Strength,
+			Touches:  l.Touches,
+			Sources:  sources,
+		})
 	}
+	return out
+}
 
-	// Five candlestick patterns (五根K线形态)
-	for i := 4; i < len(ek.Data); i++ {
-		candles := []identify.CandlestickWrapper{
-			ek.Data[i], ek.Data[i-1], ek.Data[i-2], ek.Data[i-3], ek.Data[i-4],
-		}
-		timestamp := time.Unix(ek.Data[i].Timestamp, 0).Format("2006-01-02 15:04:05")
-
-		if identify.RisingThreeMethods(candles) {
-			patterns = append(patterns, Pattern{
-				Type:     "Rising Three Methods",
-				Position: i,
-				Strength: 0.9,
-				Risk:     0.2,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
+// toTrendLines converts fitted lines into chart segments that run from the
+// first anchor to the break bar, or to the last bar while the line holds.
+// toTrendLines 将拟合的趋势线转换为图表线段：从首个锚点延伸至突破K线，未突破时延伸至最后一根K线。
+func toTrendLines(lines []identify.TrendLineFit, n int) []TrendLine {
+	out := make([]TrendLine, 0, len(lines))
+	for _, l := range lines {
+		end := n - 1
+		if l.Broken {
+			end = l.BreakPosition
 		}
-
-		if identify.FallingThreeMethods(candles) {
-			patterns = append(patterns, Pattern{
-				Type:     "Falling Three Methods",
-				Position: i,
-				Strength: 0.9,
-				Risk:     0.2,
-				Price:    ek.Data[i].Close,
-				Time:     timestamp,
-			})
+		trendType := TrendTypeUp
+		if l.Type == identify.TrendLineDown {
+			trendType = TrendTypeDown
 		}
+		out = append(out, TrendLine{
+			StartPoint: Point{X: float64(l.StartPosition), Y: l.StartPrice},
+			EndPoint:   Point{X: float64(end), Y: l.PriceAt(end)},
+			Type:       trendType,
+			Touches:    l.Touches,
+			Broken:     l.Broken,
+		})
 	}
-
-	ek.Patterns = patterns
-	// Analyze volume-price signals after pattern detection
-	// 形态识别后补充量价信号分析
-	ek.VolumeSignals = identify.AnalyzeVolumePriceSignals(ek.Data, 5)
-	ek.Evidences = identify.BuildPatternEvidence(
-		toPatternSignals(ek.Patterns),
-		ek.Data,
-		identify.DefaultEvidenceConfig(),
-	)
+	return out
 }
 
 func toPatternSignals(patterns []Pattern) []identify.PatternSignal {
@@ -837,7 +586,7 @@ func toPatternSignals(patterns []Pattern) []identify.PatternSignal {
 	for _, p := range patterns {
 		out = append(out, identify.PatternSignal{
 			Type:      p.Type,
-			Direction: patternDirection(p.Type),
+			Direction: identify.PatternDirection(p.Type),
 			Position:  p.Position,
 			Strength:  p.Strength,
 			Risk:      p.Risk,
@@ -848,33 +597,6 @@ func toPatternSignals(patterns []Pattern) []identify.PatternSignal {
 	return out
 }
 
-func patternDirection(patternType string) string {
-	switch patternType {
-	case "Hammer",
-		"Inverted Hammer",
-		"Bullish Engulfing",
-		"Piercing Line",
-		"Morning Star",
-		"Three White Soldiers",
-		"Tweezer Bottoms",
-		"Rising Window",
-		"Rising Three Methods":
-		return "bullish"
-	case "Hanging Man",
-		"Shooting Star",
-		"Bearish Engulfing",
-		"Dark Cloud Cover",
-		"Evening Star",
-		"Three Black Crows",
-		"Tweezer Tops",
-		"Falling Window",
-		"Falling Three Methods":
-		return "bearish"
-	default:
-		return "neutral"
-	}
-}
-
 // MarkPatterns marks detected patterns on the chart
 // 在图表上标记检测到的形态
 func (ek *EnhancedKline) MarkPatterns() {
@@ -884,7 +606,7 @@ func (ek *EnhancedKline) MarkPatterns() {
 
 	// Create precise coordinate mark points (limit to top 10 for readability)
 	// 为检测到的形态创建精确坐标标记，最多 10 个以减轻拥挤
-	displayPatterns := getDisplayPatterns(ek.Patterns, 0.85)
+	displayPatterns := getDisplayPatterns(ek.Patterns, displayMinStrength)
 	if len(displayPatterns) > 10 {
 		displayPatterns = displayPatterns[:10]
 	}
@@ -1136,6 +858,10 @@ func getPatternShortName(patternType string) string {
 	}
 }
 
+// displayMinStrength is the graded strength a pattern needs to be drawn on the chart.
+// displayMinStrength 是形态在图上显示所需的最低评分。
+const displayMinStrength = 0.85
+
 // getDisplayPatterns keeps one strongest signal per candle for readability.
 // getDisplayPatterns 为每根K线只保留一个最强信号，提升可读性。
 func getDisplayPatterns(patterns []Pattern, minStrength float64) []Pattern {
@@ -1172,10 +898,12 @@ func (ek *EnhancedKline) CreateChart(title string) {
 	volMA5 := make([]opts.LineData, len(ek.Data))
 	volMA10 := make([]opts.LineData, len(ek.Data))
 
+	display := ek.displayData()
 	for i, candle := range ek.Data {
 		x[i] = time.Unix(candle.Timestamp, 0).Format("2006-01-02")
+		body := display[i]
 		y[i] = opts.KlineData{
-			Value: []interface{}{candle.Open, candle.Close, candle.Low, candle.High},
+			Value: []interface{}{body.Open, body.Close, body.Low, body.High},
 		}
 		volumeColor := "#ec0000"
 		if candle.Close >= candle.Open {
@@ -1215,14 +943,65 @@ func (ek *EnhancedKline) CreateChart(title string) {
 
 	// Configure chart options
 	// 配置图表选项
-	displayPatterns := getDisplayPatterns(ek.Patterns, 0.85)
+	displayPatterns := getDisplayPatterns(ek.Patterns, displayMinStrength)
 	if len(displayPatterns) > 10 {
 		displayPatterns = displayPatterns[:10]
 	}
+	overlayKeys := ek.overlayKeys()
+	panels := make([]panelSeries, 0, len(ek.ChartConfig.Panels))
+	for _, p := range ek.ChartConfig.Panels {
+		if panel, ok := ek.buildPanel(p, len(panels)+2, x); ok {
+			panels = append(panels, panel)
+		}
+	}
+	sidebar, hasProfile := ek.buildProfile(len(panels)+2, x)
+	grids := chartLayout(len(panels))
+	gridIndexes := make([]int, len(panels)+2)
+	legend := append([]string{"Volume", "VOL MA5", "VOL MA10", "Swing", "Support", "Resistance", "Trendlines"}, overlayKeys...)
+	for g := range gridIndexes {
+		gridIndexes[g] = g
+		if g < 2 {
+			continue
+		}
+		legend = append(legend, panels[g-2].legend...)
+		ek.Kline.ExtendXAxis(opts.XAxis{
+			Type:      "category",
+			GridIndex: g,
+			AxisLabel: &opts.AxisLabel{Show: opts.Bool(false)},
+			AxisTick:  &opts.AxisTick{Show: opts.Bool(false)},
+		})
+		ek.Kline.ExtendYAxis(opts.YAxis{
+			Scale:       opts.Bool(true),
+			GridIndex:   g,
+			Name:        panels[g-2].name,
+			SplitNumber: 2,
+			AxisLabel:   &opts.AxisLabel{Show: opts.Bool(true)},
+		})
+	}
+	if hasProfile {
+		// Sidebar axes: volume along x, price bins along y
+		// 侧栏坐标轴：x 为成交量，y 为价格档位
+		grids = withProfileGrid(grids)
+		legend = append(legend, sidebar.legend...)
+		ek.Kline.ExtendXAxis(opts.XAxis{
+			Type:      "value",
+			GridIndex: len(grids) - 1,
+			AxisLabel: &opts.AxisLabel{Show: opts.Bool(false)},
+			SplitLine: &opts.SplitLine{Show: opts.Bool(false)},
+		})
+		ek.Kline.ExtendYAxis(opts.YAxis{
+			Type:      "category",
+			GridIndex: len(grids) - 1,
+			Name:      "Profile",
+			Position:  "right",
+			Data:      sidebar.labels,
+			AxisLabel: &opts.AxisLabel{Show: opts.Bool(true)},
+		})
+	}
 	ek.Kline.SetGlobalOptions(
 		charts.WithTitleOpts(opts.Title{
 			Title:    title,
-			Subtitle: fmt.Sprintf("Detected %d patterns, showing %d | 点击图例可切换 Volume/VOL MA5/VOL MA10", len(ek.Patterns), len(displayPatterns)),
+			Subtitle: fmt.Sprintf("Detected %d patterns, showing %d | 点击图例可切换 Volume/VOL MA5/VOL MA10/Swing/Support/Resistance/Trendlines", len(ek.Patterns), len(displayPatterns)),
 		}),
 		charts.WithXAxisOpts(opts.XAxis{
 			SplitNumber: 20,
@@ -1232,33 +1011,18 @@ func (ek *EnhancedKline) CreateChart(title string) {
 			Scale:     opts.Bool(true),
 			GridIndex: 0,
 		}, 0),
-		charts.WithGridOpts(
-			opts.Grid{
-				Left:         "8%",
-				Right:        "8%",
-				Top:          "10%",
-				Height:       "58%",
-				ContainLabel: opts.Bool(true),
-			},
-			opts.Grid{
-				Left:         "8%",
-				Right:        "8%",
-				Top:          "74%",
-				Height:       "16%",
-				ContainLabel: opts.Bool(true),
-			},
-		),
+		charts.WithGridOpts(grids...),
 		charts.WithDataZoomOpts(opts.DataZoom{
 			Type:       "slider",
 			Start:      0,
 			End:        100,
-			XAxisIndex: []int{0, 1},
+			XAxisIndex: gridIndexes,
 		}),
 		charts.WithDataZoomOpts(opts.DataZoom{
 			Type:       "inside",
 			Start:      0,
 			End:        100,
-			XAxisIndex: []int{0, 1},
+			XAxisIndex: gridIndexes,
 		}),
 		charts.WithTooltipOpts(opts.Tooltip{
 			Show:    opts.Bool(true),
@@ -1267,7 +1031,7 @@ func (ek *EnhancedKline) CreateChart(title string) {
 		charts.WithLegendOpts(opts.Legend{
 			Show:         opts.Bool(true),
 			SelectedMode: "multiple",
-			Data:         []string{"Volume", "VOL MA5", "VOL MA10"},
+			Data:         legend,
 			Top:          "3%",
 			Right:        "3%",
 		}),
@@ -1311,6 +1075,174 @@ func (ek *EnhancedKline) CreateChart(title string) {
 			charts.WithLineStyleOpts(opts.LineStyle{Width: 1}),
 		)
 	ek.Kline.Overlap(volLine)
+
+	// Overlay price-scale indicators (moving averages, Bollinger bands) on the price panel
+	// 在价格面板叠加与价格同坐标的指标（均线、布林带）
+	if len(overlayKeys) > 0 {
+		indicatorLine := charts.NewLine()
+		indicatorLine.SetXAxis(x)
+		for _, key := range overlayKeys {
+			indicatorLine.AddSeries(key, indicatorLineData(ek.Indicators[key]),
+				charts.WithLineChartOpts(opts.LineChart{XAxisIndex: 0, YAxisIndex: 0, Symbol: "none"}),
+				charts.WithLineStyleOpts(opts.LineStyle{Width: 1}),
+			)
+		}
+		ek.Kline.Overlap(indicatorLine)
+	}
+
+	// Stack configured indicator panels below the volume panel
+	// 在成交量面板下方叠放配置的指标副图
+	for _, panel := range panels {
+		ek.Kline.Overlap(panel.charts...)
+	}
+	if hasProfile {
+		ek.Kline.Overlap(sidebar.charts...)
+	}
+
+	// Overlay confirmed swing points as a ZigZag line on the price panel
+	// 在价格面板叠加已确认摆动点连成的之字形线
+	if len(ek.Pivots) > 0 {
+		swingLine := charts.NewLine()
+		swingLine.SetXAxis(x).AddSeries("Swing", pivotLineData(ek.Pivots, len(ek.Data)),
+			charts.WithLineChartOpts(opts.LineChart{
+				XAxisIndex:   0,
+				YAxisIndex:   0,
+				ConnectNulls: opts.Bool(true),
+				Symbol:       "circle",
+				SymbolSize:   5,
+			}),
+			charts.WithLineStyleOpts(opts.LineStyle{Width: 1, Color: "#5470c6", Type: "dashed"}),
+		)
+		ek.Kline.Overlap(swingLine)
+	}
+
+	// Draw support/resistance levels as horizontal mark lines on the price panel
+	// 在价格面板以水平标记线绘制支撑/阻力位
+	for _, group := range []struct {
+		name      string
+		levelType LevelType
+		color     string
+	}{
+		{"Support", LevelTypeSupport, "#00a86b"},
+		{"Resistance", LevelTypeResistance, "#d9534f"},
+	} {
+		items := levelMarkLines(ek.SupportResistance, group.levelType)
+		if len(items) == 0 {
+			continue
+		}
+		levelLine := charts.NewLine()
+		levelLine.SetXAxis(x).AddSeries(group.name, emptyLineData(len(ek.Data)),
+			charts.WithLineChartOpts(opts.LineChart{XAxisIndex: 0, YAxisIndex: 0}),
+			charts.WithMarkLineNameYAxisItemOpts(items...),
+			charts.WithMarkLineStyleOpts(opts.MarkLineStyle{
+				Symbol:    []string{"none", "none"},
+				LineStyle: &opts.LineStyle{Color: group.color, Type: "dashed", Width: 1},
+				Label: &opts.Label{
+					Show:      opts.Bool(true),
+					Position:  "insideEndTop",
+					Formatter: "{b}",
+					Color:     group.color,
+				},
+			}),
+		)
+		ek.Kline.Overlap(levelLine)
+	}
+
+	// Draw fitted trendlines as sloped mark line segments on the price panel
+	// 在价格面板以倾斜标记线段绘制拟合的趋势线
+	if items := trendLineMarkLines(ek.TrendLines, x); len(items) > 0 {
+		trendLine := charts.NewLine()
+		trendLine.SetXAxis(x).AddSeries("Trendlines", emptyLineData(len(ek.Data)),
+			charts.WithLineChartOpts(opts.LineChart{XAxisIndex: 0, YAxisIndex: 0}),
+			charts.WithMarkLineNameCoordItemOpts(items...),
+			charts.WithMarkLineStyleOpts(opts.MarkLineStyle{
+				Symbol:    []string{"none", "none"},
+				LineStyle: &opts.LineStyle{Color: "#9a60b4", Width: 1.5},
+				Label: &opts.Label{
+					Show:      opts.Bool(true),
+					Position:  "end",
+					Formatter: "{b}",
+					Color:     "#9a60b4",
+				},
+			}),
+		)
+		ek.Kline.Overlap(trendLine)
+	}
+}
+
+// trendLineMarkLines converts trendlines into [date, price] coordinate pairs.
+// trendLineMarkLines 将趋势线转换为 [日期, 价格] 坐标对。
+func trendLineMarkLines(lines []TrendLine, x []string) []opts.MarkLineNameCoordItem {
+	items := make([]opts.MarkLineNameCoordItem, 0, len(lines))
+	for _, l := range lines {
+		start, end := int(l.StartPoint.X), int(l.EndPoint.X)
+		if start < 0 || end >= len(x) || start >= end {
+			continue
+		}
+		name := fmt.Sprintf("%s x%d", strings.ToUpper(string(l.Type)), l.Touches)
+		if l.Broken {
+			name += " broken"
+		}
+		items = append(items, opts.MarkLineNameCoordItem{
+			Name:        name,
+			Coordinate0: []interface{}{x[start], l.StartPoint.Y},
+			Coordinate1: []interface{}{x[end], l.EndPoint.Y},
+		})
+	}
+	return items
+}
+
+// levelMarkLines converts levels of one type into labelled horizontal mark lines.
+// levelMarkLines 将某一类型的价位转换为带标签的水平标记线。
+func levelMarkLines(levels []Level, levelType LevelType) []opts.MarkLineNameYAxisItem {
+	items := make([]opts.MarkLineNameYAxisItem, 0, len(levels))
+	for _, l := range levels {
+		if l.Type != levelType {
+			continue
+		}
+		items = append(items, opts.MarkLineNameYAxisItem{
+			Name:  fmt.Sprintf("%s %.2f x%d (%.0f)", strings.ToUpper(string(levelType)[:1]), l.Price, l.Touches, l.Strength*100),
+			YAxis: l.Price,
+		})
+	}
+	return items
+}
+
+// indicatorLineData converts an indicator series, leaving warm-up (NaN) points empty.
+// indicatorLineData 转换指标序列，预热期（NaN）留空。
+func indicatorLineData(series []float64) []opts.LineData {
+	out := make([]opts.LineData, len(series))
+	for i, v := range series {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			out[i] = opts.LineData{Value: "-"}
+			continue
+		}
+		out[i] = opts.LineData{Value: v}
+	}
+	return out
+}
+
+// emptyLineData returns placeholder points for carrier series that only hold marks.
+// emptyLineData 返回仅承载标记线的占位序列数据。
+func emptyLineData(n int) []opts.LineData {
+	out := make([]opts.LineData, n)
+	for i := range out {
+		out[i] = opts.LineData{Value: "-"}
+	}
+	return out
+}
+
+// pivotLineData places each swing price at its bar and leaves other bars empty.
+// pivotLineData 将摆动点价格放在对应K线上，其余K线留空。
+func pivotLineData(pivots []identify.SwingPoint, n int) []opts.LineData {
+	out := emptyLineData(n)
+	for _, p := range pivots {
+		if p.Position < 0 || p.Position >= n {
+			continue
+		}
+		out[p.Position] = opts.LineData{Value: p.Price}
+	}
+	return out
 }
 
 // RenderToFile renders the chart to an HTML file
@@ -1,6 +1,10 @@
 package charting
 
 import (
+	"bytes"
+	"math"
+	"math/rand"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -72,6 +76,17 @@ func TestEnhancedKlineWithPatternRecognition(t *testing.T) {
 	}
 }
 
+func TestAutoDetectPatternsUsesEvidenceConfig(t *testing.T) {
+	ek := NewEnhancedKline()
+	ek.LoadData(createTestCandlestickData())
+	ek.EvidenceConfig.BaseWeight, ek.EvidenceConfig.ContextWeight, ek.EvidenceConfig.VolumeWeight = 1, 0, 0
+	ek.AutoDetectPatterns()
+	want := identify.BuildPatternEvidenceWithIndicators(toPatternSignals(ek.Patterns), ek.Data, ek.EvidenceConfig, ek.Indicators)
+	if len(ek.Evidences) == 0 || !reflect.DeepEqual(ek.Evidences, want) {
+		t.Fatalf("evidence should follow EvidenceConfig:\n%+v\nwant\n%+v", ek.Evidences, want)
+	}
+}
+
 func TestPatternColorAndSymbol(t *testing.T) {
 	// Test pattern color assignment
 	// 测试形态颜色分配
@@ -124,6 +139,184 @@ func TestFormatPatternLabelIncludesScoreAndReasons(t *testing.T) {
 	}
 }
 
+func TestCreateChartIndicatorPanels(t *testing.T) {
+	baseTime := time.Now().AddDate(0, 0, -80)
+	candles := make([]*v1.Candlestick, 0, 80)
+	price := 100.0
+	for i := 0; i < 80; i++ {
+		next := price + float64(i%7) - 3
+		candles = append(candles, &v1.Candlestick{
+			Timestamp: baseTime.AddDate(0, 0, i).Unix(),
+			Open:      price,
+			High:      max(price, next) + 1,
+			Low:       min(price, next) - 1,
+			Close:     next,
+			Volume:    float64(1000 + i*10),
+		})
+		price = next
+	}
+
+	ek := NewEnhancedKline()
+	ek.LoadData(candles)
+	ek.AutoDetectPatterns()
+	ek.ChartConfig.Panels = []PanelSpec{{Type: PanelMACD}, {Type: PanelRSI}, {Type: PanelCMF, Params: []float64{10}}}
+	ek.CreateChart("panels")
+
+	var buf bytes.Buffer
+	if err := ek.Kline.Render(&buf); err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	html := buf.String()
+	for _, want := range []string{"MA20", "BOLL20_2.UPPER", "MACD12_26_9.HIST", "MACD12_26_9.DIF", "RSI14", "CMF10", `"gridIndex":4`} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("chart missing %s", want)
+		}
+	}
+	if len(ek.Kline.XAxisList) != 5 {
+		t.Fatalf("expected 5 x axes (price, volume, 3 panels), got %d", len(ek.Kline.XAxisList))
+	}
+}
+
+func TestCreateChartVolumeProfileAndVWAP(t *testing.T) {
+	ek := NewEnhancedKline()
+	ek.LoadData(createTestCandlestickData())
+	ek.AutoDetectPatterns()
+	ek.ChartConfig.Profile = &identify.VolumeProfileConfig{Bins: 12}
+	ek.ChartConfig.SessionVWAP = true
+	ek.ChartConfig.SessionTimezone = "Asia/Shanghai"
+	ek.ChartConfig.AnchoredVWAP = []int{2}
+	ek.CreateChart("profile")
+
+	if ek.Profile == nil || len(ek.Profile.Volumes) != 12 {
+		t.Fatalf("expected a 12-bin profile, got %+v", ek.Profile)
+	}
+	var buf bytes.Buffer
+	if err := ek.Kline.Render(&buf); err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	html := buf.String()
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Fatalf("load zone: %v", err)
+	}
+	anchor := "AVWAP." + time.Unix(ek.Data[2].Timestamp, 0).In(shanghai).Format("2006-01-02")
+	for _, want := range []string{"Volume Profile", `"POC"`, `"VAH"`, `"VAL"`, "VWAP.SESSION", anchor, `"gridIndex":2`} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("chart missing %s", want)
+		}
+	}
+	if len(ek.Kline.XAxisList) != 3 || len(ek.Kline.YAxisList) != 3 {
+		t.Fatalf("expected price, volume and profile axes, got %d x / %d y", len(ek.Kline.XAxisList), len(ek.Kline.YAxisList))
+	}
+	if err := ValidateChartConfig(ChartConfig{SessionTimezone: "Mars/Olympus"}); err == nil {
+		t.Fatal("expected an error for an unknown session timezone")
+	}
+}
+
+func TestCreateChartRelativeStrengthPanel(t *testing.T) {
+	data := createTestCandlestickData()
+	ek := NewEnhancedKline()
+	ek.LoadData(data)
+	ek.ChartConfig.Panels = []PanelSpec{{Type: PanelRS}}
+	ek.AutoDetectPatterns()
+	ek.CreateChart("no benchmark")
+	if len(ek.Kline.YAxisList) != 2 {
+		t.Fatalf("rs panel should be skipped without a benchmark, got %d y axes", len(ek.Kline.YAxisList))
+	}
+
+	bench := make([]*v1.Candlestick, len(data))
+	for i, c := range data {
+		bench[i] = &v1.Candlestick{Timestamp: c.Timestamp, Open: 100, High: 101, Low: 99, Close: 100}
+	}
+	ek = NewEnhancedKline()
+	ek.LoadData(data)
+	ek.LoadBenchmark(bench)
+	ek.ChartConfig.Panels = []PanelSpec{{Type: PanelRS}}
+	ek.AutoDetectPatterns()
+	if rs := ek.Indicators[identify.RelativeStrengthKey]; len(rs) != len(data) || rs[0] != 1 {
+		t.Fatalf("expected an RS line starting at 1, got %v", rs)
+	}
+	ek.CreateChart("rs")
+	var buf bytes.Buffer
+	if err := ek.Kline.Render(&buf); err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"name":"RS"`) || len(ek.Kline.YAxisList) != 3 {
+		t.Fatalf("expected an RS panel, got %d y axes", len(ek.Kline.YAxisList))
+	}
+}
+
+func TestHeikinAshiModes(t *testing.T) {
+	data := createTestCandlestickData()
+	ha := identify.HeikinAshi(data)
+
+	ek := NewEnhancedKline()
+	ek.LoadData(data)
+	ek.ChartConfig.HeikinAshi = HeikinAshiDisplay
+	ek.AutoDetectPatterns()
+	if ek.DetectionData()[3].Close != data[3].Close {
+		t.Fatal("display mode should detect on raw candles")
+	}
+	if got := ek.displayData()[3]; got.Open != ha[3].Open || got.Close != ha[3].Close {
+		t.Fatalf("display mode should draw HA bars, got %+v", got)
+	}
+
+	ek.ChartConfig.HeikinAshi = HeikinAshiDetect
+	ek.AutoDetectPatterns()
+	if ek.DetectionData()[3].Close != ha[3].Close || ek.displayData()[3].Close != data[3].Close {
+		t.Fatal("detect mode should detect on HA bars and draw raw candles")
+	}
+	if ek.Data[3].Close != data[3].Close {
+		t.Fatal("loaded candles must stay raw")
+	}
+
+	if err := ValidateChartConfig(ChartConfig{HeikinAshi: "sometimes"}); err == nil {
+		t.Fatal("expected an error for an unknown heikin_ashi mode")
+	}
+}
+
+func TestBuildPriceCharts(t *testing.T) {
+	data := createTestCandlestickData()
+	for _, kind := range PriceChartTypes() {
+		chart, _, err := BuildPriceChart(kind, data, PriceChartOptions{Box: identify.BoxConfig{Size: 1}}, kind)
+		if err != nil {
+			t.Fatalf("%s: %v", kind, err)
+		}
+		var buf bytes.Buffer
+		if err := chart.Render(&buf); err != nil {
+			t.Fatalf("%s: render failed: %v", kind, err)
+		}
+		if !strings.Contains(buf.String(), kind) {
+			t.Fatalf("%s: chart missing its title", kind)
+		}
+	}
+	if _, _, err := BuildPriceChart("heikin", data, PriceChartOptions{}, ""); err == nil {
+		t.Fatal("expected an error for an unknown chart type")
+	}
+}
+
+func TestChartLayoutAndConfig(t *testing.T) {
+	for n := 0; n <= 4; n++ {
+		grids := chartLayout(n)
+		if len(grids) != n+2 {
+			t.Fatalf("expected %d grids, got %d", n+2, len(grids))
+		}
+	}
+	if grids := chartLayout(0); grids[0].Height != "60.8%" {
+		t.Fatalf("price grid should keep most of the height, got %s", grids[0].Height)
+	}
+
+	if err := ValidateChartConfig(ChartConfig{Panels: []PanelSpec{{Type: "macd", Params: []float64{8, 21, 5}}, {Type: "obv"}}}); err != nil {
+		t.Fatalf("valid config rejected: %v", err)
+	}
+	if err := ValidateChartConfig(ChartConfig{Overlays: []identify.IndicatorSpec{{Name: "rsi"}}}); err == nil {
+		t.Fatal("rsi is not a price overlay")
+	}
+	if err := ValidateChartConfig(ChartConfig{Panels: []PanelSpec{{Type: "volume_profile"}}}); err == nil {
+		t.Fatal("expected unknown panel error")
+	}
+}
+
 // createTestCandlestickData creates test data with various candlestick patterns
 // 创建包含各种蜡烛图形态的测试数据
 func createTestCandlestickData() []*v1.Candlestick {
@@ -180,3 +373,70 @@ func createTestCandlestickData() []*v1.Candlestick {
 		{Timestamp: baseTime.AddDate(0, 0, 20).Unix(), Open: 141, High: 145, Low: 139, Close: 143, Volume: 1500},
 	}
 }
+
+// TestStreamMatchesAutoDetectPatterns checks the stream engine against the
+// chart pipeline the reports use, directions included.
+// TestStreamMatchesAutoDetectPatterns 以报告所用的图表流程校验增量引擎，包括形态方向。
+func TestStreamMatchesAutoDetectPatterns(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	candles := make([]*v1.Candlestick, 0, 160)
+	price := 20.0
+	for i := 0; i < 160; i++ {
+		open := price * (1 + rng.NormFloat64()*0.01)
+		closep := open * (1 + rng.NormFloat64()*0.02)
+		if rng.Intn(8) == 0 {
+			closep = open * (1 + rng.NormFloat64()*0.001)
+		}
+		candles = append(candles, &v1.Candlestick{
+			Timestamp: int64(1700000000 + i*86400),
+			Open:      open,
+			High:      math.Max(open, closep) * (1 + rng.Float64()*0.015),
+			Low:       math.Min(open, closep) * (1 - rng.Float64()*0.015),
+			Close:     closep,
+			Volume:    1000 + rng.Float64()*2000,
+		})
+		price = closep
+	}
+	cfg := identify.DefaultStreamConfig()
+	engine, err := identify.NewStreamEngine(cfg)
+	if err != nil {
+		t.Fatalf("new engine: %v", err)
+	}
+
+	hits, neutral := 0, 0
+	for n := 1; n <= len(candles); n++ {
+		update, err := engine.Push(identify.NewCandlestickWrapper(candles[n-1]))
+		if err != nil {
+			t.Fatalf("push %d: %v", n-1, err)
+		}
+		ek := NewEnhancedKline()
+		ek.DetectorConfig = cfg.Detector
+		ek.EvidenceConfig = cfg.Evidence
+		ek.IndicatorSpecs = cfg.Indicators
+		ek.LoadData(candles[:n])
+		ek.AutoDetectPatterns()
+		var want []identify.PatternEvidence
+		for _, ev := range ek.Evidences {
+			if ev.Position == n-1 {
+				want = append(want, ev)
+			}
+		}
+		if len(update.Evidence) != len(want) {
+			t.Fatalf("bar %d: stream evidence %+v, chart %+v", n-1, update.Evidence, want)
+		}
+		for k, exp := range want {
+			got := update.Evidence[k]
+			if got.PatternType != exp.PatternType || got.Direction != exp.Direction || update.Patterns[k].Direction != exp.Direction ||
+				math.Abs(got.FinalScore-exp.FinalScore) > 1e-9 || len(got.ContextFactors) != len(exp.ContextFactors) {
+				t.Fatalf("bar %d: stream %+v, chart %+v", n-1, got, exp)
+			}
+			if exp.Direction == "neutral" {
+				neutral++
+			}
+			hits++
+		}
+	}
+	if hits == 0 || neutral == 0 {
+		t.Fatalf("expected directional and neutral patterns, got %d patterns, %d neutral", hits, neutral)
+	}
+}
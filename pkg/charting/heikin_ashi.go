@@ -0,0 +1,34 @@
+package charting
+
+import "github.com/LEVI-Tempest/Candle/pkg/identify"
+
+// HeikinAshiMode selects where Heikin-Ashi bars replace the raw candles.
+// HeikinAshiMode 选择在何处以平均K线替代原始K线。
+type HeikinAshiMode string
+
+const (
+	HeikinAshiOff     HeikinAshiMode = ""        // Raw candles only (仅原始K线)
+	HeikinAshiDisplay HeikinAshiMode = "display" // Draw HA bars, detect on raw candles (绘制平均K线，基于原始K线识别)
+	HeikinAshiDetect  HeikinAshiMode = "detect"  // Draw raw candles, detect on HA bars (绘制原始K线，基于平均K线识别)
+	HeikinAshiBoth    HeikinAshiMode = "both"    // Draw and detect on HA bars (绘制与识别均使用平均K线)
+)
+
+// DetectionData returns the bars patterns, levels and evidence are computed
+// on: Heikin-Ashi bars in detect/both mode, otherwise the loaded candles.
+// DetectionData 返回用于形态、支撑阻力与证据计算的K线：detect/both 模式下为平均K线，否则为原始K线。
+func (ek *EnhancedKline) DetectionData() []identify.CandlestickWrapper {
+	switch ek.ChartConfig.HeikinAshi {
+	case HeikinAshiDetect, HeikinAshiBoth:
+		return identify.HeikinAshiWrappers(ek.Data)
+	}
+	return ek.Data
+}
+
+// displayData returns the bars drawn as candle bodies.
+func (ek *EnhancedKline) displayData() []identify.CandlestickWrapper {
+	switch ek.ChartConfig.HeikinAshi {
+	case HeikinAshiDisplay, HeikinAshiBoth:
+		return identify.HeikinAshiWrappers(ek.Data)
+	}
+	return ek.Data
+}
@@ -0,0 +1,311 @@
+package charting
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/LEVI-Tempest/Candle/pkg/identify"
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+// Panel types stacked below the volume panel.
+// 成交量面板下方可叠放的副图类型。
+const (
+	PanelMACD = "macd" // DIF/DEA lines and histogram, params as identify macd (MACD 线与柱)
+	PanelRSI  = "rsi"  // RSI with 30/70 bands (RSI 及 30/70 区间线)
+	PanelKDJ  = "kdj"  // K/D/J lines, params as identify kdj
+	PanelOBV  = "obv"  // On-Balance Volume (能量潮)
+	PanelMFI  = "mfi"  // Money Flow Index with 20/80 bands, params [period] (资金流量指数)
+	PanelCMF  = "cmf"  // Chaikin Money Flow with zero line, params [period] (蔡金资金流)
+	PanelRS   = "rs"   // Relative strength vs the loaded benchmark with a 1.0 line (相对基准强弱)
+)
+
+// PanelSpec requests one indicator sub-panel.
+// PanelSpec 请求一个指标副图。
+type PanelSpec struct {
+	Type   string    `json:"type"`
+	Params []float64 `json:"params,omitempty"`
+}
+
+// ChartConfig decides what CreateChart draws besides candles and volume:
+// price overlays on the main grid and indicator panels below volume.
+// ChartConfig 决定 CreateChart 在K线与成交量之外绘制的内容：主图叠加指标与成交量下方的指标副图。
+type ChartConfig struct {
+	// Overlays are price-scale indicators (ma/ema/wma/dema/tema/boll).
+	// Overlays 为与价格同坐标的指标（ma/ema/wma/dema/tema/boll）。
+	Overlays []identify.IndicatorSpec `json:"overlays"`
+	// Panels are stacked below the volume panel in order.
+	// Panels 按顺序叠放在成交量面板下方。
+	Panels []PanelSpec `json:"panels"`
+	// Profile draws a volume-at-price sidebar over the last Window bars
+	// (all bars when Window is 0); nil disables it.
+	// Profile 在右侧绘制最近 Window 根K线（为 0 时为全部）的成交量价格分布；为空时不绘制。
+	Profile *identify.VolumeProfileConfig `json:"profile,omitempty"`
+	// SessionVWAP adds a VWAP that restarts every trading day.
+	// SessionVWAP 叠加按交易日重新累计的 VWAP。
+	SessionVWAP bool `json:"session_vwap"`
+	// SessionTimezone is the IANA zone, e.g. "Asia/Shanghai", whose calendar
+	// days the session VWAP restarts on; empty means UTC.
+	// SessionTimezone 为分时段 VWAP 划分自然日所用的 IANA 时区（如 "Asia/Shanghai"）；为空时为 UTC。
+	SessionTimezone string `json:"session_timezone,omitempty"`
+	// AnchoredVWAP lists bar indexes to anchor VWAP lines at; AnchorLastPattern
+	// also anchors one at the latest detected pattern.
+	// AnchoredVWAP 为锚定 VWAP 的K线序号；AnchorLastPattern 为 true 时另在最近形态处锚定一条。
+	AnchoredVWAP      []int `json:"anchored_vwap,omitempty"`
+	AnchorLastPattern bool  `json:"anchor_last_pattern"`
+	// HeikinAshi draws and/or detects on Heikin-Ashi bars (display, detect or
+	// both) and adds HA flip and shadowless-bar signals; empty keeps raw candles.
+	// HeikinAshi 在平均K线上绘制和/或识别（display、detect 或 both），并加入平均K线翻转与无影线信号；为空时使用原始K线。
+	HeikinAshi HeikinAshiMode `json:"heikin_ashi,omitempty"`
+}
+
+// DefaultChartConfig returns MA5/10/20 and Bollinger overlays without extra panels.
+// DefaultChartConfig 返回 MA5/10/20 与布林带主图叠加，不含额外副图。
+func DefaultChartConfig() ChartConfig {
+	overlays := make([]identify.IndicatorSpec, 0)
+	for _, spec := range identify.DefaultIndicatorSpecs() {
+		if identify.IndicatorOverlaysPrice(spec.Name) {
+			overlays = append(overlays, spec)
+		}
+	}
+	return ChartConfig{Overlays: overlays}
+}
+
+// LoadChartConfig reads a ChartConfig JSON file; an empty path returns the defaults.
+// LoadChartConfig 读取 ChartConfig JSON 文件；路径为空时返回默认配置。
+func LoadChartConfig(path string) (ChartConfig, error) {
+	if path == "" {
+		return DefaultChartConfig(), nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return ChartConfig{}, err
+	}
+	var cfg ChartConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return ChartConfig{}, err
+	}
+	return cfg, ValidateChartConfig(cfg)
+}
+
+// ValidateChartConfig checks overlay and panel names and params.
+// ValidateChartConfig 检查主图叠加与副图的名称和参数。
+func ValidateChartConfig(cfg ChartConfig) error {
+	for i, spec := range cfg.Overlays {
+		if !identify.IndicatorOverlaysPrice(spec.Name) {
+			return fmt.Errorf("overlays[%d]: %q is not a price overlay", i, spec.Name)
+		}
+		if err := identify.ValidateIndicatorSpec(spec); err != nil {
+			return fmt.Errorf("overlays[%d]: %v", i, err)
+		}
+	}
+	for i, p := range cfg.Panels {
+		switch strings.ToLower(p.Type) {
+		case PanelMACD, PanelRSI, PanelKDJ:
+			if err := identify.ValidateIndicatorSpec(identify.IndicatorSpec{Name: p.Type, Params: p.Params}); err != nil {
+				return fmt.Errorf("panels[%d]: %v", i, err)
+			}
+		case PanelOBV, PanelRS:
+		case PanelMFI, PanelCMF:
+			if len(p.Params) > 1 || (len(p.Params) == 1 && p.Params[0] < 2) {
+				return fmt.Errorf("panels[%d]: %s takes one period >= 2", i, p.Type)
+			}
+		default:
+			return fmt.Errorf("panels[%d]: unknown panel type %q", i, p.Type)
+		}
+	}
+	if p := cfg.Profile; p != nil && (p.Window < 0 || p.Bins < 0 || p.ValueArea < 0 || p.ValueArea > 1) {
+		return fmt.Errorf("profile: window and bins must be >= 0 and value_area within [0,1]")
+	}
+	if _, err := time.LoadLocation(cfg.SessionTimezone); err != nil {
+		return fmt.Errorf("session_timezone: %v", err)
+	}
+	for i, a := range cfg.AnchoredVWAP {
+		if a < 0 {
+			return fmt.Errorf("anchored_vwap[%d]: bar index must be >= 0", i)
+		}
+	}
+	switch cfg.HeikinAshi {
+	case HeikinAshiOff, HeikinAshiDisplay, HeikinAshiDetect, HeikinAshiBoth:
+	default:
+		return fmt.Errorf("heikin_ashi must be display, detect or both")
+	}
+	return nil
+}
+
+// chartLayout returns the grids for price, volume and n panels: price gets
+// four shares of the height and every other grid one share.
+// chartLayout 返回价格、成交量及 n 个副图的网格：价格占四份高度，其余各占一份。
+func chartLayout(n int) []opts.Grid {
+	const top, bottom, gap = 10.0, 90.0, 4.0
+	grids := n + 2
+	share := (bottom - top - gap*float64(grids-1)) / float64(grids+3)
+	out := make([]opts.Grid, 0, grids)
+	y := top
+	for g := 0; g < grids; g++ {
+		h := share
+		if g == 0 {
+			h = 4 * share
+		}
+		out = append(out, opts.Grid{
+			Left:         "8%",
+			Right:        "8%",
+			Top:          fmt.Sprintf("%.1f%%", y),
+			Height:       fmt.Sprintf("%.1f%%", h),
+			ContainLabel: opts.Bool(true),
+		})
+		y += h + gap
+	}
+	return out
+}
+
+// panelSeries is one rendered panel: its axis name, series and legend entries.
+type panelSeries struct {
+	name   string
+	charts []charts.Overlaper
+	legend []string
+}
+
+// buildPanel renders a panel on grid g. ok is false when the panel has no data.
+// buildPanel 在第 g 个网格上绘制副图；无数据时 ok 为 false。
+func (ek *EnhancedKline) buildPanel(p PanelSpec, g int, x []string) (panelSeries, bool) {
+	axis := charts.WithLineChartOpts(opts.LineChart{XAxisIndex: g, YAxisIndex: g, Symbol: "none"})
+	width := charts.WithLineStyleOpts(opts.LineStyle{Width: 1})
+	line := charts.NewLine()
+	line.SetXAxis(x)
+	out := panelSeries{}
+
+	addLines := func(keys []string, bands []float64) {
+		for k, key := range keys {
+			options := []charts.SeriesOpts{axis, width}
+			if k == 0 && len(bands) > 0 {
+				options = append(options, bandMarkLines(bands)...)
+			}
+			line.AddSeries(key, indicatorLineData(ek.Indicators[key]), options...)
+			out.legend = append(out.legend, key)
+		}
+	}
+
+	switch strings.ToLower(p.Type) {
+	case PanelMACD, PanelRSI, PanelKDJ:
+		spec := identify.IndicatorSpec{Name: strings.ToLower(p.Type), Params: p.Params}
+		keys := ek.ensureIndicator(spec)
+		if len(keys) == 0 {
+			return out, false
+		}
+		out.name = strings.ToUpper(spec.Name)
+		switch spec.Name {
+		case PanelMACD:
+			hist := charts.NewBar()
+			hist.SetXAxis(x).AddSeries(keys[2], histogramBarData(ek.Indicators[keys[2]]),
+				charts.WithBarChartOpts(opts.BarChart{XAxisIndex: g, YAxisIndex: g}),
+			)
+			out.charts = append(out.charts, hist)
+			out.legend = append(out.legend, keys[2])
+			addLines(keys[:2], nil)
+		case PanelRSI:
+			addLines(keys, []float64{30, 70})
+		default:
+			addLines(keys, []float64{20, 80})
+		}
+	case PanelOBV, PanelMFI, PanelCMF:
+		mfiPeriod, cmfPeriod := 14, 20
+		if len(p.Params) == 1 {
+			mfiPeriod, cmfPeriod = int(p.Params[0]), int(p.Params[0])
+		}
+		vol := identify.ComputeVolumeIndicators(ek.Data, mfiPeriod, cmfPeriod)
+		switch strings.ToLower(p.Type) {
+		case PanelOBV:
+			out.name = "OBV"
+			ek.Indicators[out.name] = vol.OBV
+			addLines([]string{out.name}, nil)
+		case PanelMFI:
+			out.name = fmt.Sprintf("MFI%d", mfiPeriod)
+			ek.Indicators[out.name] = vol.MFI
+			addLines([]string{out.name}, []float64{20, 80})
+		default:
+			out.name = fmt.Sprintf("CMF%d", cmfPeriod)
+			ek.Indicators[out.name] = vol.CMF
+			addLines([]string{out.name}, []float64{0})
+		}
+	case PanelRS:
+		if _, ok := ek.Indicators[identify.RelativeStrengthKey]; !ok {
+			return out, false
+		}
+		out.name = identify.RelativeStrengthKey
+		addLines([]string{out.name}, []float64{1})
+	default:
+		return out, false
+	}
+	out.charts = append(out.charts, line)
+	return out, true
+}
+
+// ensureIndicator returns the keys of spec, computing them into ek.Indicators when missing.
+// ensureIndicator 返回 spec 对应的键，缺失时计算并写入 ek.Indicators。
+func (ek *EnhancedKline) ensureIndicator(spec identify.IndicatorSpec) []string {
+	if identify.ValidateIndicatorSpec(spec) != nil {
+		return nil
+	}
+	keys := spec.Keys()
+	if _, ok := ek.Indicators[keys[0]]; !ok {
+		for key, series := range identify.ComputeIndicators(ek.Data, []identify.IndicatorSpec{spec}) {
+			ek.Indicators[key] = series
+		}
+	}
+	return keys
+}
+
+// overlayKeys returns the configured price overlay series, computing missing ones.
+// overlayKeys 返回配置的主图叠加序列，缺失时补算。
+func (ek *EnhancedKline) overlayKeys() []string {
+	keys := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, spec := range ek.ChartConfig.Overlays {
+		if !identify.IndicatorOverlaysPrice(spec.Name) {
+			continue
+		}
+		for _, key := range ek.ensureIndicator(spec) {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return append(keys, ek.vwapKeys()...)
+}
+
+// bandMarkLines draws horizontal reference lines such as RSI 30/70.
+// bandMarkLines 绘制 RSI 30/70 等水平参考线。
+func bandMarkLines(bands []float64) []charts.SeriesOpts {
+	items := make([]opts.MarkLineNameYAxisItem, 0, len(bands))
+	for _, b := range bands {
+		items = append(items, opts.MarkLineNameYAxisItem{Name: fmt.Sprintf("%g", b), YAxis: b})
+	}
+	return []charts.SeriesOpts{
+		charts.WithMarkLineNameYAxisItemOpts(items...),
+		charts.WithMarkLineStyleOpts(opts.MarkLineStyle{
+			Symbol:    []string{"none", "none"},
+			LineStyle: &opts.LineStyle{Color: "#999999", Type: "dashed", Width: 1},
+			Label:     &opts.Label{Show: opts.Bool(true), Position: "insideEndTop", Formatter: "{b}"},
+		}),
+	}
+}
+
+// histogramBarData colors positive bars green and negative bars red, leaving warm-up empty.
+// histogramBarData 将正值柱标绿、负值柱标红，预热期留空。
+func histogramBarData(series []float64) []opts.BarData {
+	out := make([]opts.BarData, len(series))
+	for i, p := range indicatorLineData(series) {
+		color := "#ec0000"
+		if v, ok := p.Value.(float64); ok && v >= 0 {
+			color = "#00da3c"
+		}
+		out[i] = opts.BarData{Value: p.Value, ItemStyle: &opts.ItemStyle{Color: color}}
+	}
+	return out
+}
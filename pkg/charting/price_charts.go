@@ -0,0 +1,266 @@
+package charting
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/LEVI-Tempest/Candle/pkg/identify"
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/go-echarts/go-echarts/v2/render"
+)
+
+// Price-only chart types
+// 仅基于价格的图表类型
+const (
+	PriceChartRenko       = "renko"     // Renko bricks (砖形图)
+	PriceChartKagi        = "kagi"      // Kagi lines (卡吉图)
+	PriceChartLineBreak   = "linebreak" // Three-line break (新价线/三线反转)
+	PriceChartPointFigure = "pnf"       // Point and figure (点数图)
+)
+
+// PriceChartTypes lists the supported price-only chart types.
+// PriceChartTypes 列出支持的仅价格图表类型。
+func PriceChartTypes() []string {
+	return []string{PriceChartRenko, PriceChartKagi, PriceChartLineBreak, PriceChartPointFigure}
+}
+
+// PriceChartOptions parameterizes the price-only charts.
+// PriceChartOptions 为仅价格图表的参数。
+type PriceChartOptions struct {
+	// Box is the Renko brick / P&F box / Kagi reversal amount, fixed or ATR-based.
+	// Box 为砖块、点数图格值或 Kagi 反转幅度，可固定或基于 ATR。
+	Box identify.BoxConfig `json:"box"`
+	// Reversal is the reversal in boxes for Renko (default 2) and P&F
+	// (default 3), and the line count for line break (default 3).
+	// Reversal 为 Renko（默认 2）与点数图（默认 3）的反转格数，以及新价线的线数（默认 3）。
+	Reversal int `json:"reversal"`
+}
+
+// BuildPriceChart converts candles into the given chart type, renders it and
+// returns the chart with its reversal/breakout signals.
+// BuildPriceChart 将K线转换为指定类型的图表并绘制，返回图表及其反转/突破信号。
+func BuildPriceChart(kind string, candles []*v1.Candlestick, o PriceChartOptions, title string) (render.Renderer, []identify.PatternSignal, error) {
+	cs := make([]identify.CandlestickWrapper, len(candles))
+	for i, c := range candles {
+		cs[i] = identify.NewCandlestickWrapper(c)
+	}
+	switch strings.ToLower(kind) {
+	case PriceChartRenko:
+		chart, sigs := RenkoChart(cs, identify.RenkoConfig{Box: o.Box, Reversal: o.Reversal}, title)
+		return chart, sigs, nil
+	case PriceChartKagi:
+		chart, sigs := KagiChart(cs, identify.KagiConfig{Reversal: o.Box}, title)
+		return chart, sigs, nil
+	case PriceChartLineBreak:
+		chart, sigs := LineBreakChart(cs, o.Reversal, title)
+		return chart, sigs, nil
+	case PriceChartPointFigure:
+		chart, sigs := PointFigureChart(cs, identify.PointFigureConfig{Box: o.Box, Reversal: o.Reversal}, title)
+		return chart, sigs, nil
+	}
+	return nil, nil, fmt.Errorf("unknown price chart %q (want %s)", kind, strings.Join(PriceChartTypes(), ", "))
+}
+
+// RenkoChart draws Renko bricks as candles, one per brick, with reversals marked.
+// RenkoChart 以每块砖一根K线的方式绘制砖形图，并标记反转。
+func RenkoChart(cs []identify.CandlestickWrapper, cfg identify.RenkoConfig, title string) (*charts.Kline, []identify.PatternSignal) {
+	bricks, box := identify.Renko(cs, cfg)
+	bars := make([]priceBar, len(bricks))
+	for k, b := range bricks {
+		bars[k] = priceBar{open: b.Open, close: b.Close, position: b.Position}
+	}
+	sigs := identify.RenkoSignals(cs, bricks)
+	return priceBarChart(cs, bars, sigs, fmt.Sprintf("%s (box %.2f)", title, box)), sigs
+}
+
+// LineBreakChart draws a line-break chart (three-line break by default) as
+// candles, one per line, with reversal lines marked.
+// LineBreakChart 以每根线一根K线的方式绘制新价线图（默认三线反转），并标记反转线。
+func LineBreakChart(cs []identify.CandlestickWrapper, lines int, title string) (*charts.Kline, []identify.PatternSignal) {
+	out := identify.LineBreak(cs, lines)
+	bars := make([]priceBar, len(out))
+	for k, l := range out {
+		bars[k] = priceBar{open: l.Open, close: l.Close, position: l.Position}
+	}
+	sigs := identify.LineBreakSignals(cs, out)
+	return priceBarChart(cs, bars, sigs, title), sigs
+}
+
+// priceBar is one Renko brick or line-break line drawn as a candle body.
+type priceBar struct {
+	open, close float64
+	position    int
+}
+
+// priceBarChart draws bars on a category axis labelled by source date and
+// marks each signal on the first bar drawn from its source bar.
+func priceBarChart(cs []identify.CandlestickWrapper, bars []priceBar, sigs []identify.PatternSignal, title string) *charts.Kline {
+	x := make([]string, len(bars))
+	y := make([]opts.KlineData, len(bars))
+	first := make(map[int]int)
+	for k, b := range bars {
+		x[k] = fmt.Sprintf("%d %s", k+1, time.Unix(cs[b.position].Timestamp, 0).Format("2006-01-02"))
+		y[k] = opts.KlineData{Value: []interface{}{b.open, b.close, math.Min(b.open, b.close), math.Max(b.open, b.close)}}
+		if _, ok := first[b.position]; !ok {
+			first[b.position] = k
+		}
+	}
+	marks := make([]opts.MarkPointNameCoordItem, 0, len(sigs))
+	for _, s := range sigs {
+		k, ok := first[s.Position]
+		if !ok {
+			continue
+		}
+		marks = append(marks, signalMark(s, x[k]))
+	}
+
+	kline := charts.NewKLine()
+	kline.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: title}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true), Trigger: "axis"}),
+		charts.WithYAxisOpts(opts.YAxis{Scale: opts.Bool(true)}),
+		charts.WithDataZoomOpts(opts.DataZoom{Type: "inside", Start: 0, End: 100}),
+	)
+	kline.SetXAxis(x).AddSeries(title, y, charts.WithItemStyleOpts(opts.ItemStyle{
+		Color: "#ec0000", Color0: "#00da3c", BorderColor: "#8A0000", BorderColor0: "#008F28",
+	}))
+	if len(marks) > 0 {
+		kline.SetSeriesOptions(charts.WithMarkPointNameCoordItemOpts(marks...))
+	}
+	return kline
+}
+
+// KagiChart draws Kagi lines on value axes: thick red yang and thin green yin
+// segments, with thickness turns marked.
+// KagiChart 在数值坐标上绘制卡吉图：粗红线为阳、细绿线为阴，并标记粗细转折。
+func KagiChart(cs []identify.CandlestickWrapper, cfg identify.KagiConfig, title string) (*charts.Line, []identify.PatternSignal) {
+	segs, r := identify.Kagi(cs, cfg)
+	yang := make([]opts.LineData, 0)
+	yin := make([]opts.LineData, 0)
+	lastYang, lastYin := -2, -2
+	for k, s := range segs {
+		points := []opts.LineData{
+			{Value: []interface{}{k, s.Start}},
+			{Value: []interface{}{k, s.End}},
+		}
+		if k+1 < len(segs) {
+			points = append(points, opts.LineData{Value: []interface{}{k + 1, s.End}})
+		}
+		// A "-" point breaks the line where the other thickness was drawn
+		// "-" 点在另一种粗细的线段处断开折线
+		if s.Yang {
+			if lastYang >= 0 && lastYang != k-1 {
+				yang = append(yang, opts.LineData{Value: []interface{}{k, "-"}})
+			}
+			yang, lastYang = append(yang, points...), k
+		} else {
+			if lastYin >= 0 && lastYin != k-1 {
+				yin = append(yin, opts.LineData{Value: []interface{}{k, "-"}})
+			}
+			yin, lastYin = append(yin, points...), k
+		}
+	}
+
+	sigs := identify.KagiSignals(cs, segs)
+	marks := make([]opts.MarkPointNameCoordItem, 0, len(sigs))
+	for _, s := range sigs {
+		for k, seg := range segs {
+			if seg.TurnPosition == s.Position {
+				marks = append(marks, signalMark(s, k))
+				break
+			}
+		}
+	}
+
+	line := charts.NewLine()
+	line.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: fmt.Sprintf("%s (reversal %.2f)", title, r)}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true), Trigger: "axis"}),
+		charts.WithXAxisOpts(opts.XAxis{Type: "value", Name: "line"}),
+		charts.WithYAxisOpts(opts.YAxis{Scale: opts.Bool(true)}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true)}),
+	)
+	line.AddSeries("Yang", yang,
+		charts.WithLineChartOpts(opts.LineChart{Symbol: "none"}),
+		charts.WithLineStyleOpts(opts.LineStyle{Width: 4, Color: "#ec0000"}),
+		charts.WithMarkPointNameCoordItemOpts(marks...),
+	)
+	line.AddSeries("Yin", yin,
+		charts.WithLineChartOpts(opts.LineChart{Symbol: "none"}),
+		charts.WithLineStyleOpts(opts.LineStyle{Width: 1.5, Color: "#00da3c"}),
+	)
+	return line, sigs
+}
+
+// pfCross is a filled X shape for point-and-figure boxes.
+const pfCross = "path://M2,0L5,3L8,0L10,2L7,5L10,8L8,10L5,7L2,10L0,8L3,5L0,2Z"
+
+// PointFigureChart draws point-and-figure columns of Xs and Os on value axes,
+// with double-top breakouts and double-bottom breakdowns marked.
+// PointFigureChart 在数值坐标上绘制 X/O 点数图列，并标记双顶突破与双底跌破。
+func PointFigureChart(cs []identify.CandlestickWrapper, cfg identify.PointFigureConfig, title string) (*charts.Scatter, []identify.PatternSignal) {
+	cols, box := identify.PointFigure(cs, cfg)
+	xBoxes := make([]opts.ScatterData, 0)
+	oBoxes := make([]opts.ScatterData, 0)
+	for k, col := range cols {
+		for b := 0; b < col.Boxes(box); b++ {
+			point := opts.ScatterData{Value: []interface{}{k, col.Low + float64(b)*box}}
+			if col.Direction == "X" {
+				xBoxes = append(xBoxes, point)
+			} else {
+				oBoxes = append(oBoxes, point)
+			}
+		}
+	}
+
+	sigs := identify.PointFigureSignals(cs, cols)
+	marks := make([]opts.MarkPointNameCoordItem, 0, len(sigs))
+	for _, s := range sigs {
+		for k, col := range cols {
+			if col.BreakPosition == s.Position {
+				marks = append(marks, signalMark(s, k))
+				break
+			}
+		}
+	}
+
+	scatter := charts.NewScatter()
+	scatter.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: fmt.Sprintf("%s (box %.2f, %d-box reversal)", title, box, max(cfg.Reversal, 1))}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true)}),
+		charts.WithXAxisOpts(opts.XAxis{Type: "value", Name: "column", MinInterval: 1}),
+		charts.WithYAxisOpts(opts.YAxis{Scale: opts.Bool(true)}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true)}),
+	)
+	scatter.AddSeries("X", xBoxes,
+		charts.WithScatterChartOpts(opts.ScatterChart{Symbol: pfCross, SymbolSize: 10}),
+		charts.WithItemStyleOpts(opts.ItemStyle{Color: "#ec0000"}),
+		charts.WithMarkPointNameCoordItemOpts(marks...),
+	)
+	scatter.AddSeries("O", oBoxes,
+		charts.WithScatterChartOpts(opts.ScatterChart{Symbol: "emptyCircle", SymbolSize: 10}),
+		charts.WithItemStyleOpts(opts.ItemStyle{Color: "#00da3c"}),
+	)
+	return scatter, sigs
+}
+
+// signalMark places a signal marker at x and the signal price, colored like
+// the pattern markers (green bullish, red bearish).
+func signalMark(s identify.PatternSignal, x interface{}) opts.MarkPointNameCoordItem {
+	color, symbol := "#00da3c", "triangle"
+	if s.Direction == "bearish" {
+		color, symbol = "#ec0000", "triangleDown"
+	}
+	return opts.MarkPointNameCoordItem{
+		Name:       s.Type,
+		Coordinate: []interface{}{x, s.Price},
+		Value:      s.Type,
+		Symbol:     symbol,
+		SymbolSize: 14,
+		ItemStyle:  &opts.ItemStyle{Color: color},
+	}
+}
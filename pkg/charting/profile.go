@@ -0,0 +1,144 @@
+package charting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/LEVI-Tempest/Candle/pkg/identify"
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+// profileSidebar is the rendered volume profile: the histogram on its own
+// grid beside the price grid and the POC/VAH/VAL lines on the price grid.
+type profileSidebar struct {
+	labels []string
+	charts []charts.Overlaper
+	legend []string
+}
+
+// buildProfile renders the volume profile of the last Window bars on grid g.
+// ok is false when the profile is disabled or has no data.
+// buildProfile 在第 g 个网格上绘制最近 Window 根K线的成交量分布；未启用或无数据时 ok 为 false。
+func (ek *EnhancedKline) buildProfile(g int, x []string) (profileSidebar, bool) {
+	out := profileSidebar{}
+	if ek.ChartConfig.Profile == nil {
+		return out, false
+	}
+	cfg := *ek.ChartConfig.Profile
+	if cfg.Window < 2 || cfg.Window > len(ek.Data) {
+		cfg.Window = len(ek.Data)
+	}
+	if cfg.Bins < 2 {
+		cfg.Bins = identify.DefaultVolumeProfileConfig().Bins
+	}
+	if cfg.ValueArea <= 0 || cfg.ValueArea > 1 {
+		cfg.ValueArea = identify.DefaultVolumeProfileConfig().ValueArea
+	}
+	start := len(ek.Data) - cfg.Window
+	vp, ok := identify.ComputeVolumeProfile(ek.Data[start:], cfg.Bins, cfg.ValueArea)
+	if !ok {
+		return out, false
+	}
+	ek.Profile = &vp
+
+	// Histogram bars: POC highlighted, value area darker than the tails
+	// 直方图：POC 高亮，价值区颜色深于两端
+	bars := make([]opts.BarData, len(vp.Volumes))
+	for b, v := range vp.Volumes {
+		out.labels = append(out.labels, fmt.Sprintf("%.2f", vp.BinPrice(b)))
+		color := "#c8cdd6"
+		switch {
+		case b == vp.POCIndex:
+			color = "#ee6666"
+		case b >= vp.VALIndex && b <= vp.VAHIndex:
+			color = "#5470c6"
+		}
+		bars[b] = opts.BarData{Value: v, ItemStyle: &opts.ItemStyle{Color: color}}
+	}
+	hist := charts.NewBar()
+	hist.AddSeries("Volume Profile", bars,
+		charts.WithBarChartOpts(opts.BarChart{XAxisIndex: g, YAxisIndex: g, BarCategoryGap: "10%"}),
+	)
+	out.charts = append(out.charts, hist)
+	out.legend = append(out.legend, "Volume Profile")
+
+	// Profile levels across the profiled bars
+	// 在统计区间内绘制 POC/VAH/VAL 水平线
+	levels := charts.NewLine()
+	levels.SetXAxis(x)
+	for _, level := range []struct {
+		name  string
+		price float64
+		color string
+	}{
+		{"POC", vp.POC, "#ee6666"},
+		{"VAH", vp.VAH, "#5470c6"},
+		{"VAL", vp.VAL, "#5470c6"},
+	} {
+		data := emptyLineData(len(ek.Data))
+		for i := start; i < len(ek.Data); i++ {
+			data[i] = opts.LineData{Value: level.price}
+		}
+		levels.AddSeries(level.name, data,
+			charts.WithLineChartOpts(opts.LineChart{XAxisIndex: 0, YAxisIndex: 0, Symbol: "none"}),
+			charts.WithLineStyleOpts(opts.LineStyle{Width: 1, Color: level.color, Type: "dashed"}),
+		)
+		out.legend = append(out.legend, level.name)
+	}
+	out.charts = append(out.charts, levels)
+	return out, true
+}
+
+// withProfileGrid narrows the price grid and adds the sidebar grid to its right.
+// withProfileGrid 收窄价格网格，并在其右侧添加成交量分布网格。
+func withProfileGrid(grids []opts.Grid) []opts.Grid {
+	price := grids[0]
+	price.Right = "22%"
+	grids[0] = price
+	return append(grids, opts.Grid{
+		Left:         "79%",
+		Right:        "8%",
+		Top:          price.Top,
+		Height:       price.Height,
+		ContainLabel: opts.Bool(true),
+	})
+}
+
+// vwapKeys computes the session and anchored VWAP overlays into ek.Indicators
+// and returns their keys; days are taken in ChartConfig.SessionTimezone.
+// vwapKeys 计算分时段 VWAP 与锚定 VWAP 叠加线并写入 ek.Indicators，返回其键；日期按 ChartConfig.SessionTimezone 划分。
+func (ek *EnhancedKline) vwapKeys() []string {
+	keys := make([]string, 0)
+	// An unknown zone is rejected by ValidateChartConfig; fall back to UTC here.
+	// 未知时区已由 ValidateChartConfig 拒绝，此处回退为 UTC。
+	loc, err := time.LoadLocation(ek.ChartConfig.SessionTimezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	if ek.ChartConfig.SessionVWAP {
+		ek.Indicators["VWAP.SESSION"] = identify.SessionVWAP(ek.Data, loc)
+		keys = append(keys, "VWAP.SESSION")
+	}
+	anchors := append([]int(nil), ek.ChartConfig.AnchoredVWAP...)
+	if ek.ChartConfig.AnchorLastPattern && len(ek.Patterns) > 0 {
+		last := ek.Patterns[0].Position
+		for _, p := range ek.Patterns {
+			if p.Position > last {
+				last = p.Position
+			}
+		}
+		anchors = append(anchors, last)
+	}
+	seen := make(map[int]bool)
+	for _, a := range anchors {
+		if a < 0 || a >= len(ek.Data) || seen[a] {
+			continue
+		}
+		seen[a] = true
+		key := fmt.Sprintf("AVWAP.%s", time.Unix(ek.Data[a].Timestamp, 0).In(loc).Format("2006-01-02"))
+		ek.Indicators[key] = identify.AnchoredVWAP(ek.Data, a)
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,242 @@
+// Package cost models transaction costs and trading rules of A-share and Hong
+// Kong markets: fees, slippage, board lots, T+N settlement and limit-locked bars.
+// 成本包 - 模拟A股与港股的交易成本与交易规则：费用、滑点、每手股数、T+N 交收与涨跌停封板。
+package cost
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Markets with built-in defaults
+// 内置默认参数的市场
+const (
+	MarketCNA = "cn_a" // Shanghai/Shenzhen/Beijing A-shares (沪深北A股)
+	MarketHK  = "hk"   // Hong Kong main board (港股)
+)
+
+// Order sides
+// 买卖方向
+const (
+	Buy  = "buy"
+	Sell = "sell"
+)
+
+// Config is the cost and rule model of one market. Rates are fractions of
+// notional per order; the built-in defaults follow the published rates at
+// the time of writing and should be checked against the broker's schedule.
+// Config 为单一市场的成本与规则模型；费率为每笔订单成交额的比例，内置默认值取自编写时公布的费率，使用前应与券商收费标准核对。
+type Config struct {
+	Market         string  `json:"market"` // cn_a/hk; empty disables cost modelling (为空表示不计成本)
+	CommissionRate float64 `json:"commission_rate"`
+	MinCommission  float64 `json:"min_commission"` // Per order, in currency (每笔最低佣金)
+	StampDutyRate  float64 `json:"stamp_duty_rate"`
+	// StampDutyOnBuy charges stamp duty on buys too (HK); A-shares levy it on sells only.
+	// StampDutyOnBuy 表示买入也征收印花税（港股）；A股仅卖出征收。
+	StampDutyOnBuy  bool    `json:"stamp_duty_on_buy"`
+	TransferFeeRate float64 `json:"transfer_fee_rate"` // 过户费
+	// ExchangeFeeRate covers exchange handling fees and regulator levies.
+	// ExchangeFeeRate 包括交易所经手费与监管征费。
+	ExchangeFeeRate   float64 `json:"exchange_fee_rate"`
+	SettlementFeeRate float64 `json:"settlement_fee_rate"` // 交收费
+	MinSettlementFee  float64 `json:"min_settlement_fee"`
+	MaxSettlementFee  float64 `json:"max_settlement_fee"`
+	// SlippageBps moves every fill against the order, in basis points.
+	// SlippageBps 使每笔成交价向不利方向偏移的基点数。
+	SlippageBps float64 `json:"slippage_bps"`
+	// LotSize is the board lot buys are rounded down to (HK lots vary by stock).
+	// LotSize 为买入向下取整的每手股数（港股每手股数因股票而异）。
+	LotSize int `json:"lot_size"`
+	// SettlementDays is how many trading days must pass before a buy can be
+	// sold: 1 for A-share T+1, 0 for HK.
+	// SettlementDays 为买入后可卖出前须经过的交易日数：A股 T+1 为 1，港股为 0。
+	SettlementDays int `json:"settlement_days"`
+	// LimitPct is the daily price limit (0.1 = ±10%); 0 means no limit.
+	// LimitPct 为每日涨跌幅限制（0.1 表示 ±10%）；0 表示不设限制。
+	LimitPct float64 `json:"limit_pct"`
+	// LimitPrefixes overrides LimitPct by stock code prefix, longest match
+	// first, e.g. "300": 0.2 for ChiNext.
+	// LimitPrefixes 按股票代码前缀覆盖 LimitPct，最长前缀优先，如创业板 "300": 0.2。
+	LimitPrefixes map[string]float64 `json:"limit_prefixes"`
+	AllowShort    bool               `json:"allow_short"`
+	// Capital is the notional of one simulated trade; it decides lot rounding
+	// and how much minimum fees weigh (default 100000).
+	// Capital 为单笔模拟交易的名义金额，决定整手取整与最低收费的影响（默认 100000）。
+	Capital float64 `json:"capital"`
+	// Timezone is the IANA location of the exchange, used to tell trading
+	// days apart for settlement; empty means UTC.
+	// Timezone 为交易所所在时区（IANA 名称），用于划分结算交易日；为空时使用 UTC。
+	Timezone string `json:"timezone"`
+}
+
+// DefaultCNAConfig returns A-share costs: 0.025% commission (min 5), 0.05%
+// stamp duty on sells, 0.001% transfer fee, 0.00341% handling fee, 5 bps
+// slippage, 100-share lots, T+1 and ±10% limits (±20% ChiNext/STAR, ±30% BSE).
+// DefaultCNAConfig 返回A股成本：佣金万2.5（最低 5 元）、卖出印花税 0.05%、过户费 0.001%、经手费 0.00341%、
+// 滑点 5 个基点、每手 100 股、T+1、涨跌停 ±10%（创业板/科创板 ±20%，北交所 ±30%）。
+func DefaultCNAConfig() Config {
+	return Config{
+		Market:          MarketCNA,
+		CommissionRate:  0.00025,
+		MinCommission:   5,
+		StampDutyRate:   0.0005,
+		TransferFeeRate: 0.00001,
+		ExchangeFeeRate: 0.0000341,
+		SlippageBps:     5,
+		LotSize:         100,
+		SettlementDays:  1,
+		LimitPct:        0.1,
+		LimitPrefixes: map[string]float64{
+			"300": 0.2, "301": 0.2, "688": 0.2, "689": 0.2,
+			"4": 0.3, "8": 0.3, "92": 0.3,
+		},
+		Capital: 100000,
+	}
+}
+
+// DefaultHKConfig returns HK costs: 0.03% commission (min 3), 0.1% stamp
+// duty on both sides, 0.0085% trading fee and levies, 0.002% settlement fee
+// (2 to 100), 10 bps slippage, 100-share lots, T+0 and no price limit.
+// DefaultHKConfig 返回港股成本：佣金 0.03%（最低 3 港元）、双边印花税 0.1%、交易费及征费 0.0085%、
+// 交收费 0.002%（2 至 100 港元）、滑点 10 个基点、每手 100 股、T+0、无涨跌幅限制。
+func DefaultHKConfig() Config {
+	return Config{
+		Market:            MarketHK,
+		CommissionRate:    0.0003,
+		MinCommission:     3,
+		StampDutyRate:     0.001,
+		StampDutyOnBuy:    true,
+		ExchangeFeeRate:   0.000085,
+		SettlementFeeRate: 0.00002,
+		MinSettlementFee:  2,
+		MaxSettlementFee:  100,
+		SlippageBps:       10,
+		LotSize:           100,
+		Capital:           100000,
+	}
+}
+
+// MarketConfig returns the defaults of market; ok is false for unknown markets.
+// MarketConfig 返回指定市场的默认配置；未知市场时 ok 为 false。
+func MarketConfig(market string) (Config, bool) {
+	switch market {
+	case MarketCNA:
+		return DefaultCNAConfig(), true
+	case MarketHK:
+		return DefaultHKConfig(), true
+	default:
+		return Config{}, false
+	}
+}
+
+// Enabled reports whether cost modelling is on.
+// Enabled 返回是否启用成本模型。
+func (c Config) Enabled() bool {
+	return c.Market != ""
+}
+
+// Validate reports the first invalid setting in c.
+// Validate 返回 c 中第一个无效配置项的错误。
+func (c Config) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	if _, ok := MarketConfig(c.Market); !ok {
+		return fmt.Errorf("market must be %s or %s", MarketCNA, MarketHK)
+	}
+	for name, rate := range map[string]float64{
+		"commission_rate":     c.CommissionRate,
+		"stamp_duty_rate":     c.StampDutyRate,
+		"transfer_fee_rate":   c.TransferFeeRate,
+		"exchange_fee_rate":   c.ExchangeFeeRate,
+		"settlement_fee_rate": c.SettlementFeeRate,
+	} {
+		if rate < 0 || rate >= 0.1 {
+			return fmt.Errorf("%s must be in [0, 0.1)", name)
+		}
+	}
+	if c.MinCommission < 0 || c.MinSettlementFee < 0 || c.SlippageBps < 0 {
+		return fmt.Errorf("fees and slippage must not be negative")
+	}
+	if c.MaxSettlementFee > 0 && c.MaxSettlementFee < c.MinSettlementFee {
+		return fmt.Errorf("max_settlement_fee must not be below min_settlement_fee")
+	}
+	if c.LotSize < 1 || c.SettlementDays < 0 || c.Capital <= 0 {
+		return fmt.Errorf("lot_size and capital must be positive and settlement_days not negative")
+	}
+	if c.LimitPct < 0 || c.LimitPct >= 1 {
+		return fmt.Errorf("limit_pct must be in [0, 1)")
+	}
+	for prefix, pct := range c.LimitPrefixes {
+		if pct < 0 || pct >= 1 {
+			return fmt.Errorf("limit_prefixes[%s] must be in [0, 1)", prefix)
+		}
+	}
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		return fmt.Errorf("timezone %q: %w", c.Timezone, err)
+	}
+	return nil
+}
+
+// Fees returns the total fees of one order of shares at price.
+// Fees 返回以 price 成交 shares 股的单笔订单总费用。
+func (c Config) Fees(side string, price float64, shares int) float64 {
+	notional := price * float64(shares)
+	if notional <= 0 {
+		return 0
+	}
+	fees := math.Max(notional*c.CommissionRate, c.MinCommission)
+	if side == Sell || c.StampDutyOnBuy {
+		fees += notional * c.StampDutyRate
+	}
+	fees += notional * (c.TransferFeeRate + c.ExchangeFeeRate)
+	if c.SettlementFeeRate > 0 {
+		settle := math.Max(notional*c.SettlementFeeRate, c.MinSettlementFee)
+		if c.MaxSettlementFee > 0 {
+			settle = math.Min(settle, c.MaxSettlementFee)
+		}
+		fees += settle
+	}
+	return fees
+}
+
+// FillPrice applies slippage to price against the order side.
+// FillPrice 按买卖方向对 price 施加不利滑点。
+func (c Config) FillPrice(side string, price float64) float64 {
+	slip := c.SlippageBps / 10000
+	if side == Sell {
+		return price * (1 - slip)
+	}
+	return price * (1 + slip)
+}
+
+// Shares returns the whole lots of capital affordable at price, in shares.
+// Shares 返回以 price 买入 capital 可得的整手股数。
+func (c Config) Shares(capital, price float64) int {
+	if price <= 0 || capital <= 0 {
+		return 0
+	}
+	lot := c.LotSize
+	if lot < 1 {
+		lot = 1
+	}
+	return int(capital/price/float64(lot)) * lot
+}
+
+// LimitPctFor returns the price limit of symbol ("XSHE:300059" or "300059").
+// LimitPctFor 返回标的（"XSHE:300059" 或 "300059"）的涨跌幅限制。
+func (c Config) LimitPctFor(symbol string) float64 {
+	code := symbol
+	if i := strings.LastIndex(code, ":"); i >= 0 {
+		code = code[i+1:]
+	}
+	pct, best := c.LimitPct, 0
+	for prefix, p := range c.LimitPrefixes {
+		if len(prefix) > best && strings.HasPrefix(code, prefix) {
+			pct, best = p, len(prefix)
+		}
+	}
+	return pct
+}
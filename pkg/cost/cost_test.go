@@ -0,0 +1,180 @@
+package cost
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+)
+
+func TestFeesCNA(t *testing.T) {
+	c := DefaultCNAConfig()
+	// 1000 shares at 10: commission 2.5 rises to the 5 minimum.
+	// 1000 股、价格 10：佣金 2.5 元按最低 5 元收取。
+	buy := c.Fees(Buy, 10, 1000)
+	if want := 5 + 10000*(0.00001+0.0000341); math.Abs(buy-want) > 1e-9 {
+		t.Fatalf("buy fees = %.6f, want %.6f", buy, want)
+	}
+	sell := c.Fees(Sell, 10, 1000)
+	if math.Abs(sell-buy-5) > 1e-9 {
+		t.Fatalf("sells should add 0.05%% stamp duty: buy %.4f sell %.4f", buy, sell)
+	}
+	if c.Fees(Buy, 10, 0) != 0 {
+		t.Fatal("an empty order should cost nothing")
+	}
+}
+
+func TestFeesHKSettlementBounds(t *testing.T) {
+	c := DefaultHKConfig()
+	small := c.Fees(Buy, 10, 100)  // 1000 notional: settlement floored at 2
+	large := c.Fees(Buy, 100, 1e5) // 1e7 notional: settlement capped at 100
+	wantSmall := 3 + 1000*(0.001+0.000085) + 2
+	wantLarge := 1e7*(0.0003+0.001+0.000085) + 100
+	if math.Abs(small-wantSmall) > 1e-9 || math.Abs(large-wantLarge) > 1e-6 {
+		t.Fatalf("fees = %.4f/%.4f, want %.4f/%.4f", small, large, wantSmall, wantLarge)
+	}
+}
+
+func TestSharesAndLimits(t *testing.T) {
+	c := DefaultCNAConfig()
+	if got := c.Shares(100000, 33); got != 3000 {
+		t.Fatalf("shares should round down to whole lots, got %d", got)
+	}
+	for symbol, want := range map[string]float64{
+		"XSHG:600519": 0.1, "XSHE:300059": 0.2, "688981": 0.2, "XBSE:830799": 0.3, "000001": 0.1,
+	} {
+		if got := c.LimitPctFor(symbol); got != want {
+			t.Fatalf("%s limit = %.2f, want %.2f", symbol, got, want)
+		}
+	}
+	prev := &v1.Candlestick{Close: 9.99}
+	up, down := c.LimitLocked("600000", prev, &v1.Candlestick{Open: 10.99, High: 10.99, Low: 10.99, Close: 10.99})
+	if !up || down {
+		t.Fatalf("one-price bar at 10.99 should be limit-up locked from 9.99: up=%v down=%v", up, down)
+	}
+	if up, _ := c.LimitLocked("600000", prev, &v1.Candlestick{Open: 10.5, High: 10.99, Low: 10.4, Close: 10.99}); up {
+		t.Fatal("a bar that traded a range is not locked")
+	}
+	if up, down := DefaultHKConfig().LimitLocked("00700", prev, &v1.Candlestick{High: 12, Low: 12, Close: 12}); up || down {
+		t.Fatal("HK has no price limit")
+	}
+}
+
+// dailyBars builds one bar per close, a day apart; closes equal to the
+// previous close ±10% become one-price bars.
+func dailyBars(closes ...float64) []*v1.Candlestick {
+	base := time.Date(2025, 3, 3, 15, 0, 0, 0, time.UTC)
+	out := make([]*v1.Candlestick, len(closes))
+	for i, c := range closes {
+		bar := &v1.Candlestick{Timestamp: base.AddDate(0, 0, i).Unix(), Open: c, High: c + 0.1, Low: c - 0.1, Close: c, Volume: 1000}
+		if i > 0 && math.Abs(math.Abs(c/closes[i-1]-1)-0.1) < 1e-3 {
+			bar.High, bar.Low = c, c
+		}
+		out[i] = bar
+	}
+	return out
+}
+
+func TestSimulate(t *testing.T) {
+	c := DefaultCNAConfig()
+	bars := dailyBars(10, 10.2, 10.4, 10.5)
+	tr := c.Simulate("600000", bars, 0, 3, true)
+	if tr.Blocked != "" || tr.ExitIndex != 3 || tr.Shares != 9900 {
+		t.Fatalf("unexpected trade: %+v", tr)
+	}
+	if math.Abs(tr.GrossReturn-5) > 1e-9 || tr.NetReturn >= tr.GrossReturn || tr.NetReturn < 4.5 {
+		t.Fatalf("net return should be a little below the 5%% gross: %+v", tr)
+	}
+
+	// A limit-down lock on the exit bar defers the sale to the next bar.
+	// 离场K线一字跌停时顺延至下一根K线卖出。
+	locked := dailyBars(10, 10.5, 9.45, 9.3)
+	if tr := c.Simulate("600000", locked, 0, 2, true); tr.Blocked != "" || tr.ExitIndex != 3 {
+		t.Fatalf("exit should move past the locked bar: %+v", tr)
+	}
+	if tr := c.Simulate("600000", locked, 1, 1, true); tr.ExitIndex != 3 {
+		t.Fatalf("exit should move past the locked bar: %+v", tr)
+	}
+	if tr := c.Simulate("600000", dailyBars(10, 11, 11.5, 11.6), 1, 1, true); tr.Blocked != BlockedLimitUp {
+		t.Fatalf("a limit-up locked entry cannot be bought: %+v", tr)
+	}
+	if tr := c.Simulate("600000", bars, 0, 1, false); tr.Blocked != BlockedShort {
+		t.Fatalf("A-shares default to no shorting: %+v", tr)
+	}
+	if tr := c.Simulate("600000", bars, 2, 3, true); tr.Blocked != BlockedExit {
+		t.Fatalf("a horizon past the data has no exit: %+v", tr)
+	}
+}
+
+func TestSimulateSettlementDays(t *testing.T) {
+	// Hourly bars of two trading days: T+1 holds until the next day's first bar.
+	// 两个交易日的小时K线：T+1 须持有到次日第一根K线。
+	base := time.Date(2025, 3, 3, 2, 0, 0, 0, time.UTC)
+	bars := make([]*v1.Candlestick, 0, 8)
+	for d := 0; d < 2; d++ {
+		for h := 0; h < 4; h++ {
+			bars = append(bars, &v1.Candlestick{Timestamp: base.AddDate(0, 0, d).Add(time.Duration(h) * time.Hour).Unix(), Open: 10, High: 10.1, Low: 9.9, Close: 10, Volume: 100})
+		}
+	}
+	if tr := DefaultCNAConfig().Simulate("600000", bars, 1, 1, true); tr.ExitIndex != 4 {
+		t.Fatalf("T+1 should defer the exit to the next day: %+v", tr)
+	}
+	hk := DefaultHKConfig()
+	hk.AllowShort = true
+	if tr := hk.Simulate("00700", bars, 1, 1, false); tr.ExitIndex != 2 || tr.NetReturn >= 0 {
+		t.Fatalf("HK trades T+0 and a flat short loses its costs: %+v", tr)
+	}
+}
+
+func TestSettlementIgnoresLocalZone(t *testing.T) {
+	// 01:00-04:00 UTC on two days; at UTC-3 each morning straddles midnight.
+	// 两天的 UTC 01:00-04:00；在 UTC-3 下每段都跨越午夜。
+	saved := time.Local
+	time.Local = time.FixedZone("UTC-3", -3*3600)
+	defer func() { time.Local = saved }()
+
+	base := time.Date(2025, 3, 3, 1, 0, 0, 0, time.UTC)
+	bars := make([]*v1.Candlestick, 0, 8)
+	for d := 0; d < 2; d++ {
+		for h := 0; h < 4; h++ {
+			bars = append(bars, &v1.Candlestick{Timestamp: base.AddDate(0, 0, d).Add(time.Duration(h) * time.Hour).Unix(), Open: 10, High: 10.1, Low: 9.9, Close: 10, Volume: 100})
+		}
+	}
+	cfg := DefaultCNAConfig()
+	if tr := cfg.Simulate("600000", bars, 1, 1, true); tr.ExitIndex != 4 {
+		t.Fatalf("trading days should be counted in UTC, not time.Local: %+v", tr)
+	}
+	cfg.Timezone = "Asia/Shanghai"
+	if tr := cfg.Simulate("600000", bars, 1, 1, true); tr.ExitIndex != 4 {
+		t.Fatalf("Shanghai mornings stay on one day: %+v", tr)
+	}
+	cfg.Timezone = "America/Sao_Paulo"
+	if tr := cfg.Simulate("600000", bars, 1, 1, true); tr.ExitIndex != 2 {
+		t.Fatalf("the configured zone should decide the day boundary: %+v", tr)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (Config{}).Validate(); err != nil {
+		t.Fatalf("disabled config should validate: %v", err)
+	}
+	c := DefaultCNAConfig()
+	if err := c.Validate(); err != nil {
+		t.Fatalf("defaults should validate: %v", err)
+	}
+	c.Market = "us"
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected an error for an unknown market")
+	}
+	c = DefaultHKConfig()
+	c.MaxSettlementFee = 1
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected an error for max below min settlement fee")
+	}
+	c = DefaultHKConfig()
+	c.Timezone = "Asia/Nowhere"
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected an error for an unknown timezone")
+	}
+}
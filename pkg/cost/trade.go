@@ -0,0 +1,142 @@
+package cost
+
+import (
+	"math"
+	"time"
+
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+)
+
+// Reasons a simulated trade could not be made
+// 模拟交易无法成交的原因
+const (
+	BlockedLimitUp   = "limit_up"   // Entry bar locked at limit up, no sellers (涨停封板无法买入)
+	BlockedLimitDown = "limit_down" // Entry bar locked at limit down, no buyers (跌停封板无法卖出)
+	BlockedShort     = "short"      // Short selling not allowed (不允许做空)
+	BlockedLot       = "lot"        // Capital below one board lot (资金不足一手)
+	BlockedExit      = "exit"       // No tradable exit bar in the data (数据内无可成交的离场K线)
+)
+
+// Trade is one simulated round trip. Returns are percentages of the entry
+// notional, positive when the position made money.
+// Trade 为一次模拟的开平仓；收益为相对开仓金额的百分比，正值表示盈利。
+type Trade struct {
+	Long       bool    `json:"long"`
+	EntryIndex int     `json:"entry_index"`
+	ExitIndex  int     `json:"exit_index"`
+	EntryPrice float64 `json:"entry_price"` // Fill including slippage (含滑点成交价)
+	ExitPrice  float64 `json:"exit_price"`
+	Shares     int     `json:"shares"`
+	Fees       float64 `json:"fees"`
+	// GrossReturn is the close-to-close return over the bars actually held.
+	// GrossReturn 为实际持有区间的收盘价收益。
+	GrossReturn float64 `json:"gross_return"`
+	NetReturn   float64 `json:"net_return"`
+	Blocked     string  `json:"blocked,omitempty"`
+}
+
+// LimitLocked reports whether bar traded at a single price pinned at the
+// limit up or down from prev's close; such bars cannot be bought or sold.
+// LimitLocked 判断 bar 是否为相对 prev 收盘价的一字涨停或一字跌停；此类K线无法买入或卖出。
+func (c Config) LimitLocked(symbol string, prev, bar *v1.Candlestick) (up, down bool) {
+	pct := c.LimitPctFor(symbol)
+	if pct <= 0 || prev == nil || bar == nil || prev.Close <= 0 || bar.High-bar.Low > 1e-9 {
+		return false, false
+	}
+	// Limit prices are rounded to the 0.01 tick.
+	// 涨跌停价按 0.01 最小变动价位取整。
+	upPrice := math.Round(prev.Close*(1+pct)*100) / 100
+	downPrice := math.Round(prev.Close*(1-pct)*100) / 100
+	return bar.Close >= upPrice-0.005, bar.Close <= downPrice+0.005
+}
+
+// Simulate enters at the close of candles[pos] and exits at the close
+// horizon bars later, deferring the exit past unsettled days and bars locked
+// against the position. Blocked is set, with zero returns, when it cannot
+// be traded.
+// Simulate 在 candles[pos] 收盘价开仓，于 horizon 根K线后的收盘价平仓；未满足交收天数或遇到不利封板时顺延离场。
+// 无法交易时设置 Blocked，收益为零。
+func (c Config) Simulate(symbol string, candles []*v1.Candlestick, pos, horizon int, long bool) Trade {
+	t := Trade{Long: long, EntryIndex: pos, ExitIndex: -1}
+	if pos < 0 || pos+horizon >= len(candles) || horizon < 1 || candles[pos].Close <= 0 {
+		t.Blocked = BlockedExit
+		return t
+	}
+	if !long && !c.AllowShort {
+		t.Blocked = BlockedShort
+		return t
+	}
+	if pos > 0 {
+		up, down := c.LimitLocked(symbol, candles[pos-1], candles[pos])
+		switch {
+		case long && up:
+			t.Blocked = BlockedLimitUp
+			return t
+		case !long && down:
+			t.Blocked = BlockedLimitDown
+			return t
+		}
+	}
+	openSide, closeSide := Buy, Sell
+	if !long {
+		openSide, closeSide = Sell, Buy
+	}
+	t.EntryPrice = c.FillPrice(openSide, candles[pos].Close)
+	t.Shares = c.Shares(c.Capital, t.EntryPrice)
+	if t.Shares == 0 {
+		t.Blocked = BlockedLot
+		return t
+	}
+
+	exit := -1
+	loc := c.location()
+	days, lastDay := 0, tradingDay(candles[pos], loc)
+	for i := pos + 1; i < len(candles); i++ {
+		if d := tradingDay(candles[i], loc); d != lastDay {
+			days, lastDay = days+1, d
+		}
+		if i < pos+horizon || days < c.SettlementDays {
+			continue
+		}
+		up, down := c.LimitLocked(symbol, candles[i-1], candles[i])
+		if (long && down) || (!long && up) {
+			continue
+		}
+		exit = i
+		break
+	}
+	if exit < 0 {
+		t.Blocked = BlockedExit
+		return t
+	}
+	t.ExitIndex = exit
+	t.ExitPrice = c.FillPrice(closeSide, candles[exit].Close)
+	t.Fees = c.Fees(openSide, t.EntryPrice, t.Shares) + c.Fees(closeSide, t.ExitPrice, t.Shares)
+
+	sign := 1.0
+	if !long {
+		sign = -1
+	}
+	entry := candles[pos].Close
+	notional := entry * float64(t.Shares)
+	t.GrossReturn = sign * (candles[exit].Close - entry) / entry * 100
+	t.NetReturn = (sign*(t.ExitPrice-t.EntryPrice)*float64(t.Shares) - t.Fees) / notional * 100
+	return t
+}
+
+// location resolves Timezone, falling back to UTC so that settlement never
+// depends on the host's local zone.
+func (c Config) location() *time.Location {
+	if c.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func tradingDay(c *v1.Candlestick, loc *time.Location) string {
+	return time.Unix(c.Timestamp, 0).In(loc).Format("2006-01-02")
+}
@@ -0,0 +1,90 @@
+package datasource
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+)
+
+// CandleFile is the envelope form of a local candle JSON file.
+// CandleFile 为本地K线 JSON 文件的封装格式。
+type CandleFile struct {
+	Symbol string            `json:"symbol"`
+	Source string            `json:"source"`
+	Data   []*v1.Candlestick `json:"data"`
+}
+
+// LoadCandleFile reads []Candlestick or {symbol,source,data} JSON. The source
+// defaults to "file"; the symbol is empty unless the envelope sets it.
+// LoadCandleFile 读取 []Candlestick 或 {symbol,source,data} 格式的 JSON；source 默认为 "file"，
+// 仅当封装格式提供时返回 symbol。
+func LoadCandleFile(path string) ([]*v1.Candlestick, string, string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	var envelope CandleFile
+	if err := json.Unmarshal(raw, &envelope); err == nil && len(envelope.Data) > 0 {
+		src := envelope.Source
+		if src == "" {
+			src = "file"
+		}
+		return envelope.Data, src, envelope.Symbol, nil
+	}
+
+	var candles []*v1.Candlestick
+	if err := json.Unmarshal(raw, &candles); err != nil {
+		return nil, "", "", fmt.Errorf("input must be []Candlestick or {symbol,source,data}: %w", err)
+	}
+	return candles, "file", "", nil
+}
+
+// LoadNamedCandleFile is LoadCandleFile with the symbol defaulting to the base
+// file name.
+// LoadNamedCandleFile 同 LoadCandleFile，symbol 缺省时使用文件名。
+func LoadNamedCandleFile(path string) ([]*v1.Candlestick, string, error) {
+	candles, _, sym, err := LoadCandleFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", path, err)
+	}
+	if sym == "" {
+		sym = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return candles, sym, nil
+}
+
+// LoadCandleFiles reads comma-separated candle files or directories of *.json
+// files into symbol -> candles; it returns nil when spec is empty.
+// LoadCandleFiles 读取以逗号分隔的K线文件或 *.json 目录，返回 标的 -> K线；spec 为空时返回 nil。
+func LoadCandleFiles(spec string) (map[string][]*v1.Candlestick, error) {
+	if spec == "" {
+		return nil, nil
+	}
+	out := make(map[string][]*v1.Candlestick)
+	for _, p := range strings.Split(spec, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		paths := []string{p}
+		if info, err := os.Stat(p); err != nil {
+			return nil, err
+		} else if info.IsDir() {
+			if paths, err = filepath.Glob(filepath.Join(p, "*.json")); err != nil {
+				return nil, err
+			}
+		}
+		for _, file := range paths {
+			candles, sym, err := LoadNamedCandleFile(file)
+			if err != nil {
+				return nil, err
+			}
+			out[sym] = candles
+		}
+	}
+	return out, nil
+}
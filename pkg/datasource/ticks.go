@@ -0,0 +1,317 @@
+package datasource
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+)
+
+// Bar types of AggregateTicks
+// AggregateTicks 支持的K线类型
+const (
+	BarTime   = "time"   // Fixed clock interval (固定时间间隔)
+	BarTick   = "tick"   // Fixed number of trades (固定成交笔数)
+	BarVolume = "volume" // Fixed traded size (固定成交量)
+	BarDollar = "dollar" // Fixed traded notional, price × size (固定成交额)
+)
+
+// Tick is one trade.
+// Tick 为一笔成交。
+type Tick struct {
+	Time  time.Time
+	Price float64
+	Size  float64
+}
+
+// BarConfig selects how ticks are grouped into bars.
+// BarConfig 指定成交明细聚合为K线的方式。
+type BarConfig struct {
+	Type string `json:"type"` // time/tick/volume/dollar
+	// Interval is the time bar length, at least one second.
+	// Interval 为时间K线的周期，至少 1 秒。
+	Interval time.Duration `json:"interval"`
+	// Threshold is the trades, size or notional that closes a tick, volume or
+	// dollar bar.
+	// Threshold 为收盘一根笔数、成交量或成交额K线所需的成交笔数、成交量或成交额。
+	Threshold float64 `json:"threshold"`
+	// BarsPerDay sets Threshold, when it is 0, to the daily average of the
+	// measure divided by BarsPerDay, so bars sample activity evenly.
+	// BarsPerDay 在 Threshold 为 0 时按日均成交笔数/量/额除以 BarsPerDay 设定阈值，使K线按活跃度均匀采样。
+	BarsPerDay float64 `json:"bars_per_day"`
+}
+
+// ParseBarSpec parses "time:5m", "tick:500", "volume:1e6", "dollar:5e7" or,
+// for information-driven bars, "volume:50/day" (50 bars on an average day).
+// ParseBarSpec 解析 "time:5m"、"tick:500"、"volume:1e6"、"dollar:5e7"，信息驱动K线也可写作 "volume:50/day"（平均每日 50 根）。
+func ParseBarSpec(spec string) (BarConfig, error) {
+	kind, size, ok := strings.Cut(strings.TrimSpace(spec), ":")
+	if !ok {
+		return BarConfig{}, fmt.Errorf("bar spec %q must be <type>:<size>", spec)
+	}
+	cfg := BarConfig{Type: kind}
+	var err error
+	switch {
+	case kind == BarTime:
+		cfg.Interval, err = time.ParseDuration(size)
+	case strings.HasSuffix(size, "/day"):
+		cfg.BarsPerDay, err = strconv.ParseFloat(strings.TrimSuffix(size, "/day"), 64)
+	default:
+		cfg.Threshold, err = strconv.ParseFloat(size, 64)
+	}
+	if err != nil {
+		return BarConfig{}, fmt.Errorf("bar spec %q: %w", spec, err)
+	}
+	return cfg, cfg.validate()
+}
+
+func (c BarConfig) validate() error {
+	switch c.Type {
+	case BarTime:
+		if c.Interval < time.Second {
+			return fmt.Errorf("time bar interval must be at least 1s")
+		}
+	case BarTick, BarVolume, BarDollar:
+		if c.Threshold < 0 || c.BarsPerDay < 0 || (c.Threshold == 0 && c.BarsPerDay == 0) {
+			return fmt.Errorf("%s bars need a positive threshold or bars per day", c.Type)
+		}
+	default:
+		return fmt.Errorf("bar type must be %s, %s, %s or %s", BarTime, BarTick, BarVolume, BarDollar)
+	}
+	return nil
+}
+
+// LoadTicks reads trades from CSV (header with timestamp, price and size
+// columns) or JSONL ({"timestamp","price","size"} per line), chosen by file
+// extension. Timestamps are Unix seconds, milliseconds, microseconds or
+// nanoseconds, RFC 3339, or "2006-01-02 15:04:05[.fff]" in local time.
+// LoadTicks 按扩展名读取 CSV（表头含 timestamp、price、size 列）或 JSONL（每行 {"timestamp","price","size"}）格式的成交明细。
+// 时间戳可为 Unix 秒、毫秒、微秒或纳秒、RFC 3339，或本地时间 "2006-01-02 15:04:05[.fff]"。
+func LoadTicks(path string) ([]Tick, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var ticks []Tick
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		ticks, err = readTicksCSV(f)
+	case ".jsonl", ".ndjson":
+		ticks, err = readTicksJSONL(f)
+	default:
+		return nil, fmt.Errorf("%s: ticks must be .csv or .jsonl", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return ticks, nil
+}
+
+func readTicksCSV(r io.Reader) ([]Tick, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	col := map[string]int{}
+	for i, h := range header {
+		switch strings.ToLower(strings.TrimSpace(h)) {
+		case "timestamp", "time", "datetime":
+			col["time"] = i
+		case "price":
+			col["price"] = i
+		case "size", "volume", "qty", "quantity":
+			col["size"] = i
+		}
+	}
+	for _, name := range []string{"time", "price", "size"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("header lacks a %s column", name)
+		}
+	}
+	ticks := make([]Tick, 0)
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return ticks, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		t, err := parseTick(rec[col["time"]], rec[col["price"]], rec[col["size"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		ticks = append(ticks, t)
+	}
+}
+
+func readTicksJSONL(r io.Reader) ([]Tick, error) {
+	ticks := make([]Tick, 0)
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		raw := strings.TrimSpace(sc.Text())
+		if raw == "" {
+			continue
+		}
+		var rec struct {
+			Timestamp json.RawMessage `json:"timestamp"`
+			Price     json.Number     `json:"price"`
+			Size      json.Number     `json:"size"`
+		}
+		if err := json.Unmarshal([]byte(raw), &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		ts := string(rec.Timestamp)
+		if s, err := strconv.Unquote(ts); err == nil {
+			ts = s
+		}
+		t, err := parseTick(ts, rec.Price.String(), rec.Size.String())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		ticks = append(ticks, t)
+	}
+	return ticks, sc.Err()
+}
+
+func parseTick(ts, price, size string) (Tick, error) {
+	t, err := parseTickTime(strings.TrimSpace(ts))
+	if err != nil {
+		return Tick{}, err
+	}
+	p, err := strconv.ParseFloat(strings.TrimSpace(price), 64)
+	if err != nil || p <= 0 {
+		return Tick{}, fmt.Errorf("invalid price %q", price)
+	}
+	s, err := strconv.ParseFloat(strings.TrimSpace(size), 64)
+	if err != nil || s < 0 {
+		return Tick{}, fmt.Errorf("invalid size %q", size)
+	}
+	return Tick{Time: t, Price: p, Size: s}, nil
+}
+
+func parseTickTime(s string) (time.Time, error) {
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		// The magnitude tells the unit of a Unix timestamp.
+		// 根据数量级判断 Unix 时间戳的单位。
+		switch {
+		case n < 1e11:
+			sec, frac := math.Modf(n)
+			return time.Unix(int64(sec), int64(frac*1e9)), nil
+		case n < 1e14:
+			return time.UnixMilli(int64(n)), nil
+		case n < 1e17:
+			return time.UnixMicro(int64(n)), nil
+		default:
+			// Parse integers exactly; float or exponent forms lose sub-microsecond digits.
+			// 整数精确解析；浮点或科学计数法形式会丢失微秒以下的精度。
+			i, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				if n >= math.MaxInt64 {
+					return time.Time{}, fmt.Errorf("timestamp %q out of range", s)
+				}
+				i = int64(n)
+			}
+			return time.Unix(0, i), nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999", "2006-01-02T15:04:05.999999999"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+}
+
+// AggregateTicks groups ticks, sorted by time, into bars. Time bars are
+// aligned to the interval in local time and stamped with their start;
+// intervals without trades have no bar. Tick, volume and dollar bars close
+// on the trade that reaches the threshold, after López de Prado, and are
+// stamped with the second of their last trade. Candle timestamps are whole
+// seconds, so bars that close within one second share a timestamp rather
+// than drifting past later trades; raise the threshold when bursts produce
+// many. The last bar may be partial.
+// AggregateTicks 将按时间排序后的成交明细聚合为K线。时间K线按本地时间对齐周期，以起始时间为时间戳，无成交的周期不生成K线。
+// 笔数、成交量与成交额K线参照 López de Prado，在累计达到阈值的那笔成交收盘，以最后一笔成交所在秒为时间戳。
+// K线时间戳精确到秒，同一秒内收盘的多根K线共用时间戳而不向后顺延；密集成交产生过多K线时请调高阈值。最后一根K线可能未走完。
+func AggregateTicks(ticks []Tick, cfg BarConfig) ([]*v1.Candlestick, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	sorted := make([]Tick, len(ticks))
+	copy(sorted, ticks)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	measure := func(t Tick) float64 {
+		switch cfg.Type {
+		case BarTick:
+			return 1
+		case BarVolume:
+			return t.Size
+		default:
+			return t.Price * t.Size
+		}
+	}
+	threshold := cfg.Threshold
+	if cfg.Type != BarTime && threshold == 0 {
+		total, days := 0.0, make(map[string]bool)
+		for _, t := range sorted {
+			total += measure(t)
+			days[t.Time.Local().Format("2006-01-02")] = true
+		}
+		if total == 0 {
+			return []*v1.Candlestick{}, nil
+		}
+		threshold = total / float64(len(days)) / cfg.BarsPerDay
+	}
+
+	out := make([]*v1.Candlestick, 0)
+	var cur *v1.Candlestick
+	var bucket time.Time
+	acc := 0.0
+	for _, t := range sorted {
+		if cfg.Type == BarTime {
+			_, offset := t.Time.Zone()
+			start := t.Time.Add(time.Duration(offset) * time.Second).Truncate(cfg.Interval).Add(-time.Duration(offset) * time.Second)
+			if cur != nil && !start.Equal(bucket) {
+				cur = nil
+			}
+			if cur == nil {
+				bucket = start
+				cur = &v1.Candlestick{Timestamp: start.Unix(), Open: t.Price, High: t.Price, Low: t.Price}
+				out = append(out, cur)
+			}
+		} else if cur == nil {
+			cur = &v1.Candlestick{Open: t.Price, High: t.Price, Low: t.Price}
+			out = append(out, cur)
+		}
+		cur.High = math.Max(cur.High, t.Price)
+		cur.Low = math.Min(cur.Low, t.Price)
+		cur.Close = t.Price
+		cur.Volume += t.Size
+		if cfg.Type == BarTime {
+			continue
+		}
+		cur.Timestamp = t.Time.Unix()
+		if acc += measure(t); acc >= threshold {
+			cur, acc = nil, 0
+		}
+	}
+	return out, nil
+}
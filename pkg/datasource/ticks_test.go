@@ -0,0 +1,162 @@
+package datasource
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// sampleTicks are six trades over three minutes, out of order.
+func sampleTicks() []Tick {
+	base := time.Date(2025, 3, 3, 9, 30, 0, 0, time.Local)
+	at := func(sec int) time.Time { return base.Add(time.Duration(sec) * time.Second) }
+	return []Tick{
+		{Time: at(10), Price: 10.0, Size: 100},
+		{Time: at(70), Price: 10.4, Size: 300},
+		{Time: at(30), Price: 10.2, Size: 200},
+		{Time: at(75), Price: 9.9, Size: 100},
+		{Time: at(75), Price: 10.1, Size: 400},
+		{Time: at(150), Price: 10.5, Size: 500},
+	}
+}
+
+func TestAggregateTimeBars(t *testing.T) {
+	bars, err := AggregateTicks(sampleTicks(), BarConfig{Type: BarTime, Interval: time.Minute})
+	if err != nil {
+		t.Fatalf("aggregate: %v", err)
+	}
+	if len(bars) != 3 {
+		t.Fatalf("expected one bar per minute with trades, got %d", len(bars))
+	}
+	b := bars[1]
+	start := time.Date(2025, 3, 3, 9, 31, 0, 0, time.Local).Unix()
+	if b.Timestamp != start || b.Open != 10.4 || b.High != 10.4 || b.Low != 9.9 || b.Close != 10.1 || b.Volume != 800 {
+		t.Fatalf("unexpected second bar: %+v", b)
+	}
+	if bars[0].Open != 10.0 || bars[0].Close != 10.2 {
+		t.Fatalf("ticks should be sorted by time first: %+v", bars[0])
+	}
+}
+
+func TestAggregateInformationBars(t *testing.T) {
+	ticks := sampleTicks()
+	tick, _ := AggregateTicks(ticks, BarConfig{Type: BarTick, Threshold: 2})
+	vol, _ := AggregateTicks(ticks, BarConfig{Type: BarVolume, Threshold: 600})
+	dollar, _ := AggregateTicks(ticks, BarConfig{Type: BarDollar, Threshold: 5000})
+	if len(tick) != 3 || tick[1].Volume != 400 || tick[2].Volume != 900 {
+		t.Fatalf("unexpected tick bars: %+v", tick)
+	}
+	// Volume 100+200+300 closes the first bar; 100+400+500 the second.
+	// 成交量 100+200+300 收盘第一根，100+400+500 收盘第二根。
+	if len(vol) != 2 || vol[0].Volume != 600 || vol[0].Close != 10.4 || vol[1].Volume != 1000 {
+		t.Fatalf("unexpected volume bars: %+v", vol)
+	}
+	if len(dollar) != 3 || dollar[0].Volume != 600 {
+		t.Fatalf("unexpected dollar bars: %+v", dollar)
+	}
+	// The two trades at 75s close two tick bars in one second.
+	// 75 秒的两笔成交在同一秒内收盘两根K线。
+	one, _ := AggregateTicks(ticks, BarConfig{Type: BarTick, Threshold: 1})
+	if len(one) != 6 || one[3].Timestamp != one[4].Timestamp || one[5].Timestamp != one[4].Timestamp+75 {
+		t.Fatalf("same-second bars should share their trade's second: %+v", one)
+	}
+
+	auto, err := AggregateTicks(ticks, BarConfig{Type: BarVolume, BarsPerDay: 2})
+	if err != nil || len(auto) != 2 {
+		t.Fatalf("1600 shares on one day at 2 bars/day should give 800-share bars: %v %+v", err, auto)
+	}
+}
+
+func TestAggregateTicksBurstKeepsTradeTime(t *testing.T) {
+	// 500 trades in one second, then one a second later: no bar may drift
+	// past the second of its last trade.
+	// 同一秒内 500 笔成交，1 秒后再一笔：任何K线都不应晚于其最后一笔成交所在秒。
+	base := time.Date(2025, 3, 3, 9, 30, 0, 0, time.Local)
+	ticks := make([]Tick, 0, 501)
+	for i := 0; i < 500; i++ {
+		ticks = append(ticks, Tick{Time: base.Add(time.Duration(i) * time.Millisecond), Price: 10, Size: 100})
+	}
+	ticks = append(ticks, Tick{Time: base.Add(time.Second), Price: 10.1, Size: 100})
+	bars, err := AggregateTicks(ticks, BarConfig{Type: BarTick, Threshold: 1})
+	if err != nil || len(bars) != 501 {
+		t.Fatalf("expected one bar per trade: %v %d", err, len(bars))
+	}
+	for i, b := range bars[:500] {
+		if b.Timestamp != base.Unix() {
+			t.Fatalf("bar %d drifted to %d, want %d", i, b.Timestamp, base.Unix())
+		}
+	}
+	if bars[500].Timestamp != base.Unix()+1 {
+		t.Fatalf("the next trade's bar should keep its own second: %+v", bars[500])
+	}
+}
+
+func TestLoadTicks(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "trades.csv")
+	csvData := "time,price,qty\n2025-03-03 09:30:10,10.0,100\n2025-03-03 09:30:30.5,10.2,200\n"
+	jsonlPath := filepath.Join(dir, "trades.jsonl")
+	ms := time.Date(2025, 3, 3, 9, 30, 10, 0, time.Local).UnixMilli()
+	jsonlData := "{\"timestamp\": " + strconv.FormatInt(ms, 10) + ", \"price\": 10.0, \"size\": 100}\n\n{\"timestamp\": \"2025-03-03T09:30:30.5+08:00\", \"price\": 10.2, \"size\": 200}\n"
+	if err := os.WriteFile(csvPath, []byte(csvData), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(jsonlPath, []byte(jsonlData), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fromCSV, err := LoadTicks(csvPath)
+	if err != nil || len(fromCSV) != 2 {
+		t.Fatalf("csv: %v %+v", err, fromCSV)
+	}
+	if fromCSV[1].Time.Nanosecond() != 5e8 || fromCSV[1].Size != 200 {
+		t.Fatalf("unexpected csv tick: %+v", fromCSV[1])
+	}
+	fromJSONL, err := LoadTicks(jsonlPath)
+	if err != nil || len(fromJSONL) != 2 {
+		t.Fatalf("jsonl: %v %+v", err, fromJSONL)
+	}
+	if !fromJSONL[0].Time.Equal(fromCSV[0].Time) || fromJSONL[1].Price != 10.2 {
+		t.Fatalf("unexpected jsonl ticks: %+v", fromJSONL)
+	}
+
+	if err := os.WriteFile(csvPath, []byte("time,price\n1,2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTicks(csvPath); err == nil {
+		t.Fatal("expected an error for a header without size")
+	}
+}
+
+func TestParseBarSpec(t *testing.T) {
+	for spec, want := range map[string]BarConfig{
+		"time:5m":       {Type: BarTime, Interval: 5 * time.Minute},
+		"tick:500":      {Type: BarTick, Threshold: 500},
+		"dollar:5e7":    {Type: BarDollar, Threshold: 5e7},
+		"volume:50/day": {Type: BarVolume, BarsPerDay: 50},
+	} {
+		got, err := ParseBarSpec(spec)
+		if err != nil || got != want {
+			t.Fatalf("%s: got %+v, %v", spec, got, err)
+		}
+	}
+	for _, spec := range []string{"volume", "time:500ms", "range:10", "tick:0"} {
+		if _, err := ParseBarSpec(spec); err == nil {
+			t.Fatalf("%s: expected an error", spec)
+		}
+	}
+}
+
+func TestParseTickTimeNanoseconds(t *testing.T) {
+	want := time.Unix(0, 1.7e18)
+	for _, s := range []string{"1700000000000000000", "1.7e18", "1700000000000000000.0"} {
+		got, err := parseTickTime(s)
+		if err != nil || !got.Equal(want) {
+			t.Fatalf("%s: got %s, %v", s, got, err)
+		}
+	}
+	if _, err := parseTickTime("1e30"); err == nil {
+		t.Fatal("expected an error for an out-of-range timestamp")
+	}
+}
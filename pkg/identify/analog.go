@@ -0,0 +1,293 @@
+package identify
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// Analog distance metrics
+// 相似形态距离度量
+const (
+	AnalogEuclidean = "euclidean" // z-normalized Euclidean distance (z 标准化欧氏距离)
+	AnalogDTW       = "dtw"       // Dynamic time warping within a Sakoe-Chiba band (带约束的动态时间规整)
+)
+
+// AnalogConfig controls historical analog search.
+// AnalogConfig 控制历史相似形态检索。
+type AnalogConfig struct {
+	Window int    `json:"window"` // Bars compared, ending at the last bar (比较的K线数量)
+	TopK   int    `json:"top_k"`
+	Metric string `json:"metric"` // euclidean/dtw
+	// VolumeWeight is the share of the distance taken by the volume shape (default 0.25).
+	// VolumeWeight 为成交量形态在距离中的占比（默认 0.25）。
+	VolumeWeight float64 `json:"volume_weight"`
+	// Band is the DTW warping band in bars (default Window/4, at least 1).
+	// Band 为 DTW 允许的最大错位K线数（默认 Window/4，至少 1）。
+	Band     int   `json:"band"`
+	Horizons []int `json:"horizons"` // Forward return horizons in bars (后续收益周期)
+}
+
+// DefaultAnalogConfig returns a 10-bar, top-5 Euclidean search with 3/5/10-bar outcomes.
+// DefaultAnalogConfig 返回 10 根K线、前 5 名、欧氏距离、3/5/10 根后续收益的默认配置。
+func DefaultAnalogConfig() AnalogConfig {
+	return AnalogConfig{
+		Window:       10,
+		TopK:         5,
+		Metric:       AnalogEuclidean,
+		VolumeWeight: 0.25,
+		Horizons:     []int{3, 5, 10},
+	}
+}
+
+func normalizeAnalogConfig(cfg AnalogConfig) AnalogConfig {
+	def := DefaultAnalogConfig()
+	if cfg.Window < 3 {
+		cfg.Window = def.Window
+	}
+	if cfg.TopK < 1 {
+		cfg.TopK = def.TopK
+	}
+	if cfg.Metric != AnalogDTW {
+		cfg.Metric = AnalogEuclidean
+	}
+	if cfg.VolumeWeight < 0 || cfg.VolumeWeight > 1 {
+		cfg.VolumeWeight = def.VolumeWeight
+	}
+	if cfg.Band < 1 {
+		cfg.Band = max(1, cfg.Window/4)
+	}
+	if len(cfg.Horizons) == 0 {
+		cfg.Horizons = def.Horizons
+	}
+	return cfg
+}
+
+// AnalogReturn is the close-to-close return (percent) Bars after a match ends.
+// AnalogReturn 为匹配结束后 Bars 根K线的收盘收益（百分比）。
+type AnalogReturn struct {
+	Bars   int     `json:"bars"`
+	Return float64 `json:"return"`
+}
+
+// AnalogMatch is one historical window similar to the current one.
+// AnalogMatch 为与当前窗口相似的一段历史窗口。
+type AnalogMatch struct {
+	Symbol    string  `json:"symbol"`
+	Start     int     `json:"start"` // Index of the first bar in the symbol's history (首根K线序号)
+	End       int     `json:"end"`
+	StartTime string  `json:"start_time"`
+	EndTime   string  `json:"end_time"`
+	Distance  float64 `json:"distance"`
+	// Forward lists the horizons with enough bars after End.
+	// Forward 仅列出 End 之后K线数量足够的周期。
+	Forward []AnalogReturn `json:"forward"`
+}
+
+// AnalogOutcome aggregates the forward returns of the matches at one horizon.
+// AnalogOutcome 汇总各匹配在某一周期的后续收益。
+type AnalogOutcome struct {
+	Bars    int     `json:"bars"`
+	Count   int     `json:"count"`
+	Mean    float64 `json:"mean"`
+	WinRate float64 `json:"win_rate"` // Share of positive returns (正收益占比)
+}
+
+// AnalogResult is the top-K analogs of the latest window.
+// AnalogResult 为最新窗口的前 K 个相似历史窗口。
+type AnalogResult struct {
+	Window   int             `json:"window"`
+	Metric   string          `json:"metric"`
+	Matches  []AnalogMatch   `json:"matches"`
+	Outcomes []AnalogOutcome `json:"outcomes"`
+}
+
+// analogFeatures is a window's z-normalized OHLC (scaled by the close
+// mean/std so bodies and shadows keep their proportions) and volume.
+type analogFeatures struct {
+	ohlc [][4]float64
+	vol  []float64
+}
+
+func newAnalogFeatures(w []CandlestickWrapper) (analogFeatures, bool) {
+	closes := make([]float64, len(w))
+	vols := make([]float64, len(w))
+	for i, c := range w {
+		closes[i], vols[i] = c.Close, c.Volume
+	}
+	mean, std := stat.MeanStdDev(closes, nil)
+	if !(std > 0) {
+		return analogFeatures{}, false
+	}
+	vMean, vStd := stat.MeanStdDev(vols, nil)
+	f := analogFeatures{ohlc: make([][4]float64, len(w)), vol: make([]float64, len(w))}
+	for i, c := range w {
+		f.ohlc[i] = [4]float64{(c.Open - mean) / std, (c.High - mean) / std, (c.Low - mean) / std, (c.Close - mean) / std}
+		if vStd > 0 {
+			f.vol[i] = (c.Volume - vMean) / vStd
+		}
+	}
+	return f, true
+}
+
+// cost is the weighted squared difference of bar i of a and bar j of b.
+func (a analogFeatures) cost(b analogFeatures, i, j int, volumeWeight float64) float64 {
+	price := 0.0
+	for k := 0; k < 4; k++ {
+		d := a.ohlc[i][k] - b.ohlc[j][k]
+		price += d * d
+	}
+	v := a.vol[i] - b.vol[j]
+	return (1-volumeWeight)*price/4 + volumeWeight*v*v
+}
+
+// analogDistance is the root mean cost along the aligned bars (Euclidean) or
+// the cheapest warping path within the band (DTW).
+func analogDistance(a, b analogFeatures, cfg AnalogConfig) float64 {
+	n := len(a.vol)
+	if cfg.Metric != AnalogDTW {
+		sum := 0.0
+		for i := 0; i < n; i++ {
+			sum += a.cost(b, i, i, cfg.VolumeWeight)
+		}
+		return math.Sqrt(sum / float64(n))
+	}
+	prev := make([]float64, n+1)
+	cur := make([]float64, n+1)
+	for j := range prev {
+		prev[j] = math.Inf(1)
+	}
+	prev[0] = 0
+	for i := 1; i <= n; i++ {
+		for j := range cur {
+			cur[j] = math.Inf(1)
+		}
+		for j := max(1, i-cfg.Band); j <= n && j <= i+cfg.Band; j++ {
+			best := math.Min(prev[j-1], math.Min(prev[j], cur[j-1]))
+			cur[j] = a.cost(b, i-1, j-1, cfg.VolumeWeight) + best
+		}
+		prev, cur = cur, prev
+	}
+	return math.Sqrt(prev[n] / float64(n))
+}
+
+// SearchAnalogs finds the TopK windows across history (symbol -> chronological
+// candles) most similar to the last Window bars of cs, the candles of symbol.
+// cs is searched too, excluding windows overlapping the current one, and
+// matches of one symbol never overlap each other. Windows and forward
+// returns must end by the last bar of cs, so no match looks ahead of it.
+// SearchAnalogs 在 history（标的 -> 按时间排列的K线）中检索与 cs（symbol 的K线）最后 Window 根最相似的前 TopK 个窗口。
+// cs 本身也参与检索（排除与当前窗口重叠的部分），同一标的的匹配互不重叠。窗口及其前瞻收益须在 cs 最后一根K线之前结束，避免前视偏差。
+func SearchAnalogs(symbol string, cs []CandlestickWrapper, history map[string][]CandlestickWrapper, cfg AnalogConfig) AnalogResult {
+	cfg = normalizeAnalogConfig(cfg)
+	res := AnalogResult{Window: cfg.Window, Metric: cfg.Metric, Matches: make([]AnalogMatch, 0), Outcomes: make([]AnalogOutcome, 0)}
+	n := len(cs)
+	if n < cfg.Window {
+		return res
+	}
+	query, ok := newAnalogFeatures(cs[n-cfg.Window:])
+	if !ok {
+		return res
+	}
+
+	cutoff := cs[n-1].Timestamp
+	series := make(map[string][]CandlestickWrapper, len(history)+1)
+	for s, h := range history {
+		series[s] = h
+	}
+	series[symbol] = cs
+	symbols := make([]string, 0, len(series))
+	for s := range series {
+		symbols = append(symbols, s)
+	}
+	sort.Strings(symbols)
+
+	candidates := make([]AnalogMatch, 0)
+	for _, s := range symbols {
+		h := series[s]
+		last := len(h) - cfg.Window
+		if s == symbol {
+			last = n - 2*cfg.Window
+		}
+		for start := 0; start <= last; start++ {
+			if !analogBefore(h, start+cfg.Window-1, cfg.Horizons, cutoff) {
+				continue
+			}
+			f, ok := newAnalogFeatures(h[start : start+cfg.Window])
+			if !ok {
+				continue
+			}
+			candidates = append(candidates, AnalogMatch{
+				Symbol:   s,
+				Start:    start,
+				End:      start + cfg.Window - 1,
+				Distance: analogDistance(query, f, cfg),
+			})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Distance < candidates[j].Distance })
+
+	for _, c := range candidates {
+		if len(res.Matches) >= cfg.TopK {
+			break
+		}
+		overlaps := false
+		for _, m := range res.Matches {
+			if m.Symbol == c.Symbol && c.Start <= m.End && m.Start <= c.End {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			continue
+		}
+		h := series[c.Symbol]
+		c.StartTime = time.Unix(h[c.Start].Timestamp, 0).Format("2006-01-02")
+		c.EndTime = time.Unix(h[c.End].Timestamp, 0).Format("2006-01-02")
+		c.Forward = make([]AnalogReturn, 0, len(cfg.Horizons))
+		for _, bars := range cfg.Horizons {
+			if entry := h[c.End].Close; c.End+bars < len(h) && entry > 0 {
+				c.Forward = append(c.Forward, AnalogReturn{Bars: bars, Return: (h[c.End+bars].Close - entry) / entry * 100})
+			}
+		}
+		res.Matches = append(res.Matches, c)
+	}
+
+	for _, bars := range cfg.Horizons {
+		out := AnalogOutcome{Bars: bars}
+		for _, m := range res.Matches {
+			for _, f := range m.Forward {
+				if f.Bars != bars {
+					continue
+				}
+				out.Count++
+				out.Mean += f.Return
+				if f.Return > 0 {
+					out.WinRate++
+				}
+			}
+		}
+		if out.Count > 0 {
+			out.Mean /= float64(out.Count)
+			out.WinRate /= float64(out.Count)
+			res.Outcomes = append(res.Outcomes, out)
+		}
+	}
+	return res
+}
+
+// analogBefore reports whether the window ending at end and every forward
+// return after it close no later than cutoff.
+func analogBefore(h []CandlestickWrapper, end int, horizons []int, cutoff int64) bool {
+	if h[end].Timestamp > cutoff {
+		return false
+	}
+	for _, bars := range horizons {
+		if end+bars < len(h) && h[end+bars].Timestamp > cutoff {
+			return false
+		}
+	}
+	return true
+}
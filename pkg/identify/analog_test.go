@@ -0,0 +1,114 @@
+package identify
+
+import (
+	"math"
+	"testing"
+
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+)
+
+// analogSeries builds n daily bars: noise-like closes around base with the
+// shape v-shape planted at start (scaled by scale), then a rise of rally% per
+// bar for 10 bars.
+func analogSeries(n, start int, base, scale, rally float64) []CandlestickWrapper {
+	shape := []float64{0, -1, -2, -3, -4, -3.5, -2, -1, 0.5, 1}
+	cs := make([]CandlestickWrapper, n)
+	price := base
+	for i := range cs {
+		switch {
+		case i >= start && i < start+len(shape):
+			price = base + shape[i-start]*scale
+		case i >= start+len(shape) && i < start+len(shape)+10:
+			price *= 1 + rally/100
+		default:
+			price = base + math.Sin(float64(i)*1.7)*scale*0.8
+		}
+		cs[i] = NewCandlestickWrapper(&v1.Candlestick{
+			Timestamp: int64(i) * 86400,
+			Open:      price - 0.1*scale, High: price + 0.3*scale, Low: price - 0.3*scale, Close: price,
+			Volume: 1000 + 100*math.Abs(math.Sin(float64(i-start))),
+		})
+	}
+	return cs
+}
+
+func TestSearchAnalogsFindsPlantedShape(t *testing.T) {
+	// The current window ends with the V shape; OTHER has it at 20 with a
+	// rally afterwards, at a different price level and scale.
+	// 当前窗口以 V 形结尾；OTHER 在第 20 根处出现同一形态（价位与幅度不同），之后上涨。
+	cs := analogSeries(50, 40, 100, 1, 0)
+	history := map[string][]CandlestickWrapper{
+		"OTHER": analogSeries(60, 20, 30, 0.4, 1),
+	}
+	res := SearchAnalogs("SELF", cs, history, DefaultAnalogConfig())
+	if len(res.Matches) != 5 || res.Window != 10 || res.Metric != AnalogEuclidean {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	best := res.Matches[0]
+	if best.Symbol != "OTHER" || best.Start != 20 || best.Distance > 0.3 {
+		t.Fatalf("expected the planted window first: %+v", best)
+	}
+	if len(best.Forward) != 3 || best.Forward[2].Bars != 10 || best.Forward[2].Return < 9 {
+		t.Fatalf("expected a ~10%% 10-bar rally after the match: %+v", best.Forward)
+	}
+	for i, m := range res.Matches {
+		if m.Symbol == "SELF" && m.End >= 40 {
+			t.Fatalf("match %d overlaps the query window: %+v", i, m)
+		}
+		for _, o := range res.Matches[:i] {
+			if o.Symbol == m.Symbol && m.Start <= o.End && o.Start <= m.End {
+				t.Fatalf("overlapping matches %+v and %+v", o, m)
+			}
+		}
+	}
+	if len(res.Outcomes) == 0 || res.Outcomes[0].Bars != 3 || res.Outcomes[0].Count == 0 {
+		t.Fatalf("expected outcomes per horizon: %+v", res.Outcomes)
+	}
+}
+
+func TestSearchAnalogsStopsAtQueryTime(t *testing.T) {
+	// LATER runs 30 bars past the query and has the V shape after it; it must
+	// not match, nor may any match's forward returns reach past bar 49.
+	// LATER 比查询序列多 30 根K线且在其后出现 V 形，不得匹配；任何匹配的前瞻收益也不得越过第 49 根。
+	cs := analogSeries(50, 40, 100, 1, 0)
+	history := map[string][]CandlestickWrapper{
+		"LATER": analogSeries(80, 55, 100, 1, 1),
+	}
+	res := SearchAnalogs("SELF", cs, history, DefaultAnalogConfig())
+	cutoff := cs[len(cs)-1].Timestamp
+	for _, m := range res.Matches {
+		h := cs
+		if m.Symbol == "LATER" {
+			h = history["LATER"]
+		}
+		if h[m.End].Timestamp > cutoff || (m.End+10 < len(h) && h[m.End+10].Timestamp > cutoff) {
+			t.Fatalf("match looks ahead of the query: %+v", m)
+		}
+	}
+	if len(res.Matches) == 0 {
+		t.Fatal("windows before the query should still match")
+	}
+}
+
+func TestAnalogDTWToleratesShift(t *testing.T) {
+	mk := func(closes []float64) analogFeatures {
+		cs := make([]CandlestickWrapper, len(closes))
+		for i, c := range closes {
+			cs[i] = NewCandlestickWrapper(&v1.Candlestick{Open: c, High: c, Low: c, Close: c, Volume: 1})
+		}
+		f, ok := newAnalogFeatures(cs)
+		if !ok {
+			t.Fatal("flat window")
+		}
+		return f
+	}
+	a := mk([]float64{0, 0, 1, 3, 1, 0, 0, 0})
+	b := mk([]float64{0, 0, 0, 1, 3, 1, 0, 0})
+	cfg := normalizeAnalogConfig(AnalogConfig{Window: 8})
+	euclid := analogDistance(a, b, cfg)
+	cfg.Metric = AnalogDTW
+	dtw := analogDistance(a, b, cfg)
+	if dtw >= euclid/2 {
+		t.Fatalf("DTW should absorb a one-bar shift: dtw %.3f, euclidean %.3f", dtw, euclid)
+	}
+}
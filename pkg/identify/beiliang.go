@@ -0,0 +1,390 @@
+package identify
+
+import (
+	"fmt"
+	"math"
+)
+
+// Price zones of a beiliang (倍量) bar within its recent range.
+// 倍量K线在近期区间内所处的价格位置。
+const (
+	BeiliangLow  = "low"  // Accumulation / launch confirmation (低位：吸筹/启动确认)
+	BeiliangMid  = "mid"  // Trend continuation (中位：趋势延续)
+	BeiliangHigh = "high" // Divergence / distribution risk (高位：分歧放大，防出货)
+)
+
+// Beiliang volume-price signal types, one per zone and bar direction, plus
+// the follow-through outcome.
+// 倍量量价信号类型：按位置与K线方向区分，另含后续跟随结果。
+const (
+	SignalLowBeiliangAccumulation  = "Low Beiliang Accumulation"
+	SignalLowBeiliangCapitulation  = "Low Beiliang Capitulation"
+	SignalMidBeiliangContinuation  = "Mid Beiliang Continuation"
+	SignalMidBeiliangBreakdown     = "Mid Beiliang Breakdown"
+	SignalHighBeiliangDivergence   = "High Beiliang Divergence"
+	SignalHighBeiliangDistribution = "High Beiliang Distribution"
+	SignalBeiliangFollowThrough    = "Beiliang Follow-Through"
+	SignalBeiliangFailed           = "Beiliang Failed"
+)
+
+// BeiliangConfig controls position classification and follow-through checks.
+// Volume lookback and threshold come from EvidenceConfig.
+// BeiliangConfig 控制倍量位置划分与后续跟随检查；均量窗口与阈值取自 EvidenceConfig。
+type BeiliangConfig struct {
+	// RangeWindow is the N-day high/low range the bar's close is placed in.
+	// RangeWindow 为确定收盘价相对位置所用的 N 日高低区间。
+	RangeWindow  int     `json:"range_window"`
+	LowPosition  float64 `json:"low_position"`  // Position below which the zone is low (低位上限)
+	HighPosition float64 `json:"high_position"` // Position above which the zone is high (高位下限)
+	// Horizons are the follow-through checkpoints in bars (default 3/5/10).
+	// Horizons 为后续跟随的检查点（默认 3/5/10 根K线）。
+	Horizons []int `json:"horizons"`
+	// PullbackVolumeRatio caps pullback-bar volume relative to the beiliang bar.
+	// PullbackVolumeRatio 为回踩K线成交量相对倍量K线的上限。
+	PullbackVolumeRatio float64 `json:"pullback_volume_ratio"`
+}
+
+// DefaultBeiliangConfig returns a 60-bar range split at 1/3 and 2/3 with
+// 3/5/10-bar follow-through and pullbacks at most half the beiliang volume.
+// DefaultBeiliangConfig 返回 60 根K线区间、1/3 与 2/3 分界、3/5/10 根跟随、回踩量不超过倍量一半的默认配置。
+func DefaultBeiliangConfig() BeiliangConfig {
+	return BeiliangConfig{
+		RangeWindow:         60,
+		LowPosition:         1.0 / 3,
+		HighPosition:        2.0 / 3,
+		Horizons:            []int{3, 5, 10},
+		PullbackVolumeRatio: 0.5,
+	}
+}
+
+func normalizeBeiliangConfig(cfg BeiliangConfig) BeiliangConfig {
+	def := DefaultBeiliangConfig()
+	if cfg.RangeWindow < 2 {
+		cfg.RangeWindow = def.RangeWindow
+	}
+	if cfg.LowPosition <= 0 || cfg.HighPosition >= 1 || cfg.LowPosition >= cfg.HighPosition {
+		cfg.LowPosition, cfg.HighPosition = def.LowPosition, def.HighPosition
+	}
+	horizons := make([]int, 0, len(cfg.Horizons))
+	for _, h := range cfg.Horizons {
+		if h > 0 && (len(horizons) == 0 || h > horizons[len(horizons)-1]) {
+			horizons = append(horizons, h)
+		}
+	}
+	if len(horizons) == 0 {
+		horizons = def.Horizons
+	}
+	cfg.Horizons = horizons
+	if cfg.PullbackVolumeRatio <= 0 {
+		cfg.PullbackVolumeRatio = def.PullbackVolumeRatio
+	}
+	return cfg
+}
+
+// BeiliangFollowThrough is the behavior Bars after the beiliang bar.
+// BeiliangFollowThrough 为倍量K线之后 Bars 根K线的表现。
+type BeiliangFollowThrough struct {
+	Bars      int  `json:"bars"`
+	Available bool `json:"available"` // Enough bars have closed (已有足够K线)
+	// Return is the close-to-close return in the bar's direction.
+	// Return 为沿倍量K线方向的收盘收益。
+	Return float64 `json:"return"`
+	// Held is true when no close crossed HoldLevel.
+	// Held 表示期间收盘未跌破（或升破）HoldLevel。
+	Held bool `json:"held"`
+	// PullbackShrink is true when every counter-direction bar had shrinking volume.
+	// PullbackShrink 表示期间所有反向K线均为缩量。
+	PullbackShrink bool `json:"pullback_shrink"`
+}
+
+// BeiliangEvent is one high-volume bar with its zone and follow-through.
+// BeiliangEvent 为一根倍量K线及其位置与后续跟随。
+type BeiliangEvent struct {
+	Position      int     `json:"position"`
+	Direction     string  `json:"direction"` // Bar direction: bullish/bearish (K线方向)
+	Zone          string  `json:"zone"`
+	PricePosition float64 `json:"price_position"` // (close-low_N)/(high_N-low_N)
+	VolumeRatio   float64 `json:"volume_ratio"`
+	// HoldLevel is the body midpoint the following closes should hold.
+	// HoldLevel 为后续收盘应守住的实体中点。
+	HoldLevel     float64                 `json:"hold_level"`
+	Signal        string                  `json:"signal"`
+	FollowThrough []BeiliangFollowThrough `json:"follow_through"`
+	// State is pending until the last horizon, confirmed when it held with a
+	// positive return, otherwise invalidated.
+	// State 在最后一个检查点之前为 pending；守住且收益为正为 confirmed，否则为 invalidated。
+	State    string `json:"state"`
+	FailedAt int    `json:"failed_at"` // First close through HoldLevel, -1 if none (首次失守位置)
+}
+
+// AnalyzeBeiliang finds beiliang bars in cs (chronological) and tracks their
+// follow-through with the bars available.
+// AnalyzeBeiliang 在 cs（时间正序）中识别倍量K线，并基于已有K线跟踪其后续表现。
+func AnalyzeBeiliang(cs []CandlestickWrapper, cfg EvidenceConfig) []BeiliangEvent {
+	out := make([]BeiliangEvent, 0)
+	for i := range cs {
+		if ev, ok := beiliangAt(cs, i, cfg); ok {
+			trackBeiliang(cs, &ev, len(cs)-1, normalizeBeiliangConfig(cfg.Beiliang))
+			out = append(out, ev)
+		}
+	}
+	return out
+}
+
+// beiliangAt classifies bar i when its volume reaches the beiliang threshold
+// and a full RangeWindow of bars ends at it.
+func beiliangAt(cs []CandlestickWrapper, i int, cfg EvidenceConfig) (BeiliangEvent, bool) {
+	bc := normalizeBeiliangConfig(cfg.Beiliang)
+	if i+1 < bc.RangeWindow {
+		return BeiliangEvent{}, false
+	}
+	c := cs[i]
+	avgVol := averageVolumeBefore(cs, i, cfg.VolumeLookback)
+	threshold := cfg.BeiliangThreshold
+	if threshold <= 0 {
+		threshold = cfg.VolumeBoostThreshold
+	}
+	if avgVol <= 0 || c.Volume/avgVol < threshold || c.Close == c.Open {
+		return BeiliangEvent{}, false
+	}
+
+	start := i - bc.RangeWindow + 1
+	high, low := c.High, c.Low
+	for _, b := range cs[start:i] {
+		high = math.Max(high, b.High)
+		low = math.Min(low, b.Low)
+	}
+	pos := 0.5
+	if high > low {
+		pos = (c.Close - low) / (high - low)
+	}
+
+	ev := BeiliangEvent{
+		Position:      i,
+		Direction:     "bullish",
+		PricePosition: pos,
+		VolumeRatio:   c.Volume / avgVol,
+		HoldLevel:     (c.Open + c.Close) / 2,
+		State:         PatternPending,
+		FailedAt:      -1,
+	}
+	if c.Close < c.Open {
+		ev.Direction = "bearish"
+	}
+	switch {
+	case pos < bc.LowPosition:
+		ev.Zone = BeiliangLow
+	case pos > bc.HighPosition:
+		ev.Zone = BeiliangHigh
+	default:
+		ev.Zone = BeiliangMid
+	}
+	ev.Signal = beiliangSignal(ev.Zone, ev.Direction)
+	return ev, true
+}
+
+func beiliangSignal(zone, direction string) string {
+	bullish := direction == "bullish"
+	switch zone {
+	case BeiliangLow:
+		if bullish {
+			return SignalLowBeiliangAccumulation
+		}
+		return SignalLowBeiliangCapitulation
+	case BeiliangHigh:
+		if bullish {
+			return SignalHighBeiliangDivergence
+		}
+		return SignalHighBeiliangDistribution
+	}
+	if bullish {
+		return SignalMidBeiliangContinuation
+	}
+	return SignalMidBeiliangBreakdown
+}
+
+// trackBeiliang fills the follow-through of ev using bars up to end.
+func trackBeiliang(cs []CandlestickWrapper, ev *BeiliangEvent, end int, cfg BeiliangConfig) {
+	i := ev.Position
+	sign := 1.0
+	if ev.Direction == "bearish" {
+		sign = -1
+	}
+	last := cfg.Horizons[len(cfg.Horizons)-1]
+	held, shrink := true, true
+	ev.FollowThrough = make([]BeiliangFollowThrough, 0, len(cfg.Horizons))
+	k := 0
+	for j := i + 1; j <= i+last && j <= end; j++ {
+		c := cs[j]
+		if held && sign*(c.Close-ev.HoldLevel) < 0 {
+			held = false
+			ev.FailedAt = j
+		}
+		if sign*(c.Close-c.Open) < 0 && c.Volume > cfg.PullbackVolumeRatio*cs[i].Volume {
+			shrink = false
+		}
+		for k < len(cfg.Horizons) && cfg.Horizons[k] == j-i {
+			ev.FollowThrough = append(ev.FollowThrough, BeiliangFollowThrough{
+				Bars:           cfg.Horizons[k],
+				Available:      true,
+				Return:         sign * (c.Close/cs[i].Close - 1),
+				Held:           held,
+				PullbackShrink: shrink,
+			})
+			k++
+		}
+	}
+	for ; k < len(cfg.Horizons); k++ {
+		ev.FollowThrough = append(ev.FollowThrough, BeiliangFollowThrough{Bars: cfg.Horizons[k]})
+	}
+
+	final := ev.FollowThrough[len(ev.FollowThrough)-1]
+	switch {
+	case !held:
+		ev.State = PatternInvalidated
+	case final.Available && final.Return > 0:
+		ev.State = PatternConfirmed
+	case final.Available:
+		ev.State = PatternInvalidated
+	default:
+		ev.State = PatternPending
+	}
+}
+
+// BeiliangVolumeSignals turns events into volume-price signals: one at the
+// beiliang bar for its zone and one where the follow-through resolved.
+// BeiliangVolumeSignals 将倍量事件转换为量价信号：倍量K线处的位置信号，以及跟随结果确定处的信号。
+func BeiliangVolumeSignals(cs []CandlestickWrapper, events []BeiliangEvent) []VolumePriceSignal {
+	out := make([]VolumePriceSignal, 0, len(events))
+	for _, ev := range events {
+		c := cs[ev.Position]
+		direction := ev.Direction
+		strength := 0.75
+		switch ev.Signal {
+		case SignalLowBeiliangAccumulation, SignalHighBeiliangDistribution:
+			strength = 0.85
+		case SignalHighBeiliangDivergence, SignalLowBeiliangCapitulation:
+			direction = "neutral"
+			strength = 0.7
+		}
+		out = append(out, VolumePriceSignal{
+			Type:      ev.Signal,
+			Direction: direction,
+			Position:  ev.Position,
+			Strength:  strength,
+			Price:     c.Close,
+			Volume:    c.Volume,
+			Reason:    fmt.Sprintf("%.2fx average volume at %.0f%% of the range (%s zone)", ev.VolumeRatio, ev.PricePosition*100, ev.Zone),
+		})
+
+		switch ev.State {
+		case PatternConfirmed:
+			final := ev.FollowThrough[len(ev.FollowThrough)-1]
+			at := ev.Position + final.Bars
+			strength := 0.75
+			if final.PullbackShrink {
+				strength = 0.85
+			}
+			out = append(out, VolumePriceSignal{
+				Type:      SignalBeiliangFollowThrough,
+				Direction: ev.Direction,
+				Position:  at,
+				Strength:  strength,
+				Price:     cs[at].Close,
+				Volume:    cs[at].Volume,
+				Reason:    fmt.Sprintf("held the beiliang bar at %d for %d bars (pullback shrink: %v)", ev.Position, final.Bars, final.PullbackShrink),
+			})
+		case PatternInvalidated:
+			at := ev.FailedAt
+			reason := fmt.Sprintf("closed through the hold level %.2f of the beiliang bar at %d", ev.HoldLevel, ev.Position)
+			if at < 0 {
+				final := ev.FollowThrough[len(ev.FollowThrough)-1]
+				at = ev.Position + final.Bars
+				reason = fmt.Sprintf("no progress %d bars after the beiliang bar at %d", final.Bars, ev.Position)
+			}
+			out = append(out, VolumePriceSignal{
+				Type:      SignalBeiliangFailed,
+				Direction: opposite(ev.Direction),
+				Position:  at,
+				Strength:  0.7,
+				Price:     cs[at].Close,
+				Volume:    cs[at].Volume,
+				Reason:    reason,
+			})
+		}
+	}
+	return out
+}
+
+// beiliangFactors scores the pattern bar's own beiliang position and the
+// follow-through, up to the pattern bar, of the latest same-direction
+// beiliang bar within the last horizon. Returns the score delta, factors and
+// contradictions; neutral patterns get none.
+func beiliangFactors(cs []CandlestickWrapper, p PatternSignal, cfg EvidenceConfig) (float64, []FactorHit, []string) {
+	bc := normalizeBeiliangConfig(cfg.Beiliang)
+	delta := 0.0
+	factors := make([]FactorHit, 0, 2)
+	contradictions := make([]string, 0, 1)
+	if !directional(p) {
+		return delta, factors, contradictions
+	}
+
+	if ev, ok := beiliangAt(cs, p.Position, cfg); ok {
+		passed := (p.Direction == "bullish" && ev.Zone != BeiliangHigh) ||
+			(p.Direction == "bearish" && ev.Zone != BeiliangLow)
+		factors = append(factors, FactorHit{
+			Name:      "beiliang_position",
+			Value:     ev.PricePosition,
+			Threshold: bc.HighPosition,
+			Passed:    passed,
+			Reason:    fmt.Sprintf("beiliang in the %s zone of the %d-bar range", ev.Zone, bc.RangeWindow),
+		})
+		switch {
+		case passed:
+			delta += 0.05
+		case ev.Zone == BeiliangHigh:
+			contradictions = append(contradictions, "high-position beiliang warns of distribution")
+		default:
+			contradictions = append(contradictions, "low-position beiliang may be capitulation")
+		}
+	}
+
+	last := bc.Horizons[len(bc.Horizons)-1]
+	for j := p.Position - 1; j >= 0 && j >= p.Position-last; j-- {
+		ev, ok := beiliangAt(cs, j, cfg)
+		if !ok || ev.Direction != p.Direction {
+			continue
+		}
+		trackBeiliang(cs, &ev, p.Position, bc)
+		held := ev.FailedAt < 0
+		sign := 1.0
+		if ev.Direction == "bearish" {
+			sign = -1
+		}
+		ret := sign * (cs[p.Position].Close/cs[j].Close - 1)
+		factors = append(factors, FactorHit{
+			Name:      "beiliang_follow_through",
+			Value:     ret,
+			Threshold: 0,
+			Passed:    held && ret > 0,
+			Reason:    fmt.Sprintf("beiliang bar %d bars earlier held its midpoint and moved in the pattern direction", p.Position-j),
+		})
+		if held && ret > 0 {
+			delta += 0.05
+		} else if !held {
+			contradictions = append(contradictions, "prior beiliang bar failed to hold its midpoint")
+		}
+		break
+	}
+	return delta, factors, contradictions
+}
+
+func opposite(direction string) string {
+	switch direction {
+	case "bullish":
+		return "bearish"
+	case "bearish":
+		return "bullish"
+	}
+	return direction
+}
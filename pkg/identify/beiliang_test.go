@@ -0,0 +1,141 @@
+package identify
+
+import (
+	"testing"
+
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+)
+
+func volumeCandle(open, high, low, close, volume float64) CandlestickWrapper {
+	return NewCandlestickWrapper(&v1.Candlestick{Open: open, High: high, Low: low, Close: close, Volume: volume})
+}
+
+// beiliangSeries is a 60-bar range between 90 and 110 followed by the given bars.
+func beiliangSeries(tail ...CandlestickWrapper) []CandlestickWrapper {
+	cs := make([]CandlestickWrapper, 0, 60+len(tail))
+	for i := 0; i < 60; i++ {
+		base := 100.0
+		if i%2 == 0 {
+			base = 101
+		}
+		cs = append(cs, volumeCandle(base, 110, 90, base+0.5, 1000))
+	}
+	return append(cs, tail...)
+}
+
+func TestAnalyzeBeiliangZonesAndFollowThrough(t *testing.T) {
+	// Low-zone bullish beiliang that holds and rises for 10 bars with quiet pullbacks.
+	// 低位放量阳线，此后 10 根K线守住中点并上行，回踩缩量。
+	tail := []CandlestickWrapper{volumeCandle(90, 93, 89.5, 92.5, 3000)}
+	price := 92.5
+	for k := 0; k < 10; k++ {
+		if k == 4 {
+			tail = append(tail, volumeCandle(price, price+0.2, price-0.6, price-0.4, 800))
+			price -= 0.4
+			continue
+		}
+		tail = append(tail, volumeCandle(price, price+0.8, price-0.2, price+0.6, 1200))
+		price += 0.6
+	}
+	cs := beiliangSeries(tail...)
+
+	events := AnalyzeBeiliang(cs, DefaultEvidenceConfig())
+	if len(events) != 1 {
+		t.Fatalf("expected one beiliang event, got %+v", events)
+	}
+	ev := events[0]
+	if ev.Position != 60 || ev.Zone != BeiliangLow || ev.Signal != SignalLowBeiliangAccumulation {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+	if ev.State != PatternConfirmed || ev.FailedAt != -1 || len(ev.FollowThrough) != 3 {
+		t.Fatalf("expected a confirmed follow-through: %+v", ev)
+	}
+	for _, ft := range ev.FollowThrough {
+		if !ft.Available || !ft.Held || !ft.PullbackShrink || ft.Return <= 0 {
+			t.Fatalf("horizon %d should hold with shrinking pullbacks: %+v", ft.Bars, ft)
+		}
+	}
+
+	sigs := BeiliangVolumeSignals(cs, events)
+	if len(sigs) != 2 || sigs[1].Type != SignalBeiliangFollowThrough || sigs[1].Position != 70 {
+		t.Fatalf("unexpected signals: %+v", sigs)
+	}
+
+	// Same bar cut short: follow-through still pending at 3 bars.
+	// 仅有 3 根后续K线时仍为 pending。
+	short := AnalyzeBeiliang(cs[:64], DefaultEvidenceConfig())
+	if short[0].State != PatternPending || !short[0].FollowThrough[0].Available || short[0].FollowThrough[1].Available {
+		t.Fatalf("expected pending with only the 3-bar horizon: %+v", short[0])
+	}
+}
+
+func TestAnalyzeBeiliangHighZoneFailure(t *testing.T) {
+	// High-zone bearish beiliang whose next close recovers above the body midpoint.
+	// 高位放量阴线，次日收盘收复实体中点。
+	cs := beiliangSeries(
+		volumeCandle(109, 109.5, 104, 105, 3000),
+		volumeCandle(105, 108, 104.5, 107.5, 1500),
+	)
+	events := AnalyzeBeiliang(cs, DefaultEvidenceConfig())
+	if len(events) != 1 {
+		t.Fatalf("expected one beiliang event, got %+v", events)
+	}
+	ev := events[0]
+	if ev.Zone != BeiliangHigh || ev.Signal != SignalHighBeiliangDistribution {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+	if ev.State != PatternInvalidated || ev.FailedAt != 61 {
+		t.Fatalf("expected failure at 61: %+v", ev)
+	}
+	sigs := BeiliangVolumeSignals(cs, events)
+	if len(sigs) != 2 || sigs[1].Type != SignalBeiliangFailed || sigs[1].Direction != "bullish" {
+		t.Fatalf("unexpected signals: %+v", sigs)
+	}
+}
+
+func TestBeiliangEvidenceFactors(t *testing.T) {
+	cs := beiliangSeries(volumeCandle(109, 109.8, 108.5, 109.6, 3000))
+	bullish := PatternSignal{Type: "Bullish Marubozu", Direction: "bullish", Position: 60, Strength: 0.8}
+	ev := BuildPatternEvidence([]PatternSignal{bullish}, cs, DefaultEvidenceConfig())[0]
+
+	found := false
+	for _, f := range ev.VolumeFactors {
+		if f.Name == "beiliang_position" {
+			found = true
+			if f.Passed {
+				t.Fatalf("high-zone beiliang should not pass for a bullish pattern: %+v", f)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("missing beiliang_position factor: %+v", ev.VolumeFactors)
+	}
+	contradicted := false
+	for _, c := range ev.ContradictionFactors {
+		if c == "high-position beiliang warns of distribution" {
+			contradicted = true
+		}
+	}
+	if !contradicted {
+		t.Fatalf("expected distribution contradiction: %+v", ev.ContradictionFactors)
+	}
+	doji := PatternSignal{Type: "Doji", Direction: "neutral", Position: 60, Strength: 0.8}
+	if delta, factors, _ := beiliangFactors(cs, doji, DefaultEvidenceConfig()); delta != 0 || len(factors) != 0 {
+		t.Fatalf("neutral patterns should get no beiliang factors: %v %+v", delta, factors)
+	}
+
+	// A later bullish pattern sees the follow-through of that beiliang bar.
+	// 之后的看涨形态可读取该倍量K线的后续跟随。
+	cs = append(cs, volumeCandle(109.6, 110.5, 109.4, 110.2, 1100), volumeCandle(110.2, 111, 110, 110.8, 1000))
+	later := PatternSignal{Type: "Bullish Marubozu", Direction: "bullish", Position: 62, Strength: 0.8}
+	ev = BuildPatternEvidence([]PatternSignal{later}, cs, DefaultEvidenceConfig())[0]
+	for _, f := range ev.VolumeFactors {
+		if f.Name == "beiliang_follow_through" {
+			if !f.Passed || f.Value <= 0 {
+				t.Fatalf("follow-through should pass: %+v", f)
+			}
+			return
+		}
+	}
+	t.Fatalf("missing beiliang_follow_through factor: %+v", ev.VolumeFactors)
+}
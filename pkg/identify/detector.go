@@ -0,0 +1,237 @@
+package identify
+
+import (
+	"math"
+	"sort"
+)
+
+// Body reference modes decide what "long" and "short" bodies are measured against.
+// 实体参照模式决定“长实体”“短实体”的衡量基准。
+const (
+	BodyReferenceRange      = "range"       // Within the candle itself, the historical behavior (仅与自身振幅比较，保持原有行为)
+	BodyReferenceATR        = "atr"         // Relative to the average true range of prior bars (相对之前K线的平均真实波幅)
+	BodyReferenceMedianBody = "median_body" // Relative to the median body of prior bars (相对之前K线的实体中位数)
+)
+
+// DetectorConfig holds the tunable ratios of the boolean pattern detectors.
+// Ratios ending in "Ratio" are fractions of the candle range unless noted;
+// "Multiple" values are multiples of the candle body. Every ratio and
+// multiple must be positive: zero or negative means unset and takes the
+// default, so a bound cannot be set to exactly 0 and a tiny positive value
+// stands in for "none".
+// DetectorConfig 保存布尔形态识别器的可调比例。除特别说明外，Ratio 为相对K线振幅的比例，Multiple 为相对实体的倍数。
+// 所有比例与倍数须为正数：零或负数视为未设置并取默认值，因此无法设为 0，需要“无影线”等效果时请用极小的正数。
+type DetectorConfig struct {
+	// BodyReference is range (default), atr or median_body. In atr/median_body mode
+	// patterns that require long or short bodies compare them with the candles
+	// preceding the pattern, which must be present in cs after the pattern span.
+	// BodyReference 取 range（默认）、atr 或 median_body；后两者将长/短实体与形态之前的K线比较，
+	// 这些K线需在 cs 中位于形态之后。
+	BodyReference string `json:"body_reference"`
+	// ReferencePeriod is the number of prior bars used by atr/median_body (> 0, default 14).
+	// ReferencePeriod 是 atr/median_body 使用的历史K线数量（> 0，默认 14）。
+	ReferencePeriod int `json:"reference_period"`
+	// LongBodyMultiple: a body is long when >= multiple x reference (> 0, default 1.0).
+	// LongBodyMultiple：实体 >= 倍数 x 参照值时为长实体（> 0，默认 1.0）。
+	LongBodyMultiple float64 `json:"long_body_multiple"`
+	// ShortBodyMultiple: a body is short when <= multiple x reference (> 0, default 0.5).
+	// ShortBodyMultiple：实体 <= 倍数 x 参照值时为短实体（> 0，默认 0.5）。
+	ShortBodyMultiple float64 `json:"short_body_multiple"`
+
+	DojiBodyRatio   float64 `json:"doji_body_ratio"`   // > 0, default 0.1
+	DojiShadowRatio float64 `json:"doji_shadow_ratio"` // > 0, default 0.2
+
+	LongLeggedBodyRatio      float64 `json:"long_legged_body_ratio"`      // > 0, default 0.08
+	LongLeggedShadowRatio    float64 `json:"long_legged_shadow_ratio"`    // > 0, default 0.3
+	LongLeggedShadowDominant float64 `json:"long_legged_shadow_dominant"` // > 0, default 0.85
+
+	// MarubozuShadowMultiple is the largest shadow allowed, as a multiple of the body (> 0, default 0.01).
+	// MarubozuShadowMultiple 是允许的最大影线（实体倍数，> 0，默认 0.01）。
+	MarubozuShadowMultiple float64 `json:"marubozu_shadow_multiple"`
+
+	// UmbrellaShadowMultiple is the minimum lower shadow of an umbrella (> 0, default 2.5).
+	// UmbrellaShadowMultiple 是伞形线下影线相对实体的最小倍数（> 0，默认 2.5）。
+	UmbrellaShadowMultiple float64 `json:"umbrella_shadow_multiple"`
+	// UmbrellaUpperMultiple is the largest upper shadow of an umbrella (> 0, default 0.2).
+	// UmbrellaUpperMultiple 是伞形线上影线相对实体的最大倍数（> 0，默认 0.2）。
+	UmbrellaUpperMultiple float64 `json:"umbrella_upper_multiple"`
+
+	// HammerShadowMultiple is the minimum long shadow of hammer-like candles (> 0, default 2).
+	// HammerShadowMultiple 是锤头类K线长影线相对实体的最小倍数（> 0，默认 2）。
+	HammerShadowMultiple float64 `json:"hammer_shadow_multiple"`
+	// HammerOppositeMultiple is the maximum opposite shadow of hammer-like candles (> 0, default 0.1).
+	// HammerOppositeMultiple 是锤头类K线反向影线相对实体的最大倍数（> 0，默认 0.1）。
+	HammerOppositeMultiple float64 `json:"hammer_opposite_multiple"`
+
+	StarBodyRatio float64 `json:"star_body_ratio"` // middle candle of stars, > 0, default 0.3
+
+	// TweezerTolerance is the relative difference allowed between matching extremes (> 0, default 0.001).
+	// TweezerTolerance 是镊子形态两根K线极值允许的相对差（> 0，默认 0.001）。
+	TweezerTolerance float64 `json:"tweezer_tolerance"`
+
+	// HaramiBodyMultiple is the largest inner body as a multiple of the mother body (> 0, default 0.5).
+	// HaramiBodyMultiple 是孕线内部实体相对母线实体的最大倍数（> 0，默认 0.5）。
+	HaramiBodyMultiple float64 `json:"harami_body_multiple"`
+
+	DragonflyBodyRatio     float64 `json:"dragonfly_body_ratio"`     // > 0, default 0.1
+	DragonflyOppositeRatio float64 `json:"dragonfly_opposite_ratio"` // > 0, default 0.05
+	DragonflyShadowRatio   float64 `json:"dragonfly_shadow_ratio"`   // > 0, default 0.5
+
+	// ThreeMethodsBodyRatio is the minimum body/range of the first candle (> 0, default 0.5).
+	// ThreeMethodsBodyRatio 是首根K线实体占振幅的最小比例（> 0，默认 0.5）。
+	ThreeMethodsBodyRatio float64 `json:"three_methods_body_ratio"`
+	// ThreeMethodsInnerMultiple is the largest inner body as a multiple of the first body (> 0, default 0.5).
+	// ThreeMethodsInnerMultiple 是中间K线实体相对首根实体的最大倍数（> 0，默认 0.5）。
+	ThreeMethodsInnerMultiple float64 `json:"three_methods_inner_multiple"`
+
+	SpinningTopBodyRatio    float64 `json:"spinning_top_body_ratio"`    // > 0, default 0.3
+	SpinningTopShadowRatio  float64 `json:"spinning_top_shadow_ratio"`  // > 0, default 0.2
+	SpinningTopBalanceRatio float64 `json:"spinning_top_balance_ratio"` // > 0, default 0.2
+}
+
+// DefaultDetectorConfig returns the ratios the detectors have always used.
+// DefaultDetectorConfig 返回识别器一直使用的默认比例。
+func DefaultDetectorConfig() DetectorConfig {
+	return DetectorConfig{
+		BodyReference:             BodyReferenceRange,
+		ReferencePeriod:           14,
+		LongBodyMultiple:          1.0,
+		ShortBodyMultiple:         0.5,
+		DojiBodyRatio:             0.1,
+		DojiShadowRatio:           0.2,
+		LongLeggedBodyRatio:       0.08,
+		LongLeggedShadowRatio:     0.3,
+		LongLeggedShadowDominant:  0.85,
+		MarubozuShadowMultiple:    0.01,
+		UmbrellaShadowMultiple:    2.5,
+		UmbrellaUpperMultiple:     0.2,
+		HammerShadowMultiple:      2,
+		HammerOppositeMultiple:    0.1,
+		StarBodyRatio:             0.3,
+		TweezerTolerance:          0.001,
+		HaramiBodyMultiple:        0.5,
+		DragonflyBodyRatio:        0.1,
+		DragonflyOppositeRatio:    0.05,
+		DragonflyShadowRatio:      0.5,
+		ThreeMethodsBodyRatio:     0.5,
+		ThreeMethodsInnerMultiple: 0.5,
+		SpinningTopBodyRatio:      0.3,
+		SpinningTopShadowRatio:    0.2,
+		SpinningTopBalanceRatio:   0.2,
+	}
+}
+
+// Detector runs the boolean pattern detectors with a given configuration.
+// Detector 按给定配置执行布尔形态识别。
+type Detector struct {
+	config DetectorConfig
+}
+
+// NewDetector creates a detector; zero-valued fields fall back to defaults.
+// NewDetector 创建识别器；零值字段回退为默认值。
+func NewDetector(config DetectorConfig) *Detector {
+	return &Detector{config: normalizeDetectorConfig(config)}
+}
+
+// Config returns the effective configuration.
+// Config 返回实际生效的配置。
+func (d *Detector) Config() DetectorConfig {
+	return d.config
+}
+
+var defaultDetector = NewDetector(DefaultDetectorConfig())
+
+func normalizeDetectorConfig(cfg DetectorConfig) DetectorConfig {
+	def := DefaultDetectorConfig()
+	if cfg.BodyReference == "" {
+		cfg.BodyReference = def.BodyReference
+	}
+	if cfg.ReferencePeriod < 1 {
+		cfg.ReferencePeriod = def.ReferencePeriod
+	}
+	fill := func(v *float64, d float64) {
+		if *v <= 0 {
+			*v = d
+		}
+	}
+	fill(&cfg.LongBodyMultiple, def.LongBodyMultiple)
+	fill(&cfg.ShortBodyMultiple, def.ShortBodyMultiple)
+	fill(&cfg.DojiBodyRatio, def.DojiBodyRatio)
+	fill(&cfg.DojiShadowRatio, def.DojiShadowRatio)
+	fill(&cfg.LongLeggedBodyRatio, def.LongLeggedBodyRatio)
+	fill(&cfg.LongLeggedShadowRatio, def.LongLeggedShadowRatio)
+	fill(&cfg.LongLeggedShadowDominant, def.LongLeggedShadowDominant)
+	fill(&cfg.MarubozuShadowMultiple, def.MarubozuShadowMultiple)
+	fill(&cfg.UmbrellaShadowMultiple, def.UmbrellaShadowMultiple)
+	fill(&cfg.UmbrellaUpperMultiple, def.UmbrellaUpperMultiple)
+	fill(&cfg.HammerShadowMultiple, def.HammerShadowMultiple)
+	fill(&cfg.HammerOppositeMultiple, def.HammerOppositeMultiple)
+	fill(&cfg.StarBodyRatio, def.StarBodyRatio)
+	fill(&cfg.TweezerTolerance, def.TweezerTolerance)
+	fill(&cfg.HaramiBodyMultiple, def.HaramiBodyMultiple)
+	fill(&cfg.DragonflyBodyRatio, def.DragonflyBodyRatio)
+	fill(&cfg.DragonflyOppositeRatio, def.DragonflyOppositeRatio)
+	fill(&cfg.DragonflyShadowRatio, def.DragonflyShadowRatio)
+	fill(&cfg.ThreeMethodsBodyRatio, def.ThreeMethodsBodyRatio)
+	fill(&cfg.ThreeMethodsInnerMultiple, def.ThreeMethodsInnerMultiple)
+	fill(&cfg.SpinningTopBodyRatio, def.SpinningTopBodyRatio)
+	fill(&cfg.SpinningTopShadowRatio, def.SpinningTopShadowRatio)
+	fill(&cfg.SpinningTopBalanceRatio, def.SpinningTopBalanceRatio)
+	return cfg
+}
+
+// bodyReference returns the ATR or median body of the ReferencePeriod candles
+// that precede a pattern spanning cs[0:span]. ok is false in range mode or
+// when cs does not carry enough history, in which case callers keep the
+// within-candle rules.
+func (d *Detector) bodyReference(cs []CandlestickWrapper, span int) (float64, bool) {
+	if d.config.BodyReference == BodyReferenceRange {
+		return 0, false
+	}
+	period := d.config.ReferencePeriod
+	if len(cs) < span+period {
+		return 0, false
+	}
+	prior := cs[span : span+period]
+
+	switch d.config.BodyReference {
+	case BodyReferenceATR:
+		sum := 0.0
+		for j, c := range prior {
+			tr := c.High - c.Low
+			if span+j+1 < len(cs) {
+				prevClose := cs[span+j+1].Close
+				tr = math.Max(tr, math.Max(math.Abs(c.High-prevClose), math.Abs(c.Low-prevClose)))
+			}
+			sum += tr
+		}
+		ref := sum / float64(period)
+		return ref, ref > 0
+	case BodyReferenceMedianBody:
+		bodies := make([]float64, 0, period)
+		for _, c := range prior {
+			bodies = append(bodies, c.Body())
+		}
+		sort.Float64s(bodies)
+		ref := bodies[period/2]
+		if period%2 == 0 {
+			ref = (bodies[period/2-1] + bodies[period/2]) / 2
+		}
+		return ref, ref > 0
+	}
+	return 0, false
+}
+
+// longBody reports whether cs[i] has a long body relative to the history
+// behind a pattern of the given span; it is always true when no reference
+// is available.
+func (d *Detector) longBody(cs []CandlestickWrapper, span, i int) bool {
+	ref, ok := d.bodyReference(cs, span)
+	return !ok || cs[i].Body() >= d.config.LongBodyMultiple*ref
+}
+
+// shortBody is the counterpart of longBody for small bodies.
+func (d *Detector) shortBody(cs []CandlestickWrapper, span, i int) bool {
+	ref, ok := d.bodyReference(cs, span)
+	return !ok || cs[i].Body() <= d.config.ShortBodyMultiple*ref
+}
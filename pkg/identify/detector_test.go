@@ -0,0 +1,71 @@
+package identify
+
+import (
+	"testing"
+
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+)
+
+func candle(open, high, low, close float64) CandlestickWrapper {
+	return NewCandlestickWrapper(&v1.Candlestick{Open: open, High: high, Low: low, Close: close, Volume: 1000})
+}
+
+func TestDetectorRatiosAreTunable(t *testing.T) {
+	// Body is 12% of the range: not a doji at the default 10%.
+	// 实体占振幅 12%：默认 10% 阈值下不是十字星。
+	cs := []CandlestickWrapper{candle(100, 105, 95, 101.2)}
+	if Doji(cs) || NewDetector(DefaultDetectorConfig()).Doji(cs) {
+		t.Fatal("default detector should reject a 12% body doji")
+	}
+	cfg := DefaultDetectorConfig()
+	cfg.DojiBodyRatio = 0.15
+	if !NewDetector(cfg).Doji(cs) {
+		t.Fatal("relaxed doji_body_ratio should accept the candle")
+	}
+
+	// Body 1, lower shadow 4, upper shadow 0.3: above the default 0.2 x body.
+	// 实体 1、下影线 4、上影线 0.3：超过默认的 0.2 倍实体。
+	umbrella := []CandlestickWrapper{candle(100, 101.3, 96, 101)}
+	if Umbrella(umbrella) {
+		t.Fatal("default detector should reject a 0.3 x body upper shadow")
+	}
+	cfg = DefaultDetectorConfig()
+	cfg.UmbrellaUpperMultiple = 0.5
+	if !NewDetector(cfg).Umbrella(umbrella) {
+		t.Fatal("relaxed umbrella_upper_multiple should accept the candle")
+	}
+}
+
+func TestDetectorRelativeBodies(t *testing.T) {
+	// Three small advancing candles, newest first, after 14 wide-bodied bars.
+	// 三根小幅上涨K线（新到旧），之前为 14 根大实体K线。
+	cs := []CandlestickWrapper{
+		candle(101.6, 102.7, 101.5, 102.6),
+		candle(100.8, 101.9, 100.7, 101.8),
+		candle(100, 101.1, 99.9, 101),
+	}
+	history := make([]CandlestickWrapper, 0, 14)
+	for i := 0; i < 14; i++ {
+		history = append(history, candle(105, 106, 99, 100))
+	}
+	full := append(append([]CandlestickWrapper(nil), cs...), history...)
+
+	if !ThreeWhiteSoldiers(full) {
+		t.Fatal("range mode should keep the within-candle behavior")
+	}
+
+	cfg := DefaultDetectorConfig()
+	cfg.BodyReference = BodyReferenceMedianBody
+	d := NewDetector(cfg)
+	if d.ThreeWhiteSoldiers(full) {
+		t.Fatal("bodies far below the median body should not be long soldiers")
+	}
+	if !d.ThreeWhiteSoldiers(cs) {
+		t.Fatal("without enough history the detector should fall back to range mode")
+	}
+
+	cfg.BodyReference = BodyReferenceATR
+	if NewDetector(cfg).ThreeWhiteSoldiers(full) {
+		t.Fatal("bodies far below ATR should not be long soldiers")
+	}
+}
@@ -0,0 +1,497 @@
+package identify
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// PatternDiagnosis lists every rule a detector evaluated for one candle window.
+// PatternDiagnosis 列出识别器对一个K线窗口评估的每条规则。
+type PatternDiagnosis struct {
+	Pattern    string      `json:"pattern"`
+	Detected   bool        `json:"detected"`
+	Conditions []FactorHit `json:"conditions"`
+	Failed     []string    `json:"failed"` // names of failed conditions (未通过的条件名)
+}
+
+// Diagnose explains the default detector's decision; see Detector.Diagnose.
+// Diagnose 使用默认识别器解释判定结果，参见 Detector.Diagnose。
+func Diagnose(pattern string, cs []CandlestickWrapper) (PatternDiagnosis, error) {
+	return defaultDetector.Diagnose(pattern, cs)
+}
+
+// Diagnose evaluates each rule of the named pattern (chart names such as
+// "Hammer") on cs, newest first, reporting actual values against thresholds.
+// Detected equals the boolean detector and is true exactly when every
+// condition passed.
+// Diagnose 在 cs（新到旧）上逐条评估指定形态的规则，给出实际值与阈值；Detected 与布尔识别器一致，
+// 且仅当全部条件通过时为 true。
+func (d *Detector) Diagnose(pattern string, cs []CandlestickWrapper) (PatternDiagnosis, error) {
+	spec, ok := gradeSpecs[pattern]
+	if !ok {
+		return PatternDiagnosis{}, fmt.Errorf("unknown pattern %q", pattern)
+	}
+	if len(cs) < spec.span {
+		return PatternDiagnosis{}, fmt.Errorf("%s needs %d candles, got %d", pattern, spec.span, len(cs))
+	}
+
+	diag := PatternDiagnosis{
+		Pattern:    pattern,
+		Detected:   spec.detect(d, cs),
+		Conditions: diagnoseRules[pattern](d, cs),
+		Failed:     make([]string, 0),
+	}
+	for _, c := range diag.Conditions {
+		if !c.Passed {
+			diag.Failed = append(diag.Failed, c.Name)
+		}
+	}
+	return diag, nil
+}
+
+// DiagnosablePatterns lists the pattern names Diagnose accepts, sorted.
+// DiagnosablePatterns 返回 Diagnose 支持的形态名称（已排序）。
+func DiagnosablePatterns() []string {
+	names := ScoredPatterns()
+	sort.Strings(names)
+	return names
+}
+
+// WindowAt returns the newest-first window of at most n candles ending at
+// chronological index i, the layout detectors expect.
+// WindowAt 返回以时间序号 i 结束、最多 n 根、按新到旧排列的K线窗口，即识别器所需的排列。
+func WindowAt(cs []CandlestickWrapper, i, n int) []CandlestickWrapper {
+	if i < 0 || i >= len(cs) {
+		return nil
+	}
+	if n > i+1 {
+		n = i + 1
+	}
+	out := make([]CandlestickWrapper, n)
+	for k := 0; k < n; k++ {
+		out[k] = cs[i-k]
+	}
+	return out
+}
+
+// compare builds a FactorHit using the same comparison as the detector.
+func compare(name string, value float64, op string, threshold float64) FactorHit {
+	var passed bool
+	switch op {
+	case "<":
+		passed = value < threshold
+	case "<=":
+		passed = value <= threshold
+	case ">":
+		passed = value > threshold
+	case ">=":
+		passed = value >= threshold
+	}
+	return FactorHit{
+		Name:      name,
+		Value:     value,
+		Threshold: threshold,
+		Passed:    passed,
+		Reason:    fmt.Sprintf("%s %.4f %s %.4f", name, value, op, threshold),
+	}
+}
+
+// flagRule builds a FactorHit for a yes/no rule such as candle color.
+func flagRule(name string, ok bool) FactorHit {
+	v := 0.0
+	if ok {
+		v = 1
+	}
+	return FactorHit{Name: name, Value: v, Threshold: 1, Passed: ok, Reason: fmt.Sprintf("%s=%t", name, ok)}
+}
+
+// bodyRule reports the long/short body rule for cs[i]; it passes trivially
+// when no reference is available, as the detector does.
+func (d *Detector) bodyRule(name string, cs []CandlestickWrapper, span, i int, long bool) FactorHit {
+	ref, ok := d.bodyReference(cs, span)
+	if !ok {
+		return FactorHit{Name: name, Value: cs[i].Body(), Passed: true, Reason: name + " not checked: no body reference"}
+	}
+	if long {
+		return compare(name, cs[i].Body(), ">=", d.config.LongBodyMultiple*ref)
+	}
+	return compare(name, cs[i].Body(), "<=", d.config.ShortBodyMultiple*ref)
+}
+
+func prefixed(prefix string, hits []FactorHit) []FactorHit {
+	for i := range hits {
+		hits[i].Name = prefix + hits[i].Name
+		hits[i].Reason = prefix + hits[i].Reason
+	}
+	return hits
+}
+
+func diagnoseDoji(d *Detector, cs []CandlestickWrapper) []FactorHit {
+	c := cs[0]
+	r := math.Abs(c.High - c.Low)
+	return []FactorHit{
+		compare("body", c.Body(), "<=", d.config.DojiBodyRatio*r),
+		compare("upper_shadow", c.UpperShadow(), ">", d.config.DojiShadowRatio*r),
+		compare("lower_shadow", c.LowerShadow(), ">", d.config.DojiShadowRatio*r),
+	}
+}
+
+func diagnoseLongLeggedDoji(d *Detector, cs []CandlestickWrapper) []FactorHit {
+	c := cs[0]
+	r := c.High - c.Low
+	hits := []FactorHit{compare("range", r, ">", 0)}
+	if r <= 0 {
+		return hits
+	}
+	return append(hits,
+		compare("body_ratio", c.Body()/r, "<=", d.config.LongLeggedBodyRatio),
+		compare("upper_shadow_ratio", c.UpperShadow()/r, ">=", d.config.LongLeggedShadowRatio),
+		compare("lower_shadow_ratio", c.LowerShadow()/r, ">=", d.config.LongLeggedShadowRatio),
+		compare("shadow_dominance", (c.UpperShadow()+c.LowerShadow())/r, ">", d.config.LongLeggedShadowDominant),
+	)
+}
+
+func diagnoseMarubozu(d *Detector, cs []CandlestickWrapper) []FactorHit {
+	c := cs[0]
+	tolerance := d.config.MarubozuShadowMultiple * c.Body()
+	return []FactorHit{
+		compare("upper_shadow", c.UpperShadow(), "<=", tolerance),
+		compare("lower_shadow", c.LowerShadow(), "<=", tolerance),
+		d.bodyRule("long_body", cs, 1, 0, true),
+	}
+}
+
+func diagnoseUmbrella(d *Detector, cs []CandlestickWrapper) []FactorHit {
+	c := cs[0]
+	body := c.Body()
+	return []FactorHit{
+		compare("upper_shadow", c.UpperShadow(), "<=", d.config.UmbrellaUpperMultiple*body),
+		compare("lower_shadow", c.LowerShadow(), ">", d.config.UmbrellaShadowMultiple*body),
+	}
+}
+
+// diagnoseShadowCandle covers Hammer, Hanging Man, Inverted Hammer and
+// Shooting Star: lower selects the long shadow, bullish the body color.
+func diagnoseShadowCandle(d *Detector, cs []CandlestickWrapper, lower, bullish bool) []FactorHit {
+	c := cs[0]
+	body := c.Body()
+	long, opposite := c.UpperShadow(), c.LowerShadow()
+	longName, oppositeName := "upper_shadow", "lower_shadow"
+	endRule := compare("open_above_low", c.Open, ">", c.Low)
+	if lower {
+		long, opposite = opposite, long
+		longName, oppositeName = oppositeName, longName
+		endRule = compare("close_above_low", c.Close, ">", c.Low)
+	}
+	color := compare("close_above_open", c.Close, ">", c.Open)
+	if !bullish {
+		color = compare("close_below_open", c.Close, "<", c.Open)
+	}
+	return []FactorHit{
+		compare("body", body, ">", 0),
+		compare(longName, long, ">", d.config.HammerShadowMultiple*body),
+		compare(oppositeName, opposite, "<", d.config.HammerOppositeMultiple*body),
+		endRule,
+		color,
+	}
+}
+
+func diagnoseEngulfing(cs []CandlestickWrapper, bullish bool) []FactorHit {
+	first, second := cs[1], cs[0]
+	if bullish {
+		return []FactorHit{
+			flagRule("first_bearish", first.IsBearish()),
+			flagRule("second_bullish", second.IsBullish()),
+			compare("second_open", second.Open, "<=", first.Close),
+			compare("second_close", second.Close, ">=", first.Open),
+		}
+	}
+	return []FactorHit{
+		flagRule("first_bullish", first.IsBullish()),
+		flagRule("second_bearish", second.IsBearish()),
+		compare("second_open", second.Open, ">=", first.Close),
+		compare("second_close", second.Close, "<=", first.Open),
+	}
+}
+
+func diagnosePenetration(d *Detector, cs []CandlestickWrapper, bullish bool) []FactorHit {
+	first, second := cs[1], cs[0]
+	if bullish {
+		return []FactorHit{
+			flagRule("first_bearish", first.IsBearish()),
+			flagRule("second_bullish", second.IsBullish()),
+			compare("second_open", second.Open, "<", first.Low),
+			compare("second_close", second.Close, ">", first.Open-first.Body()/2),
+			d.bodyRule("first_long_body", cs, 2, 1, true),
+		}
+	}
+	return []FactorHit{
+		flagRule("first_bullish", first.IsBullish()),
+		flagRule("second_bearish", second.IsBearish()),
+		compare("second_open", second.Open, ">", first.High),
+		compare("second_close", second.Close, "<", first.Open+first.Body()/2),
+		d.bodyRule("first_long_body", cs, 2, 1, true),
+	}
+}
+
+func diagnoseStar(d *Detector, cs []CandlestickWrapper, bullish bool) []FactorHit {
+	first, second, third := cs[2], cs[1], cs[0]
+	var hits []FactorHit
+	if bullish {
+		hits = []FactorHit{
+			flagRule("first_bearish", first.IsBearish()),
+			compare("second_body_top", math.Min(second.Open, second.Close), "<", first.Close),
+			flagRule("third_bullish", third.IsBullish()),
+			compare("third_close", third.Close, ">", (first.Open+first.Close)/2),
+		}
+	} else {
+		hits = []FactorHit{
+			flagRule("first_bullish", first.IsBullish()),
+			compare("second_body_bottom", math.Max(second.Open, second.Close), ">", first.Close),
+			flagRule("third_bearish", third.IsBearish()),
+			compare("third_close", third.Close, "<", (first.Open+first.Close)/2),
+		}
+	}
+	return append(hits,
+		compare("second_body", math.Abs(second.Open-second.Close), "<=", d.config.StarBodyRatio*(second.High-second.Low)),
+		d.bodyRule("first_long_body", cs, 3, 2, true),
+		d.bodyRule("second_short_body", cs, 3, 1, false),
+	)
+}
+
+func diagnoseDojiStar(d *Detector, cs []CandlestickWrapper, bullish bool) []FactorHit {
+	hits := diagnoseStar(d, cs, bullish)
+	return append(hits, prefixed("middle_", diagnoseDoji(d, []CandlestickWrapper{cs[1]}))...)
+}
+
+func diagnoseThreeCandles(d *Detector, cs []CandlestickWrapper, bullish bool) []FactorHit {
+	first, second, third := cs[2], cs[1], cs[0]
+	var hits []FactorHit
+	if bullish {
+		hits = []FactorHit{
+			flagRule("first_bullish", first.IsBullish()),
+			flagRule("second_bullish", second.IsBullish()),
+			flagRule("third_bullish", third.IsBullish()),
+			compare("second_open", second.Open, "<=", first.Close),
+			compare("third_open", third.Open, "<=", second.Close),
+			compare("second_close", second.Close, ">", first.High),
+			compare("third_close", third.Close, ">", second.High),
+		}
+	} else {
+		hits = []FactorHit{
+			flagRule("first_bearish", first.IsBearish()),
+			flagRule("second_bearish", second.IsBearish()),
+			flagRule("third_bearish", third.IsBearish()),
+			compare("second_open", second.Open, ">=", first.Close),
+			compare("third_open", third.Open, ">=", second.Close),
+			compare("second_close", second.Close, "<", first.Low),
+			compare("third_close", third.Close, "<", second.Low),
+		}
+	}
+	return append(hits,
+		d.bodyRule("first_long_body", cs, 3, 2, true),
+		d.bodyRule("second_long_body", cs, 3, 1, true),
+		d.bodyRule("third_long_body", cs, 3, 0, true),
+	)
+}
+
+func diagnoseTweezer(d *Detector, cs []CandlestickWrapper, bottoms bool) []FactorHit {
+	first, second := cs[1], cs[0]
+	if bottoms {
+		return []FactorHit{compare("low_difference", math.Abs(first.Low-second.Low), "<", d.config.TweezerTolerance*math.Min(first.Low, second.Low))}
+	}
+	return []FactorHit{compare("high_difference", math.Abs(first.High-second.High), "<", d.config.TweezerTolerance*math.Min(first.High, second.High))}
+}
+
+func diagnoseWindow(cs []CandlestickWrapper, rising bool) []FactorHit {
+	first, second := cs[1], cs[0]
+	if rising {
+		return []FactorHit{
+			flagRule("first_bullish", first.IsBullish()),
+			flagRule("second_bullish", second.IsBullish()),
+			compare("second_low", second.Low, ">", first.High),
+		}
+	}
+	return []FactorHit{
+		flagRule("first_bearish", first.IsBearish()),
+		flagRule("second_bearish", second.IsBearish()),
+		compare("second_high", second.High, "<", first.Low),
+	}
+}
+
+func diagnoseDojiExtreme(d *Detector, cs []CandlestickWrapper, dragonfly bool) []FactorHit {
+	c := cs[0]
+	r := c.High - c.Low
+	long, opposite := c.LowerShadow(), c.UpperShadow()
+	longName, oppositeName := "lower_shadow", "upper_shadow"
+	if !dragonfly {
+		long, opposite = opposite, long
+		longName, oppositeName = oppositeName, longName
+	}
+	return []FactorHit{
+		compare("range", r, ">", 0),
+		compare("body", c.Body(), "<=", d.config.DragonflyBodyRatio*r),
+		compare(oppositeName, opposite, "<=", d.config.DragonflyOppositeRatio*r),
+		compare(longName, long, ">", d.config.DragonflyShadowRatio*r),
+	}
+}
+
+func diagnoseHarami(d *Detector, cs []CandlestickWrapper, bullish bool) []FactorHit {
+	first, second := cs[1], cs[0]
+	hits := []FactorHit{
+		flagRule("first_bearish", first.IsBearish()),
+		flagRule("second_bullish", second.IsBullish()),
+	}
+	if !bullish {
+		hits = []FactorHit{
+			flagRule("first_bullish", first.IsBullish()),
+			flagRule("second_bearish", second.IsBearish()),
+		}
+	}
+	return append(hits,
+		compare("first_body", first.Body(), ">=", 0.0001),
+		compare("second_body", second.Body(), "<=", d.config.HaramiBodyMultiple*first.Body()),
+		d.bodyRule("first_long_body", cs, 2, 1, true),
+		compare("second_body_top", math.Max(second.Open, second.Close), "<", math.Max(first.Open, first.Close)),
+		compare("second_body_bottom", math.Min(second.Open, second.Close), ">", math.Min(first.Open, first.Close)),
+	)
+}
+
+func diagnoseThreeMethods(d *Detector, cs []CandlestickWrapper, rising bool) []FactorHit {
+	first, fifth := cs[4], cs[0]
+	firstBody := first.Body()
+	r := first.High - first.Low
+	var hits []FactorHit
+	if rising {
+		hits = []FactorHit{flagRule("first_bullish", first.IsBullish()), flagRule("fifth_bullish", fifth.IsBullish())}
+	} else {
+		hits = []FactorHit{flagRule("first_bearish", first.IsBearish()), flagRule("fifth_bearish", fifth.IsBearish())}
+	}
+	hits = append(hits,
+		compare("first_range", r, ">=", 0.0001),
+		compare("first_body", firstBody, ">=", d.config.ThreeMethodsBodyRatio*r),
+		d.bodyRule("first_long_body", cs, 5, 4, true),
+	)
+	for k, c := range []CandlestickWrapper{cs[3], cs[2], cs[1]} {
+		name := fmt.Sprintf("inner%d_", k+1)
+		color := flagRule(name+"bearish", c.IsBearish())
+		if !rising {
+			color = flagRule(name+"bullish", c.IsBullish())
+		}
+		hits = append(hits,
+			color,
+			compare(name+"high", c.High, "<=", first.High),
+			compare(name+"low", c.Low, ">=", first.Low),
+			compare(name+"body", c.Body(), "<=", d.config.ThreeMethodsInnerMultiple*firstBody),
+		)
+	}
+	if rising {
+		hits = append(hits, compare("fifth_close", fifth.Close, ">", math.Max(first.Open, first.Close)))
+	} else {
+		hits = append(hits, compare("fifth_close", fifth.Close, "<", math.Min(first.Open, first.Close)))
+	}
+	return append(hits, d.bodyRule("fifth_long_body", cs, 5, 0, true))
+}
+
+func diagnoseSpinningTop(d *Detector, cs []CandlestickWrapper) []FactorHit {
+	c := cs[0]
+	r := c.High - c.Low
+	hits := []FactorHit{compare("range", r, ">", 0)}
+	if r <= 0 {
+		return hits
+	}
+	return append(hits,
+		compare("body_ratio", c.Body()/r, "<", d.config.SpinningTopBodyRatio),
+		compare("upper_shadow_ratio", c.UpperShadow()/r, ">", d.config.SpinningTopShadowRatio),
+		compare("lower_shadow_ratio", c.LowerShadow()/r, ">", d.config.SpinningTopShadowRatio),
+		compare("shadow_imbalance", math.Abs(c.UpperShadow()-c.LowerShadow()), "<", d.config.SpinningTopBalanceRatio*r),
+		d.bodyRule("short_body", cs, 1, 0, false),
+	)
+}
+
+var diagnoseRules = map[string]func(*Detector, []CandlestickWrapper) []FactorHit{
+	"Doji":             diagnoseDoji,
+	"Long-Legged Doji": diagnoseLongLeggedDoji,
+	"Hammer": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseShadowCandle(d, cs, true, true)
+	},
+	"Hanging Man": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseShadowCandle(d, cs, true, false)
+	},
+	"Inverted Hammer": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseShadowCandle(d, cs, false, true)
+	},
+	"Shooting Star": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseShadowCandle(d, cs, false, false)
+	},
+	"Marubozu": diagnoseMarubozu,
+	"White Marubozu": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return append(diagnoseMarubozu(d, cs), flagRule("bullish", cs[0].IsBullish()))
+	},
+	"Black Marubozu": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return append(diagnoseMarubozu(d, cs), flagRule("bearish", cs[0].IsBearish()))
+	},
+	"Spinning Top": diagnoseSpinningTop,
+	"Umbrella":     diagnoseUmbrella,
+	"Dragonfly Doji": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseDojiExtreme(d, cs, true)
+	},
+	"Gravestone Doji": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseDojiExtreme(d, cs, false)
+	},
+	"Bullish Engulfing": func(_ *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseEngulfing(cs, true)
+	},
+	"Bearish Engulfing": func(_ *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseEngulfing(cs, false)
+	},
+	"Piercing Line": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnosePenetration(d, cs, true)
+	},
+	"Dark Cloud Cover": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnosePenetration(d, cs, false)
+	},
+	"Tweezer Bottoms": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseTweezer(d, cs, true)
+	},
+	"Tweezer Tops": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseTweezer(d, cs, false)
+	},
+	"Falling Window": func(_ *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseWindow(cs, false)
+	},
+	"Rising Window": func(_ *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseWindow(cs, true)
+	},
+	"Bullish Harami": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseHarami(d, cs, true)
+	},
+	"Bearish Harami": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseHarami(d, cs, false)
+	},
+	"Morning Star": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseStar(d, cs, true)
+	},
+	"Evening Star": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseStar(d, cs, false)
+	},
+	"Morning Doji Star": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseDojiStar(d, cs, true)
+	},
+	"Evening Doji Star": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseDojiStar(d, cs, false)
+	},
+	"Three White Soldiers": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseThreeCandles(d, cs, true)
+	},
+	"Three Black Crows": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseThreeCandles(d, cs, false)
+	},
+	"Rising Three Methods": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseThreeMethods(d, cs, true)
+	},
+	"Falling Three Methods": func(d *Detector, cs []CandlestickWrapper) []FactorHit {
+		return diagnoseThreeMethods(d, cs, false)
+	},
+}
@@ -0,0 +1,78 @@
+package identify
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// TestDiagnoseMatchesDetectors checks on random candles that every detector
+// fires exactly when all diagnosed conditions pass.
+func TestDiagnoseMatchesDetectors(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	series := make([]CandlestickWrapper, 0, 2000)
+	price := 100.0
+	for i := 0; i < cap(series); i++ {
+		open := price
+		// Small moves with occasional gaps make multi-candle patterns reachable.
+		// 小幅波动并偶尔跳空，使多K线形态可被触发。
+		if rng.Intn(10) == 0 {
+			open += rng.NormFloat64() * 3
+		}
+		closePrice := open + rng.NormFloat64()*2
+		if rng.Intn(8) == 0 {
+			closePrice = open + rng.NormFloat64()*0.05
+		}
+		high := math.Max(open, closePrice) + rng.ExpFloat64()*rng.Float64()*2
+		low := math.Min(open, closePrice) - rng.ExpFloat64()*rng.Float64()*2
+		if rng.Intn(6) == 0 {
+			high = math.Max(open, closePrice)
+		}
+		if rng.Intn(6) == 0 {
+			low = math.Min(open, closePrice)
+		}
+		series = append(series, candle(open, high, low, closePrice))
+		price = closePrice
+	}
+
+	relative := DefaultDetectorConfig()
+	relative.BodyReference = BodyReferenceMedianBody
+	for _, d := range []*Detector{NewDetector(DefaultDetectorConfig()), NewDetector(relative)} {
+		hits := 0
+		for _, name := range DiagnosablePatterns() {
+			for i := 20; i < len(series); i++ {
+				window := WindowAt(series, i, 20)
+				diag, err := d.Diagnose(name, window)
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				if diag.Detected != (len(diag.Failed) == 0) {
+					t.Fatalf("%s at %d (%s): detected=%t failed=%v conditions=%+v",
+						name, i, d.Config().BodyReference, diag.Detected, diag.Failed, diag.Conditions)
+				}
+				if diag.Detected {
+					hits++
+				}
+			}
+		}
+		if hits == 0 {
+			t.Fatal("random series should trigger some patterns")
+		}
+	}
+}
+
+func TestDiagnoseExplainsNearMiss(t *testing.T) {
+	// Lower shadow is only 1.5x the body: a near-miss Hammer.
+	// 下影线仅为实体的 1.5 倍：接近但不满足锤头线。
+	cs := []CandlestickWrapper{candle(100, 101, 98.5, 101)}
+	diag, err := Diagnose("Hammer", cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diag.Detected || len(diag.Failed) != 1 || diag.Failed[0] != "lower_shadow" {
+		t.Fatalf("expected only lower_shadow to fail, got %+v", diag)
+	}
+	if _, err := Diagnose("Nope", cs); err == nil {
+		t.Fatal("expected error for unknown pattern")
+	}
+}
@@ -61,6 +61,19 @@ type EvidenceConfig struct {
 	// OBVDivergenceLookback is the candlestick lookback for OBV divergence check (default 5).
 	// OBVDivergenceLookback 是 OBV 背离检测的回望窗口（默认 5）。
 	OBVDivergenceLookback int `json:"obv_divergence_lookback"`
+	// Levels controls support/resistance detection for the "pattern at level" context factor.
+	// Levels 控制“形态位于支撑/阻力位”上下文因子所用的价位识别参数。
+	Levels LevelConfig `json:"levels"`
+	// Beiliang controls position-aware beiliang factors and follow-through tracking.
+	// Beiliang 控制按价格位置区分的倍量因子与后续跟随跟踪。
+	Beiliang BeiliangConfig `json:"beiliang"`
+	// Profile controls the "pattern near POC / value area edge" context factor.
+	// Profile 控制“形态位于 POC/价值区边缘附近”的上下文因子。
+	Profile VolumeProfileConfig `json:"profile"`
+	// RelativeStrength controls the "outperforming benchmark" factor, scored
+	// when the indicators carry an RS line.
+	// RelativeStrength 控制“跑赢基准”因子，仅在指标中含 RS 线时评分。
+	RelativeStrength RelativeStrengthConfig `json:"relative_strength"`
 }
 
 // DefaultEvidenceConfig returns a conservative default config.
@@ -79,5 +92,9 @@ func DefaultEvidenceConfig() EvidenceConfig {
 		ContextWindow:         9,
 		ContextTrendThreshold: 3.0,
 		OBVDivergenceLookback: 5,
+		Levels:                DefaultLevelConfig(),
+		Beiliang:              DefaultBeiliangConfig(),
+		Profile:               DefaultVolumeProfileConfig(),
+		RelativeStrength:      DefaultRelativeStrengthConfig(),
 	}
 }
@@ -1,6 +1,10 @@
 package identify
 
-import "math"
+import (
+	"fmt"
+	"math"
+	"strings"
+)
 
 // BuildPatternEvidence combines pattern signals and volume/context features.
 // BuildPatternEvidence 将形态信号与量价/上下文特征融合为证据输出。
@@ -8,6 +12,18 @@ func BuildPatternEvidence(
 	patterns []PatternSignal,
 	candles []CandlestickWrapper,
 	cfg EvidenceConfig,
+) []PatternEvidence {
+	return BuildPatternEvidenceWithIndicators(patterns, candles, cfg, nil)
+}
+
+// BuildPatternEvidenceWithIndicators is BuildPatternEvidence with technical
+// indicators (see ComputeIndicators) added as RSI/MACD context factors when present.
+// BuildPatternEvidenceWithIndicators 在 BuildPatternEvidence 基础上，将已计算的技术指标作为 RSI/MACD 上下文因子。
+func BuildPatternEvidenceWithIndicators(
+	patterns []PatternSignal,
+	candles []CandlestickWrapper,
+	cfg EvidenceConfig,
+	indicators map[string][]float64,
 ) []PatternEvidence {
 	if len(patterns) == 0 || len(candles) == 0 {
 		return nil
@@ -15,43 +31,87 @@ func BuildPatternEvidence(
 
 	ind := ComputeVolumeIndicators(candles, cfg.MFIPeriod, cfg.CMFPeriod)
 	out := make([]PatternEvidence, 0, len(patterns))
+	// Levels depend only on the bars before a position; patterns sharing a
+	// bar reuse them.
+	// 价位只取决于该位置之前的K线，同一K线上的形态共用一次计算结果。
+	levelsAt := make(map[int][]PriceLevel)
 
 	for _, p := range patterns {
 		if p.Position < 0 || p.Position >= len(candles) {
 			continue
 		}
-		ev := PatternEvidence{
-			PatternType:  p.Type,
-			Direction:    p.Direction,
-			Position:     p.Position,
-			Time:         p.Time,
-			Price:        p.Price,
-			BaseStrength: clamp01(p.Strength),
+		levels, ok := levelsAt[p.Position]
+		if !ok {
+			levels = DetectLevels(candles[:p.Position], cfg.Levels)
+			levelsAt[p.Position] = levels
 		}
+		avgVol := averageVolumeBefore(candles, p.Position, cfg.VolumeLookback)
+		out = append(out, patternEvidence(p, candles, ind, avgVol, indicators, levels, cfg))
+	}
 
-		contextScore, ctxFactors := scoreContext(candles, p, cfg)
-		volumeScore, volFactors, contradictions := scoreVolume(candles, ind, p, cfg)
+	return out
+}
 
-		finalScore := ev.BaseStrength*cfg.BaseWeight +
-			contextScore*cfg.ContextWeight +
-			volumeScore*cfg.VolumeWeight
-		finalScore -= contradictionPenalty(contradictions)
+// patternEvidence scores one pattern; avgVol is the mean volume of the
+// VolumeLookback bars before the pattern bar and levels are the
+// support/resistance levels detected on the bars before it.
+func patternEvidence(
+	p PatternSignal,
+	candles []CandlestickWrapper,
+	ind VolumeIndicatorSeries,
+	avgVol float64,
+	indicators map[string][]float64,
+	levels []PriceLevel,
+	cfg EvidenceConfig,
+) PatternEvidence {
+	ev := PatternEvidence{
+		PatternType:  p.Type,
+		Direction:    p.Direction,
+		Position:     p.Position,
+		Time:         p.Time,
+		Price:        p.Price,
+		BaseStrength: clamp01(p.Strength),
+	}
 
-		ev.ContextScore = clamp01(contextScore)
-		ev.VolumeScore = clamp01(volumeScore)
-		ev.FinalScore = clamp01(finalScore)
-		ev.ConfidenceLevel = toConfidence(ev.FinalScore)
-		ev.ContextFactors = ctxFactors
-		ev.VolumeFactors = volFactors
-		ev.ContradictionFactors = contradictions
-		out = append(out, ev)
+	contextScore, ctxFactors := scoreContext(candles, p, levels, cfg)
+	for _, f := range indicatorFactors(indicators, p) {
+		ctxFactors = append(ctxFactors, f)
+		if f.Passed {
+			contextScore += 0.05
+		}
 	}
+	if f, ok := profileFactor(candles, p, cfg.Profile); ok {
+		ctxFactors = append(ctxFactors, f)
+		if f.Passed {
+			contextScore += 0.05
+		}
+	}
+	if f, ok := relativeStrengthFactor(indicators, p, cfg.RelativeStrength); ok {
+		ctxFactors = append(ctxFactors, f)
+		if f.Passed {
+			contextScore += 0.05
+		}
+	}
+	contextScore = clamp01(contextScore)
+	volumeScore, volFactors, contradictions := scoreVolume(candles, ind, avgVol, p, cfg)
 
-	return out
+	finalScore := ev.BaseStrength*cfg.BaseWeight +
+		contextScore*cfg.ContextWeight +
+		volumeScore*cfg.VolumeWeight
+	finalScore -= contradictionPenalty(contradictions)
+
+	ev.ContextScore = clamp01(contextScore)
+	ev.VolumeScore = clamp01(volumeScore)
+	ev.FinalScore = clamp01(finalScore)
+	ev.ConfidenceLevel = toConfidence(ev.FinalScore)
+	ev.ContextFactors = ctxFactors
+	ev.VolumeFactors = volFactors
+	ev.ContradictionFactors = contradictions
+	return ev
 }
 
-func scoreContext(cs []CandlestickWrapper, p PatternSignal, cfg EvidenceConfig) (float64, []FactorHit) {
-	factors := make([]FactorHit, 0, 2)
+func scoreContext(cs []CandlestickWrapper, p PatternSignal, levels []PriceLevel, cfg EvidenceConfig) (float64, []FactorHit) {
+	factors := make([]FactorHit, 0, 3)
 	if p.Position < 3 {
 		factors = append(factors, FactorHit{
 			Name:      "trend_window",
@@ -106,12 +166,124 @@ func scoreContext(cs []CandlestickWrapper, p PatternSignal, cfg EvidenceConfig)
 	} else {
 		score -= 0.2
 	}
+
+	levelFactor := levelContextFactor(cs, p, levels, cfg.Levels)
+	factors = append(factors, levelFactor)
+	if levelFactor.Passed {
+		score += 0.15
+	}
 	return clamp01(score), factors
 }
 
+// levelContextFactor checks whether the pattern formed at a support (bullish),
+// resistance (bearish) or any (neutral) level of levels, which the caller
+// builds only from bars before the pattern.
+// levelContextFactor 检查形态是否出现在 levels 中的支撑（看涨）、阻力（看跌）或任一（中性）价位附近；levels 由调用方仅用形态之前的K线构建。
+func levelContextFactor(cs []CandlestickWrapper, p PatternSignal, levels []PriceLevel, cfg LevelConfig) FactorHit {
+	cfg = normalizeLevelConfig(cfg)
+	c := cs[p.Position]
+	levelType, price := "", c.Close
+	switch p.Direction {
+	case "bullish":
+		levelType, price = LevelSupport, c.Low
+	case "bearish":
+		levelType, price = LevelResistance, c.High
+	}
+
+	factor := FactorHit{
+		Name:      "at_support_resistance",
+		Threshold: cfg.ProximityPercent,
+		Reason:    "pattern forms near a prior support/resistance level (distance %)",
+	}
+	level, dist, ok := NearestLevel(levels, levelType, price)
+	if !ok {
+		factor.Reason = "no prior support/resistance level in lookback"
+		return factor
+	}
+	factor.Value = dist
+	factor.Passed = dist <= cfg.ProximityPercent
+	if factor.Passed {
+		factor.Reason = fmt.Sprintf("pattern at %s %.2f (touches=%d)", level.Type, level.Price, level.Touches)
+	}
+	return factor
+}
+
+// indicatorFactors checks the RSI regime and MACD histogram momentum at the
+// pattern bar, using the first RSI/MACD series by key; missing or warm-up
+// values, and neutral patterns, yield no factor.
+// indicatorFactors 在形态K线处检查 RSI 区间与 MACD 柱动能（按键名取首个 RSI/MACD 序列），缺失、预热期或中性形态不输出因子。
+func indicatorFactors(indicators map[string][]float64, p PatternSignal) []FactorHit {
+	factors := make([]FactorHit, 0, 2)
+	if !directional(p) {
+		return factors
+	}
+	i := p.Position
+
+	if rsi, ok := indicatorValue(indicators, "RSI", "", i); ok {
+		pass := (p.Direction == "bullish" && rsi < 40) ||
+			(p.Direction == "bearish" && rsi > 60)
+		factors = append(factors, FactorHit{
+			Name:      "rsi_regime",
+			Value:     rsi,
+			Threshold: 50,
+			Passed:    pass,
+			Reason:    "RSI oversold for bullish / overbought for bearish patterns",
+		})
+	}
+
+	hist, ok := indicatorValue(indicators, "MACD", ".HIST", i)
+	prev, okPrev := indicatorValue(indicators, "MACD", ".HIST", i-1)
+	if ok && okPrev {
+		delta := hist - prev
+		pass := (p.Direction == "bullish" && delta > 0) ||
+			(p.Direction == "bearish" && delta < 0)
+		factors = append(factors, FactorHit{
+			Name:      "macd_momentum",
+			Value:     delta,
+			Threshold: 0,
+			Passed:    pass,
+			Reason:    "MACD histogram turns in the pattern direction",
+		})
+	}
+	return factors
+}
+
+// directional reports whether p is bullish or bearish. Factors added on top
+// of the original MFI/CMF checks follow one rule: those that confirm a side
+// (RSI/MACD, beiliang, relative strength) do not apply to neutral patterns,
+// and those that locate the pattern (levels, volume profile) accept any
+// nearby level for them.
+func directional(p PatternSignal) bool {
+	return p.Direction == "bullish" || p.Direction == "bearish"
+}
+
+// indicatorValue returns series[i] of the alphabetically first key with the
+// given prefix (followed by a digit) and suffix.
+func indicatorValue(indicators map[string][]float64, prefix, suffix string, i int) (float64, bool) {
+	best := ""
+	for key := range indicators {
+		rest := strings.TrimPrefix(key, prefix)
+		if rest == key || rest == "" || rest[0] < '0' || rest[0] > '9' || !strings.HasSuffix(key, suffix) {
+			continue
+		}
+		if suffix == "" && strings.Contains(rest, ".") {
+			continue
+		}
+		if best == "" || key < best {
+			best = key
+		}
+	}
+	series := indicators[best]
+	if best == "" || i < 0 || i >= len(series) || math.IsNaN(series[i]) || math.IsInf(series[i], 0) {
+		return 0, false
+	}
+	return series[i], true
+}
+
 func scoreVolume(
 	cs []CandlestickWrapper,
 	ind VolumeIndicatorSeries,
+	avgVol float64,
 	p PatternSignal,
 	cfg EvidenceConfig,
 ) (float64, []FactorHit, []string) {
@@ -121,7 +293,6 @@ func scoreVolume(
 	contradictions := make([]string, 0, 2)
 	score := 0.5
 
-	avgVol := averageVolumeBefore(cs, i, cfg.VolumeLookback)
 	volRatio := 0.0
 	if avgVol > 0 {
 		volRatio = c.Volume / avgVol
@@ -192,6 +363,11 @@ func scoreVolume(
 		score += 0.10
 	}
 
+	beiliangDelta, beiliangHits, beiliangContradictions := beiliangFactors(cs, p, cfg)
+	score += beiliangDelta
+	factors = append(factors, beiliangHits...)
+	contradictions = append(contradictions, beiliangContradictions...)
+
 	obvContradiction := detectOBVDivergence(cs, ind.OBV, i, p.Direction, cfg.OBVDivergenceLookback)
 	if obvContradiction != "" {
 		contradictions = append(contradictions, obvContradiction)
@@ -59,3 +59,30 @@ func TestBuildPatternEvidence(t *testing.T) {
 		t.Fatal("expected beiliang_confirm factor to be present")
 	}
 }
+
+func TestNeutralPatternsGetNoDirectionalCredit(t *testing.T) {
+	cs := beiliangSeries(volumeCandle(100, 100.6, 99.8, 100.1, 1000))
+	p := PatternSignal{Type: "Doji", Direction: "neutral", Position: len(cs) - 1, Strength: 0.6}
+	indicators := map[string][]float64{"RSI14": make([]float64, len(cs)), "MACD12_26_9.HIST": make([]float64, len(cs))}
+	for i := range cs {
+		indicators["RSI14"][i] = 50
+		indicators["MACD12_26_9.HIST"][i] = float64(i)
+	}
+	if f := indicatorFactors(indicators, p); len(f) != 0 {
+		t.Fatalf("neutral patterns should get no RSI/MACD factors: %+v", f)
+	}
+	// Location factors still apply: a neutral pattern may sit at either edge.
+	// 位置类因子仍然适用：中性形态可位于任一价值区边缘。
+	if _, ok := profileFactor(cs, p, DefaultVolumeProfileConfig()); !ok {
+		t.Fatal("neutral patterns should still get a profile factor")
+	}
+	ev := BuildPatternEvidenceWithIndicators([]PatternSignal{p}, cs, DefaultEvidenceConfig(), indicators)[0]
+	for _, f := range append(ev.ContextFactors, ev.VolumeFactors...) {
+		switch f.Name {
+		case "beiliang_position", "beiliang_follow_through", "relative_strength":
+			if f.Passed {
+				t.Fatalf("%s should not pass for a neutral pattern: %+v", f.Name, f)
+			}
+		}
+	}
+}
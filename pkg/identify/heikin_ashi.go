@@ -0,0 +1,131 @@
+package identify
+
+import (
+	"math"
+	"time"
+
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+)
+
+// Heikin-Ashi signal types
+// 平均K线（Heikin-Ashi）信号类型
+const (
+	SignalHABullishFlip    = "HA Bullish Flip"    // Bearish HA bar followed by a bullish one (阴转阳)
+	SignalHABearishFlip    = "HA Bearish Flip"    // Bullish HA bar followed by a bearish one (阳转阴)
+	SignalHAShadowlessBull = "HA Shadowless Bull" // First bullish HA bar without a lower shadow (首根无下影阳线)
+	SignalHAShadowlessBear = "HA Shadowless Bear" // First bearish HA bar without an upper shadow (首根无上影阴线)
+)
+
+// HeikinAshiConfig controls Heikin-Ashi signal extraction.
+// HeikinAshiConfig 控制平均K线信号提取。
+type HeikinAshiConfig struct {
+	// ShadowTolerance is the largest shadow, as a share of the bar range,
+	// still treated as shadowless (default 0.05).
+	// ShadowTolerance 为仍视作“无影线”的最大影线占K线振幅比例（默认 0.05）。
+	ShadowTolerance float64 `json:"shadow_tolerance"`
+}
+
+// DefaultHeikinAshiConfig returns a 5% shadow tolerance.
+// DefaultHeikinAshiConfig 返回 5% 的影线容差。
+func DefaultHeikinAshiConfig() HeikinAshiConfig {
+	return HeikinAshiConfig{ShadowTolerance: 0.05}
+}
+
+// HeikinAshi transforms chronological candles into Heikin-Ashi bars:
+// close = (O+H+L+C)/4, open = midpoint of the previous HA body (the first
+// bar uses (O+C)/2), high/low extend to cover the HA body. Timestamps and
+// volumes are kept.
+// HeikinAshi 将按时间排列的K线转换为平均K线：收盘 = (O+H+L+C)/4，开盘 = 前一根平均K线实体中点
+// （首根取 (O+C)/2），最高/最低价扩展以覆盖实体。保留时间戳与成交量。
+func HeikinAshi(candles []*v1.Candlestick) []*v1.Candlestick {
+	out := make([]*v1.Candlestick, len(candles))
+	for i, c := range candles {
+		haClose := (c.Open + c.High + c.Low + c.Close) / 4
+		haOpen := (c.Open + c.Close) / 2
+		if i > 0 {
+			haOpen = (out[i-1].Open + out[i-1].Close) / 2
+		}
+		out[i] = &v1.Candlestick{
+			Timestamp: c.Timestamp,
+			Open:      haOpen,
+			Close:     haClose,
+			High:      math.Max(c.High, math.Max(haOpen, haClose)),
+			Low:       math.Min(c.Low, math.Min(haOpen, haClose)),
+			Volume:    c.Volume,
+		}
+	}
+	return out
+}
+
+// HeikinAshiWrappers is HeikinAshi over wrapped candles.
+// HeikinAshiWrappers 为包装K线版本的 HeikinAshi。
+func HeikinAshiWrappers(cs []CandlestickWrapper) []CandlestickWrapper {
+	raw := make([]*v1.Candlestick, len(cs))
+	for i, c := range cs {
+		raw[i] = c.Candlestick
+	}
+	ha := HeikinAshi(raw)
+	out := make([]CandlestickWrapper, len(ha))
+	for i, c := range ha {
+		out[i] = NewCandlestickWrapper(c)
+	}
+	return out
+}
+
+// HeikinAshiSignals finds color flips and the first shadowless trend bar of
+// each run on Heikin-Ashi bars ha (chronological). Strength is the body's
+// share of the bar range.
+// HeikinAshiSignals 在平均K线 ha（按时间排列）上识别颜色翻转及每段趋势中首根无影线K线；强度为实体占振幅比例。
+func HeikinAshiSignals(ha []CandlestickWrapper, cfg HeikinAshiConfig) []PatternSignal {
+	if cfg.ShadowTolerance <= 0 || cfg.ShadowTolerance >= 1 {
+		cfg.ShadowTolerance = DefaultHeikinAshiConfig().ShadowTolerance
+	}
+	shadowless := func(c CandlestickWrapper) string {
+		rng := c.High - c.Low
+		if rng <= 0 {
+			return ""
+		}
+		switch {
+		case c.Close > c.Open && (c.Open-c.Low)/rng <= cfg.ShadowTolerance:
+			return SignalHAShadowlessBull
+		case c.Close < c.Open && (c.High-c.Open)/rng <= cfg.ShadowTolerance:
+			return SignalHAShadowlessBear
+		}
+		return ""
+	}
+
+	out := make([]PatternSignal, 0)
+	emit := func(i int, typ, direction string, risk float64) {
+		c := ha[i]
+		strength := 0.0
+		if rng := c.High - c.Low; rng > 0 {
+			strength = math.Abs(c.Close-c.Open) / rng
+		}
+		out = append(out, PatternSignal{
+			Type:      typ,
+			Direction: direction,
+			Position:  i,
+			Strength:  strength,
+			Risk:      risk,
+			Price:     c.Close,
+			Time:      time.Unix(c.Timestamp, 0).Format("2006-01-02 15:04:05"),
+		})
+	}
+	for i := 1; i < len(ha); i++ {
+		prev, cur := ha[i-1], ha[i]
+		switch {
+		case prev.Close < prev.Open && cur.Close > cur.Open:
+			emit(i, SignalHABullishFlip, "bullish", 0.4)
+		case prev.Close > prev.Open && cur.Close < cur.Open:
+			emit(i, SignalHABearishFlip, "bearish", 0.4)
+		}
+		if s := shadowless(cur); s != "" && s != shadowless(prev) {
+			direction := "bullish"
+			if s == SignalHAShadowlessBear {
+				direction = "bearish"
+			}
+			emit(i, s, direction, 0.3)
+		}
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package identify
+
+import (
+	"math"
+	"testing"
+
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+)
+
+func TestHeikinAshiTransform(t *testing.T) {
+	candles := []*v1.Candlestick{
+		{Timestamp: 1, Open: 10, High: 12, Low: 9, Close: 11, Volume: 100},
+		{Timestamp: 2, Open: 11, High: 13, Low: 10.5, Close: 12.5, Volume: 200},
+	}
+	ha := HeikinAshi(candles)
+	if ha[0].Open != 10.5 || ha[0].Close != 10.5 || ha[0].High != 12 || ha[0].Low != 9 {
+		t.Fatalf("unexpected first bar: %+v", ha[0])
+	}
+	if ha[1].Open != 10.5 || ha[1].Close != 11.75 || ha[1].Low != 10.5 || ha[1].Volume != 200 || ha[1].Timestamp != 2 {
+		t.Fatalf("unexpected second bar: %+v", ha[1])
+	}
+	if candles[1].Open != 11 {
+		t.Fatal("input candles must not be modified")
+	}
+}
+
+func TestHeikinAshiSignals(t *testing.T) {
+	// Five falling bars then five rising ones.
+	// 先五根下跌K线，再五根上涨K线。
+	candles := make([]*v1.Candlestick, 0, 10)
+	price := 100.0
+	for i := 0; i < 10; i++ {
+		step := -2.0
+		if i >= 5 {
+			step = 2
+		}
+		open := price
+		price += step
+		candles = append(candles, &v1.Candlestick{
+			Timestamp: int64(i) * 86400,
+			Open:      open,
+			High:      math.Max(open, price) + 0.2,
+			Low:       math.Min(open, price) - 0.2,
+			Close:     price,
+			Volume:    1000,
+		})
+	}
+	ha := make([]CandlestickWrapper, 0, len(candles))
+	for _, c := range HeikinAshi(candles) {
+		ha = append(ha, NewCandlestickWrapper(c))
+	}
+
+	byType := make(map[string][]int)
+	for _, s := range HeikinAshiSignals(ha, DefaultHeikinAshiConfig()) {
+		byType[s.Type] = append(byType[s.Type], s.Position)
+		if s.Strength < 0 || s.Strength > 1 {
+			t.Fatalf("strength out of range: %+v", s)
+		}
+	}
+	// HA bars lag the turn by one bar.
+	// 平均K线比实际拐点滞后一根。
+	if got := byType[SignalHABullishFlip]; len(got) != 1 || got[0] != 6 {
+		t.Fatalf("expected one bullish flip at 6, got %v", byType)
+	}
+	if len(byType[SignalHABearishFlip]) != 0 {
+		t.Fatalf("unexpected bearish flip: %v", byType)
+	}
+	// One signal per shadowless run, not one per bar.
+	// 每段无影线趋势只发出一次信号。
+	if len(byType[SignalHAShadowlessBear]) != 1 || len(byType[SignalHAShadowlessBull]) != 1 || byType[SignalHAShadowlessBull][0] != 7 {
+		t.Fatalf("expected one shadowless signal per run, got %v", byType)
+	}
+}
@@ -0,0 +1,188 @@
+package identify
+
+import (
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// gaussianHMM is a hidden Markov model with one Gaussian emission per state.
+// gaussianHMM 是每个状态对应一个高斯发射分布的隐马尔可夫模型。
+type gaussianHMM struct {
+	start []float64
+	trans [][]float64
+	mean  []float64
+	std   []float64
+}
+
+// newGaussianHMM seeds k states around the sample mean with increasing
+// spread and sticky transitions, so fitting is deterministic.
+func newGaussianHMM(obs []float64, k int) *gaussianHMM {
+	mu, sd := stat.MeanStdDev(obs, nil)
+	h := &gaussianHMM{
+		start: make([]float64, k),
+		trans: make([][]float64, k),
+		mean:  make([]float64, k),
+		std:   make([]float64, k),
+	}
+	for i := 0; i < k; i++ {
+		h.start[i] = 1 / float64(k)
+		h.trans[i] = make([]float64, k)
+		for j := range h.trans[i] {
+			h.trans[i][j] = 0.1 / float64(k-1)
+		}
+		h.trans[i][i] = 0.9
+		h.mean[i] = mu
+		h.std[i] = sd * math.Pow(2, float64(i)-float64(k-1)/2)
+	}
+	return h
+}
+
+func (h *gaussianHMM) emission(state int, x float64) float64 {
+	z := (x - h.mean[state]) / h.std[state]
+	return math.Exp(-z*z/2) / (h.std[state] * math.Sqrt(2*math.Pi))
+}
+
+// posterior runs scaled forward-backward and returns the state posteriors
+// (gamma), pairwise transition sums (xi) and the log likelihood.
+func (h *gaussianHMM) posterior(obs []float64) ([][]float64, [][]float64, float64) {
+	k, t := len(h.mean), len(obs)
+	alpha := make([][]float64, t)
+	beta := make([][]float64, t)
+	scale := make([]float64, t)
+	for s := 0; s < t; s++ {
+		alpha[s] = make([]float64, k)
+		beta[s] = make([]float64, k)
+		for i := 0; i < k; i++ {
+			p := h.start[i]
+			if s > 0 {
+				p = 0
+				for j := 0; j < k; j++ {
+					p += alpha[s-1][j] * h.trans[j][i]
+				}
+			}
+			alpha[s][i] = p * h.emission(i, obs[s])
+			scale[s] += alpha[s][i]
+		}
+		if scale[s] <= 0 {
+			scale[s] = math.SmallestNonzeroFloat64
+		}
+		for i := range alpha[s] {
+			alpha[s][i] /= scale[s]
+		}
+	}
+	for i := 0; i < k; i++ {
+		beta[t-1][i] = 1
+	}
+	for s := t - 2; s >= 0; s-- {
+		for i := 0; i < k; i++ {
+			for j := 0; j < k; j++ {
+				beta[s][i] += h.trans[i][j] * h.emission(j, obs[s+1]) * beta[s+1][j]
+			}
+			beta[s][i] /= scale[s+1]
+		}
+	}
+
+	gamma := make([][]float64, t)
+	xi := make([][]float64, k)
+	for i := range xi {
+		xi[i] = make([]float64, k)
+	}
+	logLik := 0.0
+	for s := 0; s < t; s++ {
+		logLik += math.Log(scale[s])
+		gamma[s] = make([]float64, k)
+		total := 0.0
+		for i := 0; i < k; i++ {
+			gamma[s][i] = alpha[s][i] * beta[s][i]
+			total += gamma[s][i]
+		}
+		for i := range gamma[s] {
+			if total > 0 {
+				gamma[s][i] /= total
+			} else {
+				gamma[s][i] = 1 / float64(k)
+			}
+		}
+		if s == t-1 {
+			continue
+		}
+		for i := 0; i < k; i++ {
+			for j := 0; j < k; j++ {
+				xi[i][j] += alpha[s][i] * h.trans[i][j] * h.emission(j, obs[s+1]) * beta[s+1][j] / scale[s+1]
+			}
+		}
+	}
+	return gamma, xi, logLik
+}
+
+// fit runs Baum-Welch until the log likelihood stops improving and returns
+// the posteriors of the fitted model.
+func (h *gaussianHMM) fit(obs []float64, iterations int) [][]float64 {
+	_, floor := stat.MeanStdDev(obs, nil)
+	floor = math.Max(floor*1e-3, 1e-12)
+	prev := math.Inf(-1)
+	gamma, xi, logLik := h.posterior(obs)
+	for it := 0; it < iterations && logLik-prev > 1e-8; it++ {
+		prev = logLik
+		for i := range h.mean {
+			h.start[i] = gamma[0][i]
+			rowTotal := 0.0
+			for _, v := range xi[i] {
+				rowTotal += v
+			}
+			for j := range h.trans[i] {
+				if rowTotal > 0 {
+					h.trans[i][j] = xi[i][j] / rowTotal
+				}
+			}
+			weights := make([]float64, len(obs))
+			for s := range obs {
+				weights[s] = gamma[s][i]
+			}
+			mu, sd := stat.MeanStdDev(obs, weights)
+			if math.IsNaN(mu) || math.IsNaN(sd) {
+				continue
+			}
+			h.mean[i], h.std[i] = mu, math.Max(sd, floor)
+		}
+		gamma, xi, logLik = h.posterior(obs)
+	}
+	return gamma
+}
+
+// hmmRegimeProbabilities fits a k-state Gaussian HMM on returns and maps the
+// last posterior to regimes: the widest state is high volatility; with three
+// states the one with the largest |mean|/std of the rest is trend, otherwise
+// the calm mass is split by trendShare.
+func hmmRegimeProbabilities(returns []float64, k, iterations int, trendShare float64) (map[string]float64, bool) {
+	if len(returns) < 10*k {
+		return nil, false
+	}
+	if _, sd := stat.MeanStdDev(returns, nil); sd == 0 || math.IsNaN(sd) {
+		return nil, false
+	}
+	h := newGaussianHMM(returns, k)
+	last := h.fit(returns, iterations)[len(returns)-1]
+
+	order := make([]int, k)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return h.std[order[a]] < h.std[order[b]] })
+	out := map[string]float64{RegimeHighVolatility: last[order[k-1]]}
+	calm := 1 - out[RegimeHighVolatility]
+	if k == 2 {
+		out[RegimeTrend] = calm * trendShare
+		out[RegimeRange] = calm * (1 - trendShare)
+		return out, true
+	}
+	trend := order[0]
+	if math.Abs(h.mean[order[1]])/h.std[order[1]] > math.Abs(h.mean[order[0]])/h.std[order[0]] {
+		trend = order[1]
+	}
+	out[RegimeTrend] = last[trend]
+	out[RegimeRange] = calm - last[trend]
+	return out, true
+}
@@ -25,13 +25,18 @@ func NewCandlestickWrapper(c *v1.Candlestick) CandlestickWrapper {
 // 2. Lower Shadow is greater than 2x～3x Volume
 // Note: also indicates Bottom Support or Top Resistance
 func Umbrella(cs []CandlestickWrapper) bool {
+	return defaultDetector.Umbrella(cs)
+}
+
+// Umbrella detects the pattern with the detector's thresholds.
+func (d *Detector) Umbrella(cs []CandlestickWrapper) bool {
 	c := cs[0]
 	body := c.Body()
-	if c.UpperShadow() > 0 || c.UpperShadow() > 0.2*body {
+	if c.UpperShadow() > d.config.UmbrellaUpperMultiple*body {
 		return false
 	}
 
-	return c.LowerShadow() > 2.5*body
+	return c.LowerShadow() > d.config.UmbrellaShadowMultiple*body
 }
 
 // Doji
@@ -39,6 +44,11 @@ func Umbrella(cs []CandlestickWrapper) bool {
 // 1. Small body (Open and Close are very close)
 // 2. Upper and Lower Shadows are significant
 func Doji(cs []CandlestickWrapper) bool {
+	return defaultDetector.Doji(cs)
+}
+
+// Doji detects the pattern with the detector's thresholds.
+func (d *Detector) Doji(cs []CandlestickWrapper) bool {
 	if len(cs) < 1 {
 		return false
 	}
@@ -48,12 +58,13 @@ func Doji(cs []CandlestickWrapper) bool {
 	lowerShadow := c.LowerShadow()
 
 	// Body is small relative to the range
-	if body > 0.1*math.Abs(c.High-c.Low) { // Adjust 0.1 as needed
+	if body > d.config.DojiBodyRatio*math.Abs(c.High-c.Low) {
 		return false
 	}
 
 	// Upper and lower shadows are significant
-	return upperShadow > 0.2*math.Abs(c.High-c.Low) && lowerShadow > 0.2*math.Abs(c.High-c.Low) // Adjust 0.2 as needed
+	shadow := d.config.DojiShadowRatio * math.Abs(c.High-c.Low)
+	return upperShadow > shadow && lowerShadow > shadow
 }
 
 // LongLeggedDoji
@@ -63,6 +74,11 @@ func Doji(cs []CandlestickWrapper) bool {
 // 3. Total shadow length dominates the full range
 // Note: Typically indicates strong indecision and potential turning point.
 func LongLeggedDoji(cs []CandlestickWrapper) bool {
+	return defaultDetector.LongLeggedDoji(cs)
+}
+
+// LongLeggedDoji detects the pattern with the detector's thresholds.
+func (d *Detector) LongLeggedDoji(cs []CandlestickWrapper) bool {
 	if len(cs) < 1 {
 		return false
 	}
@@ -78,18 +94,18 @@ func LongLeggedDoji(cs []CandlestickWrapper) bool {
 
 	// Very small body relative to range
 	bodyRatio := body / totalRange
-	if bodyRatio > 0.08 {
+	if bodyRatio > d.config.LongLeggedBodyRatio {
 		return false
 	}
 
 	// Both shadows should be meaningful and long
-	if upperShadow/totalRange < 0.3 || lowerShadow/totalRange < 0.3 {
+	if upperShadow/totalRange < d.config.LongLeggedShadowRatio || lowerShadow/totalRange < d.config.LongLeggedShadowRatio {
 		return false
 	}
 
 	// Shadows should dominate the whole candle
 	shadowDominance := (upperShadow + lowerShadow) / totalRange
-	return shadowDominance > 0.85
+	return shadowDominance > d.config.LongLeggedShadowDominant
 }
 
 // Marubozu (Bullish and Bearish)
@@ -98,6 +114,11 @@ func LongLeggedDoji(cs []CandlestickWrapper) bool {
 // 2. No lower shadow or very small lower shadow
 // Note: Bullish Marubozu indicates strong buying pressure, Bearish Marubozu indicates strong selling pressure.
 func Marubozu(cs []CandlestickWrapper) bool {
+	return defaultDetector.Marubozu(cs)
+}
+
+// Marubozu detects the pattern with the detector's thresholds.
+func (d *Detector) Marubozu(cs []CandlestickWrapper) bool {
 	if len(cs) < 1 {
 		return false
 	}
@@ -105,27 +126,37 @@ func Marubozu(cs []CandlestickWrapper) bool {
 	body := c.Body()
 	upperShadow := c.UpperShadow()
 	lowerShadow := c.LowerShadow()
-	tolerance := 0.01 * body // Allow for very small shadows
+	tolerance := d.config.MarubozuShadowMultiple * body // Allow for very small shadows
 
-	return upperShadow <= tolerance && lowerShadow <= tolerance
+	return upperShadow <= tolerance && lowerShadow <= tolerance && d.longBody(cs, 1, 0)
 }
 
 // WhiteMarubozu
 // 光头光脚阳线
 func WhiteMarubozu(cs []CandlestickWrapper) bool {
+	return defaultDetector.WhiteMarubozu(cs)
+}
+
+// WhiteMarubozu detects the pattern with the detector's thresholds.
+func (d *Detector) WhiteMarubozu(cs []CandlestickWrapper) bool {
 	if len(cs) < 1 {
 		return false
 	}
-	return Marubozu(cs) && cs[0].IsBullish()
+	return d.Marubozu(cs) && cs[0].IsBullish()
 }
 
 // BlackMarubozu
 // 光头光脚阴线
 func BlackMarubozu(cs []CandlestickWrapper) bool {
+	return defaultDetector.BlackMarubozu(cs)
+}
+
+// BlackMarubozu detects the pattern with the detector's thresholds.
+func (d *Detector) BlackMarubozu(cs []CandlestickWrapper) bool {
 	if len(cs) < 1 {
 		return false
 	}
-	return Marubozu(cs) && cs[0].IsBearish()
+	return d.Marubozu(cs) && cs[0].IsBearish()
 }
 
 // Hammer
@@ -135,6 +166,11 @@ func BlackMarubozu(cs []CandlestickWrapper) bool {
 // 3. Little or no upper shadow
 // Note: Appears in a downtrend and suggests a potential bullish reversal.
 func Hammer(cs []CandlestickWrapper) bool {
+	return defaultDetector.Hammer(cs)
+}
+
+// Hammer detects the pattern with the detector's thresholds.
+func (d *Detector) Hammer(cs []CandlestickWrapper) bool {
 	if len(cs) < 1 {
 		return false
 	}
@@ -147,7 +183,7 @@ func Hammer(cs []CandlestickWrapper) bool {
 		return false
 	}
 
-	return lowerShadow > 2*body && upperShadow < 0.1*body && c.Close > c.Low && c.Close > c.Open // Body at the upper end
+	return lowerShadow > d.config.HammerShadowMultiple*body && upperShadow < d.config.HammerOppositeMultiple*body && c.Close > c.Low && c.Close > c.Open // Body at the upper end
 }
 
 // HangingMan
@@ -157,6 +193,11 @@ func Hammer(cs []CandlestickWrapper) bool {
 // 3. Little or no upper shadow
 // Note: Appears in an uptrend and suggests a potential bearish reversal. The shape is the same as a Hammer, but its context is different.
 func HangingMan(cs []CandlestickWrapper) bool {
+	return defaultDetector.HangingMan(cs)
+}
+
+// HangingMan detects the pattern with the detector's thresholds.
+func (d *Detector) HangingMan(cs []CandlestickWrapper) bool {
 	if len(cs) < 1 {
 		return false
 	}
@@ -169,7 +210,7 @@ func HangingMan(cs []CandlestickWrapper) bool {
 		return false
 	}
 
-	return lowerShadow > 2*body && upperShadow < 0.1*body && c.Close > c.Low && c.Close < c.Open // Body at the upper end
+	return lowerShadow > d.config.HammerShadowMultiple*body && upperShadow < d.config.HammerOppositeMultiple*body && c.Close > c.Low && c.Close < c.Open // Body at the upper end
 }
 
 // InvertedHammer
@@ -179,6 +220,11 @@ func HangingMan(cs []CandlestickWrapper) bool {
 // 3. Little or no lower shadow
 // Note: Appears in a downtrend and suggests a potential bullish reversal.
 func InvertedHammer(cs []CandlestickWrapper) bool {
+	return defaultDetector.InvertedHammer(cs)
+}
+
+// InvertedHammer detects the pattern with the detector's thresholds.
+func (d *Detector) InvertedHammer(cs []CandlestickWrapper) bool {
 	if len(cs) < 1 {
 		return false
 	}
@@ -191,7 +237,7 @@ func InvertedHammer(cs []CandlestickWrapper) bool {
 		return false
 	}
 
-	return upperShadow > 2*body && lowerShadow < 0.1*body && c.Open > c.Low && c.Close > c.Open // Body at the lower end
+	return upperShadow > d.config.HammerShadowMultiple*body && lowerShadow < d.config.HammerOppositeMultiple*body && c.Open > c.Low && c.Close > c.Open // Body at the lower end
 }
 
 // ShootingStar
@@ -201,6 +247,11 @@ func InvertedHammer(cs []CandlestickWrapper) bool {
 // 3. Little or no lower shadow
 // Note: Appears in an uptrend and suggests a potential bearish reversal. The shape is the same as an Inverted Hammer, but its context is different.
 func ShootingStar(cs []CandlestickWrapper) bool {
+	return defaultDetector.ShootingStar(cs)
+}
+
+// ShootingStar detects the pattern with the detector's thresholds.
+func (d *Detector) ShootingStar(cs []CandlestickWrapper) bool {
 	if len(cs) < 1 {
 		return false
 	}
@@ -213,7 +264,7 @@ func ShootingStar(cs []CandlestickWrapper) bool {
 		return false
 	}
 
-	return upperShadow > 2*body && lowerShadow < 0.1*body && c.Open > c.Low && c.Close < c.Open // Body at the lower end
+	return upperShadow > d.config.HammerShadowMultiple*body && lowerShadow < d.config.HammerOppositeMultiple*body && c.Open > c.Low && c.Close < c.Open // Body at the lower end
 }
 
 // BullishEngulfing EngulfingPattern (Bullish and Bearish)
@@ -224,6 +275,11 @@ func ShootingStar(cs []CandlestickWrapper) bool {
 // 4. Bearish Engulfing: First is bullish, second is bearish.
 // Note: Suggests a potential reversal of the current trend.
 func BullishEngulfing(cs []CandlestickWrapper) bool {
+	return defaultDetector.BullishEngulfing(cs)
+}
+
+// BullishEngulfing detects the pattern with the detector's thresholds.
+func (d *Detector) BullishEngulfing(cs []CandlestickWrapper) bool {
 	if len(cs) < 2 {
 		return false
 	}
@@ -238,6 +294,11 @@ func BullishEngulfing(cs []CandlestickWrapper) bool {
 // 看跌吞噬
 // (Implements the Bearish Engulfing part of the Engulfing Pattern)
 func BearishEngulfing(cs []CandlestickWrapper) bool {
+	return defaultDetector.BearishEngulfing(cs)
+}
+
+// BearishEngulfing detects the pattern with the detector's thresholds.
+func (d *Detector) BearishEngulfing(cs []CandlestickWrapper) bool {
 	if len(cs) < 2 {
 		return false
 	}
@@ -255,6 +316,11 @@ func BearishEngulfing(cs []CandlestickWrapper) bool {
 // 3. Second candlestick is a bullish candle that opens below the low of the first and closes above the midpoint of the first.
 // Note: Appears in a downtrend and suggests a potential bullish reversal.
 func PiercingLine(cs []CandlestickWrapper) bool {
+	return defaultDetector.PiercingLine(cs)
+}
+
+// PiercingLine detects the pattern with the detector's thresholds.
+func (d *Detector) PiercingLine(cs []CandlestickWrapper) bool {
 	if len(cs) < 2 {
 		return false
 	}
@@ -266,7 +332,7 @@ func PiercingLine(cs []CandlestickWrapper) bool {
 	}
 
 	midPoint := first.Open - first.Body()/2
-	return second.Open < first.Low && second.Close > midPoint
+	return second.Open < first.Low && second.Close > midPoint && d.longBody(cs, 2, 1)
 }
 
 // DarkCloudCover
@@ -276,6 +342,11 @@ func PiercingLine(cs []CandlestickWrapper) bool {
 // 3. Second candlestick is a bearish candle that opens above the high of the first and closes below the midpoint of the first.
 // Note: Appears in an uptrend and suggests a potential bearish reversal.
 func DarkCloudCover(cs []CandlestickWrapper) bool {
+	return defaultDetector.DarkCloudCover(cs)
+}
+
+// DarkCloudCover detects the pattern with the detector's thresholds.
+func (d *Detector) DarkCloudCover(cs []CandlestickWrapper) bool {
 	if len(cs) < 2 {
 		return false
 	}
@@ -287,7 +358,7 @@ func DarkCloudCover(cs []CandlestickWrapper) bool {
 	}
 
 	midPoint := first.Open + first.Body()/2
-	return second.Open > first.High && second.Close < midPoint
+	return second.Open > first.High && second.Close < midPoint && d.longBody(cs, 2, 1)
 }
 
 // MorningStar
@@ -298,6 +369,11 @@ func DarkCloudCover(cs []CandlestickWrapper) bool {
 // 4. Third is a bullish candle that closes well into the body of the first candle.
 // Note: Appears in a downtrend and suggests a potential bullish reversal.
 func MorningStar(cs []CandlestickWrapper) bool {
+	return defaultDetector.MorningStar(cs)
+}
+
+// MorningStar detects the pattern with the detector's thresholds.
+func (d *Detector) MorningStar(cs []CandlestickWrapper) bool {
 	if len(cs) < 3 {
 		return false
 	}
@@ -315,7 +391,10 @@ func MorningStar(cs []CandlestickWrapper) bool {
 
 	bodySecond := math.Abs(second.Open - second.Close)
 	rangeSecond := second.High - second.Low
-	return bodySecond <= 0.3*rangeSecond // Second candle has a small body (adjust 0.3 as needed)
+	if bodySecond > d.config.StarBodyRatio*rangeSecond { // Second candle has a small body
+		return false
+	}
+	return d.longBody(cs, 3, 2) && d.shortBody(cs, 3, 1)
 }
 
 // EveningStar
@@ -326,6 +405,11 @@ func MorningStar(cs []CandlestickWrapper) bool {
 // 4. Third is a bearish candle that closes well into the body of the first candle.
 // Note: Appears in an uptrend and suggests a potential bearish reversal.
 func EveningStar(cs []CandlestickWrapper) bool {
+	return defaultDetector.EveningStar(cs)
+}
+
+// EveningStar detects the pattern with the detector's thresholds.
+func (d *Detector) EveningStar(cs []CandlestickWrapper) bool {
 	if len(cs) < 3 {
 		return false
 	}
@@ -343,12 +427,20 @@ func EveningStar(cs []CandlestickWrapper) bool {
 
 	bodySecond := math.Abs(second.Open - second.Close)
 	rangeSecond := second.High - second.Low
-	return bodySecond <= 0.3*rangeSecond // Second candle has a small body (adjust 0.3 as needed)
+	if bodySecond > d.config.StarBodyRatio*rangeSecond { // Second candle has a small body
+		return false
+	}
+	return d.longBody(cs, 3, 2) && d.shortBody(cs, 3, 1)
 }
 
 // MorningDojiStar
 // 早晨十字星
 func MorningDojiStar(cs []CandlestickWrapper) bool {
+	return defaultDetector.MorningDojiStar(cs)
+}
+
+// MorningDojiStar detects the pattern with the detector's thresholds.
+func (d *Detector) MorningDojiStar(cs []CandlestickWrapper) bool {
 	if len(cs) < 3 {
 		return false
 	}
@@ -360,12 +452,17 @@ func MorningDojiStar(cs []CandlestickWrapper) bool {
 	}
 	// 第二根需为十字星，且整体结构满足启明星
 	// Doji as middle candle and still satisfy morning star structure
-	return Doji([]CandlestickWrapper{second}) && MorningStar(cs)
+	return d.Doji([]CandlestickWrapper{second}) && d.MorningStar(cs)
 }
 
 // EveningDojiStar
 // 黄昏十字星
 func EveningDojiStar(cs []CandlestickWrapper) bool {
+	return defaultDetector.EveningDojiStar(cs)
+}
+
+// EveningDojiStar detects the pattern with the detector's thresholds.
+func (d *Detector) EveningDojiStar(cs []CandlestickWrapper) bool {
 	if len(cs) < 3 {
 		return false
 	}
@@ -377,7 +474,7 @@ func EveningDojiStar(cs []CandlestickWrapper) bool {
 	}
 	// 第二根需为十字星，且整体结构满足黄昏之星
 	// Doji as middle candle and still satisfy evening star structure
-	return Doji([]CandlestickWrapper{second}) && EveningStar(cs)
+	return d.Doji([]CandlestickWrapper{second}) && d.EveningStar(cs)
 }
 
 // ThreeWhiteSoldiers
@@ -387,6 +484,11 @@ func EveningDojiStar(cs []CandlestickWrapper) bool {
 // 3. Each candle closes above the high of the previous candle.
 // Note: Appears in a downtrend and suggests a strong bullish reversal.
 func ThreeWhiteSoldiers(cs []CandlestickWrapper) bool {
+	return defaultDetector.ThreeWhiteSoldiers(cs)
+}
+
+// ThreeWhiteSoldiers detects the pattern with the detector's thresholds.
+func (d *Detector) ThreeWhiteSoldiers(cs []CandlestickWrapper) bool {
 	if len(cs) < 3 {
 		return false
 	}
@@ -406,7 +508,7 @@ func ThreeWhiteSoldiers(cs []CandlestickWrapper) bool {
 		return false
 	}
 
-	return true
+	return d.longBody(cs, 3, 2) && d.longBody(cs, 3, 1) && d.longBody(cs, 3, 0)
 }
 
 // ThreeBlackCrows
@@ -416,6 +518,11 @@ func ThreeWhiteSoldiers(cs []CandlestickWrapper) bool {
 // 3. Each candle closes below the low of the previous candle.
 // Note: Appears in an uptrend and suggests a strong bearish reversal.
 func ThreeBlackCrows(cs []CandlestickWrapper) bool {
+	return defaultDetector.ThreeBlackCrows(cs)
+}
+
+// ThreeBlackCrows detects the pattern with the detector's thresholds.
+func (d *Detector) ThreeBlackCrows(cs []CandlestickWrapper) bool {
 	if len(cs) < 3 {
 		return false
 	}
@@ -435,7 +542,7 @@ func ThreeBlackCrows(cs []CandlestickWrapper) bool {
 		return false
 	}
 
-	return true
+	return d.longBody(cs, 3, 2) && d.longBody(cs, 3, 1) && d.longBody(cs, 3, 0)
 }
 
 // TweezerBottoms
@@ -444,13 +551,18 @@ func ThreeBlackCrows(cs []CandlestickWrapper) bool {
 // 2. The first candle is usually bearish, and the second is usually bullish.
 // 3. Occurs in a downtrend and suggests a potential bullish reversal.
 func TweezerBottoms(cs []CandlestickWrapper) bool {
+	return defaultDetector.TweezerBottoms(cs)
+}
+
+// TweezerBottoms detects the pattern with the detector's thresholds.
+func (d *Detector) TweezerBottoms(cs []CandlestickWrapper) bool {
 	if len(cs) < 2 {
 		return false
 	}
 	first := cs[1]
 	second := cs[0]
 
-	return math.Abs(first.Low-second.Low) < 0.001*math.Min(first.Low, second.Low) // Lows are very close
+	return math.Abs(first.Low-second.Low) < d.config.TweezerTolerance*math.Min(first.Low, second.Low) // Lows are very close
 }
 
 // TweezerTops
@@ -459,13 +571,18 @@ func TweezerBottoms(cs []CandlestickWrapper) bool {
 // 2. The first candle is usually bullish, and the second is usually bearish.
 // 3. Occurs in an uptrend and suggests a potential bearish reversal.
 func TweezerTops(cs []CandlestickWrapper) bool {
+	return defaultDetector.TweezerTops(cs)
+}
+
+// TweezerTops detects the pattern with the detector's thresholds.
+func (d *Detector) TweezerTops(cs []CandlestickWrapper) bool {
 	if len(cs) < 2 {
 		return false
 	}
 	first := cs[1]
 	second := cs[0]
 
-	return math.Abs(first.High-second.High) < 0.001*math.Min(first.High, second.High) // Highs are very close
+	return math.Abs(first.High-second.High) < d.config.TweezerTolerance*math.Min(first.High, second.High) // Highs are very close
 }
 
 // FallingWindow
@@ -474,6 +591,11 @@ func TweezerTops(cs []CandlestickWrapper) bool {
 // 2. The gap represents an area of selling pressure.
 // Note: Suggests continuation of the downtrend or a potential resistance level.
 func FallingWindow(cs []CandlestickWrapper) bool {
+	return defaultDetector.FallingWindow(cs)
+}
+
+// FallingWindow detects the pattern with the detector's thresholds.
+func (d *Detector) FallingWindow(cs []CandlestickWrapper) bool {
 	if len(cs) < 2 {
 		return false
 	}
@@ -489,6 +611,11 @@ func FallingWindow(cs []CandlestickWrapper) bool {
 // 2. The gap represents an area of buying pressure.
 // Note: Suggests continuation of the uptrend or a potential support level.
 func RisingWindow(cs []CandlestickWrapper) bool {
+	return defaultDetector.RisingWindow(cs)
+}
+
+// RisingWindow detects the pattern with the detector's thresholds.
+func (d *Detector) RisingWindow(cs []CandlestickWrapper) bool {
 	if len(cs) < 2 {
 		return false
 	}
@@ -504,6 +631,11 @@ func RisingWindow(cs []CandlestickWrapper) bool {
 // 2. Long lower shadow, no or minimal upper shadow
 // Note: Bullish reversal signal in downtrend
 func DragonflyDoji(cs []CandlestickWrapper) bool {
+	return defaultDetector.DragonflyDoji(cs)
+}
+
+// DragonflyDoji detects the pattern with the detector's thresholds.
+func (d *Detector) DragonflyDoji(cs []CandlestickWrapper) bool {
 	if len(cs) < 1 {
 		return false
 	}
@@ -516,16 +648,16 @@ func DragonflyDoji(cs []CandlestickWrapper) bool {
 	if totalRange == 0 {
 		return false
 	}
-	// Small body (<10% of range)
-	if body > 0.1*totalRange {
+	// Small body (<10% of range by default)
+	if body > d.config.DragonflyBodyRatio*totalRange {
 		return false
 	}
-	// Upper shadow minimal (<5% of range)
-	if upperShadow > 0.05*totalRange {
+	// Upper shadow minimal (<5% of range by default)
+	if upperShadow > d.config.DragonflyOppositeRatio*totalRange {
 		return false
 	}
-	// Lower shadow significant (>50% of range)
-	return lowerShadow > 0.5*totalRange
+	// Lower shadow significant (>50% of range by default)
+	return lowerShadow > d.config.DragonflyShadowRatio*totalRange
 }
 
 // GravestoneDoji
@@ -534,6 +666,11 @@ func DragonflyDoji(cs []CandlestickWrapper) bool {
 // 2. Long upper shadow, no or minimal lower shadow
 // Note: Bearish reversal signal in uptrend
 func GravestoneDoji(cs []CandlestickWrapper) bool {
+	return defaultDetector.GravestoneDoji(cs)
+}
+
+// GravestoneDoji detects the pattern with the detector's thresholds.
+func (d *Detector) GravestoneDoji(cs []CandlestickWrapper) bool {
 	if len(cs) < 1 {
 		return false
 	}
@@ -546,13 +683,13 @@ func GravestoneDoji(cs []CandlestickWrapper) bool {
 	if totalRange == 0 {
 		return false
 	}
-	if body > 0.1*totalRange {
+	if body > d.config.DragonflyBodyRatio*totalRange {
 		return false
 	}
-	if lowerShadow > 0.05*totalRange {
+	if lowerShadow > d.config.DragonflyOppositeRatio*totalRange {
 		return false
 	}
-	return upperShadow > 0.5*totalRange
+	return upperShadow > d.config.DragonflyShadowRatio*totalRange
 }
 
 // BullishHarami
@@ -561,6 +698,11 @@ func GravestoneDoji(cs []CandlestickWrapper) bool {
 // 2. Second candle: small body completely inside first's body
 // Note: Reversal signal in downtrend
 func BullishHarami(cs []CandlestickWrapper) bool {
+	return defaultDetector.BullishHarami(cs)
+}
+
+// BullishHarami detects the pattern with the detector's thresholds.
+func (d *Detector) BullishHarami(cs []CandlestickWrapper) bool {
 	if len(cs) < 2 {
 		return false
 	}
@@ -574,7 +716,7 @@ func BullishHarami(cs []CandlestickWrapper) bool {
 	if firstBody < 0.0001 {
 		return false
 	}
-	if secondBody > 0.5*firstBody {
+	if secondBody > d.config.HaramiBodyMultiple*firstBody || !d.longBody(cs, 2, 1) {
 		return false
 	}
 	// Second candle body completely inside first's body
@@ -591,6 +733,11 @@ func BullishHarami(cs []CandlestickWrapper) bool {
 // 2. Second candle: small body completely inside first's body
 // Note: Reversal signal in uptrend
 func BearishHarami(cs []CandlestickWrapper) bool {
+	return defaultDetector.BearishHarami(cs)
+}
+
+// BearishHarami detects the pattern with the detector's thresholds.
+func (d *Detector) BearishHarami(cs []CandlestickWrapper) bool {
 	if len(cs) < 2 {
 		return false
 	}
@@ -604,7 +751,7 @@ func BearishHarami(cs []CandlestickWrapper) bool {
 	if firstBody < 0.0001 {
 		return false
 	}
-	if secondBody > 0.5*firstBody {
+	if secondBody > d.config.HaramiBodyMultiple*firstBody || !d.longBody(cs, 2, 1) {
 		return false
 	}
 	firstHigh := math.Max(first.Open, first.Close)
@@ -620,6 +767,11 @@ func BearishHarami(cs []CandlestickWrapper) bool {
 // 2. Next 3: small bearish candles inside first's range
 // 3. Fifth: long bullish candle closing above first's high
 func RisingThreeMethods(cs []CandlestickWrapper) bool {
+	return defaultDetector.RisingThreeMethods(cs)
+}
+
+// RisingThreeMethods detects the pattern with the detector's thresholds.
+func (d *Detector) RisingThreeMethods(cs []CandlestickWrapper) bool {
 	if len(cs) < 5 {
 		return false
 	}
@@ -633,7 +785,7 @@ func RisingThreeMethods(cs []CandlestickWrapper) bool {
 	firstBody := first.Body()
 	firstHigh := math.Max(first.Open, first.Close)
 	totalRange := first.High - first.Low
-	if totalRange < 0.0001 || firstBody < 0.5*totalRange {
+	if totalRange < 0.0001 || firstBody < d.config.ThreeMethodsBodyRatio*totalRange || !d.longBody(cs, 5, 4) {
 		return false
 	}
 	// c2, c3, c4 should be small and bearish, inside first's range
@@ -644,12 +796,12 @@ func RisingThreeMethods(cs []CandlestickWrapper) bool {
 		if c.High > first.High || c.Low < first.Low {
 			return false
 		}
-		if c.Body() > 0.5*firstBody {
+		if c.Body() > d.config.ThreeMethodsInnerMultiple*firstBody {
 			return false
 		}
 	}
 	// Fifth candle closes above first's high
-	return fifth.Close > firstHigh
+	return fifth.Close > firstHigh && d.longBody(cs, 5, 0)
 }
 
 // FallingThreeMethods
@@ -658,6 +810,11 @@ func RisingThreeMethods(cs []CandlestickWrapper) bool {
 // 2. Next 3: small bullish candles inside first's range
 // 3. Fifth: long bearish candle closing below first's low
 func FallingThreeMethods(cs []CandlestickWrapper) bool {
+	return defaultDetector.FallingThreeMethods(cs)
+}
+
+// FallingThreeMethods detects the pattern with the detector's thresholds.
+func (d *Detector) FallingThreeMethods(cs []CandlestickWrapper) bool {
 	if len(cs) < 5 {
 		return false
 	}
@@ -671,7 +828,7 @@ func FallingThreeMethods(cs []CandlestickWrapper) bool {
 	firstBody := first.Body()
 	firstLow := math.Min(first.Open, first.Close)
 	totalRange := first.High - first.Low
-	if totalRange < 0.0001 || firstBody < 0.5*totalRange {
+	if totalRange < 0.0001 || firstBody < d.config.ThreeMethodsBodyRatio*totalRange || !d.longBody(cs, 5, 4) {
 		return false
 	}
 	for _, c := range []CandlestickWrapper{c2, c3, c4} {
@@ -681,11 +838,11 @@ func FallingThreeMethods(cs []CandlestickWrapper) bool {
 		if c.High > first.High || c.Low < first.Low {
 			return false
 		}
-		if c.Body() > 0.5*firstBody {
+		if c.Body() > d.config.ThreeMethodsInnerMultiple*firstBody {
 			return false
 		}
 	}
-	return fifth.Close < firstLow
+	return fifth.Close < firstLow && d.longBody(cs, 5, 0)
 }
 
 // SpinningTop
@@ -694,6 +851,11 @@ func FallingThreeMethods(cs []CandlestickWrapper) bool {
 // 2. Long upper and lower shadows of roughly equal length.
 // Note: Represents indecision in the market.
 func SpinningTop(cs []CandlestickWrapper) bool {
+	return defaultDetector.SpinningTop(cs)
+}
+
+// SpinningTop detects the pattern with the detector's thresholds.
+func (d *Detector) SpinningTop(cs []CandlestickWrapper) bool {
 	if len(cs) < 1 {
 		return false
 	}
@@ -711,5 +873,7 @@ func SpinningTop(cs []CandlestickWrapper) bool {
 	lowerShadowRatio := lowerShadow / totalRange
 
 	// Check for small body and significant shadows
-	return bodyRatio < 0.3 && upperShadowRatio > 0.2 && lowerShadowRatio > 0.2 && math.Abs(upperShadow-lowerShadow) < 0.2*totalRange
+	cfg := d.config
+	return bodyRatio < cfg.SpinningTopBodyRatio && upperShadowRatio > cfg.SpinningTopShadowRatio && lowerShadowRatio > cfg.SpinningTopShadowRatio &&
+		math.Abs(upperShadow-lowerShadow) < cfg.SpinningTopBalanceRatio*totalRange && d.shortBody(cs, 1, 0)
 }
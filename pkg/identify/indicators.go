@@ -0,0 +1,269 @@
+package identify
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	talib "github.com/markcheno/go-talib"
+)
+
+// Indicator families accepted by IndicatorSpec.Name.
+// IndicatorSpec.Name 支持的指标族。
+const (
+	IndicatorMA   = "ma"   // Simple moving average, params [period] (简单均线)
+	IndicatorEMA  = "ema"  // Exponential moving average, params [period] (指数均线)
+	IndicatorWMA  = "wma"  // Weighted moving average, params [period] (加权均线)
+	IndicatorDEMA = "dema" // Double EMA, params [period] (双重指数均线)
+	IndicatorTEMA = "tema" // Triple EMA, params [period] (三重指数均线)
+	IndicatorMACD = "macd" // params [fast, slow, signal]
+	IndicatorRSI  = "rsi"  // params [period]
+	IndicatorKDJ  = "kdj"  // params [period, k smoothing, d smoothing]
+	IndicatorBOLL = "boll" // Bollinger bands, params [period, stddev multiple] (布林带)
+	IndicatorATR  = "atr"  // params [period]
+	IndicatorADX  = "adx"  // params [period]
+	IndicatorCCI  = "cci"  // params [period]
+	IndicatorWR   = "wr"   // Williams %R, params [period] (威廉指标)
+	IndicatorVWAP = "vwap" // Rolling VWAP, params [period] (滚动成交量加权均价)
+)
+
+// indicatorDefaults holds the default params of every family; its keys are the valid names.
+var indicatorDefaults = map[string][]float64{
+	IndicatorMA:   {20},
+	IndicatorEMA:  {20},
+	IndicatorWMA:  {20},
+	IndicatorDEMA: {20},
+	IndicatorTEMA: {20},
+	IndicatorMACD: {12, 26, 9},
+	IndicatorRSI:  {14},
+	IndicatorKDJ:  {9, 3, 3},
+	IndicatorBOLL: {20, 2},
+	IndicatorATR:  {14},
+	IndicatorADX:  {14},
+	IndicatorCCI:  {14},
+	IndicatorWR:   {14},
+	IndicatorVWAP: {20},
+}
+
+// IndicatorSpec requests one indicator by family name and params, e.g.
+// {"name":"macd","params":[12,26,9]}. Missing params use the family defaults.
+// IndicatorSpec 按指标族名称与参数请求一个指标，缺省参数使用该族默认值。
+type IndicatorSpec struct {
+	Name   string    `json:"name"`
+	Params []float64 `json:"params,omitempty"`
+}
+
+// DefaultIndicatorSpecs returns the indicator set computed when none is configured.
+// DefaultIndicatorSpecs 返回未配置时计算的默认指标集。
+func DefaultIndicatorSpecs() []IndicatorSpec {
+	return []IndicatorSpec{
+		{Name: IndicatorMA, Params: []float64{5}},
+		{Name: IndicatorMA, Params: []float64{10}},
+		{Name: IndicatorMA, Params: []float64{20}},
+		{Name: IndicatorMACD, Params: []float64{12, 26, 9}},
+		{Name: IndicatorRSI, Params: []float64{14}},
+		{Name: IndicatorKDJ, Params: []float64{9, 3, 3}},
+		{Name: IndicatorBOLL, Params: []float64{20, 2}},
+		{Name: IndicatorATR, Params: []float64{14}},
+	}
+}
+
+// ValidateIndicatorSpec reports unknown names and out-of-range params.
+// ValidateIndicatorSpec 检查未知指标名与越界参数。
+func ValidateIndicatorSpec(spec IndicatorSpec) error {
+	name := strings.ToLower(spec.Name)
+	def, ok := indicatorDefaults[name]
+	if !ok {
+		return fmt.Errorf("unknown indicator %q", spec.Name)
+	}
+	if len(spec.Params) > len(def) {
+		return fmt.Errorf("%s takes at most %d params, got %d", name, len(def), len(spec.Params))
+	}
+	p := normalizeIndicatorSpec(spec).Params
+	switch name {
+	case IndicatorBOLL:
+		if p[0] < 2 || p[1] <= 0 {
+			return fmt.Errorf("boll needs period >= 2 and stddev > 0")
+		}
+		return nil
+	case IndicatorMACD:
+		if p[0] >= p[1] {
+			return fmt.Errorf("macd fast period must be < slow period")
+		}
+	}
+	for _, v := range p {
+		if v < 1 || v != math.Trunc(v) {
+			return fmt.Errorf("%s periods must be positive integers", name)
+		}
+	}
+	if p[0] < 2 && name != IndicatorKDJ {
+		return fmt.Errorf("%s period must be >= 2", name)
+	}
+	return nil
+}
+
+// normalizeIndicatorSpec lowercases the name and fills missing or non-positive params.
+func normalizeIndicatorSpec(spec IndicatorSpec) IndicatorSpec {
+	name := strings.ToLower(spec.Name)
+	def := indicatorDefaults[name]
+	params := make([]float64, len(def))
+	for i := range def {
+		params[i] = def[i]
+		if i < len(spec.Params) && spec.Params[i] > 0 {
+			params[i] = spec.Params[i]
+		}
+	}
+	return IndicatorSpec{Name: name, Params: params}
+}
+
+// Keys returns the Indicators map keys the spec produces: the upper-case name
+// followed by its params (MA20, MACD12_26_9.HIST, BOLL20_2.UPPER).
+// Keys 返回该指标写入 Indicators 的键：大写名称加参数，如 MA20、MACD12_26_9.HIST、BOLL20_2.UPPER。
+func (s IndicatorSpec) Keys() []string {
+	s = normalizeIndicatorSpec(s)
+	parts := make([]string, len(s.Params))
+	for i, v := range s.Params {
+		parts[i] = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	base := strings.ToUpper(s.Name) + strings.Join(parts, "_")
+	switch s.Name {
+	case IndicatorMACD:
+		return []string{base + ".DIF", base + ".DEA", base + ".HIST"}
+	case IndicatorKDJ:
+		return []string{base + ".K", base + ".D", base + ".J"}
+	case IndicatorBOLL:
+		return []string{base + ".UPPER", base + ".MID", base + ".LOWER"}
+	}
+	return []string{base}
+}
+
+// IndicatorOverlaysPrice reports whether a family is drawn on the price scale.
+// IndicatorOverlaysPrice 判断指标族是否与价格同坐标绘制。
+func IndicatorOverlaysPrice(name string) bool {
+	switch strings.ToLower(name) {
+	case IndicatorMA, IndicatorEMA, IndicatorWMA, IndicatorDEMA, IndicatorTEMA, IndicatorBOLL, IndicatorVWAP:
+		return true
+	}
+	return false
+}
+
+// ComputeIndicators computes the requested indicators over chronological
+// candles. Series are aligned by candle index and are NaN during warm-up so
+// callers can tell missing values from zero; invalid specs are skipped.
+// ComputeIndicators 基于按时间排列的K线计算所请求的指标；序列按K线索引对齐，
+// 预热期为 NaN 以区分缺失值与零值；无效配置被跳过。
+func ComputeIndicators(cs []CandlestickWrapper, specs []IndicatorSpec) map[string][]float64 {
+	out := make(map[string][]float64)
+	n := len(cs)
+	high := make([]float64, n)
+	low := make([]float64, n)
+	closep := make([]float64, n)
+	for i, c := range cs {
+		high[i] = c.High
+		low[i] = c.Low
+		closep[i] = c.Close
+	}
+
+	for _, spec := range specs {
+		if ValidateIndicatorSpec(spec) != nil {
+			continue
+		}
+		spec = normalizeIndicatorSpec(spec)
+		keys := spec.Keys()
+		p := spec.Params
+		period := int(p[0])
+		warmup, series := indicatorWarmup(spec), [][]float64(nil)
+		if n > warmup {
+			switch spec.Name {
+			case IndicatorMA:
+				series = [][]float64{talib.Sma(closep, period)}
+			case IndicatorEMA:
+				series = [][]float64{talib.Ema(closep, period)}
+			case IndicatorWMA:
+				series = [][]float64{talib.Wma(closep, period)}
+			case IndicatorDEMA:
+				series = [][]float64{talib.Dema(closep, period)}
+			case IndicatorTEMA:
+				series = [][]float64{talib.Tema(closep, period)}
+			case IndicatorMACD:
+				dif, dea, hist := talib.Macd(closep, period, int(p[1]), int(p[2]))
+				series = [][]float64{dif, dea, hist}
+			case IndicatorRSI:
+				series = [][]float64{talib.Rsi(closep, period)}
+			case IndicatorKDJ:
+				k, d, j := computeKDJ(high, low, closep, period, p[1], p[2])
+				series = [][]float64{k, d, j}
+			case IndicatorBOLL:
+				upper, mid, lower := talib.BBands(closep, period, p[1], p[1], talib.SMA)
+				series = [][]float64{upper, mid, lower}
+			case IndicatorATR:
+				series = [][]float64{talib.Atr(high, low, closep, period)}
+			case IndicatorADX:
+				series = [][]float64{talib.Adx(high, low, closep, period)}
+			case IndicatorCCI:
+				series = [][]float64{talib.Cci(high, low, closep, period)}
+			case IndicatorWR:
+				series = [][]float64{talib.WillR(high, low, closep, period)}
+			case IndicatorVWAP:
+				series = [][]float64{RollingVWAP(cs, period)}
+			}
+		}
+		for k, key := range keys {
+			values := make([]float64, n)
+			if k < len(series) {
+				copy(values, series[k])
+			}
+			for i := 0; i < n && i < warmup; i++ {
+				values[i] = math.NaN()
+			}
+			out[key] = values
+		}
+	}
+	return out
+}
+
+// indicatorWarmup returns the index of the first valid value of a normalized spec.
+func indicatorWarmup(spec IndicatorSpec) int {
+	p := int(spec.Params[0])
+	switch spec.Name {
+	case IndicatorDEMA:
+		return 2 * (p - 1)
+	case IndicatorTEMA:
+		return 3 * (p - 1)
+	case IndicatorMACD:
+		return int(spec.Params[1]) - 1 + int(spec.Params[2]) - 1
+	case IndicatorRSI, IndicatorATR:
+		return p
+	case IndicatorADX:
+		return 2*p - 1
+	}
+	return p - 1
+}
+
+// computeKDJ is the stochastic oscillator in its KDJ form: RSV over period
+// bars, K and D smoothed with weights 1/kSmooth and 1/dSmooth starting at 50,
+// and J = 3K - 2D.
+func computeKDJ(high, low, closep []float64, period int, kSmooth, dSmooth float64) ([]float64, []float64, []float64) {
+	n := len(closep)
+	k := make([]float64, n)
+	d := make([]float64, n)
+	j := make([]float64, n)
+	prevK, prevD := 50.0, 50.0
+	for i := period - 1; i < n; i++ {
+		hh, ll := high[i], low[i]
+		for m := i - period + 1; m < i; m++ {
+			hh = math.Max(hh, high[m])
+			ll = math.Min(ll, low[m])
+		}
+		rsv := 50.0
+		if hh > ll {
+			rsv = (closep[i] - ll) / (hh - ll) * 100
+		}
+		k[i] = ((kSmooth-1)*prevK + rsv) / kSmooth
+		d[i] = ((dSmooth-1)*prevD + k[i]) / dSmooth
+		j[i] = 3*k[i] - 2*d[i]
+		prevK, prevD = k[i], d[i]
+	}
+	return k, d, j
+}
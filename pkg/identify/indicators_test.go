@@ -0,0 +1,125 @@
+package identify
+
+import (
+	"math"
+	"testing"
+)
+
+func indicatorCandles(n int) []CandlestickWrapper {
+	cs := make([]CandlestickWrapper, n)
+	for i := range cs {
+		base := 10 + math.Sin(float64(i)/4)*2 + float64(i)*0.05
+		cs[i] = candle(base, base+0.6, base-0.5, base+0.3)
+	}
+	return cs
+}
+
+func TestComputeIndicatorsKeysAndWarmup(t *testing.T) {
+	cs := indicatorCandles(80)
+	specs := []IndicatorSpec{
+		{Name: "ma", Params: []float64{5}},
+		{Name: "macd"},
+		{Name: "boll", Params: []float64{20, 2}},
+		{Name: "kdj"},
+		{Name: "rsi"},
+		{Name: "adx"},
+		{Name: "cci"},
+		{Name: "wr"},
+		{Name: "tema", Params: []float64{10}},
+	}
+	ind := ComputeIndicators(cs, specs)
+
+	for _, key := range []string{"MA5", "MACD12_26_9.DIF", "MACD12_26_9.DEA", "MACD12_26_9.HIST",
+		"BOLL20_2.UPPER", "BOLL20_2.MID", "BOLL20_2.LOWER", "KDJ9_3_3.K", "KDJ9_3_3.D", "KDJ9_3_3.J",
+		"RSI14", "ADX14", "CCI14", "WR14", "TEMA10"} {
+		series, ok := ind[key]
+		if !ok || len(series) != len(cs) {
+			t.Fatalf("missing or misaligned series %s", key)
+		}
+		last := series[len(series)-1]
+		if math.IsNaN(last) || math.IsInf(last, 0) {
+			t.Fatalf("%s should be valid after warm-up, got %v", key, last)
+		}
+	}
+
+	ma := ind["MA5"]
+	for i := 0; i < 4; i++ {
+		if !math.IsNaN(ma[i]) {
+			t.Fatalf("MA5[%d] should be NaN during warm-up, got %v", i, ma[i])
+		}
+	}
+	want := 0.0
+	for _, c := range cs[75:] {
+		want += c.Close / 5
+	}
+	if math.Abs(ma[79]-want) > 1e-9 {
+		t.Fatalf("MA5 = %v, want %v", ma[79], want)
+	}
+	if !math.IsNaN(ind["MACD12_26_9.HIST"][32]) || math.IsNaN(ind["MACD12_26_9.HIST"][33]) {
+		t.Fatal("MACD warm-up should end at slow+signal-2")
+	}
+	if ind["BOLL20_2.UPPER"][79] <= ind["BOLL20_2.MID"][79] || ind["BOLL20_2.LOWER"][79] >= ind["BOLL20_2.MID"][79] {
+		t.Fatal("Bollinger bands should straddle the middle band")
+	}
+	for i := 8; i < len(cs); i++ {
+		k := ind["KDJ9_3_3.K"][i]
+		if k < 0 || k > 100 {
+			t.Fatalf("KDJ K out of range at %d: %v", i, k)
+		}
+	}
+}
+
+func TestComputeIndicatorsShortSeries(t *testing.T) {
+	ind := ComputeIndicators(indicatorCandles(10), []IndicatorSpec{{Name: "macd"}, {Name: "unknown"}})
+	if _, ok := ind["UNKNOWN"]; ok {
+		t.Fatal("invalid specs should be skipped")
+	}
+	for _, v := range ind["MACD12_26_9.DIF"] {
+		if !math.IsNaN(v) {
+			t.Fatalf("series shorter than warm-up should be all NaN, got %v", v)
+		}
+	}
+}
+
+func TestValidateIndicatorSpec(t *testing.T) {
+	cases := []struct {
+		spec IndicatorSpec
+		ok   bool
+	}{
+		{IndicatorSpec{Name: "MA", Params: []float64{20}}, true},
+		{IndicatorSpec{Name: "boll", Params: []float64{20, 2.5}}, true},
+		{IndicatorSpec{Name: "ma", Params: []float64{2.5}}, false},
+		{IndicatorSpec{Name: "rsi", Params: []float64{14, 3}}, false},
+		{IndicatorSpec{Name: "macd", Params: []float64{26, 12}}, false},
+		{IndicatorSpec{Name: "obv"}, false},
+	}
+	for _, tc := range cases {
+		if err := ValidateIndicatorSpec(tc.spec); (err == nil) != tc.ok {
+			t.Fatalf("ValidateIndicatorSpec(%+v) err=%v, want ok=%v", tc.spec, err, tc.ok)
+		}
+	}
+}
+
+func TestEvidenceIndicatorFactors(t *testing.T) {
+	cs := indicatorCandles(60)
+	ind := ComputeIndicators(cs, []IndicatorSpec{{Name: "rsi"}, {Name: "macd"}})
+	signals := []PatternSignal{{Type: "Hammer", Direction: "bullish", Position: 50, Strength: 0.8}}
+
+	plain := BuildPatternEvidence(signals, cs, DefaultEvidenceConfig())
+	withInd := BuildPatternEvidenceWithIndicators(signals, cs, DefaultEvidenceConfig(), ind)
+	if len(withInd) != 1 || len(withInd[0].ContextFactors) != len(plain[0].ContextFactors)+2 {
+		t.Fatalf("expected rsi_regime and macd_momentum context factors, got %+v", withInd[0].ContextFactors)
+	}
+	names := map[string]bool{}
+	for _, f := range withInd[0].ContextFactors {
+		names[f.Name] = true
+	}
+	if !names["rsi_regime"] || !names["macd_momentum"] {
+		t.Fatalf("missing indicator factors: %+v", withInd[0].ContextFactors)
+	}
+
+	early := BuildPatternEvidenceWithIndicators([]PatternSignal{{Type: "Hammer", Direction: "bullish", Position: 5}}, cs, DefaultEvidenceConfig(), ind)
+	if len(early[0].ContextFactors) != len(BuildPatternEvidence([]PatternSignal{{Type: "Hammer", Direction: "bullish", Position: 5}}, cs, DefaultEvidenceConfig())[0].ContextFactors) {
+		t.Fatal("warm-up indicator values should not produce factors")
+	}
+}
@@ -0,0 +1,112 @@
+package identify
+
+import "math"
+
+// Kagi signal types
+// 卡吉图（Kagi）信号类型
+const (
+	SignalKagiYang = "Kagi Yang Turn" // Thin line rises above the previous shoulder (阴线升破前肩，转阳)
+	SignalKagiYin  = "Kagi Yin Turn"  // Thick line falls below the previous waist (阳线跌破前腰，转阴)
+)
+
+// KagiConfig controls Kagi construction; Reversal is the price move against
+// the current line that starts a new line.
+// KagiConfig 控制卡吉图构建；Reversal 为开启新线所需的反向价格幅度。
+type KagiConfig struct {
+	Reversal BoxConfig `json:"reversal"`
+}
+
+// DefaultKagiConfig returns an ATR(14) reversal amount.
+// DefaultKagiConfig 返回以 ATR(14) 为反转幅度的默认配置。
+func DefaultKagiConfig() KagiConfig {
+	return KagiConfig{Reversal: DefaultBoxConfig()}
+}
+
+// KagiSegment is one vertical Kagi line from Start to End.
+// KagiSegment 为一根从 Start 到 End 的竖直卡吉线。
+type KagiSegment struct {
+	Start         float64 `json:"start"`
+	End           float64 `json:"end"`
+	Direction     string  `json:"direction"` // up/down
+	StartPosition int     `json:"start_position"`
+	EndPosition   int     `json:"end_position"`
+	// Yang is the line thickness at End: thick (yang) after rising above a
+	// shoulder, thin (yin) after falling below a waist.
+	// Yang 为线段末端的粗细：升破前肩后为粗线（阳），跌破前腰后为细线（阴）。
+	Yang bool `json:"yang"`
+	// TurnPosition is the source bar where the line changed thickness, -1 if it did not.
+	// TurnPosition 为线条粗细发生变化的原始K线序号，未变化为 -1。
+	TurnPosition int `json:"turn_position"`
+}
+
+// Kagi builds Kagi lines from closes of chronological cs and returns them
+// with the reversal amount used.
+// Kagi 基于按时间排列的 cs 收盘价构建卡吉线，并返回所用反转幅度。
+func Kagi(cs []CandlestickWrapper, cfg KagiConfig) ([]KagiSegment, float64) {
+	r := BoxSize(cs, cfg.Reversal)
+	out := make([]KagiSegment, 0)
+	if r <= 0 || len(cs) == 0 {
+		return out, r
+	}
+
+	cur := KagiSegment{Start: cs[0].Close, End: cs[0].Close, TurnPosition: -1}
+	shoulder, waist := math.NaN(), math.NaN()
+	yang := false
+	for i := 1; i < len(cs); i++ {
+		c := cs[i].Close
+		switch {
+		case cur.Direction == "":
+			if math.Abs(c-cur.Start) < r {
+				continue
+			}
+			cur.Direction = "down"
+			if c > cur.Start {
+				cur.Direction = "up"
+			}
+			cur.End, cur.EndPosition = c, i
+			yang = cur.Direction == "up"
+		case cur.Direction == "up" && c > cur.End, cur.Direction == "down" && c < cur.End:
+			cur.End, cur.EndPosition = c, i
+		case cur.Direction == "up" && cur.End-c >= r:
+			shoulder = cur.End
+			out = append(out, cur)
+			cur = KagiSegment{Start: cur.End, End: c, Direction: "down", StartPosition: cur.EndPosition, EndPosition: i, TurnPosition: -1}
+		case cur.Direction == "down" && c-cur.End >= r:
+			waist = cur.End
+			out = append(out, cur)
+			cur = KagiSegment{Start: cur.End, End: c, Direction: "up", StartPosition: cur.EndPosition, EndPosition: i, TurnPosition: -1}
+		default:
+			continue
+		}
+
+		switch {
+		case !yang && cur.Direction == "up" && cur.End > shoulder:
+			yang, cur.TurnPosition = true, i
+		case yang && cur.Direction == "down" && cur.End < waist:
+			yang, cur.TurnPosition = false, i
+		}
+		cur.Yang = yang
+	}
+	if cur.Direction != "" {
+		out = append(out, cur)
+	}
+	return out, r
+}
+
+// KagiSignals marks the yin-to-yang and yang-to-yin turns.
+// KagiSignals 标记由阴转阳与由阳转阴的转折。
+func KagiSignals(cs []CandlestickWrapper, segments []KagiSegment) []PatternSignal {
+	out := make([]PatternSignal, 0)
+	for _, s := range segments {
+		if s.TurnPosition < 0 || s.TurnPosition >= len(cs) {
+			continue
+		}
+		price := cs[s.TurnPosition].Close
+		if s.Yang {
+			out = append(out, priceChartSignal(cs, s.TurnPosition, SignalKagiYang, "bullish", price))
+		} else {
+			out = append(out, priceChartSignal(cs, s.TurnPosition, SignalKagiYin, "bearish", price))
+		}
+	}
+	return out
+}
@@ -0,0 +1,327 @@
+package identify
+
+import (
+	"math"
+	"sort"
+)
+
+// LevelSource describes where a support/resistance candidate came from.
+// LevelSource 描述支撑/阻力候选价位的来源。
+type LevelSource string
+
+const (
+	LevelSourcePivot  LevelSource = "pivot"  // Clustered swing highs/lows (摆动点聚类)
+	LevelSourceVolume LevelSource = "volume" // High-volume price node (成交密集区)
+	LevelSourceGap    LevelSource = "gap"    // Prior window/gap edge (缺口边缘)
+)
+
+const (
+	LevelSupport    = "support"
+	LevelResistance = "resistance"
+)
+
+// PriceLevel is a detected horizontal support or resistance level.
+// PriceLevel 是识别出的水平支撑或阻力位。
+type PriceLevel struct {
+	Price         float64       `json:"price"`
+	Type          string        `json:"type"` // support/resistance relative to the last close
+	Touches       int           `json:"touches"`
+	Strength      float64       `json:"strength"` // 0-1, recency weighted
+	Sources       []LevelSource `json:"sources"`
+	FirstPosition int           `json:"first_position"`
+	LastPosition  int           `json:"last_position"`
+}
+
+// LevelConfig controls support/resistance detection.
+// LevelConfig 控制支撑阻力位识别参数。
+type LevelConfig struct {
+	// Lookback bounds the bars used to build levels (default 120).
+	// Lookback 限定用于识别价位的K线数量（默认 120）。
+	Lookback int `json:"lookback"`
+	// ClusterTolerance merges candidates within this percent of each other (default 1.0).
+	// ClusterTolerance 将相距在该百分比内的候选价位合并（默认 1.0）。
+	ClusterTolerance float64 `json:"cluster_tolerance"`
+	// ProximityPercent is how close a pattern must form to a level to count as "at" it (default 1.5).
+	// ProximityPercent 是形态与价位的距离百分比阈值，用于判断“位于”价位（默认 1.5）。
+	ProximityPercent float64 `json:"proximity_percent"`
+	// VolumeBins is the number of price bins of the volume-at-price histogram (default 24).
+	// VolumeBins 是成交量价格分布直方图的分箱数（默认 24）。
+	VolumeBins int `json:"volume_bins"`
+	// VolumeNodes is how many high-volume nodes become candidates (default 3).
+	// VolumeNodes 是作为候选的成交密集区数量（默认 3）。
+	VolumeNodes int `json:"volume_nodes"`
+	// RecencyHalfLife is the half-life in bars used to decay old touches (default 40).
+	// RecencyHalfLife 是触及次数按K线衰减的半衰期（默认 40）。
+	RecencyHalfLife float64 `json:"recency_half_life"`
+	// MaxLevels caps the number of returned levels (default 8).
+	// MaxLevels 限制返回的价位数量（默认 8）。
+	MaxLevels int         `json:"max_levels"`
+	Pivot     PivotConfig `json:"pivot"`
+}
+
+// DefaultLevelConfig returns defaults suitable for daily bars.
+// DefaultLevelConfig 返回适合日线的默认配置。
+func DefaultLevelConfig() LevelConfig {
+	return LevelConfig{
+		Lookback:         120,
+		ClusterTolerance: 1.0,
+		ProximityPercent: 1.5,
+		VolumeBins:       24,
+		VolumeNodes:      3,
+		RecencyHalfLife:  40,
+		MaxLevels:        8,
+		Pivot:            DefaultPivotConfig(),
+	}
+}
+
+type levelCandidate struct {
+	price    float64
+	position int
+	source   LevelSource
+}
+
+// DetectLevels finds support/resistance levels in the last cfg.Lookback bars
+// by clustering pivots, high-volume nodes and gap edges. Positions in the
+// result refer to indexes of cs.
+// DetectLevels 在最近 cfg.Lookback 根K线中，通过聚类摆动点、成交密集区与缺口边缘识别支撑阻力位。
+func DetectLevels(cs []CandlestickWrapper, cfg LevelConfig) []PriceLevel {
+	cfg = normalizeLevelConfig(cfg)
+	if len(cs) < 3 {
+		return nil
+	}
+	start := len(cs) - cfg.Lookback
+	if start < 0 {
+		start = 0
+	}
+	window := cs[start:]
+
+	candidates := make([]levelCandidate, 0)
+	for _, p := range DetectPivots(window, cfg.Pivot) {
+		candidates = append(candidates, levelCandidate{price: p.Price, position: start + p.Position, source: LevelSourcePivot})
+	}
+	for _, node := range highVolumeNodes(window, cfg.VolumeBins, cfg.VolumeNodes) {
+		candidates = append(candidates, levelCandidate{price: node, position: len(cs) - 1, source: LevelSourceVolume})
+	}
+	for i := 1; i < len(window); i++ {
+		prev, cur := window[i-1], window[i]
+		if cur.Low > prev.High {
+			candidates = append(candidates, levelCandidate{price: prev.High, position: start + i, source: LevelSourceGap})
+		} else if cur.High < prev.Low {
+			candidates = append(candidates, levelCandidate{price: prev.Low, position: start + i, source: LevelSourceGap})
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	lastClose := cs[len(cs)-1].Close
+	levels := make([]PriceLevel, 0)
+	for _, cluster := range clusterCandidates(candidates, cfg.ClusterTolerance) {
+		level := buildLevel(cs, start, cluster, cfg)
+		if level.Touches == 0 {
+			continue
+		}
+		if level.Price < lastClose {
+			level.Type = LevelSupport
+		} else {
+			level.Type = LevelResistance
+		}
+		levels = append(levels, level)
+	}
+
+	sort.Slice(levels, func(i, j int) bool {
+		if levels[i].Strength == levels[j].Strength {
+			return levels[i].Price < levels[j].Price
+		}
+		return levels[i].Strength > levels[j].Strength
+	})
+	if len(levels) > cfg.MaxLevels {
+		levels = levels[:cfg.MaxLevels]
+	}
+	return levels
+}
+
+// NearestLevel returns the level of the given type closest to price and its
+// distance in percent; ok is false when no such level exists.
+// NearestLevel 返回与价格最近的指定类型价位及距离百分比。
+func NearestLevel(levels []PriceLevel, levelType string, price float64) (PriceLevel, float64, bool) {
+	best := PriceLevel{}
+	bestDist := math.Inf(1)
+	for _, l := range levels {
+		if levelType != "" && l.Type != levelType {
+			continue
+		}
+		if l.Price == 0 {
+			continue
+		}
+		dist := math.Abs(price-l.Price) / l.Price * 100
+		if dist < bestDist {
+			best, bestDist = l, dist
+		}
+	}
+	return best, bestDist, !math.IsInf(bestDist, 1)
+}
+
+func normalizeLevelConfig(cfg LevelConfig) LevelConfig {
+	def := DefaultLevelConfig()
+	if cfg.Lookback < 3 {
+		cfg.Lookback = def.Lookback
+	}
+	if cfg.ClusterTolerance <= 0 {
+		cfg.ClusterTolerance = def.ClusterTolerance
+	}
+	if cfg.ProximityPercent <= 0 {
+		cfg.ProximityPercent = def.ProximityPercent
+	}
+	if cfg.VolumeBins < 2 {
+		cfg.VolumeBins = def.VolumeBins
+	}
+	if cfg.VolumeNodes < 0 {
+		cfg.VolumeNodes = def.VolumeNodes
+	}
+	if cfg.RecencyHalfLife <= 0 {
+		cfg.RecencyHalfLife = def.RecencyHalfLife
+	}
+	if cfg.MaxLevels < 1 {
+		cfg.MaxLevels = def.MaxLevels
+	}
+	if cfg.Pivot.Method == "" {
+		cfg.Pivot = def.Pivot
+	}
+	return cfg
+}
+
+// clusterCandidates greedily merges price-sorted candidates whose distance to
+// the running cluster mean is within tolerance percent.
+func clusterCandidates(candidates []levelCandidate, tolerance float64) [][]levelCandidate {
+	sorted := append([]levelCandidate(nil), candidates...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].price < sorted[j].price })
+
+	clusters := make([][]levelCandidate, 0)
+	current := []levelCandidate{sorted[0]}
+	sum := sorted[0].price
+	for _, c := range sorted[1:] {
+		mean := sum / float64(len(current))
+		if mean > 0 && (c.price-mean)/mean*100 <= tolerance {
+			current = append(current, c)
+			sum += c.price
+			continue
+		}
+		clusters = append(clusters, current)
+		current = []levelCandidate{c}
+		sum = c.price
+	}
+	return append(clusters, current)
+}
+
+// buildLevel counts touch episodes (consecutive touching bars count once) and
+// derives a recency-weighted strength for one cluster.
+func buildLevel(cs []CandlestickWrapper, start int, cluster []levelCandidate, cfg LevelConfig) PriceLevel {
+	sum := 0.0
+	sources := make([]LevelSource, 0, 3)
+	seen := make(map[LevelSource]bool)
+	for _, c := range cluster {
+		sum += c.price
+		if !seen[c.source] {
+			seen[c.source] = true
+			sources = append(sources, c.source)
+		}
+	}
+	price := sum / float64(len(cluster))
+	band := price * cfg.ClusterTolerance / 100
+
+	level := PriceLevel{Price: price, Sources: sources, FirstPosition: -1, LastPosition: -1}
+	last := len(cs) - 1
+	weighted := 0.0
+	touching := false
+	for i := start; i <= last; i++ {
+		hit := cs[i].Low <= price+band && cs[i].High >= price-band
+		if hit && !touching {
+			level.Touches++
+			weighted += math.Exp(-math.Ln2 * float64(last-i) / cfg.RecencyHalfLife)
+			if level.FirstPosition < 0 {
+				level.FirstPosition = i
+			}
+		}
+		if hit {
+			level.LastPosition = i
+		}
+		touching = hit
+	}
+
+	raw := weighted + 0.5*float64(len(sources)-1)
+	level.Strength = clamp01(raw / (raw + 2))
+	return level
+}
+
+// highVolumeNodes returns bin-centre prices of the largest local maxima of the
+// volume-at-price histogram.
+func highVolumeNodes(cs []CandlestickWrapper, bins, nodes int) []float64 {
+	if nodes == 0 {
+		return nil
+	}
+	lo, step, vols := volumeHistogram(cs, bins)
+	if step <= 0 {
+		return nil
+	}
+	type node struct {
+		price  float64
+		volume float64
+	}
+	peaks := make([]node, 0)
+	for i, v := range vols {
+		if v <= 0 {
+			continue
+		}
+		if (i > 0 && vols[i-1] > v) || (i+1 < len(vols) && vols[i+1] > v) {
+			continue
+		}
+		peaks = append(peaks, node{price: lo + (float64(i)+0.5)*step, volume: v})
+	}
+	sort.Slice(peaks, func(i, j int) bool { return peaks[i].volume > peaks[j].volume })
+	if len(peaks) > nodes {
+		peaks = peaks[:nodes]
+	}
+	out := make([]float64, 0, len(peaks))
+	for _, p := range peaks {
+		out = append(out, p.price)
+	}
+	return out
+}
+
+// volumeHistogram spreads each bar's volume uniformly over its [Low, High]
+// range across equal-width price bins. It returns the lowest price, bin width
+// and per-bin volume.
+func volumeHistogram(cs []CandlestickWrapper, bins int) (float64, float64, []float64) {
+	if len(cs) == 0 || bins < 1 {
+		return 0, 0, nil
+	}
+	lo, hi := cs[0].Low, cs[0].High
+	for _, c := range cs {
+		lo = math.Min(lo, c.Low)
+		hi = math.Max(hi, c.High)
+	}
+	if hi <= lo {
+		return lo, 0, nil
+	}
+	step := (hi - lo) / float64(bins)
+	vols := make([]float64, bins)
+	for _, c := range cs {
+		if c.Volume <= 0 {
+			continue
+		}
+		first := int((c.Low - lo) / step)
+		lastBin := int((c.High - lo) / step)
+		if lastBin >= bins {
+			lastBin = bins - 1
+		}
+		if first >= bins {
+			first = bins - 1
+		}
+		share := c.Volume / float64(lastBin-first+1)
+		for b := first; b <= lastBin; b++ {
+			vols[b] += share
+		}
+	}
+	return lo, step, vols
+}
@@ -0,0 +1,52 @@
+package identify
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDetectLevelsFindsRepeatedSupport(t *testing.T) {
+	// Price bounces off ~100 three times, then trades higher.
+	// 价格三次在 ~100 附近反弹，随后上行。
+	closes := []float64{
+		112, 109, 105, 101, 100, 104, 108, 111, 108, 104,
+		101, 100.3, 103, 107, 110, 107, 103, 100.5, 100.2, 104,
+		108, 112, 115,
+	}
+	cs := wrapCloses(closes)
+	levels := DetectLevels(cs, DefaultLevelConfig())
+	if len(levels) == 0 {
+		t.Fatal("expected at least one level")
+	}
+
+	level, dist, ok := NearestLevel(levels, LevelSupport, 100)
+	if !ok {
+		t.Fatalf("expected a support level, got %+v", levels)
+	}
+	if dist > 1.5 {
+		t.Fatalf("expected support near 100, got %+v (dist %.2f%%)", level, dist)
+	}
+	if level.Touches < 3 {
+		t.Fatalf("expected >= 3 touches, got %+v", level)
+	}
+	if level.Strength <= 0 || level.Strength > 1 || math.IsNaN(level.Strength) {
+		t.Fatalf("strength out of range: %+v", level)
+	}
+}
+
+func TestLevelContextFactorUsesOnlyPriorBars(t *testing.T) {
+	closes := []float64{
+		112, 109, 105, 101, 100, 104, 108, 111, 108, 104,
+		101, 100.3, 103, 107, 110, 107, 103, 100.4,
+	}
+	cs := wrapCloses(closes)
+	p := PatternSignal{Type: "Hammer", Direction: "bullish", Position: len(cs) - 1}
+
+	factor := levelContextFactor(cs, p, DetectLevels(cs[:p.Position], DefaultLevelConfig()), DefaultLevelConfig())
+	if factor.Name != "at_support_resistance" {
+		t.Fatalf("unexpected factor: %+v", factor)
+	}
+	if !factor.Passed {
+		t.Fatalf("expected pattern at support to pass, got %+v", factor)
+	}
+}
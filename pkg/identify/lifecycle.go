@@ -0,0 +1,152 @@
+package identify
+
+import (
+	"fmt"
+	"math"
+)
+
+// Pattern lifecycle states.
+// 形态生命周期状态。
+const (
+	PatternPending     = "pending"     // Waiting for a confirming close (等待确认)
+	PatternConfirmed   = "confirmed"   // A later close confirmed the pattern (已确认)
+	PatternInvalidated = "invalidated" // A later close went through the invalidation price (已失效)
+	PatternExpired     = "expired"     // No confirmation within the window (超时未确认)
+)
+
+// LifecycleConfig controls pattern confirmation tracking.
+// LifecycleConfig 控制形态确认跟踪。
+type LifecycleConfig struct {
+	// ConfirmBars is how many bars after the pattern may confirm it (default 3).
+	// ConfirmBars 是形态之后允许确认的K线数量（默认 3）。
+	ConfirmBars int `json:"confirm_bars"`
+}
+
+// DefaultLifecycleConfig returns defaults suitable for daily bars.
+// DefaultLifecycleConfig 返回适合日线的默认配置。
+func DefaultLifecycleConfig() LifecycleConfig {
+	return LifecycleConfig{ConfirmBars: 3}
+}
+
+// PatternLifecycle is the confirmation state of one pattern as of the last bar.
+// PatternLifecycle 是截至最后一根K线的形态确认状态。
+type PatternLifecycle struct {
+	State string `json:"state"`
+	// InvalidationPrice is the close that voids the pattern; 0 for neutral patterns.
+	// InvalidationPrice 是使形态失效的收盘价位；中性形态为 0。
+	InvalidationPrice float64 `json:"invalidation_price"`
+	ConfirmationPrice float64 `json:"confirmation_price"`
+	ConfirmationRule  string  `json:"confirmation_rule"`
+	ConfirmedAt       int     `json:"confirmed_at"`   // -1 when not confirmed
+	InvalidatedAt     int     `json:"invalidated_at"` // -1 when not invalidated
+}
+
+// PatternSpan returns the number of candles a named pattern covers (1 when unknown).
+// PatternSpan 返回指定形态包含的K线数量（未知形态为 1）。
+func PatternSpan(pattern string) int {
+	if spec, ok := gradeSpecs[pattern]; ok {
+		return spec.span
+	}
+	return 1
+}
+
+// InvalidationPrice returns the level whose close-through voids the pattern:
+// the pattern low for bullish patterns and the high for bearish ones, with
+// the engulfing body edge and the window edge as pattern-specific levels.
+// cs is chronological and p.Position is the last bar of the pattern.
+// InvalidationPrice 返回收盘穿越即令形态失效的价位：看涨形态取形态最低价，看跌形态取最高价；
+// 吞噬形态取实体边缘，窗口取缺口边缘。cs 按时间顺序排列，p.Position 为形态最后一根K线。
+func InvalidationPrice(p PatternSignal, cs []CandlestickWrapper) float64 {
+	if p.Position < 0 || p.Position >= len(cs) {
+		return 0
+	}
+	last := cs[p.Position]
+	switch p.Type {
+	case "Bullish Engulfing":
+		return math.Min(last.Open, last.Close)
+	case "Bearish Engulfing":
+		return math.Max(last.Open, last.Close)
+	case "Rising Window":
+		if p.Position > 0 {
+			return cs[p.Position-1].High
+		}
+	case "Falling Window":
+		if p.Position > 0 {
+			return cs[p.Position-1].Low
+		}
+	}
+
+	start := p.Position - PatternSpan(p.Type) + 1
+	if start < 0 {
+		start = 0
+	}
+	low, high := last.Low, last.High
+	for _, c := range cs[start:p.Position] {
+		low = math.Min(low, c.Low)
+		high = math.Max(high, c.High)
+	}
+	switch p.Direction {
+	case "bullish":
+		return low
+	case "bearish":
+		return high
+	}
+	return 0
+}
+
+// TrackPatternLifecycle replays the bars after p and reports its state.
+// Directional patterns confirm on a close beyond the pattern's last close in
+// their direction within cfg.ConfirmBars bars, and are invalidated by any
+// later close through InvalidationPrice (also after confirmation); expired
+// patterns are no longer tracked. Neutral patterns confirm on a close outside
+// the last pattern bar's range.
+// TrackPatternLifecycle 回放形态之后的K线并给出状态：方向性形态在 ConfirmBars 根K线内收盘越过形态末根收盘价即确认，
+// 之后任意收盘穿越失效价即失效（确认后亦然），超时后不再跟踪；中性形态在收盘突破末根K线区间时确认。
+func TrackPatternLifecycle(p PatternSignal, cs []CandlestickWrapper, cfg LifecycleConfig) PatternLifecycle {
+	if cfg.ConfirmBars < 1 {
+		cfg.ConfirmBars = DefaultLifecycleConfig().ConfirmBars
+	}
+	lc := PatternLifecycle{State: PatternPending, ConfirmedAt: -1, InvalidatedAt: -1}
+	if p.Position < 0 || p.Position >= len(cs) {
+		return lc
+	}
+	last := cs[p.Position]
+	lc.InvalidationPrice = InvalidationPrice(p, cs)
+
+	var confirmed func(c CandlestickWrapper) bool
+	invalidated := func(CandlestickWrapper) bool { return false }
+	switch p.Direction {
+	case "bullish":
+		lc.ConfirmationPrice = last.Close
+		lc.ConfirmationRule = fmt.Sprintf("close above %.2f within %d bars", last.Close, cfg.ConfirmBars)
+		confirmed = func(c CandlestickWrapper) bool { return c.Close > last.Close }
+		invalidated = func(c CandlestickWrapper) bool { return c.Close < lc.InvalidationPrice }
+	case "bearish":
+		lc.ConfirmationPrice = last.Close
+		lc.ConfirmationRule = fmt.Sprintf("close below %.2f within %d bars", last.Close, cfg.ConfirmBars)
+		confirmed = func(c CandlestickWrapper) bool { return c.Close < last.Close }
+		invalidated = func(c CandlestickWrapper) bool { return c.Close > lc.InvalidationPrice }
+	default:
+		lc.ConfirmationRule = fmt.Sprintf("close outside %.2f-%.2f within %d bars", last.Low, last.High, cfg.ConfirmBars)
+		confirmed = func(c CandlestickWrapper) bool { return c.Close > last.High || c.Close < last.Low }
+	}
+
+	for i := p.Position + 1; i < len(cs); i++ {
+		if invalidated(cs[i]) {
+			lc.State = PatternInvalidated
+			lc.InvalidatedAt = i
+			return lc
+		}
+		if lc.State != PatternPending {
+			continue
+		}
+		if confirmed(cs[i]) {
+			lc.State = PatternConfirmed
+			lc.ConfirmedAt = i
+		} else if i-p.Position >= cfg.ConfirmBars {
+			lc.State = PatternExpired
+			return lc
+		}
+	}
+	return lc
+}
@@ -0,0 +1,49 @@
+package identify
+
+import "testing"
+
+func TestTrackPatternLifecycle(t *testing.T) {
+	hammer := PatternSignal{Type: "Hammer", Direction: "bullish", Position: 0}
+	cs := []CandlestickWrapper{
+		candle(100, 101, 90, 101),
+		candle(101, 103, 100.5, 102.5), // closes higher: confirms
+		candle(102.5, 103, 95, 96),
+	}
+
+	lc := TrackPatternLifecycle(hammer, cs[:1], DefaultLifecycleConfig())
+	if lc.State != PatternPending || lc.InvalidationPrice != 90 {
+		t.Fatalf("expected pending hammer invalidated below its low, got %+v", lc)
+	}
+
+	lc = TrackPatternLifecycle(hammer, cs, DefaultLifecycleConfig())
+	if lc.State != PatternConfirmed || lc.ConfirmedAt != 1 {
+		t.Fatalf("expected confirmation on bar 1, got %+v", lc)
+	}
+
+	cs = append(cs, candle(96, 96.5, 88, 89))
+	lc = TrackPatternLifecycle(hammer, cs, DefaultLifecycleConfig())
+	if lc.State != PatternInvalidated || lc.InvalidatedAt != 3 || lc.ConfirmedAt != 1 {
+		t.Fatalf("expected invalidation on bar 3 after confirmation, got %+v", lc)
+	}
+
+	flat := []CandlestickWrapper{
+		candle(100, 101, 90, 101),
+		candle(101, 101.5, 100, 100.5),
+		candle(100.5, 101, 100, 100.8),
+		candle(100.8, 101, 100, 100.9),
+	}
+	if lc := TrackPatternLifecycle(hammer, flat, DefaultLifecycleConfig()); lc.State != PatternExpired {
+		t.Fatalf("expected expiry without a higher close, got %+v", lc)
+	}
+}
+
+func TestInvalidationPriceUsesEngulfingBody(t *testing.T) {
+	cs := []CandlestickWrapper{
+		candle(105, 106, 99, 100),
+		candle(99, 108, 97, 107),
+	}
+	p := PatternSignal{Type: "Bullish Engulfing", Direction: "bullish", Position: 1}
+	if got := InvalidationPrice(p, cs); got != 99 {
+		t.Fatalf("expected engulfing body low 99, got %.2f", got)
+	}
+}
@@ -0,0 +1,85 @@
+package identify
+
+import "math"
+
+// Line-break signal types
+// 新价线（三线反转）信号类型
+const (
+	SignalLineBreakReversalUp   = "Line Break Reversal Up"
+	SignalLineBreakReversalDown = "Line Break Reversal Down"
+)
+
+// LineBreakLine is one line of a line-break chart; Position is the source bar
+// whose close drew it.
+// LineBreakLine 为新价线图中的一根线；Position 为绘出该线的原始K线序号。
+type LineBreakLine struct {
+	Open      float64 `json:"open"`
+	Close     float64 `json:"close"`
+	Direction string  `json:"direction"` // up/down
+	Position  int     `json:"position"`
+	Reversal  bool    `json:"reversal"` // Breaks the extreme of the previous lines (突破此前各线极值的反转线)
+}
+
+// LineBreak builds a line-break chart (three-line break when lines is 3,
+// the default) from closes of chronological cs. A new line is drawn when the
+// close exceeds the last line; a reversal needs a close beyond the extreme of
+// the last lines lines.
+// LineBreak 基于按时间排列的 cs 收盘价构建新价线图（lines 为 3 即三线反转，默认值）。收盘超越最后一根线时
+// 顺势画线；反转需收盘突破最近 lines 根线的极值。
+func LineBreak(cs []CandlestickWrapper, lines int) []LineBreakLine {
+	if lines < 1 {
+		lines = 3
+	}
+	out := make([]LineBreakLine, 0)
+	if len(cs) == 0 {
+		return out
+	}
+	base := cs[0].Close
+	for i := 1; i < len(cs); i++ {
+		c := cs[i].Close
+		if len(out) == 0 {
+			switch {
+			case c > base:
+				out = append(out, LineBreakLine{Open: base, Close: c, Direction: "up", Position: i})
+			case c < base:
+				out = append(out, LineBreakLine{Open: base, Close: c, Direction: "down", Position: i})
+			}
+			continue
+		}
+
+		last := out[len(out)-1]
+		hi, lo := math.Inf(-1), math.Inf(1)
+		for _, l := range out[max(0, len(out)-lines):] {
+			hi = math.Max(hi, math.Max(l.Open, l.Close))
+			lo = math.Min(lo, math.Min(l.Open, l.Close))
+		}
+		switch {
+		case last.Direction == "up" && c > last.Close:
+			out = append(out, LineBreakLine{Open: last.Close, Close: c, Direction: "up", Position: i})
+		case last.Direction == "down" && c < last.Close:
+			out = append(out, LineBreakLine{Open: last.Close, Close: c, Direction: "down", Position: i})
+		case last.Direction == "up" && c < lo:
+			out = append(out, LineBreakLine{Open: last.Open, Close: c, Direction: "down", Position: i, Reversal: true})
+		case last.Direction == "down" && c > hi:
+			out = append(out, LineBreakLine{Open: last.Open, Close: c, Direction: "up", Position: i, Reversal: true})
+		}
+	}
+	return out
+}
+
+// LineBreakSignals marks the reversal lines.
+// LineBreakSignals 标记反转线。
+func LineBreakSignals(cs []CandlestickWrapper, lines []LineBreakLine) []PatternSignal {
+	out := make([]PatternSignal, 0)
+	for _, l := range lines {
+		if !l.Reversal || l.Position >= len(cs) {
+			continue
+		}
+		if l.Direction == "up" {
+			out = append(out, priceChartSignal(cs, l.Position, SignalLineBreakReversalUp, "bullish", l.Close))
+		} else {
+			out = append(out, priceChartSignal(cs, l.Position, SignalLineBreakReversalDown, "bearish", l.Close))
+		}
+	}
+	return out
+}
@@ -0,0 +1,448 @@
+package identify
+
+import (
+	"math"
+	"time"
+)
+
+// ScoringLookback is the number of bars before a pattern used for its size,
+// volume and trend components.
+// ScoringLookback 是形态之前用于计算大小、成交量与趋势分项的K线数量。
+const ScoringLookback = 10
+
+// trendExpectation describes what prior move a pattern wants.
+type trendExpectation int
+
+const (
+	trendAny     trendExpectation = iota // Indecision / neutral patterns (中性形态)
+	trendFalling                         // Bullish reversals, bearish continuations (看涨反转、下跌延续需前期下跌)
+	trendRising                          // Bearish reversals, bullish continuations (看跌反转、上涨延续需前期上涨)
+)
+
+// gradeSpec ties a chart pattern name to its detector and shape grader.
+type gradeSpec struct {
+	span        int
+	direction   string
+	trend       trendExpectation
+	description string
+	detect      func(*Detector, []CandlestickWrapper) bool
+	shape       func(DetectorConfig, []CandlestickWrapper) float64
+}
+
+// PatternScore is the component breakdown of a graded pattern.
+// PatternScore 是形态评分的分项明细。
+type PatternScore struct {
+	Shape  float64 `json:"shape"`  // How cleanly the candles fit the definition (形状契合度)
+	Size   float64 `json:"size"`   // Pattern range versus recent ranges (相对近期振幅的大小)
+	Volume float64 `json:"volume"` // Last-bar volume versus recent volume (量能)
+	Trend  float64 `json:"trend"`  // Whether the prior move suits the pattern (前期趋势匹配度)
+	Total  float64 `json:"total"`
+}
+
+// WithDetectorConfig sets the thresholds used for detection and shape grading.
+// WithDetectorConfig 设置用于识别与形状评分的阈值。
+func (ps *PatternScorer) WithDetectorConfig(cfg DetectorConfig) *PatternScorer {
+	ps.detector = NewDetector(cfg)
+	return ps
+}
+
+// ScoredPatterns lists every pattern name ScorePattern understands.
+// ScoredPatterns 列出 ScorePattern 支持的全部形态名称。
+func ScoredPatterns() []string {
+	names := make([]string, 0, len(gradeSpecs))
+	for name := range gradeSpecs {
+		names = append(names, name)
+	}
+	return names
+}
+
+// PatternDirection returns bullish, bearish or neutral for a pattern or
+// Heikin-Ashi signal type; it is the one direction table detection, evidence,
+// charts and reports share. Unknown types are neutral.
+// PatternDirection 返回形态或平均K线信号类型的方向（bullish、bearish 或 neutral），为识别、证据、图表与报告共用的唯一方向表；未知类型为 neutral。
+func PatternDirection(patternType string) string {
+	if spec, ok := gradeSpecs[patternType]; ok {
+		return spec.direction
+	}
+	switch patternType {
+	case SignalHABullishFlip, SignalHAShadowlessBull:
+		return "bullish"
+	case SignalHABearishFlip, SignalHAShadowlessBear:
+		return "bearish"
+	default:
+		return "neutral"
+	}
+}
+
+// ScorePattern grades any detector by name (chart names such as "Hammer" or
+// "Bullish Engulfing"). cs is newest first; bars after the pattern span feed
+// the size, volume and trend components, which fall back to 0.5 without history.
+// ScorePattern 按名称为任意形态评分（如 "Hammer"、"Bullish Engulfing"）。cs 按新到旧排列；
+// 形态之后的K线用于大小、成交量与趋势分项，无历史数据时取 0.5。
+func (ps *PatternScorer) ScorePattern(pattern string, cs []CandlestickWrapper) PatternResult {
+	spec, ok := gradeSpecs[pattern]
+	if !ok || len(cs) < spec.span {
+		return PatternResult{Pattern: pattern, Detected: false}
+	}
+	detector := ps.detector
+	if detector == nil {
+		detector = defaultDetector
+	}
+
+	score := ps.gradeComponents(spec, detector.Config(), cs)
+	return PatternResult{
+		Pattern:     pattern,
+		Detected:    spec.detect(detector, cs),
+		Strength:    score.Total,
+		Confidence:  score.Total,
+		Position:    0,
+		Direction:   spec.direction,
+		Description: spec.description,
+		Timestamp:   time.Now(),
+	}
+}
+
+// ScoreComponents returns the component breakdown behind ScorePattern.
+// ScoreComponents 返回 ScorePattern 的分项明细。
+func (ps *PatternScorer) ScoreComponents(pattern string, cs []CandlestickWrapper) (PatternScore, bool) {
+	spec, ok := gradeSpecs[pattern]
+	if !ok || len(cs) < spec.span {
+		return PatternScore{}, false
+	}
+	detector := ps.detector
+	if detector == nil {
+		detector = defaultDetector
+	}
+	return ps.gradeComponents(spec, detector.Config(), cs), true
+}
+
+func (ps *PatternScorer) gradeComponents(spec gradeSpec, cfg DetectorConfig, cs []CandlestickWrapper) PatternScore {
+	prior := cs[spec.span:]
+	if len(prior) > ScoringLookback {
+		prior = prior[:ScoringLookback]
+	}
+
+	score := PatternScore{
+		Shape:  clamp01(spec.shape(cfg, cs)),
+		Size:   sizeComponent(cs[:spec.span], prior),
+		Volume: volumeComponent(cs[0], prior, ps.config.VolumeRatioThreshold),
+		Trend:  trendComponent(prior, spec.trend),
+	}
+	weights := ps.config.ShapeWeight + ps.config.SizeWeight + ps.config.VolumeWeight + ps.config.TrendWeight
+	if weights <= 0 {
+		score.Total = (score.Shape + score.Size + score.Volume + score.Trend) / 4
+		return score
+	}
+	score.Total = clamp01((score.Shape*ps.config.ShapeWeight +
+		score.Size*ps.config.SizeWeight +
+		score.Volume*ps.config.VolumeWeight +
+		score.Trend*ps.config.TrendWeight) / weights)
+	return score
+}
+
+// sizeComponent compares the average range of the pattern bars with the
+// average range of prior bars: equal ranges give 0.5, double gives 1.
+func sizeComponent(pattern, prior []CandlestickWrapper) float64 {
+	if len(prior) == 0 {
+		return 0.5
+	}
+	priorRange := averageRange(prior)
+	if priorRange <= 0 {
+		return 0.5
+	}
+	return clamp01(0.5 * averageRange(pattern) / priorRange)
+}
+
+// volumeComponent scores the last bar's volume against the prior average;
+// reaching the configured ratio threshold scores 1.
+func volumeComponent(last CandlestickWrapper, prior []CandlestickWrapper, threshold float64) float64 {
+	avg := calculateAverageVolume(prior)
+	if avg <= 0 || last.Volume <= 0 {
+		return 0.5
+	}
+	if threshold <= 0 {
+		threshold = 1.5
+	}
+	return clamp01(last.Volume / avg / threshold)
+}
+
+// trendComponent maps the percent move from the oldest prior bar to the bar
+// before the pattern onto [0,1]; a 3% move in the expected direction scores 1.
+func trendComponent(prior []CandlestickWrapper, want trendExpectation) float64 {
+	if want == trendAny || len(prior) < 2 {
+		return 0.5
+	}
+	start := prior[len(prior)-1].Close
+	if start <= 0 {
+		return 0.5
+	}
+	move := (prior[0].Close - start) / start * 100
+	if want == trendFalling {
+		move = -move
+	}
+	return clamp01(0.5 + move/6)
+}
+
+func averageRange(cs []CandlestickWrapper) float64 {
+	if len(cs) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, c := range cs {
+		sum += c.High - c.Low
+	}
+	return sum / float64(len(cs))
+}
+
+// atLeast scores v against a minimum: 0.5 at threshold, 1 at full.
+func atLeast(v, threshold, full float64) float64 {
+	if v < threshold {
+		if threshold <= 0 {
+			return 0
+		}
+		return clamp01(0.5 * v / threshold)
+	}
+	if full <= threshold {
+		return 1
+	}
+	return clamp01(0.5 + 0.5*(v-threshold)/(full-threshold))
+}
+
+// atMost scores v against a maximum: 0.5 at threshold, 1 at zero.
+func atMost(v, threshold float64) float64 {
+	if threshold <= 0 {
+		return 0.5
+	}
+	if v > threshold {
+		return clamp01(0.5 - 0.5*(v-threshold)/threshold)
+	}
+	return clamp01(1 - 0.5*v/threshold)
+}
+
+func mean(vs ...float64) float64 {
+	if len(vs) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range vs {
+		sum += v
+	}
+	return sum / float64(len(vs))
+}
+
+// ratios returns body, upper and lower shadow as fractions of the range.
+func ratios(c CandlestickWrapper) (float64, float64, float64) {
+	r := c.High - c.Low
+	if r <= 0 {
+		return 1, 0, 0
+	}
+	return c.Body() / r, c.UpperShadow() / r, c.LowerShadow() / r
+}
+
+// shadowToBody returns shadow/body, treating a zero body as 1% of the range.
+func shadowToBody(shadow float64, c CandlestickWrapper) float64 {
+	body := c.Body()
+	if body <= 0 {
+		body = 0.01 * (c.High - c.Low)
+	}
+	if body <= 0 {
+		return 0
+	}
+	return shadow / body
+}
+
+func gradeDoji(cfg DetectorConfig, cs []CandlestickWrapper) float64 {
+	b, u, l := ratios(cs[0])
+	return mean(atMost(b, cfg.DojiBodyRatio), atLeast(math.Min(u, l), cfg.DojiShadowRatio, 0.45))
+}
+
+func gradeLongLeggedDoji(cfg DetectorConfig, cs []CandlestickWrapper) float64 {
+	b, u, l := ratios(cs[0])
+	return mean(atMost(b, cfg.LongLeggedBodyRatio), atLeast(math.Min(u, l), cfg.LongLeggedShadowRatio, 0.48))
+}
+
+func gradeLowerShadowCandle(cfg DetectorConfig, cs []CandlestickWrapper) float64 {
+	c := cs[0]
+	long := shadowToBody(c.LowerShadow(), c)
+	opposite := shadowToBody(c.UpperShadow(), c)
+	return mean(atLeast(long, cfg.HammerShadowMultiple, 2*cfg.HammerShadowMultiple), atMost(opposite, cfg.HammerOppositeMultiple))
+}
+
+func gradeUpperShadowCandle(cfg DetectorConfig, cs []CandlestickWrapper) float64 {
+	c := cs[0]
+	long := shadowToBody(c.UpperShadow(), c)
+	opposite := shadowToBody(c.LowerShadow(), c)
+	return mean(atLeast(long, cfg.HammerShadowMultiple, 2*cfg.HammerShadowMultiple), atMost(opposite, cfg.HammerOppositeMultiple))
+}
+
+func gradeMarubozu(cfg DetectorConfig, cs []CandlestickWrapper) float64 {
+	c := cs[0]
+	b, _, _ := ratios(c)
+	shadows := shadowToBody(c.UpperShadow()+c.LowerShadow(), c)
+	return mean(atMost(shadows, 2*cfg.MarubozuShadowMultiple), atLeast(b, 0.9, 1))
+}
+
+func gradeSpinningTop(cfg DetectorConfig, cs []CandlestickWrapper) float64 {
+	c := cs[0]
+	b, u, l := ratios(c)
+	return mean(atMost(b, cfg.SpinningTopBodyRatio), atMost(math.Abs(u-l), cfg.SpinningTopBalanceRatio),
+		atLeast(math.Min(u, l), cfg.SpinningTopShadowRatio, 0.4))
+}
+
+func gradeUmbrella(cfg DetectorConfig, cs []CandlestickWrapper) float64 {
+	c := cs[0]
+	_, u, _ := ratios(c)
+	long := shadowToBody(c.LowerShadow(), c)
+	return mean(atLeast(long, cfg.UmbrellaShadowMultiple, 2*cfg.UmbrellaShadowMultiple), atMost(u, 0.05))
+}
+
+func gradeDragonflyDoji(cfg DetectorConfig, cs []CandlestickWrapper) float64 {
+	b, u, l := ratios(cs[0])
+	return mean(atMost(b, cfg.DragonflyBodyRatio), atMost(u, cfg.DragonflyOppositeRatio), atLeast(l, cfg.DragonflyShadowRatio, 0.95))
+}
+
+func gradeGravestoneDoji(cfg DetectorConfig, cs []CandlestickWrapper) float64 {
+	b, u, l := ratios(cs[0])
+	return mean(atMost(b, cfg.DragonflyBodyRatio), atMost(l, cfg.DragonflyOppositeRatio), atLeast(u, cfg.DragonflyShadowRatio, 0.95))
+}
+
+func gradeEngulfing(_ DetectorConfig, cs []CandlestickWrapper) float64 {
+	first, second := cs[1], cs[0]
+	if first.Body() <= 0 {
+		return 0.5
+	}
+	b2, _, _ := ratios(second)
+	return mean(atLeast(second.Body()/first.Body(), 1, 2), atLeast(b2, 0.4, 0.8))
+}
+
+// gradePenetration scores how deep the second candle closes into the first
+// body, beyond the midpoint required by Piercing Line / Dark Cloud Cover.
+func gradePenetration(_ DetectorConfig, cs []CandlestickWrapper) float64 {
+	first, second := cs[1], cs[0]
+	half := first.Body() / 2
+	if half <= 0 {
+		return 0.5
+	}
+	depth := math.Abs(second.Close-first.Close) / (2 * half)
+	b1, _, _ := ratios(first)
+	return mean(atLeast(depth, 0.5, 1), atLeast(b1, 0.5, 0.9))
+}
+
+func gradeTweezerBottoms(cfg DetectorConfig, cs []CandlestickWrapper) float64 {
+	first, second := cs[1], cs[0]
+	base := math.Min(first.Low, second.Low)
+	if base <= 0 {
+		return 0.5
+	}
+	colors := 0.5
+	if first.IsBearish() && second.IsBullish() {
+		colors = 1
+	}
+	return mean(atMost(math.Abs(first.Low-second.Low)/base, cfg.TweezerTolerance), colors)
+}
+
+func gradeTweezerTops(cfg DetectorConfig, cs []CandlestickWrapper) float64 {
+	first, second := cs[1], cs[0]
+	base := math.Min(first.High, second.High)
+	if base <= 0 {
+		return 0.5
+	}
+	colors := 0.5
+	if first.IsBullish() && second.IsBearish() {
+		colors = 1
+	}
+	return mean(atMost(math.Abs(first.High-second.High)/base, cfg.TweezerTolerance), colors)
+}
+
+// gradeWindow scores the gap size in percent of the first close; a 1% gap scores 1.
+func gradeWindow(_ DetectorConfig, cs []CandlestickWrapper) float64 {
+	first, second := cs[1], cs[0]
+	if first.Close <= 0 {
+		return 0.5
+	}
+	gap := math.Max(second.Low-first.High, first.Low-second.High)
+	return atLeast(gap/first.Close*100, 0, 1)
+}
+
+func gradeHarami(cfg DetectorConfig, cs []CandlestickWrapper) float64 {
+	first, second := cs[1], cs[0]
+	if first.Body() <= 0 {
+		return 0.5
+	}
+	b1, _, _ := ratios(first)
+	return mean(atMost(second.Body()/first.Body(), cfg.HaramiBodyMultiple), atLeast(b1, 0.5, 0.9))
+}
+
+func gradeStar(cfg DetectorConfig, cs []CandlestickWrapper) float64 {
+	first, second, third := cs[2], cs[1], cs[0]
+	b1, _, _ := ratios(first)
+	b2, _, _ := ratios(second)
+	depth := 0.0
+	if first.Body() > 0 {
+		depth = math.Abs(third.Close-first.Close) / first.Body()
+	}
+	return mean(atLeast(b1, 0.5, 0.9), atMost(b2, cfg.StarBodyRatio), atLeast(depth, 0.5, 1))
+}
+
+// gradeThreeCandles scores long bodies that close near their extreme, as in
+// Three White Soldiers (upper shadow) and Three Black Crows (lower shadow).
+func gradeThreeCandles(cs []CandlestickWrapper, bullish bool) float64 {
+	parts := make([]float64, 0, 6)
+	for _, c := range cs[:3] {
+		b, u, l := ratios(c)
+		tail := u
+		if !bullish {
+			tail = l
+		}
+		parts = append(parts, atLeast(b, 0.5, 0.9), atMost(tail, 0.3))
+	}
+	return mean(parts...)
+}
+
+func gradeThreeMethods(cfg DetectorConfig, cs []CandlestickWrapper) float64 {
+	first, fifth := cs[4], cs[0]
+	b1, _, _ := ratios(first)
+	if first.Body() <= 0 {
+		return 0.5
+	}
+	inner := make([]float64, 0, 3)
+	for _, c := range cs[1:4] {
+		inner = append(inner, atMost(c.Body()/first.Body(), cfg.ThreeMethodsInnerMultiple))
+	}
+	breakout := math.Max(fifth.Close-math.Max(first.Open, first.Close), math.Min(first.Open, first.Close)-fifth.Close)
+	return mean(atLeast(b1, cfg.ThreeMethodsBodyRatio, 0.9), mean(inner...), atLeast(breakout/first.Body(), 0, 0.5))
+}
+
+var gradeSpecs = map[string]gradeSpec{
+	"Doji":                  {1, "neutral", trendAny, "十字星 - 市场犹豫信号", (*Detector).Doji, gradeDoji},
+	"Long-Legged Doji":      {1, "neutral", trendAny, "长腿十字星 - 强烈犹豫信号", (*Detector).LongLeggedDoji, gradeLongLeggedDoji},
+	"Hammer":                {1, "bullish", trendFalling, "锤头线 - 看涨反转信号", (*Detector).Hammer, gradeLowerShadowCandle},
+	"Hanging Man":           {1, "bearish", trendRising, "吊颈线 - 看跌反转信号", (*Detector).HangingMan, gradeLowerShadowCandle},
+	"Inverted Hammer":       {1, "bullish", trendFalling, "倒锤头线 - 看涨反转信号", (*Detector).InvertedHammer, gradeUpperShadowCandle},
+	"Shooting Star":         {1, "bearish", trendRising, "流星线 - 看跌反转信号", (*Detector).ShootingStar, gradeUpperShadowCandle},
+	"Marubozu":              {1, "neutral", trendAny, "光头光脚 - 强烈趋势信号", (*Detector).Marubozu, gradeMarubozu},
+	"White Marubozu":        {1, "neutral", trendAny, "光头光脚阳线 - 强势买盘", (*Detector).WhiteMarubozu, gradeMarubozu},
+	"Black Marubozu":        {1, "neutral", trendAny, "光头光脚阴线 - 强势卖盘", (*Detector).BlackMarubozu, gradeMarubozu},
+	"Spinning Top":          {1, "neutral", trendAny, "纺锤线 - 市场犹豫信号", (*Detector).SpinningTop, gradeSpinningTop},
+	"Umbrella":              {1, "neutral", trendAny, "伞形线 - 支撑或阻力信号", (*Detector).Umbrella, gradeUmbrella},
+	"Dragonfly Doji":        {1, "neutral", trendAny, "蜻蜓十字星 - 待确认的犹豫信号", (*Detector).DragonflyDoji, gradeDragonflyDoji},
+	"Gravestone Doji":       {1, "neutral", trendAny, "墓碑十字星 - 待确认的犹豫信号", (*Detector).GravestoneDoji, gradeGravestoneDoji},
+	"Bullish Engulfing":     {2, "bullish", trendFalling, "看涨吞噬 - 强烈反转信号", (*Detector).BullishEngulfing, gradeEngulfing},
+	"Bearish Engulfing":     {2, "bearish", trendRising, "看跌吞噬 - 强烈反转信号", (*Detector).BearishEngulfing, gradeEngulfing},
+	"Piercing Line":         {2, "bullish", trendFalling, "刺透形态 - 看涨反转信号", (*Detector).PiercingLine, gradePenetration},
+	"Dark Cloud Cover":      {2, "bearish", trendRising, "乌云盖顶 - 看跌反转信号", (*Detector).DarkCloudCover, gradePenetration},
+	"Tweezer Bottoms":       {2, "bullish", trendFalling, "镊子底部 - 看涨反转信号", (*Detector).TweezerBottoms, gradeTweezerBottoms},
+	"Tweezer Tops":          {2, "bearish", trendRising, "镊子顶部 - 看跌反转信号", (*Detector).TweezerTops, gradeTweezerTops},
+	"Falling Window":        {2, "bearish", trendFalling, "下降窗口 - 下跌延续信号", (*Detector).FallingWindow, gradeWindow},
+	"Rising Window":         {2, "bullish", trendRising, "上升窗口 - 上涨延续信号", (*Detector).RisingWindow, gradeWindow},
+	"Bullish Harami":        {2, "neutral", trendAny, "看涨孕线 - 待确认的犹豫信号", (*Detector).BullishHarami, gradeHarami},
+	"Bearish Harami":        {2, "neutral", trendAny, "看跌孕线 - 待确认的犹豫信号", (*Detector).BearishHarami, gradeHarami},
+	"Morning Star":          {3, "bullish", trendFalling, "启明星 - 强烈看涨反转信号", (*Detector).MorningStar, gradeStar},
+	"Evening Star":          {3, "bearish", trendRising, "黄昏之星 - 强烈看跌反转信号", (*Detector).EveningStar, gradeStar},
+	"Morning Doji Star":     {3, "neutral", trendAny, "早晨十字星 - 待确认的犹豫信号", (*Detector).MorningDojiStar, gradeStar},
+	"Evening Doji Star":     {3, "neutral", trendAny, "黄昏十字星 - 待确认的犹豫信号", (*Detector).EveningDojiStar, gradeStar},
+	"Three White Soldiers":  {3, "bullish", trendFalling, "红三兵 - 强烈看涨信号", (*Detector).ThreeWhiteSoldiers, func(_ DetectorConfig, cs []CandlestickWrapper) float64 { return gradeThreeCandles(cs, true) }},
+	"Three Black Crows":     {3, "bearish", trendRising, "黑三鸦 - 强烈看跌信号", (*Detector).ThreeBlackCrows, func(_ DetectorConfig, cs []CandlestickWrapper) float64 { return gradeThreeCandles(cs, false) }},
+	"Rising Three Methods":  {5, "bullish", trendRising, "上升三法 - 上涨延续信号", (*Detector).RisingThreeMethods, gradeThreeMethods},
+	"Falling Three Methods": {5, "bearish", trendFalling, "下降三法 - 下跌延续信号", (*Detector).FallingThreeMethods, gradeThreeMethods},
+}
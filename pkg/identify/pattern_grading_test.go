@@ -0,0 +1,62 @@
+package identify
+
+import "testing"
+
+func TestScorePatternGradesQuality(t *testing.T) {
+	scorer := NewPatternScorer(DefaultPatternConfig())
+
+	// Both pass the boolean detector; the clean hammer has a much longer lower shadow.
+	// 两者均通过布尔识别；标准锤头线的下影线明显更长。
+	clean := []CandlestickWrapper{candle(100, 101, 90, 101)}
+	marginal := []CandlestickWrapper{candle(100, 101.05, 97.5, 101)}
+	if !Hammer(clean) || !Hammer(marginal) {
+		t.Fatal("fixtures should both be hammers")
+	}
+	cleanScore := scorer.ScorePattern("Hammer", clean)
+	marginalScore := scorer.ScorePattern("Hammer", marginal)
+	if !cleanScore.Detected || !marginalScore.Detected {
+		t.Fatal("ScorePattern should report detector result")
+	}
+	if cleanScore.Strength <= marginalScore.Strength {
+		t.Fatalf("clean hammer should outscore marginal one: %.3f <= %.3f", cleanScore.Strength, marginalScore.Strength)
+	}
+
+	// A hammer after a decline fits better than after an advance.
+	// 下跌后的锤头线比上涨后的更契合。
+	falling := append([]CandlestickWrapper{clean[0]}, reverseCandles(wrapCloses([]float64{110, 108, 106, 104, 102}))...)
+	rising := append([]CandlestickWrapper{clean[0]}, reverseCandles(wrapCloses([]float64{92, 94, 96, 98, 100}))...)
+	fall, _ := scorer.ScoreComponents("Hammer", falling)
+	rise, _ := scorer.ScoreComponents("Hammer", rising)
+	if fall.Trend <= rise.Trend {
+		t.Fatalf("expected downtrend context to score higher: %+v vs %+v", fall, rise)
+	}
+
+	// The legacy entry points agree with ScorePattern.
+	// 旧评分入口与 ScorePattern 结果一致。
+	if h := scorer.ScoreHammer(falling); h.Strength != scorer.ScorePattern("Hammer", falling).Strength || !h.Detected {
+		t.Fatalf("ScoreHammer should match ScorePattern: %+v", h)
+	}
+}
+
+func TestScorePatternCoversEveryPattern(t *testing.T) {
+	scorer := NewPatternScorer(DefaultPatternConfig())
+	cs := reverseCandles(wrapCloses([]float64{100, 102, 101, 104, 103, 106, 105, 108}))
+	for _, name := range ScoredPatterns() {
+		r := scorer.ScorePattern(name, cs)
+		if r.Pattern != name || r.Strength < 0 || r.Strength > 1 {
+			t.Fatalf("%s: unexpected result %+v", name, r)
+		}
+	}
+	if r := scorer.ScorePattern("No Such Pattern", cs); r.Detected || r.Strength != 0 {
+		t.Fatalf("unknown pattern should not score: %+v", r)
+	}
+}
+
+// reverseCandles turns chronological candles into the newest-first order detectors expect.
+func reverseCandles(cs []CandlestickWrapper) []CandlestickWrapper {
+	out := make([]CandlestickWrapper, len(cs))
+	for i, c := range cs {
+		out[len(cs)-1-i] = c
+	}
+	return out
+}
@@ -1,7 +1,6 @@
 package identify
 
 import (
-	"math"
 	"time"
 )
 
@@ -61,7 +60,8 @@ func DefaultPatternConfig() PatternConfig {
 
 // PatternScorer 形态评分器
 type PatternScorer struct {
-	config PatternConfig
+	config   PatternConfig
+	detector *Detector // 识别阈值，为空时使用默认值
 }
 
 // NewPatternScorer 创建新的形态评分器
@@ -71,340 +71,34 @@ func NewPatternScorer(config PatternConfig) *PatternScorer {
 	}
 }
 
-// ScoreHammer 锤头线评分
+// ScoreHammer is ScorePattern("Hammer", cs), kept for existing callers.
+// ScoreHammer 锤头线评分，等同于 ScorePattern("Hammer", cs)。
 func (ps *PatternScorer) ScoreHammer(cs []CandlestickWrapper) PatternResult {
-	if len(cs) < 1 {
-		return PatternResult{Pattern: "Hammer", Detected: false}
-	}
-	
-	c := cs[0]
-	body := c.Body()
-	lowerShadow := c.LowerShadow()
-	upperShadow := c.UpperShadow()
-	totalRange := c.High - c.Low
-	
-	if body == 0 || totalRange == 0 {
-		return PatternResult{Pattern: "Hammer", Detected: false}
-	}
-	
-	// 基础条件检查
-	bodyRatio := body / totalRange
-	lowerShadowRatio := lowerShadow / totalRange
-	upperShadowRatio := upperShadow / totalRange
-	
-	// 形状评分 (40%)
-	shapeScore := 0.0
-	if bodyRatio < ps.config.BodyRatioThreshold {
-		shapeScore += 0.4
-	}
-	if lowerShadowRatio > ps.config.ShadowRatioThreshold {
-		shapeScore += 0.4
-	}
-	if upperShadowRatio < 0.05 {
-		shapeScore += 0.2
-	}
-	
-	// 大小评分 (30%)
-	sizeScore := 0.0
-	if lowerShadow > 2*body {
-		sizeScore += 0.5
-	}
-	if lowerShadow > 3*body {
-		sizeScore += 0.3
-	}
-	if body > 0.01*totalRange {
-		sizeScore += 0.2
-	}
-	
-	// 成交量评分 (10%)
-	volumeScore := 0.0
-	if len(cs) > 1 {
-		avgVolume := calculateAverageVolume(cs[1:min(len(cs), 6)])
-		if c.Volume > avgVolume*ps.config.VolumeRatioThreshold {
-			volumeScore = 1.0
-		}
-	}
-	
-	// 趋势背景评分 (10%)
-	trendScore := 0.0
-	if ps.config.TrendContextRequired && len(cs) > 5 {
-		trend := analyzeTrendContext(cs[1:6])
-		if trend == "downtrend" {
-			trendScore = 1.0
-		}
-	}
-	
-	// 上下文评分 (10%)
-	contextScore := 0.0
-	if c.Close > c.Low && c.Close > c.Open {
-		contextScore = 1.0
-	}
-	
-	// 计算总分
-	totalScore := shapeScore*ps.config.ShapeWeight +
-		sizeScore*ps.config.SizeWeight +
-		volumeScore*ps.config.VolumeWeight +
-		trendScore*ps.config.TrendWeight +
-		contextScore*ps.config.ContextWeight
-	
-	detected := totalScore > 0.7
-	confidence := math.Min(totalScore, 1.0)
-	
-	return PatternResult{
-		Pattern:     "Hammer",
-		Detected:    detected,
-		Strength:    totalScore,
-		Confidence:  confidence,
-		Position:    0,
-		Direction:   "bullish",
-		Description: "锤头线 - 看涨反转信号",
-		Timestamp:   time.Now(),
-	}
+	return ps.ScorePattern("Hammer", cs)
 }
 
-// ScoreDoji 十字星评分
+// ScoreDoji is ScorePattern("Doji", cs), kept for existing callers.
+// ScoreDoji 十字星评分，等同于 ScorePattern("Doji", cs)。
 func (ps *PatternScorer) ScoreDoji(cs []CandlestickWrapper) PatternResult {
-	if len(cs) < 1 {
-		return PatternResult{Pattern: "Doji", Detected: false}
-	}
-	
-	c := cs[0]
-	body := c.Body()
-	upperShadow := c.UpperShadow()
-	lowerShadow := c.LowerShadow()
-	totalRange := c.High - c.Low
-	
-	if totalRange == 0 {
-		return PatternResult{Pattern: "Doji", Detected: false}
-	}
-	
-	// 形状评分 (50%)
-	shapeScore := 0.0
-	bodyRatio := body / totalRange
-	if bodyRatio < ps.config.BodyRatioThreshold {
-		shapeScore += 0.5
-	}
-	
-	// 影线评分 (30%)
-	shadowScore := 0.0
-	upperShadowRatio := upperShadow / totalRange
-	lowerShadowRatio := lowerShadow / totalRange
-	if upperShadowRatio > ps.config.ShadowRatioThreshold && lowerShadowRatio > ps.config.ShadowRatioThreshold {
-		shadowScore = 1.0
-	}
-	
-	// 平衡性评分 (20%)
-	balanceScore := 0.0
-	shadowBalance := math.Abs(upperShadow - lowerShadow) / totalRange
-	if shadowBalance < 0.1 {
-		balanceScore = 1.0
-	}
-	
-	totalScore := shapeScore*0.5 + shadowScore*0.3 + balanceScore*0.2
-	detected := totalScore > 0.7
-	confidence := math.Min(totalScore, 1.0)
-	
-	return PatternResult{
-		Pattern:     "Doji",
-		Detected:    detected,
-		Strength:    totalScore,
-		Confidence:  confidence,
-		Position:    0,
-		Direction:   "neutral",
-		Description: "十字星 - 市场犹豫信号",
-		Timestamp:   time.Now(),
-	}
+	return ps.ScorePattern("Doji", cs)
 }
 
-// ScoreMarubozu 光头光脚评分
+// ScoreMarubozu is ScorePattern("Marubozu", cs), kept for existing callers.
+// ScoreMarubozu 光头光脚评分，等同于 ScorePattern("Marubozu", cs)。
 func (ps *PatternScorer) ScoreMarubozu(cs []CandlestickWrapper) PatternResult {
-	if len(cs) < 1 {
-		return PatternResult{Pattern: "Marubozu", Detected: false}
-	}
-	
-	c := cs[0]
-	body := c.Body()
-	upperShadow := c.UpperShadow()
-	lowerShadow := c.LowerShadow()
-	totalRange := c.High - c.Low
-	
-	if totalRange == 0 {
-		return PatternResult{Pattern: "Marubozu", Detected: false}
-	}
-	
-	// 实体大小评分 (40%)
-	sizeScore := 0.0
-	bodyRatio := body / totalRange
-	if bodyRatio > 0.8 {
-		sizeScore = 1.0
-	} else if bodyRatio > 0.6 {
-		sizeScore = 0.7
-	}
-	
-	// 影线缺失评分 (40%)
-	shadowScore := 0.0
-	tolerance := 0.01 * body
-	if upperShadow <= tolerance && lowerShadow <= tolerance {
-		shadowScore = 1.0
-	} else if upperShadow <= tolerance*2 && lowerShadow <= tolerance*2 {
-		shadowScore = 0.7
-	}
-	
-	// 方向评分 (20%)
-	directionScore := 0.0
-	if c.IsBullish() {
-		directionScore = 1.0
-	} else if c.IsBearish() {
-		directionScore = 1.0
-	}
-	
-	totalScore := sizeScore*0.4 + shadowScore*0.4 + directionScore*0.2
-	detected := totalScore > 0.7
-	confidence := math.Min(totalScore, 1.0)
-	
-	direction := "neutral"
-	if c.IsBullish() {
-		direction = "bullish"
-	} else if c.IsBearish() {
-		direction = "bearish"
-	}
-	
-	return PatternResult{
-		Pattern:     "Marubozu",
-		Detected:    detected,
-		Strength:    totalScore,
-		Confidence:  confidence,
-		Position:    0,
-		Direction:   direction,
-		Description: "光头光脚 - 强烈趋势信号",
-		Timestamp:   time.Now(),
-	}
+	return ps.ScorePattern("Marubozu", cs)
 }
 
-// ScoreBullishEngulfing 看涨吞噬评分
+// ScoreBullishEngulfing is ScorePattern("Bullish Engulfing", cs), kept for existing callers.
+// ScoreBullishEngulfing 看涨吞噬评分，等同于 ScorePattern("Bullish Engulfing", cs)。
 func (ps *PatternScorer) ScoreBullishEngulfing(cs []CandlestickWrapper) PatternResult {
-	if len(cs) < 2 {
-		return PatternResult{Pattern: "BullishEngulfing", Detected: false}
-	}
-	
-	first := cs[1]
-	second := cs[0]
-	
-	// 基础条件检查
-	if !first.IsBearish() || !second.IsBullish() {
-		return PatternResult{Pattern: "BullishEngulfing", Detected: false}
-	}
-	
-	// 吞噬程度评分 (50%)
-	engulfingScore := 0.0
-	if second.Open <= first.Close && second.Close >= first.Open {
-		engulfingRatio := (second.Close - second.Open) / (first.Open - first.Close)
-		if engulfingRatio > 1.5 {
-			engulfingScore = 1.0
-		} else if engulfingRatio > 1.2 {
-			engulfingScore = 0.8
-		} else {
-			engulfingScore = 0.6
-		}
-	}
-	
-	// 实体大小评分 (30%)
-	sizeScore := 0.0
-	firstBodyRatio := first.Body() / (first.High - first.Low)
-	secondBodyRatio := second.Body() / (second.High - second.Low)
-	if firstBodyRatio > 0.6 && secondBodyRatio > 0.6 {
-		sizeScore = 1.0
-	} else if firstBodyRatio > 0.4 && secondBodyRatio > 0.4 {
-		sizeScore = 0.7
-	}
-	
-	// 成交量评分 (20%)
-	volumeScore := 0.0
-	if second.Volume > first.Volume*1.2 {
-		volumeScore = 1.0
-	} else if second.Volume > first.Volume {
-		volumeScore = 0.7
-	}
-	
-	totalScore := engulfingScore*0.5 + sizeScore*0.3 + volumeScore*0.2
-	detected := totalScore > 0.6
-	confidence := math.Min(totalScore, 1.0)
-	
-	return PatternResult{
-		Pattern:     "BullishEngulfing",
-		Detected:    detected,
-		Strength:    totalScore,
-		Confidence:  confidence,
-		Position:    0,
-		Direction:   "bullish",
-		Description: "看涨吞噬 - 强烈反转信号",
-		Timestamp:   time.Now(),
-	}
+	return ps.ScorePattern("Bullish Engulfing", cs)
 }
 
-// ScoreMorningStar 启明星评分
+// ScoreMorningStar is ScorePattern("Morning Star", cs), kept for existing callers.
+// ScoreMorningStar 启明星评分，等同于 ScorePattern("Morning Star", cs)。
 func (ps *PatternScorer) ScoreMorningStar(cs []CandlestickWrapper) PatternResult {
-	if len(cs) < 3 {
-		return PatternResult{Pattern: "MorningStar", Detected: false}
-	}
-	
-	first := cs[2]  // 长阴线
-	second := cs[1] // 小实体
-	third := cs[0]  // 长阳线
-	
-	// 第一根蜡烛评分 (30%)
-	firstScore := 0.0
-	if first.IsBearish() {
-		firstBodyRatio := first.Body() / (first.High - first.Low)
-		if firstBodyRatio > 0.6 {
-			firstScore = 1.0
-		} else if firstBodyRatio > 0.4 {
-			firstScore = 0.7
-		}
-	}
-	
-	// 第二根蜡烛评分 (20%)
-	secondScore := 0.0
-	secondBodyRatio := second.Body() / (second.High - second.Low)
-	if secondBodyRatio < 0.3 {
-		secondScore = 1.0
-	} else if secondBodyRatio < 0.5 {
-		secondScore = 0.7
-	}
-	
-	// 第三根蜡烛评分 (30%)
-	thirdScore := 0.0
-	if third.IsBullish() {
-		thirdBodyRatio := third.Body() / (third.High - third.Low)
-		if thirdBodyRatio > 0.6 {
-			thirdScore = 1.0
-		} else if thirdBodyRatio > 0.4 {
-			thirdScore = 0.7
-		}
-	}
-	
-	// 位置关系评分 (20%)
-	positionScore := 0.0
-	if second.High < first.Close && third.Close > (first.Open+first.Close)/2 {
-		positionScore = 1.0
-	} else if second.High < first.Close {
-		positionScore = 0.7
-	}
-	
-	totalScore := firstScore*0.3 + secondScore*0.2 + thirdScore*0.3 + positionScore*0.2
-	detected := totalScore > 0.6
-	confidence := math.Min(totalScore, 1.0)
-	
-	return PatternResult{
-		Pattern:     "MorningStar",
-		Detected:    detected,
-		Strength:    totalScore,
-		Confidence:  confidence,
-		Position:    0,
-		Direction:   "bullish",
-		Description: "启明星 - 强烈看涨反转信号",
-		Timestamp:   time.Now(),
-	}
+	return ps.ScorePattern("Morning Star", cs)
 }
 
 // 辅助函数
@@ -422,31 +116,6 @@ func calculateAverageVolume(candles []CandlestickWrapper) float64 {
 	return total / float64(len(candles))
 }
 
-// analyzeTrendContext 分析趋势背景
-func analyzeTrendContext(candles []CandlestickWrapper) string {
-	if len(candles) < 3 {
-		return "unknown"
-	}
-	
-	risingCount := 0
-	fallingCount := 0
-	
-	for i := 1; i < len(candles); i++ {
-		if candles[i].Close > candles[i-1].Close {
-			risingCount++
-		} else if candles[i].Close < candles[i-1].Close {
-			fallingCount++
-		}
-	}
-	
-	if risingCount > fallingCount*2 {
-		return "uptrend"
-	} else if fallingCount > risingCount*2 {
-		return "downtrend"
-	}
-	return "sideways"
-}
-
 // min 返回两个整数中的较小值
 func min(a, b int) int {
 	if a < b {
@@ -0,0 +1,203 @@
+package identify
+
+import (
+	talib "github.com/markcheno/go-talib"
+)
+
+// PivotMethod selects how swing points are detected.
+// PivotMethod 选择摆动高低点的识别方法。
+type PivotMethod string
+
+const (
+	PivotMethodFractal PivotMethod = "fractal" // N-bar fractal (N根K线分形)
+	PivotMethodZigZag  PivotMethod = "zigzag"  // Percentage ZigZag (百分比之字形)
+	PivotMethodATR     PivotMethod = "atr"     // ATR-based reversal (基于ATR的反转)
+)
+
+// PivotKind marks a swing point as a high or a low.
+// PivotKind 标记摆动点为高点或低点。
+type PivotKind string
+
+const (
+	PivotHigh PivotKind = "high"
+	PivotLow  PivotKind = "low"
+)
+
+// SwingPoint is a confirmed pivot high or low.
+// Position is the bar of the extreme, ConfirmedAt is the first bar at which the
+// pivot is known, so consumers working bar by bar never see it before that bar.
+// SwingPoint 是已确认的摆动高/低点。Position 是极值所在K线，ConfirmedAt 是首次可确认的K线，
+// 逐根回放时在该K线之前不会出现，避免重绘。
+type SwingPoint struct {
+	Kind        PivotKind `json:"kind"`
+	Position    int       `json:"position"`
+	ConfirmedAt int       `json:"confirmed_at"`
+	Price       float64   `json:"price"`
+}
+
+// PivotConfig controls swing point detection.
+// PivotConfig 控制摆动点识别参数。
+type PivotConfig struct {
+	Method PivotMethod `json:"method"`
+	// FractalBars is the number of bars required on each side of a fractal (default 2).
+	// FractalBars 是分形两侧所需的K线数量（默认 2）。
+	FractalBars int `json:"fractal_bars"`
+	// ZigZagPercent is the reversal size in percent of the last extreme (default 5).
+	// ZigZagPercent 是相对上一个极值的反转幅度百分比（默认 5）。
+	ZigZagPercent float64 `json:"zigzag_percent"`
+	// ATRPeriod and ATRMultiplier define the ATR reversal threshold (default 14 x 2.0).
+	// ATRPeriod 与 ATRMultiplier 定义 ATR 反转阈值（默认 14 x 2.0）。
+	ATRPeriod     int     `json:"atr_period"`
+	ATRMultiplier float64 `json:"atr_multiplier"`
+}
+
+// DefaultPivotConfig returns a percentage ZigZag setup suitable for daily bars.
+// DefaultPivotConfig 返回适合日线的百分比 ZigZag 默认配置。
+func DefaultPivotConfig() PivotConfig {
+	return PivotConfig{
+		Method:        PivotMethodZigZag,
+		FractalBars:   2,
+		ZigZagPercent: 5,
+		ATRPeriod:     14,
+		ATRMultiplier: 2,
+	}
+}
+
+// DetectPivots returns swing points ordered by confirmation bar.
+// DetectPivots 返回按确认K线排序的摆动点。
+func DetectPivots(cs []CandlestickWrapper, cfg PivotConfig) []SwingPoint {
+	if len(cs) == 0 {
+		return nil
+	}
+	switch cfg.Method {
+	case PivotMethodFractal:
+		bars := cfg.FractalBars
+		if bars < 1 {
+			bars = 2
+		}
+		return detectFractalPivots(cs, bars)
+	case PivotMethodATR:
+		period := cfg.ATRPeriod
+		if period < 1 {
+			period = 14
+		}
+		mult := cfg.ATRMultiplier
+		if mult <= 0 {
+			mult = 2
+		}
+		atr := computeATR(cs, period)
+		return detectZigZagPivots(cs, func(i int, _ float64) float64 {
+			return atr[i] * mult
+		})
+	default:
+		pct := cfg.ZigZagPercent
+		if pct <= 0 {
+			pct = 5
+		}
+		return detectZigZagPivots(cs, func(_ int, ref float64) float64 {
+			return ref * pct / 100
+		})
+	}
+}
+
+// detectFractalPivots marks bars whose high (low) is strictly above (below) the
+// N neighbours on both sides; the pivot is confirmed N bars later.
+func detectFractalPivots(cs []CandlestickWrapper, bars int) []SwingPoint {
+	out := make([]SwingPoint, 0)
+	for i := bars; i+bars < len(cs); i++ {
+		isHigh, isLow := true, true
+		for k := 1; k <= bars; k++ {
+			if cs[i].High <= cs[i-k].High || cs[i].High <= cs[i+k].High {
+				isHigh = false
+			}
+			if cs[i].Low >= cs[i-k].Low || cs[i].Low >= cs[i+k].Low {
+				isLow = false
+			}
+		}
+		if isHigh {
+			out = append(out, SwingPoint{Kind: PivotHigh, Position: i, ConfirmedAt: i + bars, Price: cs[i].High})
+		}
+		if isLow {
+			out = append(out, SwingPoint{Kind: PivotLow, Position: i, ConfirmedAt: i + bars, Price: cs[i].Low})
+		}
+	}
+	return out
+}
+
+// detectZigZagPivots runs a single-pass ZigZag. threshold receives the bar index
+// and the reference extreme price and returns the reversal distance; a zero
+// threshold (e.g. during ATR warm-up) suppresses reversals.
+func detectZigZagPivots(cs []CandlestickWrapper, threshold func(i int, ref float64) float64) []SwingPoint {
+	out := make([]SwingPoint, 0)
+	dir := 0 // 0: undecided, 1: tracking a high, -1: tracking a low
+	hiPos, loPos := 0, 0
+
+	for i := 1; i < len(cs); i++ {
+		switch dir {
+		case 0:
+			if cs[i].High > cs[hiPos].High {
+				hiPos = i
+			}
+			if cs[i].Low < cs[loPos].Low {
+				loPos = i
+			}
+			if th := threshold(i, cs[loPos].Low); th > 0 && loPos < i && cs[i].High-cs[loPos].Low >= th {
+				out = append(out, SwingPoint{Kind: PivotLow, Position: loPos, ConfirmedAt: i, Price: cs[loPos].Low})
+				dir, hiPos = 1, i
+			} else if th := threshold(i, cs[hiPos].High); th > 0 && hiPos < i && cs[hiPos].High-cs[i].Low >= th {
+				out = append(out, SwingPoint{Kind: PivotHigh, Position: hiPos, ConfirmedAt: i, Price: cs[hiPos].High})
+				dir, loPos = -1, i
+			}
+		case 1:
+			if cs[i].High > cs[hiPos].High {
+				hiPos = i
+				continue
+			}
+			if th := threshold(i, cs[hiPos].High); th > 0 && cs[hiPos].High-cs[i].Low >= th {
+				out = append(out, SwingPoint{Kind: PivotHigh, Position: hiPos, ConfirmedAt: i, Price: cs[hiPos].High})
+				dir, loPos = -1, i
+			}
+		case -1:
+			if cs[i].Low < cs[loPos].Low {
+				loPos = i
+				continue
+			}
+			if th := threshold(i, cs[loPos].Low); th > 0 && cs[i].High-cs[loPos].Low >= th {
+				out = append(out, SwingPoint{Kind: PivotLow, Position: loPos, ConfirmedAt: i, Price: cs[loPos].Low})
+				dir, hiPos = 1, i
+			}
+		}
+	}
+	return out
+}
+
+// PivotsConfirmedBy returns the swing points already confirmed at bar i.
+// PivotsConfirmedBy 返回截至第 i 根K线已确认的摆动点。
+func PivotsConfirmedBy(pivots []SwingPoint, i int) []SwingPoint {
+	out := make([]SwingPoint, 0, len(pivots))
+	for _, p := range pivots {
+		if p.ConfirmedAt <= i {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// computeATR returns talib ATR aligned by candle index, zero-filled during warm-up.
+func computeATR(cs []CandlestickWrapper, period int) []float64 {
+	n := len(cs)
+	if period < 1 || n <= period {
+		return make([]float64, n)
+	}
+	high := make([]float64, n)
+	low := make([]float64, n)
+	closep := make([]float64, n)
+	for i, c := range cs {
+		high[i] = c.High
+		low[i] = c.Low
+		closep[i] = c.Close
+	}
+	out := talib.Atr(high, low, closep, period)
+	normalizeIndicatorNaN(out)
+	return out
+}
@@ -0,0 +1,87 @@
+package identify
+
+import (
+	"testing"
+
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+)
+
+func wrapCloses(closes []float64) []CandlestickWrapper {
+	out := make([]CandlestickWrapper, 0, len(closes))
+	for i, c := range closes {
+		open := c
+		if i > 0 {
+			open = closes[i-1]
+		}
+		high, low := open, c
+		if c > open {
+			high, low = c, open
+		}
+		out = append(out, NewCandlestickWrapper(&v1.Candlestick{
+			Open: open, High: high + 0.2, Low: low - 0.2, Close: c, Volume: 1000,
+		}))
+	}
+	return out
+}
+
+func TestDetectPivotsZigZagAlternatesAndDoesNotRepaint(t *testing.T) {
+	closes := []float64{100, 102, 105, 109, 112, 108, 104, 100, 97, 99, 103, 108, 113, 118, 114, 109}
+	cs := wrapCloses(closes)
+	cfg := DefaultPivotConfig()
+
+	pivots := DetectPivots(cs, cfg)
+	if len(pivots) < 2 {
+		t.Fatalf("expected at least 2 pivots, got %+v", pivots)
+	}
+	for i := 1; i < len(pivots); i++ {
+		if pivots[i].Kind == pivots[i-1].Kind {
+			t.Fatalf("zigzag pivots should alternate: %+v", pivots)
+		}
+		if pivots[i].ConfirmedAt < pivots[i-1].ConfirmedAt {
+			t.Fatalf("pivots must be ordered by confirmation: %+v", pivots)
+		}
+	}
+	if pivots[1].Kind != PivotHigh || pivots[1].Position != 4 {
+		t.Fatalf("expected second pivot to be the high at bar 4, got %+v", pivots[1])
+	}
+
+	// Replaying bar by bar must never change a pivot once it is confirmed.
+	// 逐根回放时，已确认的摆动点不应改变。
+	for _, p := range pivots {
+		prefix := DetectPivots(cs[:p.ConfirmedAt+1], cfg)
+		found := false
+		for _, q := range prefix {
+			if q == p {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("pivot %+v not reproduced on prefix ending at %d: %+v", p, p.ConfirmedAt, prefix)
+		}
+	}
+}
+
+func TestDetectPivotsFractal(t *testing.T) {
+	closes := []float64{10, 11, 12, 11, 10, 9, 10, 11}
+	cs := make([]CandlestickWrapper, 0, len(closes))
+	for _, c := range closes {
+		cs = append(cs, NewCandlestickWrapper(&v1.Candlestick{Open: c, High: c + 0.5, Low: c - 0.5, Close: c}))
+	}
+	pivots := DetectPivots(cs, PivotConfig{Method: PivotMethodFractal, FractalBars: 2})
+
+	var high, low *SwingPoint
+	for i := range pivots {
+		switch pivots[i].Kind {
+		case PivotHigh:
+			high = &pivots[i]
+		case PivotLow:
+			low = &pivots[i]
+		}
+	}
+	if high == nil || high.Position != 2 || high.ConfirmedAt != 4 {
+		t.Fatalf("expected fractal high at bar 2 confirmed at 4, got %+v", pivots)
+	}
+	if low == nil || low.Position != 5 || low.ConfirmedAt != 7 {
+		t.Fatalf("expected fractal low at bar 5 confirmed at 7, got %+v", pivots)
+	}
+}
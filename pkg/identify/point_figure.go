@@ -0,0 +1,128 @@
+package identify
+
+import "math"
+
+// Point-and-figure signal types
+// 点数图信号类型
+const (
+	SignalPFDoubleTopBreakout     = "P&F Double Top Breakout"     // X column exceeds the previous X column (X 列突破前一 X 列高点)
+	SignalPFDoubleBottomBreakdown = "P&F Double Bottom Breakdown" // O column breaks the previous O column (O 列跌破前一 O 列低点)
+)
+
+// PointFigureConfig controls point-and-figure construction.
+// PointFigureConfig 控制点数图构建。
+type PointFigureConfig struct {
+	Box BoxConfig `json:"box"`
+	// Reversal is the number of boxes against the column that starts a new one (default 3).
+	// Reversal 为开启新列所需的反向格数（默认 3）。
+	Reversal int `json:"reversal"`
+}
+
+// DefaultPointFigureConfig returns ATR boxes with the classic three-box reversal.
+// DefaultPointFigureConfig 返回以 ATR 为格值、三格反转的经典配置。
+func DefaultPointFigureConfig() PointFigureConfig {
+	return PointFigureConfig{Box: DefaultBoxConfig(), Reversal: 3}
+}
+
+// PointFigureColumn is a column of Xs (rising) or Os (falling) spanning
+// Low..High in whole boxes.
+// PointFigureColumn 为一列 X（上涨）或 O（下跌），以整格覆盖 Low..High。
+type PointFigureColumn struct {
+	Direction     string  `json:"direction"` // X/O
+	Low           float64 `json:"low"`
+	High          float64 `json:"high"`
+	StartPosition int     `json:"start_position"`
+	EndPosition   int     `json:"end_position"`
+	// BreakPosition is the source bar whose close first took the column past
+	// the previous same-direction column, -1 if it never did.
+	// BreakPosition 为本列首次超越前一同向列的原始K线序号，未超越为 -1。
+	BreakPosition int `json:"break_position"`
+}
+
+// Boxes returns the number of boxes in the column.
+// Boxes 返回该列的格数。
+func (c PointFigureColumn) Boxes(box float64) int {
+	if box <= 0 {
+		return 0
+	}
+	return int(math.Round((c.High-c.Low)/box)) + 1
+}
+
+// PointFigure builds a close-only point-and-figure chart from chronological
+// cs, with box prices snapped to multiples of the box size, and returns it
+// with the box size used.
+// PointFigure 基于按时间排列的 cs 收盘价构建点数图，格价对齐到格值整数倍，并返回所用格值。
+func PointFigure(cs []CandlestickWrapper, cfg PointFigureConfig) ([]PointFigureColumn, float64) {
+	if cfg.Reversal < 1 {
+		cfg.Reversal = DefaultPointFigureConfig().Reversal
+	}
+	box := BoxSize(cs, cfg.Box)
+	out := make([]PointFigureColumn, 0)
+	if box <= 0 || len(cs) == 0 {
+		return out, box
+	}
+	floor := func(p float64) float64 { return math.Floor(p/box+1e-9) * box }
+	ceil := func(p float64) float64 { return math.Ceil(p/box-1e-9) * box }
+	rev := float64(cfg.Reversal) * box
+
+	ref := floor(cs[0].Close)
+	var cur *PointFigureColumn
+	// checkBreak records when the column first passes the previous column of its kind.
+	// checkBreak 记录本列首次超越前一同向列的位置。
+	checkBreak := func(i int) {
+		if cur.BreakPosition >= 0 || len(out) < 2 {
+			return
+		}
+		prev := out[len(out)-2]
+		if (cur.Direction == "X" && cur.High > prev.High) || (cur.Direction == "O" && cur.Low < prev.Low) {
+			cur.BreakPosition = i
+		}
+	}
+	for i := 1; i < len(cs); i++ {
+		c := cs[i].Close
+		switch {
+		case cur == nil:
+			switch {
+			case floor(c) >= ref+box:
+				out = append(out, PointFigureColumn{Direction: "X", Low: ref + box, High: floor(c), StartPosition: i, EndPosition: i, BreakPosition: -1})
+			case ceil(c) <= ref-box:
+				out = append(out, PointFigureColumn{Direction: "O", Low: ceil(c), High: ref - box, StartPosition: i, EndPosition: i, BreakPosition: -1})
+			default:
+				continue
+			}
+			cur = &out[len(out)-1]
+		case cur.Direction == "X" && floor(c) > cur.High:
+			cur.High, cur.EndPosition = floor(c), i
+			checkBreak(i)
+		case cur.Direction == "O" && ceil(c) < cur.Low:
+			cur.Low, cur.EndPosition = ceil(c), i
+			checkBreak(i)
+		case cur.Direction == "X" && c <= cur.High-rev:
+			out = append(out, PointFigureColumn{Direction: "O", Low: ceil(c), High: cur.High - box, StartPosition: i, EndPosition: i, BreakPosition: -1})
+			cur = &out[len(out)-1]
+			checkBreak(i)
+		case cur.Direction == "O" && c >= cur.Low+rev:
+			out = append(out, PointFigureColumn{Direction: "X", Low: cur.Low + box, High: floor(c), StartPosition: i, EndPosition: i, BreakPosition: -1})
+			cur = &out[len(out)-1]
+			checkBreak(i)
+		}
+	}
+	return out, box
+}
+
+// PointFigureSignals marks double-top breakouts and double-bottom breakdowns.
+// PointFigureSignals 标记双顶突破与双底跌破。
+func PointFigureSignals(cs []CandlestickWrapper, columns []PointFigureColumn) []PatternSignal {
+	out := make([]PatternSignal, 0)
+	for _, col := range columns {
+		if col.BreakPosition < 0 || col.BreakPosition >= len(cs) {
+			continue
+		}
+		if col.Direction == "X" {
+			out = append(out, priceChartSignal(cs, col.BreakPosition, SignalPFDoubleTopBreakout, "bullish", col.High))
+		} else {
+			out = append(out, priceChartSignal(cs, col.BreakPosition, SignalPFDoubleBottomBreakdown, "bearish", col.Low))
+		}
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+package identify
+
+import (
+	"time"
+)
+
+// BoxConfig sizes the box (Renko brick, P&F box, Kagi reversal) either as a
+// fixed price step or as a multiple of the latest ATR.
+// BoxConfig 以固定价差或最新 ATR 的倍数确定格值（砖块、点数图格子、Kagi 反转幅度）。
+type BoxConfig struct {
+	// Size is a fixed box size; 0 derives it from the ATR.
+	// Size 为固定格值；为 0 时由 ATR 推导。
+	Size        float64 `json:"size"`
+	ATRPeriod   int     `json:"atr_period"`
+	ATRMultiple float64 `json:"atr_multiple"`
+}
+
+// DefaultBoxConfig returns an ATR(14)-sized box.
+// DefaultBoxConfig 返回以 ATR(14) 为格值的默认配置。
+func DefaultBoxConfig() BoxConfig {
+	return BoxConfig{ATRPeriod: 14, ATRMultiple: 1}
+}
+
+// BoxSize resolves cfg against cs: the fixed Size, else ATRMultiple times the
+// last ATR, else (too few bars for the ATR) the mean high-low range. It is 0
+// only for empty or flat series.
+// BoxSize 依据 cs 解析格值：优先固定 Size，否则为 ATRMultiple 倍的最新 ATR；K线不足以计算 ATR 时取平均振幅。仅空序列或无波动时为 0。
+func BoxSize(cs []CandlestickWrapper, cfg BoxConfig) float64 {
+	if cfg.Size > 0 {
+		return cfg.Size
+	}
+	def := DefaultBoxConfig()
+	if cfg.ATRPeriod < 1 {
+		cfg.ATRPeriod = def.ATRPeriod
+	}
+	if cfg.ATRMultiple <= 0 {
+		cfg.ATRMultiple = def.ATRMultiple
+	}
+	if atr := computeATR(cs, cfg.ATRPeriod); len(atr) > 0 && atr[len(atr)-1] > 0 {
+		return atr[len(atr)-1] * cfg.ATRMultiple
+	}
+	if len(cs) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, c := range cs {
+		sum += c.High - c.Low
+	}
+	return sum / float64(len(cs)) * cfg.ATRMultiple
+}
+
+// priceChartSignal builds a signal at source bar i of cs.
+func priceChartSignal(cs []CandlestickWrapper, i int, typ, direction string, price float64) PatternSignal {
+	return PatternSignal{
+		Type:      typ,
+		Direction: direction,
+		Position:  i,
+		Strength:  0.6,
+		Risk:      0.4,
+		Price:     price,
+		Time:      time.Unix(cs[i].Timestamp, 0).Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,138 @@
+package identify
+
+import (
+	"testing"
+
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+)
+
+// closeSeries builds daily bars with the given closes and a 1-point range.
+func closeSeries(closes ...float64) []CandlestickWrapper {
+	cs := make([]CandlestickWrapper, len(closes))
+	for i, c := range closes {
+		cs[i] = NewCandlestickWrapper(&v1.Candlestick{
+			Timestamp: int64(i) * 86400,
+			Open:      c, High: c + 0.5, Low: c - 0.5, Close: c, Volume: 1000,
+		})
+	}
+	return cs
+}
+
+func TestBoxSize(t *testing.T) {
+	cs := closeSeries(10, 11, 12)
+	if got := BoxSize(cs, BoxConfig{Size: 2.5}); got != 2.5 {
+		t.Fatalf("fixed box: got %v", got)
+	}
+	// Too few bars for ATR(14): mean high-low range.
+	// 不足以计算 ATR(14)：取平均振幅。
+	if got := BoxSize(cs, BoxConfig{ATRMultiple: 2}); got != 2 {
+		t.Fatalf("range fallback: got %v", got)
+	}
+	long := make([]float64, 30)
+	for i := range long {
+		long[i] = 100
+	}
+	if got := BoxSize(closeSeries(long...), DefaultBoxConfig()); got != 1 {
+		t.Fatalf("ATR box: got %v", got)
+	}
+}
+
+func TestRenko(t *testing.T) {
+	cs := closeSeries(100, 101.2, 103.5, 102.5, 101.9, 100.9, 99.5, 102)
+	bricks, box := Renko(cs, RenkoConfig{Box: BoxConfig{Size: 1}})
+	if box != 1 {
+		t.Fatalf("box = %v", box)
+	}
+	// Up to 103; pullbacks to 102.5 and 101.9 are not enough; 100.9 (two
+	// boxes below 103) reverses from 102, 99.5 extends; 102 reverses up.
+	// 上涨至 103；回落至 102.5、101.9 不足以反转；100.9（低于 103 两格）自 102 起反转，99.5 延续；102 向上反转。
+	want := []struct {
+		open, close float64
+		pos         int
+	}{{100, 101, 1}, {101, 102, 2}, {102, 103, 2}, {102, 101, 5}, {101, 100, 6}, {101, 102, 7}}
+	if len(bricks) != len(want) {
+		t.Fatalf("got %d bricks: %+v", len(bricks), bricks)
+	}
+	for k, w := range want {
+		if bricks[k].Open != w.open || bricks[k].Close != w.close || bricks[k].Position != w.pos {
+			t.Fatalf("brick %d = %+v, want %+v", k, bricks[k], w)
+		}
+	}
+	sigs := RenkoSignals(cs, bricks)
+	if len(sigs) != 2 || sigs[0].Type != SignalRenkoReversalDown || sigs[0].Position != 5 ||
+		sigs[1].Type != SignalRenkoReversalUp || sigs[1].Position != 7 {
+		t.Fatalf("unexpected signals: %+v", sigs)
+	}
+}
+
+func TestLineBreak(t *testing.T) {
+	cs := closeSeries(10, 11, 12, 13, 12.5, 10.5, 9.5, 11, 13.5)
+	lines := LineBreak(cs, 3)
+	// 12.5 and 10.5 stay inside the last three lines (10-13); 9.5 breaks
+	// below them; 11 is inside; 13.5 breaks above the last three (9.5-13).
+	// 12.5 与 10.5 仍在最近三根线区间（10-13）内；9.5 向下突破；11 在区间内；13.5 向上突破最近三根线（9.5-13）。
+	if len(lines) != 5 {
+		t.Fatalf("got %d lines: %+v", len(lines), lines)
+	}
+	down := lines[3]
+	if !down.Reversal || down.Direction != "down" || down.Open != 12 || down.Close != 9.5 || down.Position != 6 {
+		t.Fatalf("unexpected down reversal: %+v", down)
+	}
+	up := lines[4]
+	if !up.Reversal || up.Direction != "up" || up.Position != 8 {
+		t.Fatalf("unexpected up reversal: %+v", up)
+	}
+	sigs := LineBreakSignals(cs, lines)
+	if len(sigs) != 2 || sigs[0].Direction != "bearish" || sigs[1].Type != SignalLineBreakReversalUp {
+		t.Fatalf("unexpected signals: %+v", sigs)
+	}
+}
+
+func TestKagi(t *testing.T) {
+	cs := closeSeries(100, 104, 101, 103, 99, 102, 105, 97)
+	segs, r := Kagi(cs, KagiConfig{Reversal: BoxConfig{Size: 2}})
+	if r != 2 {
+		t.Fatalf("reversal = %v", r)
+	}
+	// up 100-104 (yang), down 104-101, up 101-103, down 103-99 (below the
+	// 101 waist: yin), up 99-105 (above the 104 shoulder: yang), down 105-97.
+	// 上 100-104（阳），下 104-101，上 101-103，下 103-99（跌破 101 腰部转阴），上 99-105（升破 104 肩部转阳），下 105-97。
+	if len(segs) != 6 {
+		t.Fatalf("got %d segments: %+v", len(segs), segs)
+	}
+	if !segs[0].Yang || segs[3].Yang || segs[3].TurnPosition != 4 || !segs[4].Yang || segs[4].TurnPosition != 6 {
+		t.Fatalf("unexpected thickness: %+v", segs)
+	}
+	if segs[5].Yang || segs[5].TurnPosition != 7 {
+		t.Fatalf("105-97 should break the 99 waist: %+v", segs[5])
+	}
+	sigs := KagiSignals(cs, segs)
+	if len(sigs) != 3 || sigs[0].Type != SignalKagiYin || sigs[1].Type != SignalKagiYang || sigs[1].Position != 6 {
+		t.Fatalf("unexpected signals: %+v", sigs)
+	}
+}
+
+func TestPointFigure(t *testing.T) {
+	cs := closeSeries(100, 103.2, 105.4, 102.1, 101.5, 104.2, 106.3, 100.8)
+	cols, box := PointFigure(cs, PointFigureConfig{Box: BoxConfig{Size: 1}, Reversal: 3})
+	if box != 1 {
+		t.Fatalf("box = %v", box)
+	}
+	// X 101-105, O 102-104 (reversal at 102.1 fills 104..103..102; 101.5
+	// rounds up to 102), X 103-106 breaks above 105, O 101-105.
+	// X 101-105；O 102-104（102.1 反转；101.5 向上取整为 102）；X 103-106 突破 105；O 101-105。
+	if len(cols) != 4 {
+		t.Fatalf("got %d columns: %+v", len(cols), cols)
+	}
+	x2 := cols[2]
+	if x2.Direction != "X" || x2.Low != 103 || x2.High != 106 || x2.BreakPosition != 6 || x2.Boxes(box) != 4 {
+		t.Fatalf("unexpected third column: %+v", x2)
+	}
+	if cols[3].Direction != "O" || cols[3].Low != 101 || cols[3].BreakPosition != 7 {
+		t.Fatalf("unexpected last column: %+v", cols[3])
+	}
+	sigs := PointFigureSignals(cs, cols)
+	if len(sigs) != 2 || sigs[0].Type != SignalPFDoubleTopBreakout || sigs[1].Type != SignalPFDoubleBottomBreakdown {
+		t.Fatalf("unexpected signals: %+v", sigs)
+	}
+}
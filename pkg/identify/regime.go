@@ -0,0 +1,255 @@
+package identify
+
+import (
+	"math"
+
+	talib "github.com/markcheno/go-talib"
+	"gonum.org/v1/gonum/stat"
+)
+
+// Market regimes used to switch scoring between strategies.
+// 用于切换评分策略的市场状态。
+const (
+	RegimeTrend          = "trend"           // Directional market, ADX high (趋势市)
+	RegimeRange          = "range"           // Range-bound market, ADX low (震荡市)
+	RegimeHighVolatility = "high_volatility" // Realized volatility in its upper percentile (高波动)
+)
+
+// Regimes returns the regime names in a fixed order.
+// Regimes 按固定顺序返回全部市场状态名称。
+func Regimes() []string {
+	return []string{RegimeTrend, RegimeRange, RegimeHighVolatility}
+}
+
+// RegimeConfig controls the regime classifier.
+// RegimeConfig 控制市场状态分类器。
+type RegimeConfig struct {
+	ADXPeriod int `json:"adx_period"`
+	// TrendADX and RangeADX bound the transition from range to trend.
+	// TrendADX 与 RangeADX 为震荡到趋势的过渡区间。
+	TrendADX float64 `json:"trend_adx"`
+	RangeADX float64 `json:"range_adx"`
+	// VolatilityWindow is the number of log returns per realized volatility value.
+	// VolatilityWindow 为计算一个已实现波动率所用的对数收益数量。
+	VolatilityWindow int `json:"volatility_window"`
+	// VolatilityLookback is the history ranked for the volatility percentile and fed to the HMM.
+	// VolatilityLookback 为计算波动率分位及 HMM 拟合所用的历史长度。
+	VolatilityLookback int     `json:"volatility_lookback"`
+	HighVolPercentile  float64 `json:"high_volatility_percentile"`
+	// HMM blends in a Gaussian hidden Markov model fitted on log returns.
+	// HMM 为 true 时混合基于对数收益拟合的高斯隐马尔可夫模型。
+	HMM           bool `json:"hmm"`
+	HMMStates     int  `json:"hmm_states"` // 2 or 3
+	HMMIterations int  `json:"hmm_iterations"`
+}
+
+// DefaultRegimeConfig returns ADX14 with 20/25 bounds and a 20-bar volatility
+// ranked over 120 bars; the HMM is off.
+// DefaultRegimeConfig 返回 ADX14（20/25 阈值）与 20 根K线波动率在 120 根内的分位；默认关闭 HMM。
+func DefaultRegimeConfig() RegimeConfig {
+	return RegimeConfig{
+		ADXPeriod:          14,
+		TrendADX:           25,
+		RangeADX:           20,
+		VolatilityWindow:   20,
+		VolatilityLookback: 120,
+		HighVolPercentile:  0.8,
+		HMMStates:          3,
+		HMMIterations:      50,
+	}
+}
+
+func normalizeRegimeConfig(cfg RegimeConfig) RegimeConfig {
+	def := DefaultRegimeConfig()
+	if cfg.ADXPeriod < 2 {
+		cfg.ADXPeriod = def.ADXPeriod
+	}
+	if cfg.TrendADX <= 0 {
+		cfg.TrendADX = def.TrendADX
+	}
+	if cfg.RangeADX <= 0 || cfg.RangeADX >= cfg.TrendADX {
+		cfg.RangeADX = cfg.TrendADX * def.RangeADX / def.TrendADX
+	}
+	if cfg.VolatilityWindow < 2 {
+		cfg.VolatilityWindow = def.VolatilityWindow
+	}
+	if cfg.VolatilityLookback < cfg.VolatilityWindow {
+		cfg.VolatilityLookback = def.VolatilityLookback
+	}
+	if cfg.HighVolPercentile <= 0 || cfg.HighVolPercentile >= 1 {
+		cfg.HighVolPercentile = def.HighVolPercentile
+	}
+	if cfg.HMMStates != 2 && cfg.HMMStates != 3 {
+		cfg.HMMStates = def.HMMStates
+	}
+	if cfg.HMMIterations < 1 {
+		cfg.HMMIterations = def.HMMIterations
+	}
+	return cfg
+}
+
+// RegimeResult is the regime of the last bar.
+// RegimeResult 为最后一根K线所处的市场状态。
+type RegimeResult struct {
+	Regime      string  `json:"regime"`
+	Probability float64 `json:"probability"`
+	// Probabilities holds every regime's probability; they sum to 1.
+	// Probabilities 为各状态的概率，总和为 1。
+	Probabilities        map[string]float64 `json:"probabilities"`
+	ADX                  float64            `json:"adx"`
+	Volatility           float64            `json:"volatility"`            // Std of log returns over VolatilityWindow (对数收益标准差)
+	VolatilityPercentile float64            `json:"volatility_percentile"` // Mid-rank of Volatility within the lookback (波动率分位)
+	HMM                  bool               `json:"hmm"`                   // The HMM was blended in (是否混合 HMM)
+}
+
+// ClassifyRegime classifies the last bar of cs (chronological) as trend,
+// range or high volatility. Rule probabilities come from ADX and the
+// volatility percentile; with cfg.HMM the posterior of a Gaussian HMM over
+// log returns is averaged in. ok is false when cs is too short.
+// ClassifyRegime 将 cs（时间正序）最后一根K线归类为趋势、震荡或高波动。规则概率来自 ADX 与波动率分位；
+// 启用 HMM 时与对数收益高斯 HMM 的后验概率取平均。数据不足时 ok 为 false。
+func ClassifyRegime(cs []CandlestickWrapper, cfg RegimeConfig) (RegimeResult, bool) {
+	cfg = normalizeRegimeConfig(cfg)
+	n := len(cs)
+	if n < regimeMinBars(cfg) {
+		return RegimeResult{}, false
+	}
+	high, low, closep := regimeInputs(cs)
+	returns := logReturns(closep)
+	vols := rollingVolatility(returns, cfg.VolatilityWindow, cfg.VolatilityLookback)
+	return classifyRegime(talib.Adx(high, low, closep, cfg.ADXPeriod)[n-1], returns, vols, cfg), true
+}
+
+// RegimeSeries classifies every bar of cs (chronological) from the bars up to
+// and including it, so that out[i] equals ClassifyRegime(cs[:i+1], cfg). ADX
+// and volatility are computed once for the whole series; out[i] is nil while
+// there are too few bars.
+// RegimeSeries 按截至每根K线（含）的数据逐根判定市场状态，out[i] 等同于 ClassifyRegime(cs[:i+1], cfg)。
+// ADX 与波动率对整段序列只计算一次；数据不足的K线为 nil。
+func RegimeSeries(cs []CandlestickWrapper, cfg RegimeConfig) []*RegimeResult {
+	cfg = normalizeRegimeConfig(cfg)
+	n := len(cs)
+	out := make([]*RegimeResult, n)
+	if n < regimeMinBars(cfg) {
+		return out
+	}
+	high, low, closep := regimeInputs(cs)
+	returns := logReturns(closep)
+	adx := talib.Adx(high, low, closep, cfg.ADXPeriod)
+	// stds[j] is the window std of returns ending at return j.
+	// stds[j] 为截至第 j 个收益的窗口标准差。
+	stds := make([]float64, len(returns))
+	for j := cfg.VolatilityWindow - 1; j < len(returns); j++ {
+		stds[j] = stat.StdDev(returns[j-cfg.VolatilityWindow+1:j+1], nil)
+	}
+	for i := regimeMinBars(cfg) - 1; i < n; i++ {
+		// Bar i sees returns[:i]; slice the same window rollingVolatility would.
+		// 第 i 根K线可见 returns[:i]，与 rollingVolatility 取相同的窗口。
+		start := i - cfg.VolatilityLookback
+		if start < cfg.VolatilityWindow-1 {
+			start = cfg.VolatilityWindow - 1
+		}
+		r := classifyRegime(adx[i], returns[:i], stds[start:i], cfg)
+		out[i] = &r
+	}
+	return out
+}
+
+// regimeMinBars is the shortest input ClassifyRegime accepts.
+func regimeMinBars(cfg RegimeConfig) int {
+	if cfg.VolatilityWindow+2 > 2*cfg.ADXPeriod+1 {
+		return cfg.VolatilityWindow + 2
+	}
+	return 2*cfg.ADXPeriod + 1
+}
+
+func regimeInputs(cs []CandlestickWrapper) (high, low, closep []float64) {
+	high = make([]float64, len(cs))
+	low = make([]float64, len(cs))
+	closep = make([]float64, len(cs))
+	for i, c := range cs {
+		high[i], low[i], closep[i] = c.High, c.Low, c.Close
+	}
+	return high, low, closep
+}
+
+// classifyRegime scores the last bar from its ADX, the returns up to it and
+// the rolling volatilities of the lookback, the last one being current.
+func classifyRegime(adx float64, returns, vols []float64, cfg RegimeConfig) RegimeResult {
+	result := RegimeResult{ADX: adx}
+	result.Volatility = vols[len(vols)-1]
+	// Mid-rank so that a flat volatility history sits at 0.5.
+	// 使用中位秩，波动率不变时分位为 0.5。
+	rank := 0.0
+	for _, v := range vols {
+		switch {
+		case v < result.Volatility:
+			rank++
+		case v == result.Volatility:
+			rank += 0.5
+		}
+	}
+	result.VolatilityPercentile = rank / float64(len(vols))
+
+	// Soft thresholds: 0.5 at the bound midpoints.
+	// 软阈值：在阈值中点处概率为 0.5。
+	highVol := logistic((result.VolatilityPercentile - cfg.HighVolPercentile) / 0.05)
+	trend := logistic((result.ADX - (cfg.TrendADX+cfg.RangeADX)/2) / ((cfg.TrendADX - cfg.RangeADX) / 4))
+	probs := map[string]float64{
+		RegimeHighVolatility: highVol,
+		RegimeTrend:          (1 - highVol) * trend,
+		RegimeRange:          (1 - highVol) * (1 - trend),
+	}
+
+	if cfg.HMM {
+		start := len(returns) - cfg.VolatilityLookback
+		if start < 0 {
+			start = 0
+		}
+		if hmm, ok := hmmRegimeProbabilities(returns[start:], cfg.HMMStates, cfg.HMMIterations, trend); ok {
+			for _, r := range Regimes() {
+				probs[r] = (probs[r] + hmm[r]) / 2
+			}
+			result.HMM = true
+		}
+	}
+
+	result.Probabilities = probs
+	for _, r := range Regimes() {
+		if probs[r] > result.Probability {
+			result.Regime, result.Probability = r, probs[r]
+		}
+	}
+	return result
+}
+
+// logReturns returns log(close[i]/close[i-1]); non-positive prices give 0.
+func logReturns(closep []float64) []float64 {
+	out := make([]float64, 0, len(closep)-1)
+	for i := 1; i < len(closep); i++ {
+		r := 0.0
+		if closep[i] > 0 && closep[i-1] > 0 {
+			r = math.Log(closep[i] / closep[i-1])
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// rollingVolatility returns the window std of returns ending at each of the
+// last lookback returns that have a full window; the last value is current.
+func rollingVolatility(returns []float64, window, lookback int) []float64 {
+	start := len(returns) - lookback
+	if start < window-1 {
+		start = window - 1
+	}
+	out := make([]float64, 0, len(returns)-start)
+	for i := start; i < len(returns); i++ {
+		out = append(out, stat.StdDev(returns[i-window+1:i+1], nil))
+	}
+	return out
+}
+
+func logistic(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
@@ -0,0 +1,103 @@
+package identify
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// regimeCandles builds closes from per-bar returns with a fixed high/low spread.
+func regimeCandles(returns []float64) []CandlestickWrapper {
+	cs := make([]CandlestickWrapper, len(returns))
+	price := 100.0
+	for i, r := range returns {
+		open := price
+		price *= math.Exp(r)
+		spread := math.Max(math.Abs(r), 0.002) * price
+		cs[i] = candle(open, math.Max(open, price)+spread/2, math.Min(open, price)-spread/2, price)
+	}
+	return cs
+}
+
+func TestClassifyRegime(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	n := 200
+	trend := make([]float64, n)
+	rangeBound := make([]float64, n)
+	volatile := make([]float64, n)
+	for i := 0; i < n; i++ {
+		noise := rng.NormFloat64() * 0.003
+		trend[i] = 0.01 + noise
+		rangeBound[i] = 0.01*math.Sin(float64(i)*math.Pi/3) + noise/10
+		volatile[i] = noise
+		if i >= n-15 {
+			volatile[i] = rng.NormFloat64() * 0.05
+		}
+	}
+
+	cases := []struct {
+		name    string
+		returns []float64
+		want    string
+	}{
+		{"trend", trend, RegimeTrend},
+		{"range", rangeBound, RegimeRange},
+		{"high volatility", volatile, RegimeHighVolatility},
+	}
+	for _, hmm := range []bool{false, true} {
+		cfg := DefaultRegimeConfig()
+		cfg.HMM = hmm
+		for _, tc := range cases {
+			got, ok := ClassifyRegime(regimeCandles(tc.returns), cfg)
+			if !ok {
+				t.Fatalf("%s (hmm=%v): expected a result", tc.name, hmm)
+			}
+			if got.Regime != tc.want {
+				t.Errorf("%s (hmm=%v): regime = %s, want %s (%+v)", tc.name, hmm, got.Regime, tc.want, got)
+			}
+			if got.HMM != hmm {
+				t.Errorf("%s: HMM = %v, want %v", tc.name, got.HMM, hmm)
+			}
+			total := 0.0
+			for _, p := range got.Probabilities {
+				total += p
+			}
+			if math.Abs(total-1) > 1e-9 || got.Probability != got.Probabilities[got.Regime] {
+				t.Errorf("%s (hmm=%v): inconsistent probabilities %+v", tc.name, hmm, got.Probabilities)
+			}
+		}
+	}
+}
+
+func TestClassifyRegimeShortInput(t *testing.T) {
+	if _, ok := ClassifyRegime(regimeCandles(make([]float64, 20)), DefaultRegimeConfig()); ok {
+		t.Fatalf("expected no result for 20 bars")
+	}
+}
+
+func TestRegimeSeriesMatchesClassifyRegime(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	returns := make([]float64, 180)
+	for i := range returns {
+		returns[i] = rng.NormFloat64() * 0.01 * (1 + float64(i/60))
+	}
+	cs := regimeCandles(returns)
+	for _, hmm := range []bool{false, true} {
+		cfg := DefaultRegimeConfig()
+		cfg.HMM = hmm
+		series := RegimeSeries(cs, cfg)
+		if len(series) != len(cs) {
+			t.Fatalf("series has %d bars, want %d", len(series), len(cs))
+		}
+		for i := range cs {
+			want, ok := ClassifyRegime(cs[:i+1], cfg)
+			switch {
+			case !ok && series[i] != nil:
+				t.Fatalf("bar %d (hmm=%v): expected no result, got %+v", i, hmm, series[i])
+			case ok && (series[i] == nil || !reflect.DeepEqual(*series[i], want)):
+				t.Fatalf("bar %d (hmm=%v): series %+v, want %+v", i, hmm, series[i], want)
+			}
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package identify
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// RelativeStrengthKey is the Indicators key of the RS line; evidence adds the
+// "outperforming benchmark" factor when it is present.
+// RelativeStrengthKey 为 RS 线在 Indicators 中的键；存在时证据引擎加入“跑赢基准”因子。
+const RelativeStrengthKey = "RS"
+
+// RelativeStrengthConfig controls benchmark-relative measures.
+// RelativeStrengthConfig 控制相对基准的强弱度量。
+type RelativeStrengthConfig struct {
+	// Lookback is the bar count over which outperformance is measured (default 20).
+	// Lookback 为衡量跑赢/跑输基准的K线数量（默认 20）。
+	Lookback int `json:"lookback"`
+	// BetaWindow is the number of daily returns used for beta (default 60).
+	// BetaWindow 为计算 beta 所用的收益数量（默认 60）。
+	BetaWindow int `json:"beta_window"`
+}
+
+// DefaultRelativeStrengthConfig returns a 20-bar lookback and a 60-return beta.
+// DefaultRelativeStrengthConfig 返回 20 根K线回望与 60 个收益的 beta 窗口。
+func DefaultRelativeStrengthConfig() RelativeStrengthConfig {
+	return RelativeStrengthConfig{Lookback: 20, BetaWindow: 60}
+}
+
+func normalizeRelativeStrengthConfig(cfg RelativeStrengthConfig) RelativeStrengthConfig {
+	def := DefaultRelativeStrengthConfig()
+	if cfg.Lookback < 1 {
+		cfg.Lookback = def.Lookback
+	}
+	if cfg.BetaWindow < 2 {
+		cfg.BetaWindow = def.BetaWindow
+	}
+	return cfg
+}
+
+// AlignBenchmark returns the benchmark close for each bar of cs, matched by
+// calendar date (UTC) and carried forward over benchmark gaps; bars before
+// the first benchmark bar are NaN. Both series are chronological.
+// AlignBenchmark 按自然日（UTC）为 cs 每根K线匹配基准收盘价，基准缺失日沿用前值；首个基准K线之前为 NaN。两序列均为时间正序。
+func AlignBenchmark(cs, benchmark []CandlestickWrapper) []float64 {
+	day := func(ts int64) string { return time.Unix(ts, 0).UTC().Format("2006-01-02") }
+	out := make([]float64, len(cs))
+	j := 0
+	last := math.NaN()
+	for i, c := range cs {
+		d := day(c.Timestamp)
+		for j < len(benchmark) && day(benchmark[j].Timestamp) <= d {
+			last = benchmark[j].Close
+			j++
+		}
+		out[i] = last
+	}
+	return out
+}
+
+// RelativeStrengthLine returns close/benchmark scaled to 1 at the first bar
+// with both prices; earlier bars are NaN. A rising line outperforms.
+// RelativeStrengthLine 返回 收盘价/基准价，并在首个两者均有效的K线处归一为 1；之前为 NaN。上升表示跑赢基准。
+func RelativeStrengthLine(cs []CandlestickWrapper, benchClose []float64) []float64 {
+	out := make([]float64, len(cs))
+	base := math.NaN()
+	for i, c := range cs {
+		out[i] = math.NaN()
+		if i >= len(benchClose) || !(benchClose[i] > 0) || c.Close <= 0 {
+			continue
+		}
+		ratio := c.Close / benchClose[i]
+		if math.IsNaN(base) {
+			base = ratio
+		}
+		out[i] = ratio / base
+	}
+	return out
+}
+
+// Beta regresses the last window bar returns of cs on the benchmark's:
+// cov(r, r_b) / var(r_b). ok is false with fewer than two paired returns.
+// Beta 以最近 window 个收益对基准收益回归：cov(r, r_b) / var(r_b)。成对收益少于两个时 ok 为 false。
+func Beta(cs []CandlestickWrapper, benchClose []float64, window int) (float64, bool) {
+	r := make([]float64, 0, window)
+	rb := make([]float64, 0, window)
+	for i := len(cs) - 1; i >= 1 && len(r) < window; i-- {
+		if i >= len(benchClose) || !(benchClose[i] > 0) || !(benchClose[i-1] > 0) || cs[i-1].Close <= 0 {
+			continue
+		}
+		r = append(r, cs[i].Close/cs[i-1].Close-1)
+		rb = append(rb, benchClose[i]/benchClose[i-1]-1)
+	}
+	if len(r) < 2 {
+		return 0, false
+	}
+	v := stat.Variance(rb, nil)
+	if v == 0 {
+		return 0, false
+	}
+	return stat.Covariance(r, rb, nil) / v, true
+}
+
+// RelativeStrengthResult summarizes the latest bar versus the benchmark.
+// RelativeStrengthResult 汇总最后一根K线相对基准的表现。
+type RelativeStrengthResult struct {
+	RS float64 `json:"rs"` // Latest RS line value (最新 RS 值)
+	// ExcessReturn is the RS change over Lookback bars: RS[n-1]/RS[n-1-Lookback]-1.
+	// ExcessReturn 为 Lookback 根K线内 RS 的变化率。
+	ExcessReturn  float64 `json:"excess_return"`
+	Outperforming bool    `json:"outperforming"`
+	Beta          float64 `json:"beta"`
+	Lookback      int     `json:"lookback"`
+}
+
+// AnalyzeRelativeStrength computes the RS line, excess return and beta of cs
+// against benchmark. ok is false when fewer than Lookback+1 aligned bars exist.
+// AnalyzeRelativeStrength 计算 cs 相对基准的 RS 线、超额收益与 beta；对齐K线不足 Lookback+1 根时 ok 为 false。
+func AnalyzeRelativeStrength(cs, benchmark []CandlestickWrapper, cfg RelativeStrengthConfig) (RelativeStrengthResult, []float64, bool) {
+	cfg = normalizeRelativeStrengthConfig(cfg)
+	bench := AlignBenchmark(cs, benchmark)
+	rs := RelativeStrengthLine(cs, bench)
+	n := len(rs)
+	if n <= cfg.Lookback || math.IsNaN(rs[n-1]) || math.IsNaN(rs[n-1-cfg.Lookback]) {
+		return RelativeStrengthResult{}, rs, false
+	}
+	res := RelativeStrengthResult{
+		RS:           rs[n-1],
+		ExcessReturn: rs[n-1]/rs[n-1-cfg.Lookback] - 1,
+		Lookback:     cfg.Lookback,
+	}
+	res.Outperforming = res.ExcessReturn > 0
+	res.Beta, _ = Beta(cs, bench, cfg.BetaWindow)
+	return res, rs, true
+}
+
+// RelativeStrengthRank is one symbol's place in a watchlist ranked by excess return.
+// RelativeStrengthRank 为观察列表中按超额收益排序后某标的的名次。
+type RelativeStrengthRank struct {
+	Symbol       string  `json:"symbol"`
+	ExcessReturn float64 `json:"excess_return"`
+	Rank         int     `json:"rank"` // 1 is the strongest (1 为最强)
+	// Percentile is the share of the other ranked symbols it beats or ties,
+	// in [0,1]; 1 when it is ranked alone.
+	// Percentile 为其余参与排名的标的中不强于它的占比，取值 [0,1]；仅一个标的时为 1。
+	Percentile float64 `json:"percentile"`
+}
+
+// RankRelativeStrength ranks the watchlist by excess return over the
+// benchmark; symbols without enough aligned bars are left out.
+// RankRelativeStrength 按相对基准的超额收益对观察列表排序；对齐K线不足的标的不参与排名。
+func RankRelativeStrength(
+	watchlist map[string][]CandlestickWrapper,
+	benchmark []CandlestickWrapper,
+	cfg RelativeStrengthConfig,
+) []RelativeStrengthRank {
+	out := make([]RelativeStrengthRank, 0, len(watchlist))
+	for symbol, cs := range watchlist {
+		if res, _, ok := AnalyzeRelativeStrength(cs, benchmark, cfg); ok {
+			out = append(out, RelativeStrengthRank{Symbol: symbol, ExcessReturn: res.ExcessReturn})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ExcessReturn == out[j].ExcessReturn {
+			return out[i].Symbol < out[j].Symbol
+		}
+		return out[i].ExcessReturn > out[j].ExcessReturn
+	})
+	for i := range out {
+		out[i].Rank = i + 1
+		out[i].Percentile = 1
+		if len(out) == 1 {
+			continue
+		}
+		atMost := 0
+		for j := range out {
+			if j != i && out[j].ExcessReturn <= out[i].ExcessReturn {
+				atMost++
+			}
+		}
+		out[i].Percentile = float64(atMost) / float64(len(out)-1)
+	}
+	return out
+}
+
+// relativeStrengthFactor checks whether the RS line rose (bullish) or fell
+// (bearish) over Lookback bars up to the pattern bar; neutral patterns get
+// no factor.
+func relativeStrengthFactor(indicators map[string][]float64, p PatternSignal, cfg RelativeStrengthConfig) (FactorHit, bool) {
+	if !directional(p) {
+		return FactorHit{}, false
+	}
+	cfg = normalizeRelativeStrengthConfig(cfg)
+	rs := indicators[RelativeStrengthKey]
+	i, j := p.Position, p.Position-cfg.Lookback
+	if j < 0 || i >= len(rs) || math.IsNaN(rs[i]) || math.IsNaN(rs[j]) || rs[j] == 0 {
+		return FactorHit{}, false
+	}
+	excess := rs[i]/rs[j] - 1
+	pass := (p.Direction == "bullish" && excess > 0) ||
+		(p.Direction == "bearish" && excess < 0)
+	return FactorHit{
+		Name:      "outperforming_benchmark",
+		Value:     excess,
+		Threshold: 0,
+		Passed:    pass,
+		Reason:    fmt.Sprintf("relative strength change vs benchmark over %d bars agrees with the pattern direction", cfg.Lookback),
+	}, true
+}
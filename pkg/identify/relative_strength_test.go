@@ -0,0 +1,125 @@
+package identify
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+)
+
+// rsSeries builds n daily bars from 2025-01-02 whose close follows price(i).
+func rsSeries(n int, price func(i int) float64) []CandlestickWrapper {
+	base := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	cs := make([]CandlestickWrapper, n)
+	for i := range cs {
+		p := price(i)
+		cs[i] = NewCandlestickWrapper(&v1.Candlestick{
+			Timestamp: base.AddDate(0, 0, i).Unix(),
+			Open:      p, High: p * 1.01, Low: p * 0.99, Close: p, Volume: 1000,
+		})
+	}
+	return cs
+}
+
+func TestAlignBenchmarkCarriesForward(t *testing.T) {
+	cs := rsSeries(5, func(i int) float64 { return 10 })
+	bench := rsSeries(5, func(i int) float64 { return float64(100 + i) })
+	// Benchmark starts a day late and misses day 3.
+	// 基准晚一天开始，且缺少第 3 天。
+	bench = append(bench[1:3:3], bench[4])
+
+	got := AlignBenchmark(cs, bench)
+	if !math.IsNaN(got[0]) || got[1] != 101 || got[2] != 102 || got[3] != 102 || got[4] != 104 {
+		t.Fatalf("unexpected alignment: %v", got)
+	}
+	rs := RelativeStrengthLine(cs, got)
+	if !math.IsNaN(rs[0]) || rs[1] != 1 || math.Abs(rs[4]-101.0/104) > 1e-12 {
+		t.Fatalf("unexpected RS line: %v", rs)
+	}
+}
+
+func TestAnalyzeRelativeStrengthBetaAndRank(t *testing.T) {
+	benchRet := func(i int) float64 { return 0.01 * math.Sin(float64(i)/2) }
+	bench := make([]float64, 80)
+	bench[0] = 100
+	for i := 1; i < len(bench); i++ {
+		bench[i] = bench[i-1] * (1 + benchRet(i))
+	}
+	benchmark := rsSeries(80, func(i int) float64 { return bench[i] })
+
+	// Twice the benchmark's daily moves plus a steady drift: beta 2, outperforming.
+	// 日收益为基准两倍并带正漂移：beta 为 2，跑赢基准。
+	strong := make([]float64, 80)
+	strong[0] = 50
+	for i := 1; i < len(strong); i++ {
+		strong[i] = strong[i-1] * (1 + 2*benchRet(i) + 0.002)
+	}
+	cs := rsSeries(80, func(i int) float64 { return strong[i] })
+
+	res, rs, ok := AnalyzeRelativeStrength(cs, benchmark, DefaultRelativeStrengthConfig())
+	if !ok || len(rs) != 80 {
+		t.Fatalf("expected a result, got ok=%v len=%d", ok, len(rs))
+	}
+	if math.Abs(res.Beta-2) > 0.05 {
+		t.Fatalf("expected beta near 2, got %.4f", res.Beta)
+	}
+	if !res.Outperforming || res.ExcessReturn <= 0 || res.Lookback != 20 {
+		t.Fatalf("expected outperformance: %+v", res)
+	}
+
+	weak := rsSeries(80, func(i int) float64 { return bench[i] * (1 - 0.002*float64(i)) })
+	ranks := RankRelativeStrength(map[string][]CandlestickWrapper{
+		"WEAK":   weak,
+		"STRONG": cs,
+		"INDEX":  benchmark,
+		"SHORT":  cs[:10],
+	}, benchmark, DefaultRelativeStrengthConfig())
+	if len(ranks) != 3 {
+		t.Fatalf("short series should be left out: %+v", ranks)
+	}
+	if ranks[0].Symbol != "STRONG" || ranks[0].Rank != 1 || ranks[0].Percentile != 1 ||
+		ranks[2].Symbol != "WEAK" || ranks[2].Percentile != 0 {
+		t.Fatalf("unexpected ranking: %+v", ranks)
+	}
+
+	// Tied symbols share a percentile.
+	// 并列的标的百分位相同。
+	tied := RankRelativeStrength(map[string][]CandlestickWrapper{"A": weak, "B": weak, "C": cs}, benchmark, DefaultRelativeStrengthConfig())
+	if tied[1].Percentile != 0.5 || tied[2].Percentile != 0.5 || tied[0].Percentile != 1 {
+		t.Fatalf("ties should share a percentile: %+v", tied)
+	}
+}
+
+func TestRelativeStrengthEvidenceFactor(t *testing.T) {
+	cs := rsSeries(30, func(i int) float64 { return 100 + float64(i) })
+	benchmark := rsSeries(30, func(i int) float64 { return 100 })
+	indicators := map[string][]float64{
+		RelativeStrengthKey: RelativeStrengthLine(cs, AlignBenchmark(cs, benchmark)),
+	}
+	cfg := DefaultEvidenceConfig()
+
+	factor := func(p PatternSignal) (FactorHit, bool) {
+		ev := BuildPatternEvidenceWithIndicators([]PatternSignal{p}, cs, cfg, indicators)[0]
+		for _, f := range ev.ContextFactors {
+			if f.Name == "outperforming_benchmark" {
+				return f, true
+			}
+		}
+		return FactorHit{}, false
+	}
+	if f, ok := factor(PatternSignal{Type: "Hammer", Direction: "bullish", Position: 25, Strength: 0.7}); !ok || !f.Passed {
+		t.Fatalf("bullish pattern should pass when outperforming: %+v", f)
+	}
+	if f, ok := factor(PatternSignal{Type: "Shooting Star", Direction: "bearish", Position: 25, Strength: 0.7}); !ok || f.Passed {
+		t.Fatalf("bearish pattern should fail when outperforming: %+v", f)
+	}
+	if f, ok := factor(PatternSignal{Type: "Doji", Direction: "neutral", Position: 25, Strength: 0.7}); ok {
+		t.Fatalf("neutral patterns should get no relative strength factor: %+v", f)
+	}
+	// Not enough RS history before the pattern bar.
+	// 形态之前的 RS 历史不足。
+	if _, ok := factor(PatternSignal{Type: "Hammer", Direction: "bullish", Position: 10, Strength: 0.7}); ok {
+		t.Fatal("factor should be skipped without a full lookback")
+	}
+}
@@ -0,0 +1,90 @@
+package identify
+
+// Renko signal types
+// 砖形图信号类型
+const (
+	SignalRenkoReversalUp   = "Renko Reversal Up"
+	SignalRenkoReversalDown = "Renko Reversal Down"
+)
+
+// RenkoConfig controls Renko brick construction.
+// RenkoConfig 控制砖形图构建。
+type RenkoConfig struct {
+	Box BoxConfig `json:"box"`
+	// Reversal is the number of boxes price must travel back from the last
+	// brick's close to start an opposite brick (default and minimum 2, since
+	// the new brick starts at the last brick's open).
+	// Reversal 为自上一砖块收盘价反向运行多少格才开启反向砖块（默认且最少为 2，因为反向砖块自上一砖块开盘价起画）。
+	Reversal int `json:"reversal"`
+}
+
+// DefaultRenkoConfig returns ATR bricks with the classic two-box reversal.
+// DefaultRenkoConfig 返回以 ATR 为格值、两格反转的经典配置。
+func DefaultRenkoConfig() RenkoConfig {
+	return RenkoConfig{Box: DefaultBoxConfig(), Reversal: 2}
+}
+
+// RenkoBrick is one brick; Position is the source bar whose close completed it.
+// RenkoBrick 为一块砖；Position 为收盘完成该砖块的原始K线序号。
+type RenkoBrick struct {
+	Open      float64 `json:"open"`
+	Close     float64 `json:"close"`
+	Direction string  `json:"direction"` // up/down
+	Position  int     `json:"position"`
+}
+
+// Renko builds bricks from closes of chronological cs and returns them with
+// the box size used.
+// Renko 基于按时间排列的 cs 收盘价构建砖块，并返回所用格值。
+func Renko(cs []CandlestickWrapper, cfg RenkoConfig) ([]RenkoBrick, float64) {
+	if cfg.Reversal < 2 {
+		cfg.Reversal = DefaultRenkoConfig().Reversal
+	}
+	box := BoxSize(cs, cfg.Box)
+	out := make([]RenkoBrick, 0)
+	if box <= 0 || len(cs) == 0 {
+		return out, box
+	}
+
+	// hi/lo bound the last brick; dir is its direction (0 before the first brick)
+	// hi/lo 为上一砖块的上下沿；dir 为其方向（首块之前为 0）
+	hi, lo := cs[0].Close, cs[0].Close
+	dir := 0
+	rev := float64(cfg.Reversal - 1)
+	for i := 1; i < len(cs); i++ {
+		c := cs[i].Close
+		switch {
+		case (dir >= 0 && c >= hi+box) || (dir < 0 && c >= hi+rev*box):
+			for c >= hi+box {
+				out = append(out, RenkoBrick{Open: hi, Close: hi + box, Direction: "up", Position: i})
+				hi += box
+			}
+			lo, dir = hi-box, 1
+		case (dir <= 0 && c <= lo-box) || (dir > 0 && c <= lo-rev*box):
+			for c <= lo-box {
+				out = append(out, RenkoBrick{Open: lo, Close: lo - box, Direction: "down", Position: i})
+				lo -= box
+			}
+			hi, dir = lo+box, -1
+		}
+	}
+	return out, box
+}
+
+// RenkoSignals marks every brick that reverses the previous brick's direction.
+// RenkoSignals 标记方向与前一砖块相反的砖块。
+func RenkoSignals(cs []CandlestickWrapper, bricks []RenkoBrick) []PatternSignal {
+	out := make([]PatternSignal, 0)
+	for k := 1; k < len(bricks); k++ {
+		b := bricks[k]
+		if b.Direction == bricks[k-1].Direction || b.Position >= len(cs) {
+			continue
+		}
+		if b.Direction == "up" {
+			out = append(out, priceChartSignal(cs, b.Position, SignalRenkoReversalUp, "bullish", b.Close))
+		} else {
+			out = append(out, priceChartSignal(cs, b.Position, SignalRenkoReversalDown, "bearish", b.Close))
+		}
+	}
+	return out
+}
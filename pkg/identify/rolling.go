@@ -0,0 +1,269 @@
+package identify
+
+import "math"
+
+// Rolling indicator states for the streaming engine. Each state holds the
+// bars committed so far; peek returns the value for a new (possibly
+// provisional) bar without changing the state and commit folds it in. Both
+// are O(1) and follow the same arithmetic as the batch functions.
+// 流式引擎使用的滚动指标状态：peek 在不修改状态的前提下给出新K线（可能为临时K线）的值，commit 将其并入状态；均为 O(1)。
+
+// ring is a fixed-capacity FIFO of float64 values.
+type ring struct {
+	values []float64
+	next   int
+	count  int
+}
+
+func newRing(size int) *ring {
+	return &ring{values: make([]float64, size)}
+}
+
+func (r *ring) full() bool { return r.count == len(r.values) }
+
+// oldest returns the value a push would evict when the ring is full.
+func (r *ring) oldest() float64 {
+	if r.count < len(r.values) || len(r.values) == 0 {
+		return 0
+	}
+	return r.values[r.next]
+}
+
+func (r *ring) push(v float64) {
+	if len(r.values) == 0 {
+		return
+	}
+	r.values[r.next] = v
+	r.next = (r.next + 1) % len(r.values)
+	if r.count < len(r.values) {
+		r.count++
+	}
+}
+
+// smaState mirrors talib.Sma: the running total of the last period-1 values.
+type smaState struct {
+	period int
+	window *ring
+	total  float64
+}
+
+func newSMAState(period int) *smaState {
+	return &smaState{period: period, window: newRing(period - 1)}
+}
+
+func (s *smaState) peek(x float64) float64 {
+	if !s.window.full() {
+		return math.NaN()
+	}
+	return (s.total + x) / float64(s.period)
+}
+
+func (s *smaState) commit(x float64) {
+	if !s.window.full() {
+		s.total += x
+		s.window.push(x)
+		return
+	}
+	temp := s.total + x
+	s.total = temp - s.window.oldest()
+	s.window.push(x)
+}
+
+// emaState mirrors talib.Ema: seeded with the SMA of the first period values.
+type emaState struct {
+	period int
+	k      float64
+	sum    float64
+	count  int
+	prev   float64
+}
+
+func newEMAState(period int) *emaState {
+	return &emaState{period: period, k: 2.0 / float64(period+1)}
+}
+
+func (s *emaState) peek(x float64) float64 {
+	switch {
+	case s.count < s.period-1:
+		return math.NaN()
+	case s.count == s.period-1:
+		return (s.sum + x) / float64(s.period)
+	}
+	return ((x - s.prev) * s.k) + s.prev
+}
+
+func (s *emaState) commit(x float64) {
+	s.prev = s.peek(x)
+	if s.count < s.period {
+		s.sum += x
+	}
+	s.count++
+}
+
+// obvState mirrors talib.Obv.
+type obvState struct {
+	bars      int
+	prevOBV   float64
+	prevClose float64
+}
+
+func (s *obvState) peek(c CandlestickWrapper) float64 {
+	if s.bars == 0 {
+		return c.Volume
+	}
+	switch {
+	case c.Close > s.prevClose:
+		return s.prevOBV + c.Volume
+	case c.Close < s.prevClose:
+		return s.prevOBV - c.Volume
+	}
+	return s.prevOBV
+}
+
+func (s *obvState) commit(c CandlestickWrapper) {
+	s.prevOBV = s.peek(c)
+	s.prevClose = c.Close
+	s.bars++
+}
+
+// mfiState mirrors ComputeVolumeIndicators' MFI, including the shorter
+// period used while fewer than period+1 bars exist.
+type mfiState struct {
+	bars     int
+	prevTP   float64
+	pos, neg *ring
+	posSum   float64
+	negSum   float64
+}
+
+func newMFIState(period int) *mfiState {
+	if period < 2 {
+		period = 14
+	}
+	return &mfiState{pos: newRing(period), neg: newRing(period)}
+}
+
+// flow splits the money flow of c into its positive and negative parts.
+func (s *mfiState) flow(c CandlestickWrapper) (float64, float64) {
+	tp := (c.High + c.Low + c.Close) / 3.0
+	diff := tp - s.prevTP
+	v := tp * c.Volume
+	switch {
+	case diff < 0:
+		return 0, v
+	case diff > 0:
+		return v, 0
+	}
+	return 0, 0
+}
+
+// sums returns the positive/negative sums after adding c's flow.
+func (s *mfiState) sums(c CandlestickWrapper) (float64, float64) {
+	posSum, negSum := s.posSum, s.negSum
+	if s.pos.full() {
+		posSum -= s.pos.oldest()
+		negSum -= s.neg.oldest()
+	}
+	p, n := s.flow(c)
+	if n > 0 {
+		negSum += n
+	} else if p > 0 {
+		posSum += p
+	}
+	return posSum, negSum
+}
+
+func (s *mfiState) peek(c CandlestickWrapper) float64 {
+	if s.bars < 2 {
+		return 0
+	}
+	posSum, negSum := s.sums(c)
+	total := posSum + negSum
+	if total < 1.0 {
+		return 0
+	}
+	return 100.0 * (posSum / total)
+}
+
+func (s *mfiState) commit(c CandlestickWrapper) {
+	if s.bars > 0 {
+		p, n := s.flow(c)
+		s.posSum, s.negSum = s.sums(c)
+		s.pos.push(p)
+		s.neg.push(n)
+	}
+	s.prevTP = (c.High + c.Low + c.Close) / 3.0
+	s.bars++
+}
+
+// cmfState keeps rolling money-flow-volume and volume sums of computeCMF's window.
+type cmfState struct {
+	mfv, vol       *ring
+	sumMFV, sumVol float64
+}
+
+func newCMFState(period int) *cmfState {
+	if period < 2 {
+		period = 20
+	}
+	return &cmfState{mfv: newRing(period - 1), vol: newRing(period - 1)}
+}
+
+// contribution returns c's money flow volume and volume; zero-range bars are skipped.
+func cmfContribution(c CandlestickWrapper) (float64, float64) {
+	hlRange := c.High - c.Low
+	if hlRange == 0 {
+		return 0, 0
+	}
+	mfm := ((c.Close - c.Low) - (c.High - c.Close)) / hlRange
+	return mfm * c.Volume, c.Volume
+}
+
+func (s *cmfState) peek(c CandlestickWrapper) float64 {
+	mfv, vol := cmfContribution(c)
+	sumMFV, sumVol := s.sumMFV+mfv, s.sumVol+vol
+	if sumVol == 0 {
+		return 0
+	}
+	return sumMFV / sumVol
+}
+
+func (s *cmfState) commit(c CandlestickWrapper) {
+	mfv, vol := cmfContribution(c)
+	if s.mfv.full() {
+		s.sumMFV -= s.mfv.oldest()
+		s.sumVol -= s.vol.oldest()
+	}
+	s.sumMFV += mfv
+	s.sumVol += vol
+	s.mfv.push(mfv)
+	s.vol.push(vol)
+}
+
+// meanState is the rolling mean of the last size committed values.
+type meanState struct {
+	window *ring
+	sum    float64
+}
+
+func newMeanState(size int) *meanState {
+	if size < 1 {
+		size = 1
+	}
+	return &meanState{window: newRing(size)}
+}
+
+func (s *meanState) mean() float64 {
+	if s.window.count == 0 {
+		return 0
+	}
+	return s.sum / float64(s.window.count)
+}
+
+func (s *meanState) commit(x float64) {
+	if s.window.full() {
+		s.sum -= s.window.oldest()
+	}
+	s.sum += x
+	s.window.push(x)
+}
@@ -0,0 +1,116 @@
+package identify
+
+import "time"
+
+// patternCatalog lists the chart patterns in scan order with their risk score.
+var patternCatalog = []struct {
+	name string
+	risk float64
+}{
+	{"Doji", 0.5},
+	{"Long-Legged Doji", 0.5},
+	{"Hammer", 0.3},
+	{"Hanging Man", 0.7},
+	{"Inverted Hammer", 0.4},
+	{"Shooting Star", 0.6},
+	{"Marubozu", 0.2},
+	{"White Marubozu", 0.2},
+	{"Black Marubozu", 0.2},
+	{"Spinning Top", 0.8},
+	{"Umbrella", 0.4},
+	{"Dragonfly Doji", 0.4},
+	{"Gravestone Doji", 0.5},
+	{"Bullish Engulfing", 0.2},
+	{"Bearish Engulfing", 0.2},
+	{"Piercing Line", 0.3},
+	{"Dark Cloud Cover", 0.3},
+	{"Tweezer Bottoms", 0.4},
+	{"Tweezer Tops", 0.4},
+	{"Falling Window", 0.5},
+	{"Rising Window", 0.5},
+	{"Bullish Harami", 0.3},
+	{"Bearish Harami", 0.3},
+	{"Morning Star", 0.1},
+	{"Evening Star", 0.1},
+	{"Morning Doji Star", 0.1},
+	{"Evening Doji Star", 0.1},
+	{"Three White Soldiers", 0.1},
+	{"Three Black Crows", 0.1},
+	{"Rising Three Methods", 0.2},
+	{"Falling Three Methods", 0.2},
+}
+
+// patternSpans are the distinct catalog spans in scan order.
+var patternSpans = []int{1, 2, 3, 5}
+
+// PatternWindow returns the newest-first window ending at bar i of
+// chronological cs: the pattern span plus the bars used for scoring and for
+// the detector's long/short body reference.
+// PatternWindow 返回以第 i 根K线结束、按新到旧排列的窗口：形态跨度加上评分及识别器长短实体参照所需的K线。
+func PatternWindow(cs []CandlestickWrapper, i, span int, d *Detector) []CandlestickWrapper {
+	return WindowAt(cs, i, span+patternLookback(d))
+}
+
+// PatternHistory is the number of bars, including the newest, that
+// ScanPatternsAt reads for a detector.
+// PatternHistory 是 ScanPatternsAt 针对某识别器读取的K线数量（含最新一根）。
+func PatternHistory(d *Detector) int {
+	return patternSpans[len(patternSpans)-1] + patternLookback(d)
+}
+
+// patternLookback is the number of bars read behind a pattern span.
+func patternLookback(d *Detector) int {
+	if d.Config().BodyReference != BodyReferenceRange && d.Config().ReferencePeriod > ScoringLookback {
+		return d.Config().ReferencePeriod
+	}
+	return ScoringLookback
+}
+
+// ScanPatternsAt returns the patterns whose last bar is cs[i] (cs chronological),
+// graded by ps, in catalog order.
+// ScanPatternsAt 返回以 cs[i] 为最后一根K线的形态（cs 按时间排列），由 ps 评分，按目录顺序排列。
+func ScanPatternsAt(cs []CandlestickWrapper, i int, d *Detector, ps *PatternScorer) []PatternSignal {
+	out := make([]PatternSignal, 0)
+	for _, span := range patternSpans {
+		out = append(out, scanSpanAt(cs, i, span, d, ps)...)
+	}
+	return out
+}
+
+// ScanPatterns runs every catalog detector over chronological cs, grouped by
+// span (all single-candle patterns first) and then by position.
+// ScanPatterns 在按时间排列的 cs 上运行全部形态识别，先按跨度分组（单根形态在前），再按位置排列。
+func ScanPatterns(cs []CandlestickWrapper, d *Detector, ps *PatternScorer) []PatternSignal {
+	out := make([]PatternSignal, 0)
+	for _, span := range patternSpans {
+		for i := span - 1; i < len(cs); i++ {
+			out = append(out, scanSpanAt(cs, i, span, d, ps)...)
+		}
+	}
+	return out
+}
+
+func scanSpanAt(cs []CandlestickWrapper, i, span int, d *Detector, ps *PatternScorer) []PatternSignal {
+	if i < span-1 || i >= len(cs) {
+		return nil
+	}
+	window := PatternWindow(cs, i, span, d)
+	timestamp := time.Unix(cs[i].Timestamp, 0).Format("2006-01-02 15:04:05")
+	var out []PatternSignal
+	for _, entry := range patternCatalog {
+		spec := gradeSpecs[entry.name]
+		if spec.span != span || !spec.detect(d, window) {
+			continue
+		}
+		out = append(out, PatternSignal{
+			Type:      entry.name,
+			Direction: spec.direction,
+			Position:  i,
+			Strength:  ps.ScorePattern(entry.name, window).Strength,
+			Risk:      entry.risk,
+			Price:     cs[i].Close,
+			Time:      timestamp,
+		})
+	}
+	return out
+}
@@ -0,0 +1,294 @@
+package identify
+
+import (
+	"fmt"
+	"math"
+)
+
+// StreamConfig controls the incremental engine.
+// StreamConfig 控制增量引擎。
+type StreamConfig struct {
+	Detector DetectorConfig `json:"detector"`
+	Evidence EvidenceConfig `json:"evidence"`
+	// Indicators are rolling moving averages; only ma and ema are supported.
+	// Indicators 为滚动均线，仅支持 ma 与 ema。
+	Indicators []IndicatorSpec `json:"indicators"`
+}
+
+// DefaultStreamConfig returns default detector/evidence settings with MA5/10/20.
+// DefaultStreamConfig 返回默认识别与证据配置，并计算 MA5/10/20。
+func DefaultStreamConfig() StreamConfig {
+	return StreamConfig{
+		Detector: DefaultDetectorConfig(),
+		Evidence: DefaultEvidenceConfig(),
+		Indicators: []IndicatorSpec{
+			{Name: IndicatorMA, Params: []float64{5}},
+			{Name: IndicatorMA, Params: []float64{10}},
+			{Name: IndicatorMA, Params: []float64{20}},
+		},
+	}
+}
+
+// StreamUpdate is what one pushed bar changed.
+// StreamUpdate 是推送一根K线所产生的变化。
+type StreamUpdate struct {
+	Position int  // Absolute index of the pushed bar (K线绝对序号)
+	Revised  bool // The push replaced the provisional last bar (替换了最后一根临时K线)
+	// Patterns are patterns ending at Position that are new or changed since the
+	// previous push of the same bar; Evidence is aligned with Patterns.
+	// Patterns 为以 Position 结束、相对同一K线上次推送新增或变化的形态；Evidence 与之对齐。
+	Patterns []PatternSignal
+	Evidence []PatternEvidence
+	// Removed are patterns of a revised bar that no longer hold.
+	// Removed 为修订后的K线不再成立的形态。
+	Removed []PatternSignal
+	// Indicators holds the values at Position (NaN during warm-up).
+	// Indicators 为 Position 处的指标值（预热期为 NaN）。
+	Indicators map[string]float64
+	OBV        float64
+	MFI        float64
+	CMF        float64
+	VolumeMA   float64 // Mean volume of the VolumeLookback bars before Position (此前 VolumeLookback 根K线的均量)
+}
+
+// StreamEngine updates patterns, evidence and rolling indicators one bar at a
+// time. Pushing a bar with the same timestamp as the last one revises it, so
+// an intraday bar can be pushed repeatedly until it closes. Results for each
+// bar match the batch path (ScanPatterns, BuildPatternEvidenceWithIndicators
+// and ComputeIndicators over all bars up to it); indicator state is O(1) per
+// bar and only a bounded window of candles is kept.
+// StreamEngine 逐根更新形态、证据与滚动指标；推送与最后一根时间戳相同的K线视为修订，
+// 因此盘中K线可反复推送直至收盘。每根K线的结果与批量路径一致；指标状态每根 O(1)，仅保留有限窗口的K线。
+type StreamEngine struct {
+	cfg      StreamConfig
+	detector *Detector
+	scorer   *PatternScorer
+	keep     int
+
+	// Trailing window; the last element is the current (possibly provisional) bar.
+	candles    []CandlestickWrapper
+	obv        []float64
+	mfi        []float64
+	cmf        []float64
+	indicators map[string][]float64
+	offset     int // Absolute index of candles[0]
+
+	// Rolling state committed through the bar before the current one.
+	obvState *obvState
+	mfiState *mfiState
+	cmfState *cmfState
+	volState *meanState
+	averages []streamAverage
+
+	lastPatterns []PatternSignal
+	lastEvidence []PatternEvidence
+}
+
+type streamAverage struct {
+	key string
+	sma *smaState
+	ema *emaState
+}
+
+// NewStreamEngine creates an engine; it rejects indicators other than ma/ema.
+// NewStreamEngine 创建增量引擎；ma/ema 以外的指标将报错。
+func NewStreamEngine(cfg StreamConfig) (*StreamEngine, error) {
+	e := &StreamEngine{
+		cfg:        cfg,
+		detector:   NewDetector(cfg.Detector),
+		scorer:     NewPatternScorer(DefaultPatternConfig()).WithDetectorConfig(cfg.Detector),
+		indicators: make(map[string][]float64),
+		obvState:   &obvState{},
+		mfiState:   newMFIState(cfg.Evidence.MFIPeriod),
+		cmfState:   newCMFState(cfg.Evidence.CMFPeriod),
+		volState:   newMeanState(cfg.Evidence.VolumeLookback),
+	}
+	for i, spec := range cfg.Indicators {
+		if err := ValidateIndicatorSpec(spec); err != nil {
+			return nil, fmt.Errorf("indicators[%d]: %v", i, err)
+		}
+		spec = normalizeIndicatorSpec(spec)
+		avg := streamAverage{key: spec.Keys()[0]}
+		switch spec.Name {
+		case IndicatorMA:
+			avg.sma = newSMAState(int(spec.Params[0]))
+		case IndicatorEMA:
+			avg.ema = newEMAState(int(spec.Params[0]))
+		default:
+			return nil, fmt.Errorf("indicators[%d]: %s is not supported by the stream engine", i, spec.Name)
+		}
+		e.averages = append(e.averages, avg)
+		e.indicators[avg.key] = nil
+	}
+	e.keep = streamHistory(e.detector, cfg.Evidence)
+	return e, nil
+}
+
+// streamHistory is the number of trailing bars the evidence and pattern
+// windows read, including the current bar.
+func streamHistory(d *Detector, cfg EvidenceConfig) int {
+	keep := PatternHistory(d)
+	context := cfg.ContextWindow
+	if context < 3 {
+		context = 9
+	}
+	obv := cfg.OBVDivergenceLookback
+	if obv < 1 {
+		obv = 5
+	}
+	// Beiliang bars up to the last horizon back, each with its range and volume lookback.
+	bc := normalizeBeiliangConfig(cfg.Beiliang)
+	beiliang := bc.Horizons[len(bc.Horizons)-1] + max(bc.RangeWindow, cfg.VolumeLookback)
+	for _, n := range []int{normalizeLevelConfig(cfg.Levels).Lookback, context, obv, cfg.VolumeLookback, beiliang, normalizeVolumeProfileConfig(cfg.Profile).Window} {
+		if n+1 > keep {
+			keep = n + 1
+		}
+	}
+	return keep
+}
+
+// Len returns the number of bars pushed so far.
+// Len 返回已推送的K线数量。
+func (e *StreamEngine) Len() int {
+	return e.offset + len(e.candles)
+}
+
+// Push adds a new bar or, when c has the last bar's timestamp, revises it.
+// Push 追加新K线；若 c 与最后一根时间戳相同，则修订最后一根。
+func (e *StreamEngine) Push(c CandlestickWrapper) (StreamUpdate, error) {
+	revised := false
+	if n := len(e.candles); n > 0 {
+		last := e.candles[n-1]
+		switch {
+		case c.Timestamp < last.Timestamp:
+			return StreamUpdate{}, fmt.Errorf("bar at %d is older than the last bar at %d", c.Timestamp, last.Timestamp)
+		case c.Timestamp == last.Timestamp:
+			revised = true
+			e.candles[n-1] = c
+		default:
+			e.commit(last)
+			e.appendBar(c)
+		}
+	} else {
+		e.appendBar(c)
+	}
+
+	// Values of the current bar from the committed state
+	// 由已提交状态计算当前K线的指标值
+	i := len(e.candles) - 1
+	e.obv[i] = e.obvState.peek(c)
+	e.mfi[i] = e.mfiState.peek(c)
+	e.cmf[i] = e.cmfState.peek(c)
+	update := StreamUpdate{
+		Position:   e.offset + i,
+		Revised:    revised,
+		Indicators: make(map[string]float64, len(e.averages)),
+		OBV:        e.obv[i],
+		MFI:        e.mfi[i],
+		CMF:        e.cmf[i],
+	}
+	for _, avg := range e.averages {
+		v := math.NaN()
+		if avg.sma != nil {
+			v = avg.sma.peek(c.Close)
+		} else {
+			v = avg.ema.peek(c.Close)
+		}
+		e.indicators[avg.key][i] = v
+		update.Indicators[avg.key] = v
+	}
+	if e.cfg.Evidence.VolumeLookback > 0 {
+		update.VolumeMA = e.volState.mean()
+	}
+
+	patterns := ScanPatternsAt(e.candles, i, e.detector, e.scorer)
+	ind := VolumeIndicatorSeries{OBV: e.obv, MFI: e.mfi, CMF: e.cmf}
+	evidence := make([]PatternEvidence, len(patterns))
+	var levels []PriceLevel
+	if len(patterns) > 0 {
+		levels = DetectLevels(e.candles[:i], e.cfg.Evidence.Levels)
+	}
+	for k, p := range patterns {
+		evidence[k] = patternEvidence(p, e.candles, ind, update.VolumeMA, e.indicators, levels, e.cfg.Evidence)
+		evidence[k].Position += e.offset
+		patterns[k].Position += e.offset
+	}
+
+	if !revised {
+		e.lastPatterns, e.lastEvidence = nil, nil
+	}
+	update.Patterns, update.Evidence, update.Removed = diffStreamPatterns(e.lastPatterns, e.lastEvidence, patterns, evidence)
+	e.lastPatterns, e.lastEvidence = patterns, evidence
+	return update, nil
+}
+
+// commit folds the closed bar c into the rolling state.
+func (e *StreamEngine) commit(c CandlestickWrapper) {
+	e.obvState.commit(c)
+	e.mfiState.commit(c)
+	e.cmfState.commit(c)
+	e.volState.commit(c.Volume)
+	for _, avg := range e.averages {
+		if avg.sma != nil {
+			avg.sma.commit(c.Close)
+		} else {
+			avg.ema.commit(c.Close)
+		}
+	}
+}
+
+// appendBar adds a slot for c, dropping old bars once the window is twice
+// the required history so that trimming stays amortized O(1).
+func (e *StreamEngine) appendBar(c CandlestickWrapper) {
+	if len(e.candles) >= 2*e.keep {
+		drop := len(e.candles) - e.keep + 1
+		e.offset += drop
+		e.candles = append([]CandlestickWrapper(nil), e.candles[drop:]...)
+		e.obv = append([]float64(nil), e.obv[drop:]...)
+		e.mfi = append([]float64(nil), e.mfi[drop:]...)
+		e.cmf = append([]float64(nil), e.cmf[drop:]...)
+		for key, series := range e.indicators {
+			e.indicators[key] = append([]float64(nil), series[drop:]...)
+		}
+	}
+	e.candles = append(e.candles, c)
+	e.obv = append(e.obv, 0)
+	e.mfi = append(e.mfi, 0)
+	e.cmf = append(e.cmf, 0)
+	for key, series := range e.indicators {
+		e.indicators[key] = append(series, math.NaN())
+	}
+}
+
+// diffStreamPatterns returns the patterns (with evidence) that are new or
+// changed versus the previous result of the same bar, and the ones that vanished.
+func diffStreamPatterns(
+	prevPatterns []PatternSignal,
+	prevEvidence []PatternEvidence,
+	patterns []PatternSignal,
+	evidence []PatternEvidence,
+) ([]PatternSignal, []PatternEvidence, []PatternSignal) {
+	before := make(map[string]int, len(prevPatterns))
+	for k, p := range prevPatterns {
+		before[p.Type] = k
+	}
+	changed := make([]PatternSignal, 0)
+	changedEvidence := make([]PatternEvidence, 0)
+	for k, p := range patterns {
+		if j, ok := before[p.Type]; ok {
+			delete(before, p.Type)
+			if prevPatterns[j] == p && prevEvidence[j].FinalScore == evidence[k].FinalScore {
+				continue
+			}
+		}
+		changed = append(changed, p)
+		changedEvidence = append(changedEvidence, evidence[k])
+	}
+	removed := make([]PatternSignal, 0)
+	for _, p := range prevPatterns {
+		if _, ok := before[p.Type]; ok {
+			removed = append(removed, p)
+		}
+	}
+	return changed, changedEvidence, removed
+}
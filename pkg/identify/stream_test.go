@@ -0,0 +1,164 @@
+package identify
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func streamCandles(n int, seed int64) []CandlestickWrapper {
+	rng := rand.New(rand.NewSource(seed))
+	out := make([]CandlestickWrapper, 0, n)
+	price := 20.0
+	for i := 0; i < n; i++ {
+		open := price * (1 + rng.NormFloat64()*0.01)
+		closep := open * (1 + rng.NormFloat64()*0.02)
+		if rng.Intn(8) == 0 {
+			closep = open * (1 + rng.NormFloat64()*0.001) // doji-like bars
+		}
+		high := math.Max(open, closep) * (1 + rng.Float64()*0.015)
+		low := math.Min(open, closep) * (1 - rng.Float64()*0.015)
+		c := candle(open, high, low, closep)
+		c.Timestamp = int64(1700000000 + i*86400)
+		c.Volume = 1000 + rng.Float64()*2000
+		out = append(out, c)
+		price = closep
+	}
+	return out
+}
+
+func closeEnough(a, b float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b))
+}
+
+func TestStreamMatchesBatch(t *testing.T) {
+	cs := streamCandles(220, 11)
+	cfg := DefaultStreamConfig()
+	cfg.Indicators = append(cfg.Indicators, IndicatorSpec{Name: IndicatorEMA, Params: []float64{12}})
+	engine, err := NewStreamEngine(cfg)
+	if err != nil {
+		t.Fatalf("new engine: %v", err)
+	}
+	detector := NewDetector(cfg.Detector)
+	scorer := NewPatternScorer(DefaultPatternConfig()).WithDetectorConfig(cfg.Detector)
+
+	hits := 0
+	for n := 1; n <= len(cs); n++ {
+		update, err := engine.Push(cs[n-1])
+		if err != nil {
+			t.Fatalf("push %d: %v", n-1, err)
+		}
+		prefix := cs[:n]
+
+		var want []PatternSignal
+		for _, p := range ScanPatterns(prefix, detector, scorer) {
+			if p.Position == n-1 {
+				want = append(want, p)
+			}
+		}
+		if len(update.Patterns) != len(want) {
+			t.Fatalf("bar %d: stream patterns %+v, batch %+v", n-1, update.Patterns, want)
+		}
+		indicators := ComputeIndicators(prefix, cfg.Indicators)
+		wantEvidence := BuildPatternEvidenceWithIndicators(want, prefix, cfg.Evidence, indicators)
+		for k := range want {
+			if update.Patterns[k] != want[k] {
+				t.Fatalf("bar %d: pattern %+v, batch %+v", n-1, update.Patterns[k], want[k])
+			}
+			got, exp := update.Evidence[k], wantEvidence[k]
+			if !closeEnough(got.FinalScore, exp.FinalScore) || got.Position != exp.Position ||
+				len(got.VolumeFactors) != len(exp.VolumeFactors) || len(got.ContextFactors) != len(exp.ContextFactors) {
+				t.Fatalf("bar %d: evidence %+v, batch %+v", n-1, got, exp)
+			}
+			for f := range exp.VolumeFactors {
+				if !closeEnough(got.VolumeFactors[f].Value, exp.VolumeFactors[f].Value) || got.VolumeFactors[f].Passed != exp.VolumeFactors[f].Passed {
+					t.Fatalf("bar %d: factor %+v, batch %+v", n-1, got.VolumeFactors[f], exp.VolumeFactors[f])
+				}
+			}
+			hits++
+		}
+
+		for key, series := range indicators {
+			if got := update.Indicators[key]; !closeEnough(got, series[n-1]) {
+				t.Fatalf("bar %d: %s = %v, batch %v", n-1, key, got, series[n-1])
+			}
+		}
+		vol := ComputeVolumeIndicators(prefix, cfg.Evidence.MFIPeriod, cfg.Evidence.CMFPeriod)
+		if update.OBV != vol.OBV[n-1] || !closeEnough(update.MFI, vol.MFI[n-1]) || !closeEnough(update.CMF, vol.CMF[n-1]) {
+			t.Fatalf("bar %d: obv/mfi/cmf %v/%v/%v, batch %v/%v/%v", n-1,
+				update.OBV, update.MFI, update.CMF, vol.OBV[n-1], vol.MFI[n-1], vol.CMF[n-1])
+		}
+		if !closeEnough(update.VolumeMA, averageVolumeBefore(prefix, n-1, cfg.Evidence.VolumeLookback)) {
+			t.Fatalf("bar %d: volume MA mismatch", n-1)
+		}
+	}
+	if hits == 0 {
+		t.Fatal("expected some patterns in the random series")
+	}
+	if engine.Len() != len(cs) || len(engine.candles) > 2*engine.keep {
+		t.Fatalf("engine should keep a bounded window, len=%d kept=%d", engine.Len(), len(engine.candles))
+	}
+}
+
+func TestStreamRevisesProvisionalBar(t *testing.T) {
+	cs := streamCandles(60, 5)
+	engine, err := NewStreamEngine(DefaultStreamConfig())
+	if err != nil {
+		t.Fatalf("new engine: %v", err)
+	}
+	for _, c := range cs[:59] {
+		if _, err := engine.Push(c); err != nil {
+			t.Fatalf("push: %v", err)
+		}
+	}
+
+	last := cs[59]
+	doji := candle(last.Open, last.Open*1.02, last.Open*0.98, last.Open)
+	doji.Timestamp, doji.Volume = last.Timestamp, last.Volume
+	first, _ := engine.Push(doji)
+	if first.Revised || !hasPattern(first.Patterns, "Doji") {
+		t.Fatalf("expected a new Doji, got %+v", first.Patterns)
+	}
+
+	again, _ := engine.Push(doji)
+	if !again.Revised || len(again.Patterns) != 0 || len(again.Removed) != 0 {
+		t.Fatalf("unchanged revision should report nothing, got %+v / %+v", again.Patterns, again.Removed)
+	}
+
+	long := candle(last.Open, last.Open*1.05, last.Open*0.999, last.Open*1.049)
+	long.Timestamp, long.Volume = last.Timestamp, last.Volume
+	revised, _ := engine.Push(long)
+	if !hasPattern(revised.Removed, "Doji") {
+		t.Fatalf("revision should remove the Doji, got %+v", revised.Removed)
+	}
+
+	final := append(append([]CandlestickWrapper(nil), cs[:59]...), long)
+	vol := ComputeVolumeIndicators(final, 14, 20)
+	if revised.OBV != vol.OBV[59] || !closeEnough(revised.MFI, vol.MFI[59]) {
+		t.Fatal("revised bar should replace the provisional values")
+	}
+
+	if _, err := engine.Push(cs[10]); err == nil {
+		t.Fatal("expected an error for an out-of-order bar")
+	}
+}
+
+func TestStreamRejectsUnsupportedIndicators(t *testing.T) {
+	cfg := DefaultStreamConfig()
+	cfg.Indicators = []IndicatorSpec{{Name: IndicatorRSI}}
+	if _, err := NewStreamEngine(cfg); err == nil {
+		t.Fatal("rsi is not a rolling average")
+	}
+}
+
+func hasPattern(patterns []PatternSignal, name string) bool {
+	for _, p := range patterns {
+		if p.Type == name {
+			return true
+		}
+	}
+	return false
+}
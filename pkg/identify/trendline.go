@@ -0,0 +1,293 @@
+package identify
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	TrendLineUp   = "up"   // Rising line through pivot lows, acts as support (上升趋势线，支撑)
+	TrendLineDown = "down" // Falling line through pivot highs, acts as resistance (下降趋势线，阻力)
+)
+
+// TrendLineFit is a trendline anchored on two pivots of the same kind.
+// TrendLineFit 是由两个同类摆动点锚定的趋势线。
+type TrendLineFit struct {
+	Type          string  `json:"type"`
+	StartPosition int     `json:"start_position"`
+	StartPrice    float64 `json:"start_price"`
+	EndPosition   int     `json:"end_position"` // second anchor pivot (第二个锚点)
+	EndPrice      float64 `json:"end_price"`
+	Slope         float64 `json:"slope"` // price change per bar (每根K线价格变化)
+	// ConfirmedAt is the first bar at which both anchors are known.
+	// ConfirmedAt 是两个锚点均已确认的首根K线。
+	ConfirmedAt int `json:"confirmed_at"`
+	Touches     int `json:"touches"`
+	// MaxViolation is the deepest intrabar breach in percent while the line held.
+	// MaxViolation 是趋势线有效期间最深的盘中穿越百分比。
+	MaxViolation float64 `json:"max_violation"`
+	Broken       bool    `json:"broken"`
+	// BreakPosition is the bar that closed through the line, -1 when intact.
+	// BreakPosition 是收盘穿越趋势线的K线，未突破时为 -1。
+	BreakPosition int `json:"break_position"`
+}
+
+// PriceAt returns the line price at bar i.
+// PriceAt 返回趋势线在第 i 根K线处的价格。
+func (t TrendLineFit) PriceAt(i int) float64 {
+	return t.StartPrice + t.Slope*float64(i-t.StartPosition)
+}
+
+// TrendLineBreak is a close through a validated trendline.
+// TrendLineBreak 是对已验证趋势线的收盘突破。
+type TrendLineBreak struct {
+	Position  int     `json:"position"`
+	Direction string  `json:"direction"` // bearish for up-line breaks, bullish for down-line breaks
+	Price     float64 `json:"price"`
+	LinePrice float64 `json:"line_price"`
+	LineType  string  `json:"line_type"`
+	Touches   int     `json:"touches"`
+}
+
+// TrendLineConfig controls trendline fitting.
+// TrendLineConfig 控制趋势线拟合参数。
+type TrendLineConfig struct {
+	Pivot PivotConfig `json:"pivot"`
+	// MinTouches is the number of touches (anchors included) required before a line is valid (default 3).
+	// MinTouches 是趋势线生效所需的触及次数（含锚点，默认 3）。
+	MinTouches int `json:"min_touches"`
+	// TouchTolerance is the distance in percent within which a bar touches the line (default 0.5).
+	// TouchTolerance 是判定K线触及趋势线的距离百分比（默认 0.5）。
+	TouchTolerance float64 `json:"touch_tolerance"`
+	// MaxViolation is the largest intrabar breach in percent tolerated before a break (default 1.0).
+	// MaxViolation 是突破前允许的最大盘中穿越百分比（默认 1.0）。
+	MaxViolation float64 `json:"max_violation"`
+	// BreakPercent is how far a close must go through the line to count as a break (default 0.5).
+	// BreakPercent 是收盘穿越趋势线多少百分比才视为突破（默认 0.5）。
+	BreakPercent float64 `json:"break_percent"`
+	// MaxPivots limits how many recent pivots of each kind are paired (default 8).
+	// MaxPivots 限制每类参与配对的最近摆动点数量（默认 8）。
+	MaxPivots int `json:"max_pivots"`
+	// MaxLines caps the number of returned lines per type (default 3).
+	// MaxLines 限制每类返回的趋势线数量（默认 3）。
+	MaxLines int `json:"max_lines"`
+}
+
+// DefaultTrendLineConfig returns defaults suitable for daily bars.
+// DefaultTrendLineConfig 返回适合日线的默认配置。
+func DefaultTrendLineConfig() TrendLineConfig {
+	return TrendLineConfig{
+		Pivot:          DefaultPivotConfig(),
+		MinTouches:     3,
+		TouchTolerance: 0.5,
+		MaxViolation:   1.0,
+		BreakPercent:   0.5,
+		MaxPivots:      8,
+		MaxLines:       3,
+	}
+}
+
+// DetectTrendLines connects pivot lows (up lines) and pivot highs (down lines),
+// keeps lines with enough touches and no excessive violation, and tracks the
+// first close through each line after it is confirmed.
+// DetectTrendLines 连接摆动低点（上升线）与摆动高点（下降线），保留触及次数足够且无过度穿越的趋势线，
+// 并跟踪趋势线确认后的首次收盘突破。
+func DetectTrendLines(cs []CandlestickWrapper, cfg TrendLineConfig) []TrendLineFit {
+	cfg = normalizeTrendLineConfig(cfg)
+	if len(cs) < 3 {
+		return nil
+	}
+	pivots := DetectPivots(cs, cfg.Pivot)
+	lows := make([]SwingPoint, 0)
+	highs := make([]SwingPoint, 0)
+	for _, p := range pivots {
+		if p.Kind == PivotLow {
+			lows = append(lows, p)
+		} else {
+			highs = append(highs, p)
+		}
+	}
+
+	out := fitTrendLines(cs, lastPivots(lows, cfg.MaxPivots), TrendLineUp, cfg)
+	return append(out, fitTrendLines(cs, lastPivots(highs, cfg.MaxPivots), TrendLineDown, cfg)...)
+}
+
+// TrendLineBreaks extracts break signals from fitted lines.
+// TrendLineBreaks 从拟合的趋势线中提取突破信号。
+func TrendLineBreaks(cs []CandlestickWrapper, lines []TrendLineFit) []TrendLineBreak {
+	out := make([]TrendLineBreak, 0)
+	for _, l := range lines {
+		if !l.Broken || l.BreakPosition < 0 || l.BreakPosition >= len(cs) {
+			continue
+		}
+		direction := "bearish"
+		if l.Type == TrendLineDown {
+			direction = "bullish"
+		}
+		out = append(out, TrendLineBreak{
+			Position:  l.BreakPosition,
+			Direction: direction,
+			Price:     cs[l.BreakPosition].Close,
+			LinePrice: l.PriceAt(l.BreakPosition),
+			LineType:  l.Type,
+			Touches:   l.Touches,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Position < out[j].Position })
+	return out
+}
+
+func normalizeTrendLineConfig(cfg TrendLineConfig) TrendLineConfig {
+	def := DefaultTrendLineConfig()
+	if cfg.Pivot.Method == "" {
+		cfg.Pivot = def.Pivot
+	}
+	if cfg.MinTouches < 2 {
+		cfg.MinTouches = def.MinTouches
+	}
+	if cfg.TouchTolerance <= 0 {
+		cfg.TouchTolerance = def.TouchTolerance
+	}
+	if cfg.MaxViolation <= 0 {
+		cfg.MaxViolation = def.MaxViolation
+	}
+	if cfg.BreakPercent <= 0 {
+		cfg.BreakPercent = def.BreakPercent
+	}
+	if cfg.MaxPivots < 2 {
+		cfg.MaxPivots = def.MaxPivots
+	}
+	if cfg.MaxLines < 1 {
+		cfg.MaxLines = def.MaxLines
+	}
+	return cfg
+}
+
+func lastPivots(pivots []SwingPoint, n int) []SwingPoint {
+	if len(pivots) > n {
+		return pivots[len(pivots)-n:]
+	}
+	return pivots
+}
+
+func fitTrendLines(cs []CandlestickWrapper, anchors []SwingPoint, lineType string, cfg TrendLineConfig) []TrendLineFit {
+	lines := make([]TrendLineFit, 0)
+	for i := 0; i < len(anchors); i++ {
+		for j := i + 1; j < len(anchors); j++ {
+			a, b := anchors[i], anchors[j]
+			if lineType == TrendLineUp && b.Price <= a.Price {
+				continue
+			}
+			if lineType == TrendLineDown && b.Price >= a.Price {
+				continue
+			}
+			if line, ok := evaluateTrendLine(cs, a, b, lineType, cfg); ok {
+				lines = append(lines, line)
+			}
+		}
+	}
+
+	// Prefer more touches, then the most recent anchors; drop collinear duplicates.
+	// 优先保留触及次数更多、锚点更新的趋势线，并去除共线重复线。
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].Touches == lines[j].Touches {
+			return lines[i].EndPosition > lines[j].EndPosition
+		}
+		return lines[i].Touches > lines[j].Touches
+	})
+	kept := make([]TrendLineFit, 0, cfg.MaxLines)
+	for _, l := range lines {
+		duplicate := false
+		for _, k := range kept {
+			if collinear(k, l, cfg.TouchTolerance) {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		kept = append(kept, l)
+		if len(kept) == cfg.MaxLines {
+			break
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i].StartPosition < kept[j].StartPosition })
+	return kept
+}
+
+// collinear reports whether two lines pass within tolerance percent of each
+// other's anchors.
+func collinear(a, b TrendLineFit, tolerance float64) bool {
+	for _, pos := range []int{a.StartPosition, a.EndPosition, b.StartPosition, b.EndPosition} {
+		pa, pb := a.PriceAt(pos), b.PriceAt(pos)
+		if pa <= 0 || math.Abs(pa-pb)/pa*100 > tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// evaluateTrendLine walks from the first anchor forward, counting touches and
+// violations, and stops at the first confirmed close through the line.
+func evaluateTrendLine(cs []CandlestickWrapper, a, b SwingPoint, lineType string, cfg TrendLineConfig) (TrendLineFit, bool) {
+	line := TrendLineFit{
+		Type:          lineType,
+		StartPosition: a.Position,
+		StartPrice:    a.Price,
+		EndPosition:   b.Position,
+		EndPrice:      b.Price,
+		Slope:         (b.Price - a.Price) / float64(b.Position-a.Position),
+		ConfirmedAt:   b.ConfirmedAt,
+		BreakPosition: -1,
+	}
+
+	for i := a.Position; i < len(cs); i++ {
+		linePrice := line.PriceAt(i)
+		if linePrice <= 0 {
+			return line, false
+		}
+		// penetration > 0 means price went through the line (percent).
+		// penetration > 0 表示价格穿越了趋势线（百分比）。
+		extreme, closePen := cs[i].Low, (linePrice-cs[i].Close)/linePrice*100
+		penetration := (linePrice - extreme) / linePrice * 100
+		if lineType == TrendLineDown {
+			extreme = cs[i].High
+			penetration = (extreme - linePrice) / linePrice * 100
+			closePen = (cs[i].Close - linePrice) / linePrice * 100
+		}
+
+		if i > line.ConfirmedAt && closePen >= cfg.BreakPercent {
+			if line.Touches < cfg.MinTouches {
+				return line, false
+			}
+			line.Broken = true
+			line.BreakPosition = i
+			return line, true
+		}
+		if penetration > cfg.MaxViolation {
+			return line, false
+		}
+		if penetration > line.MaxViolation {
+			line.MaxViolation = penetration
+		}
+		if math.Abs(penetration) <= cfg.TouchTolerance && !touchedPrev(cs, line, i, cfg) {
+			line.Touches++
+		}
+	}
+	return line, line.Touches >= cfg.MinTouches
+}
+
+// touchedPrev reports whether bar i-1 also touched the line, so consecutive
+// touching bars count as a single touch.
+func touchedPrev(cs []CandlestickWrapper, line TrendLineFit, i int, cfg TrendLineConfig) bool {
+	if i-1 < line.StartPosition {
+		return false
+	}
+	linePrice := line.PriceAt(i - 1)
+	extreme := cs[i-1].Low
+	if line.Type == TrendLineDown {
+		extreme = cs[i-1].High
+	}
+	return math.Abs(extreme-linePrice)/linePrice*100 <= cfg.TouchTolerance
+}
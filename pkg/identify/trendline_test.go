@@ -0,0 +1,44 @@
+package identify
+
+import "testing"
+
+func TestDetectTrendLinesTracksBreak(t *testing.T) {
+	// Three higher lows on one rising line (bars 0, 10, 20), then a sell-off through it.
+	// 三个更高的低点位于同一上升线（第 0、10、20 根），随后跌破该线。
+	closes := make([]float64, 0, 32)
+	legs := []struct {
+		from, to float64
+	}{{100, 112}, {112, 105}, {105, 117}, {117, 110}, {110, 122}, {122, 100}}
+	for i, leg := range legs {
+		for k := 0; k < 5; k++ {
+			if i > 0 && k == 0 {
+				continue
+			}
+			closes = append(closes, leg.from+(leg.to-leg.from)*float64(k)/5)
+		}
+		closes = append(closes, leg.to)
+	}
+	cs := wrapCloses(closes)
+
+	lines := DetectTrendLines(cs, DefaultTrendLineConfig())
+	var up *TrendLineFit
+	for i := range lines {
+		if lines[i].Type == TrendLineUp {
+			up = &lines[i]
+		}
+	}
+	if up == nil {
+		t.Fatalf("expected a rising trendline, got %+v", lines)
+	}
+	if up.StartPosition != 0 || up.Touches < 3 {
+		t.Fatalf("expected line anchored at bar 0 with >= 3 touches, got %+v", *up)
+	}
+	if !up.Broken || up.BreakPosition <= 20 {
+		t.Fatalf("expected a break after the third touch, got %+v", *up)
+	}
+
+	breaks := TrendLineBreaks(cs, lines)
+	if len(breaks) == 0 || breaks[0].Direction != "bearish" {
+		t.Fatalf("expected a bearish trendline break signal, got %+v", breaks)
+	}
+}
@@ -58,10 +58,15 @@ func ComputeVolumeIndicators(cs []CandlestickWrapper, mfiPeriod, cmfPeriod int)
 	out := VolumeIndicatorSeries{
 		OBV: talib.Obv(closep, volume),
 		AD:  talib.Ad(high, low, closep, volume),
-		MFI: talib.Mfi(high, low, closep, volume, effectiveMFIPeriod),
+		MFI: empty.MFI,
 		CMF: computeCMF(high, low, closep, volume, cmfPeriod),
 		VPT: computeVPT(closep, volume),
 	}
+	// MFI needs one bar more than its period; shorter series stay zero.
+	// MFI 需要比周期多一根K线，更短的序列保持为零。
+	if n > effectiveMFIPeriod {
+		out.MFI = talib.Mfi(high, low, closep, volume, effectiveMFIPeriod)
+	}
 
 	normalizeIndicatorNaN(out.MFI)
 	normalizeIndicatorNaN(out.CMF)
@@ -0,0 +1,163 @@
+package identify
+
+import (
+	"fmt"
+	"math"
+)
+
+// VolumeProfileConfig controls volume-at-price histograms.
+// VolumeProfileConfig 控制成交量分布（按价格）直方图。
+type VolumeProfileConfig struct {
+	Window int `json:"window"` // Bars ending at the evaluated bar (统计的K线数量)
+	Bins   int `json:"bins"`
+	// ValueArea is the share of volume around the POC forming the value area (default 0.7).
+	// ValueArea 为围绕 POC 构成价值区的成交量占比（默认 0.7）。
+	ValueArea float64 `json:"value_area"`
+	// ProximityPercent is how close (percent of price) a pattern must be to POC/VAH/VAL.
+	// ProximityPercent 为形态距 POC/VAH/VAL 的最大百分比距离。
+	ProximityPercent float64 `json:"proximity_percent"`
+}
+
+// DefaultVolumeProfileConfig returns a 60-bar, 24-bin profile with a 70% value area.
+// DefaultVolumeProfileConfig 返回 60 根K线、24 档、70% 价值区的默认配置。
+func DefaultVolumeProfileConfig() VolumeProfileConfig {
+	return VolumeProfileConfig{
+		Window:           60,
+		Bins:             24,
+		ValueArea:        0.7,
+		ProximityPercent: 1.0,
+	}
+}
+
+func normalizeVolumeProfileConfig(cfg VolumeProfileConfig) VolumeProfileConfig {
+	def := DefaultVolumeProfileConfig()
+	if cfg.Window < 2 {
+		cfg.Window = def.Window
+	}
+	if cfg.Bins < 2 {
+		cfg.Bins = def.Bins
+	}
+	if cfg.ValueArea <= 0 || cfg.ValueArea > 1 {
+		cfg.ValueArea = def.ValueArea
+	}
+	if cfg.ProximityPercent <= 0 {
+		cfg.ProximityPercent = def.ProximityPercent
+	}
+	return cfg
+}
+
+// VolumeProfile is the volume traded at each price bin.
+// VolumeProfile 为各价格档位的成交量分布。
+type VolumeProfile struct {
+	Low     float64   `json:"low"`  // Bottom of the first bin (最低价)
+	Step    float64   `json:"step"` // Bin width (档宽)
+	Volumes []float64 `json:"volumes"`
+	// POC is the middle of the highest-volume bin; VAL/VAH bound the value area.
+	// POC 为成交量最大档位的中点；VAL/VAH 为价值区下沿与上沿。
+	POC      float64 `json:"poc"`
+	VAH      float64 `json:"vah"`
+	VAL      float64 `json:"val"`
+	POCIndex int     `json:"poc_index"`
+	VALIndex int     `json:"val_index"` // First bin in the value area (价值区首档)
+	VAHIndex int     `json:"vah_index"` // Last bin in the value area (价值区末档)
+}
+
+// BinPrice returns the middle price of bin b.
+// BinPrice 返回第 b 档的中间价。
+func (vp VolumeProfile) BinPrice(b int) float64 {
+	return vp.Low + (float64(b)+0.5)*vp.Step
+}
+
+// ComputeVolumeProfile spreads each bar's volume over its range into bins and
+// grows the value area from the POC toward the heavier neighbour until it
+// holds valueArea of the volume. ok is false without a price range or volume.
+// ComputeVolumeProfile 将每根K线的成交量均匀分摊到其价格区间的各档位，并从 POC 起向成交量较大的一侧扩展价值区，
+// 直至覆盖 valueArea 比例的成交量。无价格区间或无成交量时 ok 为 false。
+func ComputeVolumeProfile(cs []CandlestickWrapper, bins int, valueArea float64) (VolumeProfile, bool) {
+	lo, step, vols := volumeHistogram(cs, bins)
+	total := 0.0
+	poc := 0
+	for b, v := range vols {
+		total += v
+		if v > vols[poc] {
+			poc = b
+		}
+	}
+	if step <= 0 || total <= 0 {
+		return VolumeProfile{}, false
+	}
+
+	low, high := poc, poc
+	inside := vols[poc]
+	for inside < valueArea*total && (low > 0 || high < len(vols)-1) {
+		below, above := -1.0, -1.0
+		if low > 0 {
+			below = vols[low-1]
+		}
+		if high < len(vols)-1 {
+			above = vols[high+1]
+		}
+		if above >= below {
+			high++
+			inside += above
+		} else {
+			low--
+			inside += below
+		}
+	}
+
+	vp := VolumeProfile{
+		Low:      lo,
+		Step:     step,
+		Volumes:  vols,
+		POCIndex: poc,
+		VALIndex: low,
+		VAHIndex: high,
+	}
+	vp.POC = vp.BinPrice(poc)
+	vp.VAL = lo + float64(low)*step
+	vp.VAH = lo + float64(high+1)*step
+	return vp, true
+}
+
+// profileFactor scores whether the pattern formed near the POC or the value
+// area edge matching its direction (VAL for bullish, VAH for bearish, either
+// for neutral), using the profile of the Window bars ending at the pattern bar.
+func profileFactor(cs []CandlestickWrapper, p PatternSignal, cfg VolumeProfileConfig) (FactorHit, bool) {
+	cfg = normalizeVolumeProfileConfig(cfg)
+	if p.Position+1 < cfg.Window {
+		return FactorHit{}, false
+	}
+	vp, ok := ComputeVolumeProfile(cs[p.Position-cfg.Window+1:p.Position+1], cfg.Bins, cfg.ValueArea)
+	price := cs[p.Position].Close
+	if !ok || price <= 0 {
+		return FactorHit{}, false
+	}
+
+	levels := map[string]float64{"POC": vp.POC}
+	switch p.Direction {
+	case "bullish":
+		levels["VAL"] = vp.VAL
+	case "bearish":
+		levels["VAH"] = vp.VAH
+	default:
+		levels["VAL"], levels["VAH"] = vp.VAL, vp.VAH
+	}
+	name, dist := "", math.Inf(1)
+	for _, key := range []string{"POC", "VAL", "VAH"} {
+		level, ok := levels[key]
+		if !ok {
+			continue
+		}
+		if d := math.Abs(price-level) / price * 100; d < dist {
+			name, dist = key, d
+		}
+	}
+	return FactorHit{
+		Name:      "volume_profile",
+		Value:     dist,
+		Threshold: cfg.ProximityPercent,
+		Passed:    dist <= cfg.ProximityPercent,
+		Reason:    fmt.Sprintf("close is %.2f%% from the %d-bar %s %.2f", dist, cfg.Window, name, levels[name]),
+	}, true
+}
@@ -0,0 +1,55 @@
+package identify
+
+import "testing"
+
+func TestComputeVolumeProfile(t *testing.T) {
+	// Most volume trades between 100 and 102; thin tails reach 95 and 110.
+	// 大部分成交集中在 100-102，两端稀薄成交延伸至 95 与 110。
+	cs := []CandlestickWrapper{
+		volumeCandle(100, 102, 100, 101, 5000),
+		volumeCandle(101, 102, 100, 100.5, 4000),
+		volumeCandle(100, 101, 95, 96, 300),
+		volumeCandle(96, 110, 96, 109, 300),
+		volumeCandle(101, 102, 100, 101.5, 4500),
+	}
+	vp, ok := ComputeVolumeProfile(cs, 15, 0.7)
+	if !ok {
+		t.Fatal("expected a profile")
+	}
+	if vp.POC < 100 || vp.POC > 102 {
+		t.Fatalf("POC should sit in the 100-102 cluster, got %v", vp.POC)
+	}
+	if vp.VAL > vp.POC || vp.VAH < vp.POC || vp.VAL < 95 || vp.VAH > 110 {
+		t.Fatalf("value area should bracket the POC: VAL %v POC %v VAH %v", vp.VAL, vp.POC, vp.VAH)
+	}
+	total, inside := 0.0, 0.0
+	for b, v := range vp.Volumes {
+		total += v
+		if b >= vp.VALIndex && b <= vp.VAHIndex {
+			inside += v
+		}
+	}
+	if inside < 0.7*total || vp.VAH-vp.VAL > 6 {
+		t.Fatalf("value area should hold 70%% of volume in a narrow band: %v of %v, %v-%v", inside, total, vp.VAL, vp.VAH)
+	}
+
+	if _, ok := ComputeVolumeProfile([]CandlestickWrapper{volumeCandle(1, 1, 1, 1, 100)}, 10, 0.7); ok {
+		t.Fatal("a flat range has no profile")
+	}
+}
+
+func TestProfileFactor(t *testing.T) {
+	cs := beiliangSeries(volumeCandle(100, 100.6, 99.8, 100.4, 1000))
+	cfg := DefaultVolumeProfileConfig()
+	p := PatternSignal{Type: "Hammer", Direction: "bullish", Position: len(cs) - 1}
+	f, ok := profileFactor(cs, p, cfg)
+	if !ok || f.Name != "volume_profile" {
+		t.Fatalf("expected a profile factor, got %+v", f)
+	}
+	if !f.Passed || f.Value > cfg.ProximityPercent {
+		t.Fatalf("close at the busiest price should be near the POC: %+v", f)
+	}
+	if _, ok := profileFactor(cs[:30], PatternSignal{Direction: "bullish", Position: 29}, cfg); ok {
+		t.Fatal("no factor before a full window")
+	}
+}
@@ -0,0 +1,77 @@
+package identify
+
+import (
+	"math"
+	"time"
+)
+
+// typicalPrice is (high+low+close)/3, the price VWAP weights by volume.
+func typicalPrice(c CandlestickWrapper) float64 {
+	return (c.High + c.Low + c.Close) / 3
+}
+
+// RollingVWAP returns the volume-weighted typical price of the last period
+// bars; values are NaN during warm-up and over zero-volume windows.
+// RollingVWAP 返回最近 period 根K线的成交量加权典型价；预热期及零成交量窗口为 NaN。
+func RollingVWAP(cs []CandlestickWrapper, period int) []float64 {
+	out := make([]float64, len(cs))
+	pv, vol := 0.0, 0.0
+	for i, c := range cs {
+		pv += typicalPrice(c) * c.Volume
+		vol += c.Volume
+		if i >= period {
+			old := cs[i-period]
+			pv -= typicalPrice(old) * old.Volume
+			vol -= old.Volume
+		}
+		out[i] = math.NaN()
+		if i >= period-1 && vol > 0 {
+			out[i] = pv / vol
+		}
+	}
+	return out
+}
+
+// SessionVWAP restarts the VWAP at the first bar of each calendar day in loc
+// (UTC when nil). On daily bars it equals the typical price.
+// SessionVWAP 在 loc（为空时为 UTC）的每个自然日首根K线处重新累计 VWAP；日线上等于典型价。
+func SessionVWAP(cs []CandlestickWrapper, loc *time.Location) []float64 {
+	if loc == nil {
+		loc = time.UTC
+	}
+	out := make([]float64, len(cs))
+	pv, vol := 0.0, 0.0
+	day := ""
+	for i, c := range cs {
+		if d := time.Unix(c.Timestamp, 0).In(loc).Format("2006-01-02"); d != day {
+			day, pv, vol = d, 0, 0
+		}
+		pv += typicalPrice(c) * c.Volume
+		vol += c.Volume
+		out[i] = math.NaN()
+		if vol > 0 {
+			out[i] = pv / vol
+		}
+	}
+	return out
+}
+
+// AnchoredVWAP accumulates the VWAP from bar anchor (e.g. a pattern position)
+// onward; earlier bars are NaN.
+// AnchoredVWAP 从第 anchor 根K线（如形态位置）起累计 VWAP；之前为 NaN。
+func AnchoredVWAP(cs []CandlestickWrapper, anchor int) []float64 {
+	out := make([]float64, len(cs))
+	pv, vol := 0.0, 0.0
+	for i, c := range cs {
+		out[i] = math.NaN()
+		if anchor < 0 || i < anchor {
+			continue
+		}
+		pv += typicalPrice(c) * c.Volume
+		vol += c.Volume
+		if vol > 0 {
+			out[i] = pv / vol
+		}
+	}
+	return out
+}
@@ -0,0 +1,49 @@
+package identify
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+)
+
+func TestVWAPVariants(t *testing.T) {
+	base := time.Date(2025, 3, 3, 9, 30, 0, 0, time.UTC)
+	bars := []struct {
+		offset        time.Duration
+		price, volume float64
+	}{
+		{0, 10, 100}, {time.Hour, 12, 300}, {2 * time.Hour, 11, 0},
+		{24 * time.Hour, 20, 200}, {25 * time.Hour, 22, 200},
+	}
+	cs := make([]CandlestickWrapper, len(bars))
+	for i, b := range bars {
+		cs[i] = NewCandlestickWrapper(&v1.Candlestick{
+			Timestamp: base.Add(b.offset).Unix(),
+			Open:      b.price, High: b.price, Low: b.price, Close: b.price,
+			Volume: b.volume,
+		})
+	}
+	near := func(got, want float64) bool { return math.Abs(got-want) < 1e-9 }
+
+	rolling := RollingVWAP(cs, 2)
+	if !math.IsNaN(rolling[0]) || !near(rolling[1], 11.5) || !near(rolling[2], 12) || !near(rolling[4], 21) {
+		t.Fatalf("rolling VWAP = %v", rolling)
+	}
+
+	session := SessionVWAP(cs, time.UTC)
+	if !near(session[2], 11.5) || !near(session[3], 20) || !near(session[4], 21) {
+		t.Fatalf("session VWAP should restart each day: %v", session)
+	}
+
+	anchored := AnchoredVWAP(cs, 1)
+	if !math.IsNaN(anchored[0]) || !near(anchored[1], 12) || !near(anchored[4], (12*300+20*200+22*200)/700.0) {
+		t.Fatalf("anchored VWAP = %v", anchored)
+	}
+
+	ind := ComputeIndicators(cs, []IndicatorSpec{{Name: IndicatorVWAP, Params: []float64{2}}})
+	if !near(ind["VWAP2"][4], rolling[4]) || !IndicatorOverlaysPrice(IndicatorVWAP) {
+		t.Fatalf("vwap indicator should match RollingVWAP: %v", ind["VWAP2"])
+	}
+}
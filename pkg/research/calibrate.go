@@ -0,0 +1,181 @@
+package research
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/LEVI-Tempest/Candle/pkg/signal"
+	"gonum.org/v1/gonum/stat"
+)
+
+// CalibrationConfig controls calibration fitting.
+// CalibrationConfig 控制校准拟合。
+type CalibrationConfig struct {
+	Method string `json:"method"` // isotonic/platt
+	// Bins is the number of equal-count score bins for expected returns (default 5).
+	// Bins 为计算期望收益的等样本数得分分箱数量（默认 5）。
+	Bins int `json:"bins"`
+	// Interval is the central coverage of the return interval (default 0.8).
+	// Interval 为收益区间的中心覆盖率（默认 0.8）。
+	Interval float64 `json:"interval"`
+	// MinSamples is the fewest samples a table needs (default 30).
+	// MinSamples 为生成一张校准表所需的最少样本数（默认 30）。
+	MinSamples int `json:"min_samples"`
+}
+
+// DefaultCalibrationConfig returns isotonic calibration with five return bins
+// and an 80% interval.
+// DefaultCalibrationConfig 返回保序回归校准、5 个收益分箱、80% 区间的默认配置。
+func DefaultCalibrationConfig() CalibrationConfig {
+	return CalibrationConfig{Method: signal.CalibrationIsotonic, Bins: 5, Interval: 0.8, MinSamples: 30}
+}
+
+func normalizeCalibrationConfig(cfg CalibrationConfig) CalibrationConfig {
+	def := DefaultCalibrationConfig()
+	if cfg.Method != signal.CalibrationPlatt {
+		cfg.Method = signal.CalibrationIsotonic
+	}
+	if cfg.Bins < 1 {
+		cfg.Bins = def.Bins
+	}
+	if cfg.Interval <= 0 || cfg.Interval >= 1 {
+		cfg.Interval = def.Interval
+	}
+	if cfg.MinSamples < 2 {
+		cfg.MinSamples = def.MinSamples
+	}
+	return cfg
+}
+
+// FitCalibration fits a table per pattern type and a pooled table at each
+// horizon (horizon -> samples), skipping those with fewer than MinSamples.
+// FitCalibration 在每个周期（周期 -> 样本）上为各形态类型及汇总样本拟合校准表，样本不足 MinSamples 的跳过。
+func FitCalibration(byHorizon map[int][]signal.TrainingSample, cfg CalibrationConfig) (*signal.Calibration, error) {
+	cfg = normalizeCalibrationConfig(cfg)
+	out := &signal.Calibration{Method: cfg.Method, Tables: make([]signal.CalibrationTable, 0)}
+	horizons := make([]int, 0, len(byHorizon))
+	for h := range byHorizon {
+		horizons = append(horizons, h)
+	}
+	sort.Ints(horizons)
+	for _, h := range horizons {
+		samples := byHorizon[h]
+		byType := make(map[string][]signal.TrainingSample)
+		for _, s := range samples {
+			byType[s.Type] = append(byType[s.Type], s)
+		}
+		patterns := make([]string, 0, len(byType)+1)
+		for p := range byType {
+			patterns = append(patterns, p)
+		}
+		sort.Strings(patterns)
+		byType[signal.CalibrationAllPatterns] = samples
+		patterns = append([]string{signal.CalibrationAllPatterns}, patterns...)
+		for _, p := range patterns {
+			if len(byType[p]) < cfg.MinSamples {
+				continue
+			}
+			t, err := FitCalibrationTable(p, h, byType[p], cfg)
+			if err != nil {
+				return nil, fmt.Errorf("%s/%d: %w", p, h, err)
+			}
+			out.Tables = append(out.Tables, t)
+		}
+	}
+	if len(out.Tables) == 0 {
+		return nil, fmt.Errorf("no pattern or horizon has %d samples", cfg.MinSamples)
+	}
+	return out, nil
+}
+
+// FitCalibrationTable maps FinalScore to the hit rate with cfg.Method and
+// bins the direction-signed returns by score.
+// FitCalibrationTable 按 cfg.Method 将 FinalScore 映射为命中率，并按得分对方向化收益分箱。
+func FitCalibrationTable(pattern string, horizon int, samples []signal.TrainingSample, cfg CalibrationConfig) (signal.CalibrationTable, error) {
+	cfg = normalizeCalibrationConfig(cfg)
+	if len(samples) == 0 {
+		return signal.CalibrationTable{}, fmt.Errorf("no samples")
+	}
+	sorted := append([]signal.TrainingSample(nil), samples...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].FinalScore < sorted[j].FinalScore })
+	t := signal.CalibrationTable{Pattern: pattern, Horizon: horizon, Method: cfg.Method, Samples: len(sorted)}
+
+	if cfg.Method == signal.CalibrationPlatt {
+		x := make([][]float64, len(sorted))
+		y := make([]bool, len(sorted))
+		for i, s := range sorted {
+			x[i], y[i] = []float64{s.FinalScore}, s.Hit
+		}
+		m, err := FitLogistic([]string{"final_score"}, x, y, 1e-4, false)
+		if err != nil {
+			return signal.CalibrationTable{}, err
+		}
+		t.A, t.B = m.Coefficients[0], m.Intercept
+	} else {
+		t.Points = isotonic(sorted)
+	}
+	t.Returns = returnBins(sorted, cfg.Bins, cfg.Interval)
+	return t, nil
+}
+
+// isotonic runs pool-adjacent-violators on samples sorted by score; tied
+// scores start in one block, equal-rate neighbours are pooled too, and each
+// block becomes a knot at its mean score.
+func isotonic(sorted []signal.TrainingSample) []signal.CalibrationPoint {
+	type block struct{ score, hits, n float64 }
+	blocks := make([]block, 0, len(sorted))
+	for i, s := range sorted {
+		hit := 0.0
+		if s.Hit {
+			hit = 1
+		}
+		if i > 0 && s.FinalScore == sorted[i-1].FinalScore {
+			b := &blocks[len(blocks)-1]
+			b.score, b.hits, b.n = b.score+s.FinalScore, b.hits+hit, b.n+1
+		} else {
+			blocks = append(blocks, block{s.FinalScore, hit, 1})
+		}
+		for len(blocks) > 1 {
+			prev, cur := blocks[len(blocks)-2], blocks[len(blocks)-1]
+			if prev.hits/prev.n < cur.hits/cur.n {
+				break
+			}
+			blocks = append(blocks[:len(blocks)-2], block{prev.score + cur.score, prev.hits + cur.hits, prev.n + cur.n})
+		}
+	}
+	out := make([]signal.CalibrationPoint, len(blocks))
+	for i, b := range blocks {
+		out[i] = signal.CalibrationPoint{Score: b.score / b.n, Probability: b.hits / b.n}
+	}
+	return out
+}
+
+// returnBins splits samples sorted by score into about bins equal-count
+// groups, never splitting tied scores.
+func returnBins(sorted []signal.TrainingSample, bins int, interval float64) []signal.ReturnBin {
+	out := make([]signal.ReturnBin, 0, bins)
+	start := 0
+	for k := 1; k <= bins && start < len(sorted); k++ {
+		end := len(sorted) * k / bins
+		if end <= start {
+			continue
+		}
+		for end < len(sorted) && sorted[end].FinalScore == sorted[end-1].FinalScore {
+			end++
+		}
+		rets := make([]float64, 0, end-start)
+		for _, s := range sorted[start:end] {
+			rets = append(rets, s.Return)
+		}
+		sort.Float64s(rets)
+		out = append(out, signal.ReturnBin{
+			MaxScore: sorted[end-1].FinalScore,
+			Count:    len(rets),
+			Mean:     stat.Mean(rets, nil),
+			Low:      stat.Quantile((1-interval)/2, stat.Empirical, rets, nil),
+			High:     stat.Quantile((1+interval)/2, stat.Empirical, rets, nil),
+		})
+		start = end
+	}
+	return out
+}
@@ -0,0 +1,93 @@
+package research
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/LEVI-Tempest/Candle/pkg/signal"
+)
+
+// scoredSamples draws n samples of typ whose hit probability equals the score
+// and whose return is the score-centered noise.
+func scoredSamples(rng *rand.Rand, typ string, n int) []signal.TrainingSample {
+	out := make([]signal.TrainingSample, n)
+	for i := range out {
+		score := rng.Float64()
+		hit := rng.Float64() < score
+		ret := 4*(score-0.5) + rng.NormFloat64()
+		out[i] = signal.TrainingSample{Type: typ, Direction: "bullish", FinalScore: score, Hit: hit, Return: ret}
+	}
+	return out
+}
+
+func TestFitCalibrationTableMethods(t *testing.T) {
+	samples := scoredSamples(rand.New(rand.NewSource(11)), "Hammer", 4000)
+	for _, method := range []string{signal.CalibrationIsotonic, signal.CalibrationPlatt} {
+		cfg := DefaultCalibrationConfig()
+		cfg.Method = method
+		table, err := FitCalibrationTable("Hammer", 5, samples, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		prev := -1.0
+		for _, s := range []float64{0.1, 0.3, 0.5, 0.7, 0.9} {
+			p := table.Probability(s)
+			if math.Abs(p-s) > 0.1 || p < prev {
+				t.Fatalf("%s: P(hit | %.1f) = %.3f, want a monotone ~%.1f", method, s, p, s)
+			}
+			prev = p
+		}
+		if len(table.Returns) != 5 {
+			t.Fatalf("%s: expected 5 return bins, got %+v", method, table.Returns)
+		}
+		count := 0
+		for i, b := range table.Returns {
+			count += b.Count
+			if b.Low > b.Mean || b.Mean > b.High || (i > 0 && b.Mean <= table.Returns[i-1].Mean) {
+				t.Fatalf("%s: bad return bin %d: %+v", method, i, table.Returns)
+			}
+		}
+		if count != len(samples) {
+			t.Fatalf("%s: bins hold %d of %d samples", method, count, len(samples))
+		}
+	}
+}
+
+func TestIsotonicPoolsViolators(t *testing.T) {
+	samples := []signal.TrainingSample{
+		{FinalScore: 0.1, Hit: true}, {FinalScore: 0.2, Hit: false},
+		{FinalScore: 0.3, Hit: false}, {FinalScore: 0.3, Hit: true},
+		{FinalScore: 0.9, Hit: true},
+	}
+	got := isotonic(samples)
+	want := []signal.CalibrationPoint{{Score: 0.225, Probability: 0.5}, {Score: 0.9, Probability: 1}}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if math.Abs(got[i].Score-want[i].Score) > 1e-12 || got[i].Probability != want[i].Probability {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestFitCalibrationTables(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	samples := append(scoredSamples(rng, "Hammer", 200), scoredSamples(rng, "Doji Star", 10)...)
+	cal, err := FitCalibration(map[int][]signal.TrainingSample{5: samples, 10: samples[180:]}, DefaultCalibrationConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]string, 0)
+	for _, tb := range cal.Tables {
+		keys = append(keys, fmt.Sprintf("%s/%d", tb.Pattern, tb.Horizon))
+	}
+	// Doji Star lacks samples; horizon 10 only has enough for the pooled table.
+	// Doji Star 样本不足；周期 10 仅够生成汇总表。
+	if len(cal.Tables) != 3 || cal.Tables[0].Pattern != "*" || cal.Tables[1].Pattern != "Hammer" ||
+		cal.Tables[2].Pattern != "*" || cal.Tables[2].Horizon != 10 {
+		t.Fatalf("unexpected tables: %v", keys)
+	}
+}
@@ -0,0 +1,365 @@
+package research
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/LEVI-Tempest/Candle/pkg/charting"
+	"github.com/LEVI-Tempest/Candle/pkg/identify"
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+	"github.com/LEVI-Tempest/Candle/pkg/signal"
+)
+
+// NoPattern is the confusion key of a bar without a label or a detection.
+// NoPattern 为混淆矩阵中无标注或无识别结果的键。
+const NoPattern = "none"
+
+// LabelFile is the ground truth of one symbol: every valid instance of the
+// reviewed patterns between From and To, as marked by an analyst.
+// LabelFile 为单一标的的人工标注：From 至 To 之间被复核形态的全部有效实例。
+type LabelFile struct {
+	Symbol string `json:"symbol"`
+	// From and To bound the reviewed bars ("2006-01-02" or "2006-01-02
+	// 15:04:05", inclusive); empty means the first/last candle.
+	// From 与 To 限定已复核的K线范围（含端点，可为日期或日期时间）；为空表示首/末根K线。
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	// Patterns lists the pattern names the analyst reviewed; empty means all
+	// detector patterns. Detections of other patterns are not scored.
+	// Patterns 为已复核的形态名称；为空表示全部形态。其他形态的识别结果不参与评估。
+	Patterns []string `json:"patterns,omitempty"`
+	// Reviewed must be set once the labels are checked; templates written from
+	// detector output start unreviewed and are refused by EvaluateLabels.
+	// 标注复核完成后须设置 Reviewed；由识别结果生成的模板初始为未复核，EvaluateLabels 拒绝评估。
+	Reviewed bool    `json:"reviewed"`
+	Labels   []Label `json:"labels"`
+}
+
+// Label marks one valid pattern ending at the bar at Time.
+// Label 标注一个以 Time 所在K线结束的有效形态。
+type Label struct {
+	Pattern string `json:"pattern"`
+	Time    string `json:"time"` // Last bar of the pattern (形态末根K线时间)
+	Note    string `json:"note,omitempty"`
+}
+
+// LoadLabelFiles reads comma-separated label files or directories of *.json
+// label files; the symbol defaults to the base file name.
+// LoadLabelFiles 读取以逗号分隔的标注文件或 *.json 目录；symbol 缺省时使用文件名。
+func LoadLabelFiles(spec string) ([]LabelFile, error) {
+	out := make([]LabelFile, 0)
+	for _, p := range strings.Split(spec, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		paths := []string{p}
+		if info, err := os.Stat(p); err != nil {
+			return nil, err
+		} else if info.IsDir() {
+			if paths, err = filepath.Glob(filepath.Join(p, "*.json")); err != nil {
+				return nil, err
+			}
+		}
+		for _, path := range paths {
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			var f LabelFile
+			if err := json.Unmarshal(raw, &f); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			if f.Symbol == "" {
+				f.Symbol = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+			}
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+// LabelTemplate pre-fills a label file with what cfg detects between from
+// and to, for an analyst to prune and complete. It is left unreviewed.
+// LabelTemplate 以 from 至 to 之间的识别结果预填标注文件，供人工删改补充；模板为未复核状态。
+func LabelTemplate(symbol string, candles []*v1.Candlestick, cfg signal.Config, from, to string, patterns []string) LabelFile {
+	f := LabelFile{Symbol: symbol, From: from, To: to, Patterns: patterns, Labels: make([]Label, 0)}
+	reviewed := patternSet(patterns)
+	for _, d := range signal.Detections(candles, cfg) {
+		if inRange(d.Time, from, to) && reviewed(d.Type) {
+			f.Labels = append(f.Labels, Label{Pattern: d.Type, Time: d.Time})
+		}
+	}
+	return f
+}
+
+// LabelChart returns the review chart for LabelTemplate: the same detections,
+// marked only between from and to and for the reviewed patterns.
+// LabelChart 返回与 LabelTemplate 对应的复核图表：识别结果相同，仅标记 from 至 to 之间且在复核范围内的形态。
+func LabelChart(candles []*v1.Candlestick, cfg signal.Config, from, to string, patterns []string) *charting.EnhancedKline {
+	ek := signal.DetectionChart(candles, cfg)
+	reviewed := patternSet(patterns)
+	kept := make([]charting.Pattern, 0, len(ek.Patterns))
+	for _, p := range ek.Patterns {
+		t := time.Unix(candles[p.Position].Timestamp, 0).Format("2006-01-02 15:04:05")
+		if inRange(t, from, to) && reviewed(p.Type) {
+			kept = append(kept, p)
+		}
+	}
+	ek.Patterns = kept
+	return ek
+}
+
+// PatternAccuracy compares one pattern's detections with its labels.
+// Precision, recall and F1 are omitted when undefined.
+// PatternAccuracy 比较单一形态的识别结果与标注；无法计算时省略精确率、召回率与 F1。
+type PatternAccuracy struct {
+	Pattern        string   `json:"pattern"`
+	Labeled        int      `json:"labeled"`
+	Detected       int      `json:"detected"`
+	TruePositives  int      `json:"true_positives"`
+	FalsePositives int      `json:"false_positives"`
+	FalseNegatives int      `json:"false_negatives"`
+	Precision      *float64 `json:"precision,omitempty"`
+	Recall         *float64 `json:"recall,omitempty"`
+	F1             *float64 `json:"f1,omitempty"`
+}
+
+// LabelMismatch is one false positive or false negative, for review.
+// LabelMismatch 为一个误报或漏报，供复核。
+type LabelMismatch struct {
+	Symbol  string `json:"symbol"`
+	Pattern string `json:"pattern"`
+	Time    string `json:"time"`
+	Kind    string `json:"kind"` // false_positive/false_negative
+	// Detected lists what was detected at a missed label's bar instead.
+	// Detected 为漏报标注所在K线上识别出的其他形态。
+	Detected []string `json:"detected,omitempty"`
+}
+
+// LabelEvaluation is the detector accuracy against analyst labels.
+// Confusion counts labeled -> detected pattern per labeled bar, with
+// NoPattern for a missed label or an unlabeled detection.
+// LabelEvaluation 为识别器相对人工标注的准确度；Confusion 按标注K线统计 标注形态 -> 识别形态，
+// 漏报或无标注的识别记为 NoPattern。
+type LabelEvaluation struct {
+	Symbols    int                       `json:"symbols"`
+	Labels     int                       `json:"labels"`
+	Detections int                       `json:"detections"`
+	Tolerance  int                       `json:"tolerance"`
+	Overall    PatternAccuracy           `json:"overall"`
+	Patterns   []PatternAccuracy         `json:"patterns"`
+	Confusion  map[string]map[string]int `json:"confusion"`
+	Mismatches []LabelMismatch           `json:"mismatches"`
+}
+
+// EvaluateLabels detects patterns with cfg on each labeled symbol's candles
+// and matches them to the labels: a detection matches an unmatched label of
+// the same pattern at most tolerance bars away. Only bars in a file's range
+// and patterns it reviewed are scored.
+// EvaluateLabels 按 cfg 识别每个已标注标的的形态并与标注匹配：同名且相距不超过 tolerance 根K线的未匹配标注视为命中。
+// 仅评估文件范围内的K线及其复核的形态。
+func EvaluateLabels(history map[string][]*v1.Candlestick, files []LabelFile, cfg signal.Config, tolerance int) (LabelEvaluation, error) {
+	if tolerance < 0 {
+		tolerance = 0
+	}
+	res := LabelEvaluation{Tolerance: tolerance, Confusion: make(map[string]map[string]int), Mismatches: make([]LabelMismatch, 0)}
+	known := patternSet(identify.DiagnosablePatterns())
+	counts := make(map[string]*PatternAccuracy)
+	count := func(pattern string) *PatternAccuracy {
+		if counts[pattern] == nil {
+			counts[pattern] = &PatternAccuracy{Pattern: pattern}
+		}
+		return counts[pattern]
+	}
+	confuse := func(labeled, detected string) {
+		if res.Confusion[labeled] == nil {
+			res.Confusion[labeled] = make(map[string]int)
+		}
+		res.Confusion[labeled][detected]++
+	}
+
+	for _, f := range files {
+		if !f.Reviewed {
+			return res, fmt.Errorf("labels for %s are not reviewed", f.Symbol)
+		}
+		candles, ok := history[f.Symbol]
+		if !ok {
+			return res, fmt.Errorf("no candles for labeled symbol %s", f.Symbol)
+		}
+		for _, p := range f.Patterns {
+			if !known(p) {
+				return res, fmt.Errorf("%s: unknown pattern %q", f.Symbol, p)
+			}
+		}
+		times := make([]string, len(candles))
+		for i, c := range candles {
+			times[i] = time.Unix(c.Timestamp, 0).Format("2006-01-02 15:04:05")
+		}
+		reviewed := patternSet(f.Patterns)
+
+		labels := make([]int, len(f.Labels))
+		for k, l := range f.Labels {
+			if !known(l.Pattern) || !reviewed(l.Pattern) {
+				return res, fmt.Errorf("%s: label %q at %s is not a reviewed detector pattern", f.Symbol, l.Pattern, l.Time)
+			}
+			pos, err := labelPosition(times, l.Time)
+			if err != nil {
+				return res, fmt.Errorf("%s: label %q: %w", f.Symbol, l.Pattern, err)
+			}
+			if !inRange(times[pos], f.From, f.To) {
+				return res, fmt.Errorf("%s: label %q at %s is outside the reviewed range", f.Symbol, l.Pattern, l.Time)
+			}
+			labels[k] = pos
+		}
+		detections := make([]signal.Detection, 0)
+		for _, d := range signal.Detections(candles, cfg) {
+			if known(d.Type) && reviewed(d.Type) && inRange(d.Time, f.From, f.To) {
+				detections = append(detections, d)
+			}
+		}
+		res.Symbols++
+		res.Labels += len(labels)
+		res.Detections += len(detections)
+
+		// Labels claim their nearest same-pattern detection in time order.
+		// 标注按时间顺序认领距离最近的同名识别结果。
+		order := make([]int, len(labels))
+		for k := range order {
+			order[k] = k
+		}
+		sort.SliceStable(order, func(a, b int) bool { return labels[order[a]] < labels[order[b]] })
+		matched := make([]bool, len(detections))
+		labeledBars := make(map[int]bool, len(labels))
+		for _, k := range order {
+			l, pos := f.Labels[k], labels[k]
+			labeledBars[pos] = true
+			count(l.Pattern).Labeled++
+			best := -1
+			for j, d := range detections {
+				dist := abs(d.Position - pos)
+				if !matched[j] && d.Type == l.Pattern && dist <= tolerance && (best < 0 || dist < abs(detections[best].Position-pos)) {
+					best = j
+				}
+			}
+			if best >= 0 {
+				matched[best] = true
+				count(l.Pattern).TruePositives++
+				confuse(l.Pattern, l.Pattern)
+				continue
+			}
+			count(l.Pattern).FalseNegatives++
+			miss := LabelMismatch{Symbol: f.Symbol, Pattern: l.Pattern, Time: times[pos], Kind: "false_negative"}
+			for _, d := range detections {
+				if d.Position == pos && d.Type != l.Pattern {
+					miss.Detected = append(miss.Detected, d.Type)
+					confuse(l.Pattern, d.Type)
+				}
+			}
+			if len(miss.Detected) == 0 {
+				confuse(l.Pattern, NoPattern)
+			}
+			res.Mismatches = append(res.Mismatches, miss)
+		}
+		for j, d := range detections {
+			count(d.Type).Detected++
+			if matched[j] {
+				continue
+			}
+			count(d.Type).FalsePositives++
+			res.Mismatches = append(res.Mismatches, LabelMismatch{Symbol: f.Symbol, Pattern: d.Type, Time: d.Time, Kind: "false_positive"})
+			if !labeledBars[d.Position] {
+				confuse(NoPattern, d.Type)
+			}
+		}
+	}
+
+	res.Overall.Pattern = "*"
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	res.Patterns = make([]PatternAccuracy, 0, len(names))
+	for _, name := range names {
+		c := *counts[name]
+		res.Overall.Labeled += c.Labeled
+		res.Overall.Detected += c.Detected
+		res.Overall.TruePositives += c.TruePositives
+		res.Overall.FalsePositives += c.FalsePositives
+		res.Overall.FalseNegatives += c.FalseNegatives
+		res.Patterns = append(res.Patterns, withRates(c))
+	}
+	res.Overall = withRates(res.Overall)
+	return res, nil
+}
+
+// withRates fills precision, recall and F1 from the counts.
+func withRates(a PatternAccuracy) PatternAccuracy {
+	if a.Detected > 0 {
+		p := float64(a.TruePositives) / float64(a.Detected)
+		a.Precision = &p
+	}
+	if a.Labeled > 0 {
+		r := float64(a.TruePositives) / float64(a.Labeled)
+		a.Recall = &r
+	}
+	if a.Precision != nil && a.Recall != nil && *a.Precision+*a.Recall > 0 {
+		f1 := 2 * *a.Precision * *a.Recall / (*a.Precision + *a.Recall)
+		a.F1 = &f1
+	}
+	return a
+}
+
+// labelPosition finds the bar at t: an exact bar time, or a date matching
+// exactly one bar.
+func labelPosition(times []string, t string) (int, error) {
+	pos := -1
+	for i, bt := range times {
+		if bt == t {
+			return i, nil
+		}
+		if strings.HasPrefix(bt, t+" ") {
+			if pos >= 0 {
+				return -1, fmt.Errorf("%s matches more than one bar; give the bar time", t)
+			}
+			pos = i
+		}
+	}
+	if pos < 0 {
+		return -1, fmt.Errorf("no bar at %s", t)
+	}
+	return pos, nil
+}
+
+// inRange reports whether bar time t lies within [from, to]; bounds may be
+// dates or full bar times.
+func inRange(t, from, to string) bool {
+	if from != "" && t < from {
+		return false
+	}
+	return to == "" || t[:min(len(to), len(t))] <= to
+}
+
+// patternSet returns membership in names; empty names admits every pattern.
+func patternSet(names []string) func(string) bool {
+	if len(names) == 0 {
+		return func(string) bool { return true }
+	}
+	set := make(map[string]bool, len(names))
+	for _, n := range names {
+		set[n] = true
+	}
+	return func(name string) bool { return set[name] }
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,180 @@
+package research
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+	"github.com/LEVI-Tempest/Candle/pkg/signal"
+	"github.com/LEVI-Tempest/Candle/pkg/synthetic"
+)
+
+// labeledSeries is a synthetic daily series with a Hammer and a Bullish
+// Engulfing injected, and the detections of those two patterns on it.
+func labeledSeries(t *testing.T) ([]*v1.Candlestick, []signal.Detection) {
+	t.Helper()
+	cfg := synthetic.DefaultConfig()
+	cfg.Bars = 120
+	cfg.Injections = []synthetic.Injection{
+		{Position: 40, Pattern: "Hammer"},
+		{Position: 80, Pattern: "Bullish Engulfing"},
+	}
+	s, err := synthetic.Generate(cfg)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	dets := make([]signal.Detection, 0)
+	for _, d := range signal.Detections(s.Candles, signal.DefaultConfig()) {
+		if d.Type == "Hammer" || d.Type == "Bullish Engulfing" {
+			dets = append(dets, d)
+		}
+	}
+	return s.Candles, dets
+}
+
+func TestEvaluateLabels(t *testing.T) {
+	candles, dets := labeledSeries(t)
+	var hammer, engulfing *signal.Detection
+	for i := range dets {
+		switch {
+		case dets[i].Type == "Hammer" && dets[i].Position == 40:
+			hammer = &dets[i]
+		case dets[i].Type == "Bullish Engulfing" && dets[i].Position == 80:
+			engulfing = &dets[i]
+		}
+	}
+	if hammer == nil || engulfing == nil {
+		t.Fatalf("injected patterns not detected: %+v", dets)
+	}
+
+	// The analyst accepts every detection but the Hammer, and adds a missed
+	// Hammer at bar 60 where nothing was detected.
+	// 分析师认可除锤头线外的全部识别结果，并在未识别出形态的第 60 根K线补标一个锤头线。
+	f := LabelFile{Symbol: "SYN", Patterns: []string{"Hammer", "Bullish Engulfing"}, Reviewed: true}
+	for _, d := range dets {
+		if d != *hammer {
+			f.Labels = append(f.Labels, Label{Pattern: d.Type, Time: d.Time})
+		}
+	}
+	missed := barTimeAt(candles, 60)
+	for _, d := range dets {
+		if d.Position == 60 {
+			t.Fatalf("bar 60 should have no detection: %+v", d)
+		}
+	}
+	f.Labels = append(f.Labels, Label{Pattern: "Hammer", Time: missed[:10]})
+
+	res, err := EvaluateLabels(map[string][]*v1.Candlestick{"SYN": candles}, []LabelFile{f}, signal.DefaultConfig(), 0)
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+	o := res.Overall
+	if o.Detected != len(dets) || o.Labeled != len(dets) || o.TruePositives != len(dets)-1 || o.FalsePositives != 1 || o.FalseNegatives != 1 {
+		t.Fatalf("unexpected overall counts for %d detections: %+v", len(dets), o)
+	}
+	for _, p := range res.Patterns {
+		if p.Pattern == "Bullish Engulfing" && (*p.Precision != 1 || *p.Recall != 1) {
+			t.Fatalf("engulfing should be perfect: %+v", p)
+		}
+	}
+	if res.Confusion["Hammer"][NoPattern] != 1 || res.Confusion[NoPattern]["Hammer"] != 1 {
+		t.Fatalf("confusion should record the miss and the false alarm: %v", res.Confusion)
+	}
+	kinds := map[string]string{}
+	for _, m := range res.Mismatches {
+		kinds[m.Time] = m.Kind
+	}
+	if kinds[hammer.Time] != "false_positive" || kinds[missed] != "false_negative" {
+		t.Fatalf("unexpected mismatches: %+v", res.Mismatches)
+	}
+
+	// A range ending before bar 60 drops the missed label's bar from review.
+	// 复核范围截止于第 60 根K线之前时，不再评估该漏报标注。
+	f.To = barTimeAt(candles, 59)[:10]
+	if _, err := EvaluateLabels(map[string][]*v1.Candlestick{"SYN": candles}, []LabelFile{f}, signal.DefaultConfig(), 0); err == nil {
+		t.Fatal("expected an error for a label outside the reviewed range")
+	}
+}
+
+func TestEvaluateLabelsTolerance(t *testing.T) {
+	candles, _ := labeledSeries(t)
+	f := LabelFile{Symbol: "SYN", Patterns: []string{"Bullish Engulfing"}, Reviewed: true, From: barTimeAt(candles, 70), To: barTimeAt(candles, 90),
+		Labels: []Label{{Pattern: "Bullish Engulfing", Time: barTimeAt(candles, 79)}}}
+	history := map[string][]*v1.Candlestick{"SYN": candles}
+	exact, err := EvaluateLabels(history, []LabelFile{f}, signal.DefaultConfig(), 0)
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+	near, _ := EvaluateLabels(history, []LabelFile{f}, signal.DefaultConfig(), 1)
+	if exact.Overall.TruePositives != 0 || near.Overall.TruePositives != 1 || near.Overall.FalsePositives != exact.Overall.FalsePositives-1 {
+		t.Fatalf("a label one bar off should match only with tolerance 1: exact %+v near %+v", exact.Overall, near.Overall)
+	}
+	if exact.Confusion["Bullish Engulfing"][NoPattern] != 1 {
+		t.Fatalf("unexpected confusion: %v", exact.Confusion)
+	}
+
+	f.Reviewed = false
+	if _, err := EvaluateLabels(history, []LabelFile{f}, signal.DefaultConfig(), 0); err == nil {
+		t.Fatal("expected an error for unreviewed labels")
+	}
+	f.Reviewed, f.Labels = true, []Label{{Pattern: "Hammer", Time: barTimeAt(candles, 80)}}
+	if _, err := EvaluateLabels(history, []LabelFile{f}, signal.DefaultConfig(), 0); err == nil {
+		t.Fatal("expected an error for a label of a pattern outside the reviewed set")
+	}
+}
+
+func TestLabelTemplateRoundTrip(t *testing.T) {
+	candles, dets := labeledSeries(t)
+	f := LabelTemplate("SYN", candles, signal.DefaultConfig(), "", "", []string{"Hammer", "Bullish Engulfing"})
+	if f.Reviewed || len(f.Labels) != len(dets) {
+		t.Fatalf("template should hold every detection, unreviewed: %+v", f)
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "SYN.json")
+	f.Symbol = ""
+	raw, _ := json.Marshal(f)
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	files, err := LoadLabelFiles(dir)
+	if err != nil || len(files) != 1 || files[0].Symbol != "SYN" {
+		t.Fatalf("load: %v %+v", err, files)
+	}
+	files[0].Reviewed = true
+	res, err := EvaluateLabels(map[string][]*v1.Candlestick{"SYN": candles}, files, signal.DefaultConfig(), 0)
+	if err != nil || res.Overall.FalsePositives != 0 || res.Overall.FalseNegatives != 0 {
+		t.Fatalf("an accepted template should score perfectly: %v %+v", err, res.Overall)
+	}
+}
+
+func TestLabelChartMatchesTemplate(t *testing.T) {
+	candles, _ := labeledSeries(t)
+	cfg := signal.DefaultConfig()
+	cfg.HeikinAshi = true
+	from, to := barTimeAt(candles, 30)[:10], barTimeAt(candles, 90)[:10]
+	tmpl := LabelTemplate("SYN", candles, cfg, from, to, nil)
+	ek := LabelChart(candles, cfg, from, to, nil)
+	if all := signal.Detections(candles, cfg); len(tmpl.Labels) == 0 || len(tmpl.Labels) == len(all) {
+		t.Fatalf("expected the range to keep some of the %d detections, kept %d", len(all), len(tmpl.Labels))
+	}
+	if len(ek.Patterns) != len(tmpl.Labels) {
+		t.Fatalf("chart marks %d patterns, template has %d labels", len(ek.Patterns), len(tmpl.Labels))
+	}
+	for i, p := range ek.Patterns {
+		if l := tmpl.Labels[i]; p.Type != l.Pattern || barTimeAt(candles, p.Position) != l.Time {
+			t.Fatalf("chart pattern %+v differs from label %+v", p, l)
+		}
+	}
+
+	names := []string{tmpl.Labels[0].Pattern}
+	if ek := LabelChart(candles, cfg, from, to, names); len(ek.Patterns) != len(LabelTemplate("SYN", candles, cfg, from, to, names).Labels) {
+		t.Fatalf("chart should mark only the reviewed %v: %+v", names, ek.Patterns)
+	}
+}
+
+func barTimeAt(candles []*v1.Candlestick, i int) string {
+	return time.Unix(candles[i].Timestamp, 0).Format("2006-01-02 15:04:05")
+}
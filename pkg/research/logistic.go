@@ -0,0 +1,171 @@
+// Package research fits and evaluates signal parameters on historical outcomes.
+// 研究包 - 基于历史结果拟合与评估信号参数
+package research
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/integrate"
+	"gonum.org/v1/gonum/optimize"
+	"gonum.org/v1/gonum/stat"
+)
+
+// LogisticModel is an L2-regularized logistic regression. Coefficients apply
+// to features standardized by Mean and Std; the intercept is not penalized.
+// LogisticModel 为 L2 正则化逻辑回归；系数作用于按 Mean/Std 标准化后的特征，截距不参与正则。
+type LogisticModel struct {
+	Features     []string  `json:"features"`
+	Intercept    float64   `json:"intercept"`
+	Coefficients []float64 `json:"coefficients"`
+	Mean         []float64 `json:"mean"`
+	Std          []float64 `json:"std"`
+}
+
+// FitLogistic fits y ~ x by minimizing the mean log loss plus lambda/2·|w|²
+// with L-BFGS. Features are standardized when standardize is set; constant
+// features are left centered and get a zero coefficient.
+// FitLogistic 使用 L-BFGS 最小化平均对数损失加 lambda/2·|w|² 拟合 y ~ x；standardize 为真时标准化特征，
+// 常数特征仅做中心化且系数为零。
+func FitLogistic(features []string, x [][]float64, y []bool, lambda float64, standardize bool) (LogisticModel, error) {
+	n, d := len(x), len(features)
+	if n == 0 || len(y) != n {
+		return LogisticModel{}, fmt.Errorf("need matching non-empty x and y, got %d and %d", n, len(y))
+	}
+	m := LogisticModel{
+		Features:     features,
+		Coefficients: make([]float64, d),
+		Mean:         make([]float64, d),
+		Std:          make([]float64, d),
+	}
+	col := make([]float64, n)
+	for j := 0; j < d; j++ {
+		for i := range x {
+			if len(x[i]) != d {
+				return LogisticModel{}, fmt.Errorf("row %d has %d features, want %d", i, len(x[i]), d)
+			}
+			col[i] = x[i][j]
+		}
+		m.Std[j] = 1
+		if standardize {
+			m.Mean[j], m.Std[j] = stat.MeanStdDev(col, nil)
+			if !(m.Std[j] > 0) {
+				m.Std[j] = 0
+			}
+		}
+	}
+
+	z := make([][]float64, n)
+	for i := range x {
+		z[i] = m.scale(x[i])
+	}
+	problem := optimize.Problem{
+		Func: func(w []float64) float64 {
+			loss := 0.0
+			for i := range z {
+				loss += logLoss(dot(w, z[i]), y[i])
+			}
+			loss /= float64(n)
+			for j := 1; j < len(w); j++ {
+				loss += lambda / 2 * w[j] * w[j]
+			}
+			return loss
+		},
+		Grad: func(grad, w []float64) {
+			for j := range grad {
+				grad[j] = 0
+			}
+			for i := range z {
+				r := sigmoid(dot(w, z[i])) - label(y[i])
+				grad[0] += r
+				for j, v := range z[i] {
+					grad[j+1] += r * v
+				}
+			}
+			for j := range grad {
+				grad[j] /= float64(n)
+				if j > 0 {
+					grad[j] += lambda * w[j]
+				}
+			}
+		},
+	}
+	// A line-search failure close to the optimum still leaves a usable res.X.
+	// 接近最优点时的线搜索失败仍返回可用的 res.X。
+	res, err := optimize.Minimize(problem, make([]float64, d+1), nil, &optimize.LBFGS{})
+	if res == nil {
+		return LogisticModel{}, err
+	}
+	m.Intercept = res.X[0]
+	copy(m.Coefficients, res.X[1:])
+	return m, nil
+}
+
+// Predict returns the probability of a positive outcome for the raw features x.
+// Predict 返回原始特征 x 对应的正类概率。
+func (m LogisticModel) Predict(x []float64) float64 {
+	w := append([]float64{m.Intercept}, m.Coefficients...)
+	return sigmoid(dot(w, m.scale(x)))
+}
+
+// scale standardizes x; features with zero Std map to zero.
+func (m LogisticModel) scale(x []float64) []float64 {
+	out := make([]float64, len(x))
+	for j, v := range x {
+		if m.Std[j] > 0 {
+			out[j] = (v - m.Mean[j]) / m.Std[j]
+		}
+	}
+	return out
+}
+
+// AUC is the area under the ROC curve of scores against labels; ok is false
+// when either class is missing.
+// AUC 为 scores 相对 labels 的 ROC 曲线下面积；任一类别缺失时 ok 为 false。
+func AUC(scores []float64, labels []bool) (float64, bool) {
+	pos := 0
+	for _, l := range labels {
+		if l {
+			pos++
+		}
+	}
+	if len(scores) != len(labels) || pos == 0 || pos == len(labels) {
+		return 0, false
+	}
+	y := append([]float64(nil), scores...)
+	classes := append([]bool(nil), labels...)
+	stat.SortWeightedLabeled(y, classes, nil)
+	tpr, fpr, _ := stat.ROC(nil, y, classes, nil)
+	return integrate.Trapezoidal(fpr, tpr), true
+}
+
+// dot is w[0] + w[1:]·z.
+func dot(w, z []float64) float64 {
+	s := w[0]
+	for j, v := range z {
+		s += w[j+1] * v
+	}
+	return s
+}
+
+func sigmoid(t float64) float64 {
+	return 1 / (1 + math.Exp(-t))
+}
+
+// logLoss is the numerically stable negative log-likelihood of y at logit t.
+func logLoss(t float64, y bool) float64 {
+	if !y {
+		t = -t
+	}
+	if t > 0 {
+		return math.Log1p(math.Exp(-t))
+	}
+	return -t + math.Log1p(math.Exp(t))
+}
+
+func label(y bool) float64 {
+	if y {
+		return 1
+	}
+	return 0
+}
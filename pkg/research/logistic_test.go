@@ -0,0 +1,44 @@
+package research
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFitLogisticRecoversSigns(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	x := make([][]float64, 2000)
+	y := make([]bool, len(x))
+	for i := range x {
+		a, b, c := rng.NormFloat64(), rng.NormFloat64(), 5.0
+		x[i] = []float64{a, b, c}
+		y[i] = rng.Float64() < sigmoid(0.5+2*a-1*b)
+	}
+	m, err := FitLogistic([]string{"a", "b", "const"}, x, y, 0.001, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(m.Coefficients[0]-2) > 0.3 || math.Abs(m.Coefficients[1]+1) > 0.3 || m.Coefficients[2] != 0 {
+		t.Fatalf("unexpected coefficients: %+v", m)
+	}
+	scores := make([]float64, len(x))
+	for i := range x {
+		scores[i] = m.Predict(x[i])
+	}
+	if auc, ok := AUC(scores, y); !ok || auc < 0.8 {
+		t.Fatalf("expected a high in-sample AUC, got %.3f ok=%v", auc, ok)
+	}
+}
+
+func TestAUC(t *testing.T) {
+	if auc, ok := AUC([]float64{0.1, 0.4, 0.35, 0.8}, []bool{false, false, true, true}); !ok || math.Abs(auc-0.75) > 1e-12 {
+		t.Fatalf("expected AUC 0.75, got %.4f", auc)
+	}
+	if auc, _ := AUC([]float64{0.9, 0.1}, []bool{true, false}); auc != 1 {
+		t.Fatalf("expected a perfect AUC, got %.4f", auc)
+	}
+	if _, ok := AUC([]float64{0.1, 0.2}, []bool{true, true}); ok {
+		t.Fatal("AUC needs both classes")
+	}
+}
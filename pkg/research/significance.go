@@ -0,0 +1,371 @@
+package research
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/LEVI-Tempest/Candle/pkg/identify"
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+	"github.com/LEVI-Tempest/Candle/pkg/signal"
+	"gonum.org/v1/gonum/stat"
+)
+
+// SignificanceConfig controls the bootstrap tests.
+// SignificanceConfig 控制自助法检验。
+type SignificanceConfig struct {
+	Bootstrap int     `json:"bootstrap"` // Resamples (重抽样次数，默认 2000)
+	Alpha     float64 `json:"alpha"`     // FDR / family-wise level (默认 0.05)
+	Seed      int64   `json:"seed"`
+	// MinSamples is the fewest returns a pattern needs to be tested (default 10).
+	// MinSamples 为形态参与检验所需的最少收益样本数（默认 10）。
+	MinSamples int `json:"min_samples"`
+}
+
+// DefaultSignificanceConfig returns 2000 resamples at the 5% level.
+// DefaultSignificanceConfig 返回 2000 次重抽样、5% 显著性水平的默认配置。
+func DefaultSignificanceConfig() SignificanceConfig {
+	return SignificanceConfig{Bootstrap: 2000, Alpha: 0.05, Seed: 1, MinSamples: 10}
+}
+
+func normalizeSignificanceConfig(cfg SignificanceConfig) SignificanceConfig {
+	def := DefaultSignificanceConfig()
+	if cfg.Bootstrap < 100 {
+		cfg.Bootstrap = def.Bootstrap
+	}
+	if cfg.Alpha <= 0 || cfg.Alpha >= 1 {
+		cfg.Alpha = def.Alpha
+	}
+	if cfg.MinSamples < 2 {
+		cfg.MinSamples = def.MinSamples
+	}
+	return cfg
+}
+
+// PatternReturns are the direction-signed forward returns of one pattern type.
+// PatternReturns 为某一形态类型的方向化前瞻收益。
+type PatternReturns struct {
+	Pattern   string    `json:"pattern"`
+	Direction string    `json:"direction"`
+	Returns   []float64 `json:"returns"`
+}
+
+// PatternSignificance is the test outcome of one pattern.
+// PatternSignificance 为单个形态的检验结果。
+type PatternSignificance struct {
+	Pattern string  `json:"pattern"`
+	N       int     `json:"n"`
+	Mean    float64 `json:"mean"`
+	// Benchmark is the mean direction-signed return of a random entry.
+	// Benchmark 为随机入场的方向化平均收益。
+	Benchmark float64 `json:"benchmark"`
+	Excess    float64 `json:"excess"`
+	TStat     float64 `json:"t_stat"` // Studentized excess (超额收益 t 统计量)
+	// PValue is the one-sided share of random-entry bootstrap means at least Mean.
+	// PValue 为随机入场自助均值不低于 Mean 的单侧比例。
+	PValue float64 `json:"p_value"`
+	QValue float64 `json:"q_value"` // Benjamini-Hochberg adjusted (BH 校正后)
+	// SurvivesFDR: QValue <= Alpha. SurvivesStepM: rejected by the
+	// studentized Romano-Wolf step-down Reality Check at Alpha.
+	// SurvivesFDR：QValue <= Alpha；SurvivesStepM：在 Alpha 水平下被学生化 Romano-Wolf 逐步 Reality Check 拒绝原假设。
+	SurvivesFDR   bool `json:"survives_fdr"`
+	SurvivesStepM bool `json:"survives_stepm"`
+}
+
+// SignificanceResult covers every pattern tested together.
+// SignificanceResult 汇总同时检验的所有形态。
+type SignificanceResult struct {
+	Tested    int                   `json:"tested"`
+	Bootstrap int                   `json:"bootstrap"`
+	Alpha     float64               `json:"alpha"`
+	Patterns  []PatternSignificance `json:"patterns"`
+	// RealityCheckP is White's p-value that the best pattern has no edge over
+	// random entry; SPAP is Hansen's studentized, recentered variant.
+	// RealityCheckP 为 White Reality Check 中“最优形态相对随机入场无优势”的 p 值；SPAP 为 Hansen 学生化、再中心化的 SPA 版本。
+	RealityCheckP float64  `json:"reality_check_p"`
+	SPAP          float64  `json:"spa_p"`
+	Survivors     []string `json:"survivors"` // Surviving both FDR and StepM (同时通过 FDR 与 StepM)
+}
+
+// AssessSignificance compares each pattern's mean return with n-draw means
+// from the random-entry returns entries (raw, signed by the pattern
+// direction), corrects the p-values with Benjamini-Hochberg, and runs White's
+// Reality Check, Hansen's SPA and a Romano-Wolf step-down on the excess
+// returns with an i.i.d. bootstrap.
+// AssessSignificance 将各形态平均收益与从随机入场收益 entries（原始收益，按形态方向取符号）中抽取 n 次的均值分布比较，
+// 以 Benjamini-Hochberg 校正 p 值，并对超额收益以独立同分布自助法执行 White Reality Check、Hansen SPA 与 Romano-Wolf 逐步检验。
+func AssessSignificance(patterns []PatternReturns, entries []float64, cfg SignificanceConfig) (SignificanceResult, error) {
+	cfg = normalizeSignificanceConfig(cfg)
+	if len(entries) < 2 {
+		return SignificanceResult{}, fmt.Errorf("need random-entry returns, got %d", len(entries))
+	}
+	tested := make([]PatternReturns, 0, len(patterns))
+	for _, p := range patterns {
+		if len(p.Returns) >= cfg.MinSamples {
+			tested = append(tested, p)
+		}
+	}
+	if len(tested) == 0 {
+		return SignificanceResult{}, fmt.Errorf("no pattern has %d returns", cfg.MinSamples)
+	}
+	sort.Slice(tested, func(i, j int) bool { return tested[i].Pattern < tested[j].Pattern })
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	res := SignificanceResult{Tested: len(tested), Bootstrap: cfg.Bootstrap, Alpha: cfg.Alpha, Survivors: make([]string, 0)}
+
+	excess := make([][]float64, len(tested))
+	res.Patterns = make([]PatternSignificance, len(tested))
+	for k, p := range tested {
+		sign := 1.0
+		if p.Direction == "bearish" {
+			sign = -1
+		}
+		pool := make([]float64, len(entries))
+		for i, r := range entries {
+			pool[i] = sign * r
+		}
+		ps := PatternSignificance{Pattern: p.Pattern, N: len(p.Returns), Mean: stat.Mean(p.Returns, nil), Benchmark: stat.Mean(pool, nil)}
+		ps.Excess = ps.Mean - ps.Benchmark
+		excess[k] = make([]float64, len(p.Returns))
+		for i, r := range p.Returns {
+			excess[k][i] = r - ps.Benchmark
+		}
+		if sd := stat.StdDev(excess[k], nil); sd > 0 {
+			ps.TStat = ps.Excess / (sd / math.Sqrt(float64(ps.N)))
+		}
+		atLeast := 0
+		for b := 0; b < cfg.Bootstrap; b++ {
+			if resampleMean(rng, pool, ps.N) >= ps.Mean {
+				atLeast++
+			}
+		}
+		ps.PValue = float64(atLeast+1) / float64(cfg.Bootstrap+1)
+		res.Patterns[k] = ps
+	}
+
+	pvals := make([]float64, len(res.Patterns))
+	for k, p := range res.Patterns {
+		pvals[k] = p.PValue
+	}
+	for k, q := range benjaminiHochberg(pvals) {
+		res.Patterns[k].QValue = q
+		res.Patterns[k].SurvivesFDR = q <= cfg.Alpha
+	}
+
+	res.RealityCheckP, res.SPAP = realityCheck(rng, excess, cfg.Bootstrap)
+	for k, ok := range stepM(rng, excess, cfg.Bootstrap, cfg.Alpha) {
+		res.Patterns[k].SurvivesStepM = ok
+	}
+	sort.SliceStable(res.Patterns, func(i, j int) bool { return res.Patterns[i].PValue < res.Patterns[j].PValue })
+	for _, p := range res.Patterns {
+		if p.SurvivesFDR && p.SurvivesStepM {
+			res.Survivors = append(res.Survivors, p.Pattern)
+		}
+	}
+	return res, nil
+}
+
+// HistoryReturns builds per-pattern returns from the samples of every symbol
+// in history and the random-entry pool of every bar with horizon bars after it.
+// HistoryReturns 由 history 中各标的的样本构建各形态收益，并以每根其后有 horizon 根K线的K线构建随机入场收益池。
+func HistoryReturns(history map[string][]*v1.Candlestick, cfg signal.Config, horizon int) ([]PatternReturns, []float64) {
+	symbols := make([]string, 0, len(history))
+	for s := range history {
+		symbols = append(symbols, s)
+	}
+	sort.Strings(symbols)
+	samples := make([]signal.TrainingSample, 0)
+	entries := make([]float64, 0)
+	for _, s := range symbols {
+		candles := history[s]
+		samples = append(samples, signal.BuildTrainingSamples(s, candles, cfg, horizon)...)
+		for i := 0; i+horizon < len(candles); i++ {
+			if entry := candles[i].Close; entry > 0 {
+				entries = append(entries, (candles[i+horizon].Close-entry)/entry*100)
+			}
+		}
+	}
+	return groupReturns(samples), entries
+}
+
+// LogReturns builds per-pattern returns from signal log rows. The log has no
+// bar data, so the random-entry pool is every logged return; exact duplicate
+// rows from repeated runs are counted once.
+// LogReturns 由信号日志行构建各形态收益；日志不含逐K线数据，随机入场收益池取全部已记录收益，重复运行产生的完全相同的行只计一次。
+func LogReturns(rows []signal.SignalLogRow, horizon int) ([]PatternReturns, []float64) {
+	seen := make(map[string]bool)
+	samples := make([]signal.TrainingSample, 0)
+	entries := make([]float64, 0)
+	for _, r := range rows {
+		ret := r.ForwardReturn(horizon)
+		if ret == nil {
+			continue
+		}
+		key := fmt.Sprintf("%s|%s|%.2f|%v|%v|%v", r.Symbol, r.Pattern, r.DecisionScore, fmtPtr(r.ForwardRet3), fmtPtr(r.ForwardRet5), fmtPtr(r.ForwardRet10))
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		entries = append(entries, *ret)
+		direction := identify.PatternDirection(r.Pattern)
+		switch direction {
+		case "bullish":
+			samples = append(samples, signal.TrainingSample{Type: r.Pattern, Direction: direction, Return: *ret})
+		case "bearish":
+			samples = append(samples, signal.TrainingSample{Type: r.Pattern, Direction: direction, Return: -*ret})
+		}
+	}
+	return groupReturns(samples), entries
+}
+
+func groupReturns(samples []signal.TrainingSample) []PatternReturns {
+	byType := make(map[string]*PatternReturns)
+	order := make([]string, 0)
+	for _, s := range samples {
+		p, ok := byType[s.Type]
+		if !ok {
+			p = &PatternReturns{Pattern: s.Type, Direction: s.Direction}
+			byType[s.Type] = p
+			order = append(order, s.Type)
+		}
+		p.Returns = append(p.Returns, s.Return)
+	}
+	out := make([]PatternReturns, len(order))
+	for i, t := range order {
+		out[i] = *byType[t]
+	}
+	return out
+}
+
+// realityCheck returns White's Reality Check p-value on max_k √n_k·mean_k and
+// Hansen's SPA p-value on the studentized statistic, recentering only the
+// patterns not far below zero.
+func realityCheck(rng *rand.Rand, excess [][]float64, bootstrap int) (float64, float64) {
+	k := len(excess)
+	means, sds := make([]float64, k), make([]float64, k)
+	rcStat, spaStat := math.Inf(-1), 0.0
+	for j, d := range excess {
+		n := float64(len(d))
+		means[j], sds[j] = stat.MeanStdDev(d, nil)
+		rcStat = math.Max(rcStat, math.Sqrt(n)*means[j])
+		if sds[j] > 0 {
+			spaStat = math.Max(spaStat, math.Sqrt(n)*means[j]/sds[j])
+		}
+	}
+	rcHits, spaHits := 0, 0
+	boot := make([]float64, k)
+	for b := 0; b < bootstrap; b++ {
+		rcMax, spaMax := math.Inf(-1), 0.0
+		for j, d := range excess {
+			boot[j] = resampleMean(rng, d, len(d))
+			n := float64(len(d))
+			rcMax = math.Max(rcMax, math.Sqrt(n)*(boot[j]-means[j]))
+			if sds[j] > 0 {
+				// Hansen's threshold keeps clearly poor patterns from diluting the test.
+				// Hansen 阈值避免明显较差的形态稀释检验功效。
+				center := 0.0
+				if math.Sqrt(n)*means[j]/sds[j] >= -math.Sqrt(2*math.Log(math.Log(math.Max(n, 3)))) {
+					center = means[j]
+				}
+				spaMax = math.Max(spaMax, math.Sqrt(n)*(boot[j]-center)/sds[j])
+			}
+		}
+		if rcMax >= rcStat {
+			rcHits++
+		}
+		if spaMax >= spaStat {
+			spaHits++
+		}
+	}
+	return float64(rcHits+1) / float64(bootstrap+1), float64(spaHits+1) / float64(bootstrap+1)
+}
+
+// stepM runs the studentized Romano-Wolf step-down: reject every remaining
+// pattern whose t-statistic exceeds the (1-alpha) bootstrap quantile of the
+// remaining maximum, and repeat until nothing new is rejected.
+func stepM(rng *rand.Rand, excess [][]float64, bootstrap int, alpha float64) []bool {
+	k := len(excess)
+	means, sds, tstats := make([]float64, k), make([]float64, k), make([]float64, k)
+	for j, d := range excess {
+		means[j], sds[j] = stat.MeanStdDev(d, nil)
+		if sds[j] > 0 {
+			tstats[j] = math.Sqrt(float64(len(d))) * means[j] / sds[j]
+		}
+	}
+	// Centered, studentized bootstrap draws per pattern, reused across steps.
+	// 各形态中心化、学生化的自助样本，在各步之间复用。
+	draws := make([][]float64, bootstrap)
+	for b := range draws {
+		draws[b] = make([]float64, k)
+		for j, d := range excess {
+			if sds[j] > 0 {
+				draws[b][j] = math.Sqrt(float64(len(d))) * (resampleMean(rng, d, len(d)) - means[j]) / sds[j]
+			}
+		}
+	}
+	rejected := make([]bool, k)
+	for {
+		maxima := make([]float64, bootstrap)
+		for b := range draws {
+			maxima[b] = math.Inf(-1)
+			for j := range excess {
+				if !rejected[j] {
+					maxima[b] = math.Max(maxima[b], draws[b][j])
+				}
+			}
+		}
+		sort.Float64s(maxima)
+		crit := stat.Quantile(1-alpha, stat.Empirical, maxima, nil)
+		changed := false
+		for j := range excess {
+			if !rejected[j] && sds[j] > 0 && tstats[j] > crit {
+				rejected[j], changed = true, true
+			}
+		}
+		if !changed || allTrue(rejected) {
+			return rejected
+		}
+	}
+}
+
+// benjaminiHochberg returns the BH-adjusted q-values in input order.
+func benjaminiHochberg(p []float64) []float64 {
+	m := len(p)
+	idx := make([]int, m)
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return p[idx[a]] < p[idx[b]] })
+	q := make([]float64, m)
+	running := 1.0
+	for r := m - 1; r >= 0; r-- {
+		i := idx[r]
+		running = math.Min(running, p[i]*float64(m)/float64(r+1))
+		q[i] = running
+	}
+	return q
+}
+
+func resampleMean(rng *rand.Rand, pool []float64, n int) float64 {
+	sum := 0.0
+	for i := 0; i < n; i++ {
+		sum += pool[rng.Intn(len(pool))]
+	}
+	return sum / float64(n)
+}
+
+func allTrue(b []bool) bool {
+	for _, v := range b {
+		if !v {
+			return false
+		}
+	}
+	return true
+}
+
+func fmtPtr(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.4f", *v)
+}
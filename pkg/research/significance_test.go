@@ -0,0 +1,126 @@
+package research
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/LEVI-Tempest/Candle/pkg/signal"
+)
+
+func TestBenjaminiHochberg(t *testing.T) {
+	// Classic example: q_i = min_{j>=i} p_(j)·m/j, in input order.
+	got := benjaminiHochberg([]float64{0.04, 0.01, 0.03, 0.5})
+	want := []float64{0.04 * 4 / 3, 0.04, 0.04 * 4 / 3, 0.5}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-12 {
+			t.Fatalf("q[%d] = %.6f, want %.6f (all %v)", i, got[i], want[i], got)
+		}
+	}
+}
+
+// noisyPatterns returns k pattern return sets drawn from the same
+// distribution as the random entries, plus one with a planted edge.
+func noisyPatterns(rng *rand.Rand, k int, edge float64) ([]PatternReturns, []float64) {
+	entries := make([]float64, 5000)
+	for i := range entries {
+		entries[i] = rng.NormFloat64() * 2
+	}
+	patterns := make([]PatternReturns, 0, k+1)
+	for j := 0; j < k; j++ {
+		p := PatternReturns{Pattern: string(rune('A' + j)), Direction: "bullish", Returns: make([]float64, 80)}
+		for i := range p.Returns {
+			p.Returns[i] = rng.NormFloat64() * 2
+		}
+		patterns = append(patterns, p)
+	}
+	if edge != 0 {
+		p := PatternReturns{Pattern: "Edge", Direction: "bullish", Returns: make([]float64, 80)}
+		for i := range p.Returns {
+			p.Returns[i] = edge + rng.NormFloat64()*2
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, entries
+}
+
+func TestAssessSignificanceFindsPlantedEdge(t *testing.T) {
+	patterns, entries := noisyPatterns(rand.New(rand.NewSource(5)), 12, 1.5)
+	res, err := AssessSignificance(patterns, entries, SignificanceConfig{Bootstrap: 1000, Seed: 2})
+	if err != nil {
+		t.Fatalf("assess: %v", err)
+	}
+	if res.Tested != 13 || res.Patterns[0].Pattern != "Edge" {
+		t.Fatalf("edge pattern should rank first: %+v", res.Patterns)
+	}
+	if len(res.Survivors) != 1 || res.Survivors[0] != "Edge" {
+		t.Fatalf("only the edge should survive, got %v", res.Survivors)
+	}
+	if res.RealityCheckP > 0.01 || res.SPAP > 0.01 {
+		t.Fatalf("reality check should reject the null: rc=%.4f spa=%.4f", res.RealityCheckP, res.SPAP)
+	}
+}
+
+func TestAssessSignificanceNull(t *testing.T) {
+	patterns, entries := noisyPatterns(rand.New(rand.NewSource(7)), 12, 0)
+	res, err := AssessSignificance(patterns, entries, SignificanceConfig{Bootstrap: 1000, Seed: 2})
+	if err != nil {
+		t.Fatalf("assess: %v", err)
+	}
+	if len(res.Survivors) != 0 {
+		t.Fatalf("noise should not survive correction, got %v", res.Survivors)
+	}
+	if res.RealityCheckP < 0.1 || res.SPAP < 0.1 {
+		t.Fatalf("best-of-12 noise should not pass the reality check: rc=%.4f spa=%.4f", res.RealityCheckP, res.SPAP)
+	}
+}
+
+func TestAssessSignificanceBearishBenchmark(t *testing.T) {
+	// In a rising market a bearish pattern is judged against short random entries.
+	// 上涨行情中看跌形态以随机做空入场为基准。
+	entries := []float64{1, 2, 3, 1, 2, 3}
+	patterns := []PatternReturns{{Pattern: "Bearish", Direction: "bearish", Returns: []float64{-1, -2, -1, -2, -1, -2, -1, -2, -1, -2}}}
+	res, err := AssessSignificance(patterns, entries, DefaultSignificanceConfig())
+	if err != nil {
+		t.Fatalf("assess: %v", err)
+	}
+	p := res.Patterns[0]
+	if p.Benchmark != -2 || math.Abs(p.Excess-0.5) > 1e-12 {
+		t.Fatalf("benchmark should be the sign-flipped entry mean: %+v", p)
+	}
+	if _, err := AssessSignificance(patterns, entries, SignificanceConfig{MinSamples: 20}); err == nil {
+		t.Fatal("expected an error when no pattern has enough returns")
+	}
+}
+
+func TestLogReturns(t *testing.T) {
+	r := func(v float64) *float64 { return &v }
+	rows := []signal.SignalLogRow{
+		{Time: "t1", Symbol: "A", Pattern: "Hammer", DecisionScore: 60, ForwardRet5: r(2)},
+		{Time: "t2", Symbol: "A", Pattern: "Hammer", DecisionScore: 60, ForwardRet5: r(2)}, // Rerun of the same signal
+		{Time: "t1", Symbol: "A", Pattern: "Hanging Man", DecisionScore: 55, ForwardRet5: r(1.5)},
+		{Time: "t1", Symbol: "A", Pattern: "Doji", DecisionScore: 30, ForwardRet5: r(-1)},
+		{Time: "t1", Symbol: "B", Pattern: "Hammer", DecisionScore: 70},
+	}
+	patterns, entries := LogReturns(rows, 5)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 distinct logged returns, got %v", entries)
+	}
+	if len(patterns) != 2 {
+		t.Fatalf("expected the two directional patterns, got %+v", patterns)
+	}
+	for _, p := range patterns {
+		switch p.Pattern {
+		case "Hammer":
+			if len(p.Returns) != 1 || p.Returns[0] != 2 {
+				t.Fatalf("unexpected hammer returns: %+v", p)
+			}
+		case "Hanging Man":
+			if p.Direction != "bearish" || p.Returns[0] != -1.5 {
+				t.Fatalf("bearish returns should be sign-flipped: %+v", p)
+			}
+		default:
+			t.Fatalf("unexpected pattern %+v", p)
+		}
+	}
+}
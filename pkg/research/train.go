@@ -0,0 +1,288 @@
+package research
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/LEVI-Tempest/Candle/pkg/signal"
+)
+
+// Pattern family groupings
+// 形态族分组方式
+const (
+	FamilyDirection = "direction" // bullish / bearish (按方向)
+	FamilyType      = "type"      // One family per pattern type (按形态类型)
+)
+
+// TrainConfig controls weight learning.
+// TrainConfig 控制权重学习。
+type TrainConfig struct {
+	// Lambda is the L2 penalty on standardized coefficients (default 0.1).
+	// Lambda 为标准化系数上的 L2 惩罚（默认 0.1）。
+	Lambda float64 `json:"lambda"`
+	// TestFraction is the latest share of samples held out for AUC (default 0.3).
+	// TestFraction 为按时间留出用于计算 AUC 的最新样本比例（默认 0.3）。
+	TestFraction float64 `json:"test_fraction"`
+	Family       string  `json:"family"` // direction/type
+	// MinSamples is the fewest training samples a family needs to be fitted (default 30).
+	// MinSamples 为形态族参与拟合所需的最少训练样本数（默认 30）。
+	MinSamples int `json:"min_samples"`
+}
+
+// DefaultTrainConfig returns lambda 0.1, a 30% chronological hold-out and
+// direction families.
+// DefaultTrainConfig 返回 lambda 0.1、按时间留出 30% 测试集、按方向分族的默认配置。
+func DefaultTrainConfig() TrainConfig {
+	return TrainConfig{Lambda: 0.1, TestFraction: 0.3, Family: FamilyDirection, MinSamples: 30}
+}
+
+func normalizeTrainConfig(cfg TrainConfig) TrainConfig {
+	def := DefaultTrainConfig()
+	if cfg.Lambda <= 0 {
+		cfg.Lambda = def.Lambda
+	}
+	if cfg.TestFraction <= 0 || cfg.TestFraction >= 1 {
+		cfg.TestFraction = def.TestFraction
+	}
+	if cfg.Family != FamilyType {
+		cfg.Family = FamilyDirection
+	}
+	if cfg.MinSamples < 1 {
+		cfg.MinSamples = def.MinSamples
+	}
+	return cfg
+}
+
+// FamilyModel is the logistic model of one pattern family over all features.
+// FamilyModel 为某一形态族基于全部特征的逻辑回归模型。
+type FamilyModel struct {
+	Family  string  `json:"family"`
+	Train   int     `json:"train"`
+	Test    int     `json:"test"`
+	HitRate float64 `json:"hit_rate"` // Training hit rate (训练集胜率)
+	// AUC is out-of-sample; nil when the test set lacks either outcome.
+	// AUC 为样本外结果；测试集缺少任一类别时为 nil。
+	AUC   *float64      `json:"auc,omitempty"`
+	Model LogisticModel `json:"model"`
+}
+
+// WeightModel turns the unstandardized coefficients of a logistic fit on
+// score components into non-negative weights.
+// WeightModel 将评分分量上逻辑回归的（未标准化）系数转换为非负权重。
+type WeightModel struct {
+	Features []string  `json:"features"`
+	Weights  []float64 `json:"weights"`
+	AUC      *float64  `json:"auc,omitempty"`
+	// Learned is false when no component had a positive coefficient; the
+	// configured weights are then kept.
+	// Learned 为 false 表示没有分量系数为正，此时保留原配置权重。
+	Learned bool          `json:"learned"`
+	Model   LogisticModel `json:"model"`
+}
+
+// TrainResult is the outcome of TrainWeights.
+// TrainResult 为 TrainWeights 的结果。
+type TrainResult struct {
+	Samples  int           `json:"samples"`
+	Train    int           `json:"train"`
+	Test     int           `json:"test"`
+	Families []FamilyModel `json:"families"`
+	// Evidence weighs base/context/volume scores (EvidenceConfig, sums to 1).
+	// Evidence 为基础/上下文/量能得分权重（EvidenceConfig，和为 1）。
+	Evidence WeightModel `json:"evidence"`
+	// Score weighs pattern/trend/volume scores (ScoreConfig, sums to 100).
+	// Score 为形态/趋势/量能得分权重（ScoreConfig，和为 100）。
+	Score WeightModel `json:"score"`
+}
+
+var (
+	evidenceFeatures = []string{signal.FeatureBaseStrength, signal.FeatureContextScore, signal.FeatureVolumeScore}
+	scoreFeatures    = []string{signal.FeatureBaseStrength, signal.FeatureTrendScore, signal.FeatureVolumeState}
+)
+
+// TrainWeights fits one logistic model per pattern family on all features
+// plus the evidence and score weight models, training on the earliest
+// samples and measuring AUC on the latest TestFraction.
+// TrainWeights 为每个形态族基于全部特征拟合逻辑回归，并拟合证据与评分权重模型；
+// 以较早样本训练，在最新 TestFraction 比例样本上计算 AUC。
+func TrainWeights(samples []signal.TrainingSample, cfg TrainConfig) (TrainResult, error) {
+	cfg = normalizeTrainConfig(cfg)
+	train, test := splitByTime(samples, cfg.TestFraction)
+	if len(train) < cfg.MinSamples {
+		return TrainResult{}, fmt.Errorf("need at least %d training samples, got %d", cfg.MinSamples, len(train))
+	}
+	res := TrainResult{Samples: len(samples), Train: len(train), Test: len(test), Families: make([]FamilyModel, 0)}
+
+	var err error
+	if res.Evidence, err = fitWeights(evidenceFeatures, train, test, cfg.Lambda, 1); err != nil {
+		return TrainResult{}, err
+	}
+	if res.Score, err = fitWeights(scoreFeatures, train, test, cfg.Lambda, 100); err != nil {
+		return TrainResult{}, err
+	}
+
+	trainBy, testBy := groupByFamily(train, cfg.Family), groupByFamily(test, cfg.Family)
+	families := make([]string, 0, len(trainBy))
+	for f := range trainBy {
+		families = append(families, f)
+	}
+	sort.Strings(families)
+	for _, f := range families {
+		tr := trainBy[f]
+		if len(tr) < cfg.MinSamples {
+			continue
+		}
+		features := featureNames(tr)
+		x, y := design(features, tr)
+		m, err := FitLogistic(features, x, y, cfg.Lambda, true)
+		if err != nil {
+			return TrainResult{}, fmt.Errorf("family %s: %w", f, err)
+		}
+		fm := FamilyModel{Family: f, Train: len(tr), Test: len(testBy[f]), HitRate: hitRate(y), Model: m}
+		fm.AUC = testAUC(m, testBy[f])
+		res.Families = append(res.Families, fm)
+	}
+	return res, nil
+}
+
+// Apply writes the learned weights into cfg.
+// Apply 将学习到的权重写入 cfg。
+func (r TrainResult) Apply(cfg signal.Config) signal.Config {
+	if w := r.Evidence.Weights; r.Evidence.Learned {
+		cfg.Evidence.BaseWeight, cfg.Evidence.ContextWeight, cfg.Evidence.VolumeWeight = w[0], w[1], w[2]
+	}
+	if w := r.Score.Weights; r.Score.Learned {
+		cfg.Score.PatternWeight, cfg.Score.TrendWeight, cfg.Score.VolumeWeight = w[0], w[1], w[2]
+	}
+	return cfg
+}
+
+// fitWeights fits the unstandardized components and scales the positive
+// coefficients to sum to total. Every weight is kept at 1% of total or more,
+// since LoadConfig treats a zero weight as unset.
+func fitWeights(features []string, train, test []signal.TrainingSample, lambda, total float64) (WeightModel, error) {
+	x, y := design(features, train)
+	m, err := FitLogistic(features, x, y, lambda, false)
+	if err != nil {
+		return WeightModel{}, err
+	}
+	wm := WeightModel{Features: features, Weights: make([]float64, len(features)), Model: m, AUC: testAUC(m, test)}
+	sum := 0.0
+	for _, c := range m.Coefficients {
+		sum += math.Max(c, 0)
+	}
+	if sum <= 0 {
+		return wm, nil
+	}
+	wm.Learned = true
+	floor := 0.01 * total
+	rest := total
+	free := 0.0
+	for i, c := range m.Coefficients {
+		if w := math.Max(c, 0) / sum * total; w < floor {
+			wm.Weights[i] = floor
+			rest -= floor
+		} else {
+			free += w
+		}
+	}
+	for i, c := range m.Coefficients {
+		if wm.Weights[i] == 0 {
+			wm.Weights[i] = math.Max(c, 0) / sum * total / free * rest
+		}
+	}
+	roundWeights(wm.Weights, total)
+	return wm, nil
+}
+
+// roundWeights rounds to 1/100 of total and moves the rounding error onto
+// the largest weight so the sum stays exact.
+func roundWeights(w []float64, total float64) {
+	unit := total / 100
+	largest, sum := 0, 0.0
+	for i := range w {
+		w[i] = math.Round(w[i]/unit) * unit
+		sum += w[i]
+		if w[i] > w[largest] {
+			largest = i
+		}
+	}
+	w[largest] += total - sum
+}
+
+// splitByTime orders samples by time and holds out the latest fraction.
+func splitByTime(samples []signal.TrainingSample, fraction float64) ([]signal.TrainingSample, []signal.TrainingSample) {
+	sorted := append([]signal.TrainingSample(nil), samples...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time < sorted[j].Time })
+	cut := len(sorted) - int(math.Round(float64(len(sorted))*fraction))
+	return sorted[:cut], sorted[cut:]
+}
+
+func groupByFamily(samples []signal.TrainingSample, family string) map[string][]signal.TrainingSample {
+	out := make(map[string][]signal.TrainingSample)
+	for _, s := range samples {
+		key := s.Direction
+		if family == FamilyType {
+			key = s.Type
+		}
+		out[key] = append(out[key], s)
+	}
+	return out
+}
+
+// featureNames is the sorted union of the samples' feature names.
+func featureNames(samples []signal.TrainingSample) []string {
+	seen := make(map[string]bool)
+	out := make([]string, 0)
+	for _, s := range samples {
+		for name := range s.Features {
+			if !seen[name] {
+				seen[name] = true
+				out = append(out, name)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// design builds the feature matrix; missing features are zero.
+func design(features []string, samples []signal.TrainingSample) ([][]float64, []bool) {
+	x := make([][]float64, len(samples))
+	y := make([]bool, len(samples))
+	for i, s := range samples {
+		x[i] = make([]float64, len(features))
+		for j, name := range features {
+			x[i][j] = s.Features[name]
+		}
+		y[i] = s.Hit
+	}
+	return x, y
+}
+
+func testAUC(m LogisticModel, test []signal.TrainingSample) *float64 {
+	x, y := design(m.Features, test)
+	scores := make([]float64, len(x))
+	for i := range x {
+		scores[i] = m.Predict(x[i])
+	}
+	auc, ok := AUC(scores, y)
+	if !ok {
+		return nil
+	}
+	return &auc
+}
+
+func hitRate(y []bool) float64 {
+	if len(y) == 0 {
+		return 0
+	}
+	hits := 0
+	for _, v := range y {
+		if v {
+			hits++
+		}
+	}
+	return float64(hits) / float64(len(y))
+}
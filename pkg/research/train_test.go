@@ -0,0 +1,95 @@
+package research
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/LEVI-Tempest/Candle/pkg/signal"
+)
+
+// volumeDrivenSamples returns samples whose hit probability rises with the
+// volume score only; the other components are noise.
+func volumeDrivenSamples(n int) []signal.TrainingSample {
+	rng := rand.New(rand.NewSource(3))
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	out := make([]signal.TrainingSample, n)
+	for i := range out {
+		vol, state := rng.Float64(), float64(rng.Intn(3))/2
+		direction, typ := "bullish", "Hammer"
+		if i%2 == 1 {
+			direction, typ = "bearish", "Shooting Star"
+		}
+		hit := rng.Float64() < 1/(1+math.Exp(-(6*vol+4*state-5)))
+		out[i] = signal.TrainingSample{
+			Symbol:    "S",
+			Type:      typ,
+			Direction: direction,
+			Time:      base.Add(time.Duration(i) * time.Hour).Format("2006-01-02 15:04:05"),
+			Features: map[string]float64{
+				signal.FeatureBaseStrength:  rng.Float64(),
+				signal.FeatureContextScore:  rng.Float64(),
+				signal.FeatureVolumeScore:   vol,
+				signal.FeatureTrendScore:    rng.Float64(),
+				signal.FeatureVolumeState:   state,
+				"volume:" + fmt.Sprint(i%3): 1,
+			},
+			Hit: hit,
+		}
+	}
+	return out
+}
+
+func TestTrainWeightsLearnsVolume(t *testing.T) {
+	res, err := TrainWeights(volumeDrivenSamples(1200), DefaultTrainConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Train != 840 || res.Test != 360 {
+		t.Fatalf("expected a 70/30 split, got %d/%d", res.Train, res.Test)
+	}
+	ev := res.Evidence
+	if !ev.Learned || ev.Weights[2] < 0.8 || ev.Weights[0] < 0.01 || ev.AUC == nil || *ev.AUC < 0.7 {
+		t.Fatalf("expected volume to dominate evidence weights: %+v auc=%v", ev.Weights, ev.AUC)
+	}
+	if sum := ev.Weights[0] + ev.Weights[1] + ev.Weights[2]; math.Abs(sum-1) > 1e-9 {
+		t.Fatalf("evidence weights should sum to 1, got %v", sum)
+	}
+	sc := res.Score
+	if !sc.Learned || sc.Weights[2] < 60 || sc.Weights[0]+sc.Weights[1]+sc.Weights[2] != 100 {
+		t.Fatalf("expected volume state to dominate score weights: %+v", sc.Weights)
+	}
+	if len(res.Families) != 2 || res.Families[0].Family != "bearish" || res.Families[0].AUC == nil {
+		t.Fatalf("expected bearish and bullish family models: %+v", res.Families)
+	}
+
+	// The learned config round-trips through LoadConfig.
+	// 学习得到的配置可经 LoadConfig 读回。
+	cfg := res.Apply(signal.DefaultConfig())
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "learned.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := signal.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("learned config should load: %v", err)
+	}
+	if loaded.Evidence.VolumeWeight != ev.Weights[2] || loaded.Score.VolumeWeight != sc.Weights[2] {
+		t.Fatalf("learned weights lost on reload: %+v %+v", loaded.Evidence, loaded.Score)
+	}
+}
+
+func TestTrainWeightsNeedsSamples(t *testing.T) {
+	if _, err := TrainWeights(volumeDrivenSamples(20), DefaultTrainConfig()); err == nil {
+		t.Fatal("expected an error with too few samples")
+	}
+}
@@ -0,0 +1,469 @@
+package research
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+	"github.com/LEVI-Tempest/Candle/pkg/signal"
+	"gonum.org/v1/gonum/stat"
+)
+
+// Parameter search strategies
+// 参数搜索方式
+const (
+	SearchGrid   = "grid"   // Every combination (全组合网格)
+	SearchRandom = "random" // Samples combinations drawn without replacement (无放回随机抽样)
+)
+
+// Walk-forward objectives on direction-signed forward returns
+// 基于方向化前瞻收益的优化目标
+const (
+	ObjectiveMeanReturn = "mean_return"
+	ObjectiveHitRate    = "hit_rate"
+	ObjectiveSharpe     = "sharpe" // Mean over standard deviation per trade (单笔均值/标准差)
+)
+
+// WalkForwardConfig declares the parameter space and the fold layout.
+// Params keys are dotted signal.Config JSON paths, e.g. "trend.period" or
+// "evidence.beiliang_threshold". Bar counts refer to the union of candle
+// times across symbols.
+// WalkForwardConfig 声明参数空间与折叠划分；Params 的键为 signal.Config 的 JSON 路径（如 "trend.period"），
+// K线数量基于所有标的K线时间的并集。
+type WalkForwardConfig struct {
+	Params    map[string][]float64 `json:"params"`
+	Search    string               `json:"search"`  // grid/random
+	Samples   int                  `json:"samples"` // Random search draws (随机搜索次数，默认 20)
+	Seed      int64                `json:"seed"`
+	Objective string               `json:"objective"` // mean_return/hit_rate/sharpe
+	Horizon   int                  `json:"horizon"`   // Forward return horizon in bars (默认 5)
+	// MinLevel is the weakest decision level traded: weak/medium/strong (default medium).
+	// MinLevel 为参与统计的最低决策等级（默认 medium）。
+	MinLevel  string `json:"min_level"`
+	TrainBars int    `json:"train_bars"` // default 250
+	TestBars  int    `json:"test_bars"`  // default 60
+	StepBars  int    `json:"step_bars"`  // default TestBars
+	// HoldoutBars are the latest bars kept out of every fold for the final
+	// winner-vs-baseline check (default 60).
+	// HoldoutBars 为不参与任何折叠、用于最终胜出配置与基线对比的最新K线数量（默认 60）。
+	HoldoutBars int `json:"holdout_bars"`
+	// MinTrades is the fewest trades a window needs to be scored (default 10).
+	// MinTrades 为窗口参与评分所需的最少交易数（默认 10）。
+	MinTrades int `json:"min_trades"`
+}
+
+// DefaultWalkForwardConfig returns a grid search on mean return with
+// 250/60-bar folds, a 60-bar holdout and medium-or-better signals.
+// DefaultWalkForwardConfig 返回以平均收益为目标的网格搜索、250/60 根K线折叠、60 根留出、中等及以上信号的默认配置。
+func DefaultWalkForwardConfig() WalkForwardConfig {
+	return WalkForwardConfig{
+		Search:      SearchGrid,
+		Samples:     20,
+		Seed:        1,
+		Objective:   ObjectiveMeanReturn,
+		Horizon:     5,
+		MinLevel:    "medium",
+		TrainBars:   250,
+		TestBars:    60,
+		HoldoutBars: 60,
+		MinTrades:   10,
+	}
+}
+
+func normalizeWalkForwardConfig(cfg WalkForwardConfig) WalkForwardConfig {
+	def := DefaultWalkForwardConfig()
+	if cfg.Search != SearchRandom {
+		cfg.Search = SearchGrid
+	}
+	if cfg.Samples < 1 {
+		cfg.Samples = def.Samples
+	}
+	switch cfg.Objective {
+	case ObjectiveHitRate, ObjectiveSharpe:
+	default:
+		cfg.Objective = ObjectiveMeanReturn
+	}
+	if cfg.Horizon < 1 {
+		cfg.Horizon = def.Horizon
+	}
+	if levelRank(cfg.MinLevel) < 0 {
+		cfg.MinLevel = def.MinLevel
+	}
+	if cfg.TrainBars < 1 {
+		cfg.TrainBars = def.TrainBars
+	}
+	if cfg.TestBars < 1 {
+		cfg.TestBars = def.TestBars
+	}
+	if cfg.StepBars < 1 {
+		cfg.StepBars = cfg.TestBars
+	}
+	if cfg.HoldoutBars < 0 {
+		cfg.HoldoutBars = def.HoldoutBars
+	}
+	if cfg.MinTrades < 1 {
+		cfg.MinTrades = def.MinTrades
+	}
+	return cfg
+}
+
+// Evaluation is a configuration's objective over one window.
+// Evaluation 为某配置在一个窗口上的目标值。
+type Evaluation struct {
+	Score  float64 `json:"score"`
+	Trades int     `json:"trades"`
+}
+
+// FoldResult is one walk-forward step: the best candidate on the training
+// window and how it did on the following test window.
+// FoldResult 为一步前推：训练窗口上的最优候选及其在随后测试窗口上的表现。
+type FoldResult struct {
+	Fold      int                `json:"fold"`
+	TrainFrom string             `json:"train_from"`
+	TrainTo   string             `json:"train_to"`
+	TestFrom  string             `json:"test_from"`
+	TestTo    string             `json:"test_to"`
+	Best      map[string]float64 `json:"best,omitempty"` // Unset when no candidate had MinTrades (无候选满足最少交易数时为空)
+	Train     Evaluation         `json:"train"`
+	Test      Evaluation         `json:"test"`
+}
+
+// Stability summarizes the folds.
+// Stability 汇总各折叠的稳定性。
+type Stability struct {
+	Folds int `json:"folds"`
+	// WinnerShare is the share of folds that chose the winner.
+	// WinnerShare 为选中最终胜出配置的折叠占比。
+	WinnerShare float64 `json:"winner_share"`
+	// The fields below cover the folds whose test window had MinTrades.
+	// 以下字段仅统计测试窗口满足最少交易数的折叠。
+	MeanTrain float64 `json:"mean_train"`
+	MeanTest  float64 `json:"mean_test"`
+	StdTest   float64 `json:"std_test"`
+	// PositiveShare is the share of those folds with a positive test score.
+	// PositiveShare 为其中测试得分为正的折叠占比。
+	PositiveShare float64 `json:"positive_share"`
+	// Efficiency is MeanTest / MeanTrain; well below 1 suggests overfitting.
+	// Efficiency 为 MeanTest / MeanTrain，明显小于 1 提示过拟合。
+	Efficiency float64 `json:"efficiency"`
+}
+
+// HoldoutResult compares the winner with the baseline config on the holdout.
+// HoldoutResult 在留出区间上对比胜出配置与基线配置。
+type HoldoutResult struct {
+	From     string     `json:"from"`
+	To       string     `json:"to"`
+	Winner   Evaluation `json:"winner"`
+	Baseline Evaluation `json:"baseline"`
+}
+
+// WalkForwardResult is the outcome of WalkForward.
+// WalkForwardResult 为 WalkForward 的结果。
+type WalkForwardResult struct {
+	Objective  string             `json:"objective"`
+	Candidates int                `json:"candidates"`
+	Folds      []FoldResult       `json:"folds"`
+	Winner     map[string]float64 `json:"winner"`
+	Stability  Stability          `json:"stability"`
+	Holdout    *HoldoutResult     `json:"holdout,omitempty"`
+	// Accepted is true when the winner scored at least the baseline on the
+	// holdout with MinTrades, or when there is no holdout.
+	// Accepted 表示胜出配置在留出区间上（满足最少交易数）不差于基线；未设置留出区间时为 true。
+	Accepted bool `json:"accepted"`
+	// Config is the baseline with the winner applied.
+	// Config 为应用胜出参数后的基线配置。
+	Config signal.Config `json:"-"`
+}
+
+// WalkForward searches cfg.Params over rolling train/test folds across the
+// symbols in history (symbol -> chronological candles). Each fold picks the
+// best candidate on its training window; the winner is the candidate chosen
+// most often (ties by mean training score) and is checked against base on
+// the untouched holdout. Samples only count in a window when both entry and
+// exit lie inside it.
+// WalkForward 在 history（标的 -> 按时间排列的K线）上以滚动训练/测试折叠搜索 cfg.Params。每个折叠在训练窗口上选出最优候选；
+// 被选中次数最多者（并列时比较平均训练得分）为胜出配置，并在未参与的留出区间上与 base 对比。
+// 样本仅在入场与出场都位于窗口内时计入该窗口。
+func WalkForward(base signal.Config, history map[string][]*v1.Candlestick, cfg WalkForwardConfig) (WalkForwardResult, error) {
+	cfg = normalizeWalkForwardConfig(cfg)
+	candidates, err := parameterCandidates(cfg)
+	if err != nil {
+		return WalkForwardResult{}, err
+	}
+	configs := make([]signal.Config, len(candidates))
+	for i, params := range candidates {
+		if configs[i], err = ApplyParams(base, params); err != nil {
+			return WalkForwardResult{}, fmt.Errorf("%v: %w", params, err)
+		}
+	}
+
+	times := barTimes(history)
+	if len(times) < cfg.TrainBars+cfg.TestBars+cfg.HoldoutBars {
+		return WalkForwardResult{}, fmt.Errorf("need %d bars for one fold and the holdout, got %d",
+			cfg.TrainBars+cfg.TestBars+cfg.HoldoutBars, len(times))
+	}
+	samples := make([][]signal.TrainingSample, len(configs))
+	for i, c := range configs {
+		samples[i] = tradedSamples(history, c, cfg)
+	}
+
+	res := WalkForwardResult{Objective: cfg.Objective, Candidates: len(candidates), Folds: make([]FoldResult, 0)}
+	chosen := make([]int, len(candidates))
+	trainSum := make([]float64, len(candidates))
+	folded := len(times) - cfg.HoldoutBars
+	for start := 0; start+cfg.TrainBars+cfg.TestBars <= folded; start += cfg.StepBars {
+		trainFrom, trainTo := times[start], times[start+cfg.TrainBars-1]
+		testFrom, testTo := times[start+cfg.TrainBars], times[start+cfg.TrainBars+cfg.TestBars-1]
+		fold := FoldResult{Fold: len(res.Folds), TrainFrom: trainFrom, TrainTo: trainTo, TestFrom: testFrom, TestTo: testTo}
+		best := -1
+		for i := range candidates {
+			ev, ok := evaluateWindow(samples[i], trainFrom, trainTo, cfg)
+			if !ok {
+				continue
+			}
+			trainSum[i] += ev.Score
+			if best < 0 || ev.Score > fold.Train.Score {
+				best, fold.Train = i, ev
+			}
+		}
+		if best >= 0 {
+			chosen[best]++
+			fold.Best = candidates[best]
+			fold.Test, _ = evaluateWindow(samples[best], testFrom, testTo, cfg)
+		}
+		res.Folds = append(res.Folds, fold)
+	}
+
+	winner := 0
+	for i := range candidates {
+		if chosen[i] > chosen[winner] || (chosen[i] == chosen[winner] && trainSum[i] > trainSum[winner]) {
+			winner = i
+		}
+	}
+	res.Winner = candidates[winner]
+	res.Config = configs[winner]
+	res.Stability = foldStability(res.Folds, chosen[winner], cfg.MinTrades)
+
+	res.Accepted = true
+	if cfg.HoldoutBars > 0 {
+		from, to := times[folded], times[len(times)-1]
+		h := &HoldoutResult{From: from, To: to}
+		var ok bool
+		h.Winner, ok = evaluateWindow(samples[winner], from, to, cfg)
+		h.Baseline, _ = evaluateWindow(tradedSamples(history, base, cfg), from, to, cfg)
+		res.Holdout = h
+		res.Accepted = ok && h.Winner.Score >= h.Baseline.Score
+	}
+	return res, nil
+}
+
+// ApplyParams sets each dotted JSON path of params in a copy of cfg and
+// validates the result; unknown paths are an error.
+// ApplyParams 在 cfg 副本上按 JSON 路径设置 params 并校验结果；未知路径返回错误。
+func ApplyParams(cfg signal.Config, params map[string]float64) (signal.Config, error) {
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		return signal.Config{}, err
+	}
+	var tree map[string]any
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		return signal.Config{}, err
+	}
+	for path, v := range params {
+		node := tree
+		keys := strings.Split(path, ".")
+		for _, k := range keys[:len(keys)-1] {
+			next, ok := node[k].(map[string]any)
+			if !ok {
+				return signal.Config{}, fmt.Errorf("unknown parameter %q", path)
+			}
+			node = next
+		}
+		last := keys[len(keys)-1]
+		if _, ok := node[last].(float64); !ok {
+			return signal.Config{}, fmt.Errorf("parameter %q is not a number", path)
+		}
+		node[last] = v
+	}
+	if raw, err = json.Marshal(tree); err != nil {
+		return signal.Config{}, err
+	}
+	var out signal.Config
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return signal.Config{}, err
+	}
+	if err := out.Validate(); err != nil {
+		return signal.Config{}, err
+	}
+	return out, nil
+}
+
+// parameterCandidates expands the grid, or draws cfg.Samples distinct
+// combinations from it for random search.
+func parameterCandidates(cfg WalkForwardConfig) ([]map[string]float64, error) {
+	names := make([]string, 0, len(cfg.Params))
+	total := 1
+	for name, values := range cfg.Params {
+		if len(values) == 0 {
+			return nil, fmt.Errorf("parameter %q has no values", name)
+		}
+		names = append(names, name)
+		total *= len(values)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no parameters to search")
+	}
+	sort.Strings(names)
+	combo := func(idx int) map[string]float64 {
+		out := make(map[string]float64, len(names))
+		for _, name := range names {
+			values := cfg.Params[name]
+			out[name] = values[idx%len(values)]
+			idx /= len(values)
+		}
+		return out
+	}
+	indices := make([]int, total)
+	for i := range indices {
+		indices[i] = i
+	}
+	if cfg.Search == SearchRandom && cfg.Samples < total {
+		rng := rand.New(rand.NewSource(cfg.Seed))
+		rng.Shuffle(total, func(i, j int) { indices[i], indices[j] = indices[j], indices[i] })
+		indices = indices[:cfg.Samples]
+		sort.Ints(indices)
+	}
+	out := make([]map[string]float64, len(indices))
+	for i, idx := range indices {
+		out[i] = combo(idx)
+	}
+	return out, nil
+}
+
+// barTimes is the sorted union of candle times, formatted like sample times.
+func barTimes(history map[string][]*v1.Candlestick) []string {
+	seen := make(map[string]bool)
+	out := make([]string, 0)
+	for _, candles := range history {
+		for _, c := range candles {
+			t := time.Unix(c.Timestamp, 0).Format("2006-01-02 15:04:05")
+			if !seen[t] {
+				seen[t] = true
+				out = append(out, t)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// tradedSamples builds every symbol's samples under c and keeps those at
+// or above cfg.MinLevel.
+func tradedSamples(history map[string][]*v1.Candlestick, c signal.Config, cfg WalkForwardConfig) []signal.TrainingSample {
+	out := make([]signal.TrainingSample, 0)
+	for symbol, candles := range history {
+		for _, s := range signal.BuildTrainingSamples(symbol, candles, c, cfg.Horizon) {
+			if levelRank(s.DecisionLevel) < levelRank(cfg.MinLevel) {
+				continue
+			}
+			// With costs on, only tradable signals count, at their net return and actual exit.
+			// 启用成本模型时仅计入可交易信号，使用其净收益与实际离场时间。
+			if c.Cost.Enabled() {
+				if s.NetReturn == nil {
+					continue
+				}
+				s.Return, s.Hit, s.ExitTime = *s.NetReturn, *s.NetReturn > 0, s.NetExitTime
+			}
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// evaluateWindow scores the samples entering and exiting within [from, to];
+// ok is false below MinTrades.
+func evaluateWindow(samples []signal.TrainingSample, from, to string, cfg WalkForwardConfig) (Evaluation, bool) {
+	rets := make([]float64, 0)
+	for _, s := range samples {
+		if s.Time >= from && s.ExitTime <= to {
+			rets = append(rets, s.Return)
+		}
+	}
+	ev := Evaluation{Trades: len(rets)}
+	if len(rets) < cfg.MinTrades {
+		return ev, false
+	}
+	switch cfg.Objective {
+	case ObjectiveHitRate:
+		hits := 0
+		for _, r := range rets {
+			if r > 0 {
+				hits++
+			}
+		}
+		ev.Score = float64(hits) / float64(len(rets))
+	case ObjectiveSharpe:
+		if mean, std := stat.MeanStdDev(rets, nil); std > 0 {
+			ev.Score = mean / std
+		}
+	default:
+		ev.Score = stat.Mean(rets, nil)
+	}
+	return ev, true
+}
+
+// foldStability summarizes the folds whose chosen candidate traded at least
+// minTrades in the test window.
+func foldStability(folds []FoldResult, winnerChosen, minTrades int) Stability {
+	train := make([]float64, 0, len(folds))
+	test := make([]float64, 0, len(folds))
+	positive := 0
+	for _, f := range folds {
+		if f.Best == nil || f.Test.Trades < minTrades {
+			continue
+		}
+		train = append(train, f.Train.Score)
+		test = append(test, f.Test.Score)
+		if f.Test.Score > 0 {
+			positive++
+		}
+	}
+	st := Stability{Folds: len(folds)}
+	if len(folds) > 0 {
+		st.WinnerShare = float64(winnerChosen) / float64(len(folds))
+	}
+	if len(test) == 0 {
+		return st
+	}
+	st.MeanTrain = stat.Mean(train, nil)
+	st.MeanTest = stat.Mean(test, nil)
+	if len(test) > 1 {
+		st.StdTest = stat.StdDev(test, nil)
+	}
+	st.PositiveShare = float64(positive) / float64(len(test))
+	if st.MeanTrain != 0 {
+		st.Efficiency = st.MeanTest / st.MeanTrain
+	}
+	if math.IsNaN(st.Efficiency) || math.IsInf(st.Efficiency, 0) {
+		st.Efficiency = 0
+	}
+	return st
+}
+
+func levelRank(level string) int {
+	switch level {
+	case "weak":
+		return 0
+	case "medium":
+		return 1
+	case "strong":
+		return 2
+	default:
+		return -1
+	}
+}
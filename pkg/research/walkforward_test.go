@@ -0,0 +1,134 @@
+package research
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+	"github.com/LEVI-Tempest/Candle/pkg/signal"
+)
+
+func TestParameterCandidates(t *testing.T) {
+	cfg := normalizeWalkForwardConfig(WalkForwardConfig{Params: map[string][]float64{
+		"trend.period":             {10, 20, 30},
+		"evidence.volume_lookback": {5, 10},
+	}})
+	grid, err := parameterCandidates(cfg)
+	if err != nil || len(grid) != 6 {
+		t.Fatalf("expected 6 grid points, got %d (%v)", len(grid), err)
+	}
+	seen := make(map[[2]float64]bool)
+	for _, p := range grid {
+		seen[[2]float64{p["trend.period"], p["evidence.volume_lookback"]}] = true
+	}
+	if len(seen) != 6 {
+		t.Fatalf("grid points should be distinct: %v", grid)
+	}
+
+	cfg.Search, cfg.Samples = SearchRandom, 4
+	a, _ := parameterCandidates(cfg)
+	b, _ := parameterCandidates(cfg)
+	if len(a) != 4 || a[0]["trend.period"] != b[0]["trend.period"] || a[3]["evidence.volume_lookback"] != b[3]["evidence.volume_lookback"] {
+		t.Fatalf("random search should draw 4 reproducible points: %v vs %v", a, b)
+	}
+	if _, err := parameterCandidates(normalizeWalkForwardConfig(WalkForwardConfig{})); err == nil {
+		t.Fatal("an empty parameter space should be an error")
+	}
+}
+
+func TestApplyParams(t *testing.T) {
+	cfg, err := ApplyParams(signal.DefaultConfig(), map[string]float64{
+		"trend.period":                10,
+		"evidence.beiliang_threshold": 2.5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Trend.Period != 10 || cfg.Evidence.BeiliangThreshold != 2.5 || cfg.Score.PatternWeight != 60 {
+		t.Fatalf("unexpected config: %+v %+v", cfg.Trend, cfg.Evidence)
+	}
+	if _, err := ApplyParams(signal.DefaultConfig(), map[string]float64{"trend.nope": 1}); err == nil {
+		t.Fatal("unknown parameter should be an error")
+	}
+	if _, err := ApplyParams(signal.DefaultConfig(), map[string]float64{"trend.period": 1}); err == nil {
+		t.Fatal("invalid config should be an error")
+	}
+	if _, err := ApplyParams(signal.DefaultConfig(), map[string]float64{"trend.period": 10.5}); err == nil {
+		t.Fatal("fractional integer parameter should be an error")
+	}
+}
+
+func TestEvaluateWindowPurgesExits(t *testing.T) {
+	samples := []signal.TrainingSample{
+		{Time: "2025-01-02", ExitTime: "2025-01-07", Return: 2},
+		{Time: "2025-01-03", ExitTime: "2025-01-08", Return: -1},
+		{Time: "2025-01-06", ExitTime: "2025-01-13", Return: 5}, // exits after the window
+	}
+	cfg := normalizeWalkForwardConfig(WalkForwardConfig{MinTrades: 2})
+	ev, ok := evaluateWindow(samples, "2025-01-01", "2025-01-10", cfg)
+	if !ok || ev.Trades != 2 || ev.Score != 0.5 {
+		t.Fatalf("expected the mean of two trades, got %+v ok=%v", ev, ok)
+	}
+	cfg.Objective = ObjectiveHitRate
+	if ev, _ := evaluateWindow(samples, "2025-01-01", "2025-01-10", cfg); ev.Score != 0.5 {
+		t.Fatalf("expected a 50%% hit rate, got %+v", ev)
+	}
+	cfg.Objective = ObjectiveSharpe
+	if ev, _ := evaluateWindow(samples, "2025-01-01", "2025-01-10", cfg); math.Abs(ev.Score-0.5/math.Sqrt(4.5)) > 1e-12 {
+		t.Fatalf("unexpected sharpe: %+v", ev)
+	}
+	if _, ok := evaluateWindow(samples, "2025-01-03", "2025-01-10", cfg); ok {
+		t.Fatal("a window below MinTrades should not be scored")
+	}
+}
+
+func TestWalkForwardFolds(t *testing.T) {
+	base := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	history := make(map[string][]*v1.Candlestick)
+	for k, symbol := range []string{"A", "B"} {
+		candles := make([]*v1.Candlestick, 140)
+		price := 50.0
+		for i := range candles {
+			open := price
+			price += 1.5 * math.Sin(float64(i+7*k)/3)
+			candles[i] = &v1.Candlestick{
+				Timestamp: base.AddDate(0, 0, i).Unix(),
+				Open:      open,
+				High:      math.Max(open, price) + 0.2 + float64(i%3)*0.3,
+				Low:       math.Min(open, price) - 0.2 - float64(i%4)*0.2,
+				Close:     price,
+				Volume:    1000 + float64((i+k)%5)*300,
+			}
+		}
+		history[symbol] = candles
+	}
+	wf := WalkForwardConfig{
+		Params:      map[string][]float64{"trend.period": {10, 20}},
+		MinLevel:    "weak",
+		TrainBars:   60,
+		TestBars:    20,
+		HoldoutBars: 20,
+		MinTrades:   1,
+	}
+	res, err := WalkForward(signal.DefaultConfig(), history, wf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Candidates != 2 || len(res.Folds) != 3 || res.Stability.Folds != 3 {
+		t.Fatalf("expected 3 folds over 2 candidates: %+v", res)
+	}
+	f := res.Folds[1]
+	if want := time.Unix(history["A"][20].Timestamp, 0).Format("2006-01-02 15:04:05"); f.TrainFrom != want || f.TestFrom <= f.TrainTo {
+		t.Fatalf("folds should step by the test size: %+v", f)
+	}
+	if res.Holdout == nil || res.Holdout.From <= res.Folds[2].TestTo {
+		t.Fatalf("holdout should follow the last fold: %+v", res.Holdout)
+	}
+	if res.Config.Trend.Period != int(res.Winner["trend.period"]) {
+		t.Fatalf("config should carry the winner: %d vs %v", res.Config.Trend.Period, res.Winner)
+	}
+	if _, err := WalkForward(signal.DefaultConfig(), history, WalkForwardConfig{Params: wf.Params, TrainBars: 200}); err == nil {
+		t.Fatal("expected an error when history is too short for a fold")
+	}
+}
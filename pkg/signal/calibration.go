@@ -0,0 +1,161 @@
+package signal
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+)
+
+// Calibration methods
+// 校准方法
+const (
+	CalibrationIsotonic = "isotonic" // Pool-adjacent-violators isotonic regression (保序回归)
+	CalibrationPlatt    = "platt"    // Logistic fit on the score (Platt 缩放)
+)
+
+// CalibrationAllPatterns is the pattern key of the pooled table used when a
+// pattern type has no table of its own.
+// CalibrationAllPatterns 为汇总校准表的形态键，形态没有专属校准表时使用。
+const CalibrationAllPatterns = "*"
+
+// Calibration maps evidence FinalScore to a hit probability and expected
+// return, per pattern type and horizon.
+// Calibration 按形态类型与持有周期，将证据 FinalScore 映射为命中概率与期望收益。
+type Calibration struct {
+	Method string             `json:"method"`
+	Tables []CalibrationTable `json:"tables"`
+}
+
+// CalibrationPoint is one knot of an isotonic calibration curve.
+// CalibrationPoint 为保序校准曲线上的一个节点。
+type CalibrationPoint struct {
+	Score       float64 `json:"score"`
+	Probability float64 `json:"probability"`
+}
+
+// ReturnBin summarizes the direction-signed forward returns of samples
+// scoring up to MaxScore (and above the previous bin).
+// ReturnBin 汇总得分不超过 MaxScore（且高于上一分箱）样本的方向化前瞻收益。
+type ReturnBin struct {
+	MaxScore float64 `json:"max_score"`
+	Count    int     `json:"count"`
+	Mean     float64 `json:"mean"`
+	Low      float64 `json:"low"`
+	High     float64 `json:"high"`
+}
+
+// CalibrationTable is the calibration of one pattern type at one horizon.
+// Platt tables use p = 1/(1+exp(-(A·score+B))); isotonic tables interpolate
+// linearly between Points.
+// CalibrationTable 为某一形态在某一周期的校准表；Platt 使用 p = 1/(1+exp(-(A·score+B)))，保序回归在 Points 间线性插值。
+type CalibrationTable struct {
+	Pattern string             `json:"pattern"`
+	Horizon int                `json:"horizon"`
+	Method  string             `json:"method"`
+	Samples int                `json:"samples"`
+	A       float64            `json:"a,omitempty"`
+	B       float64            `json:"b,omitempty"`
+	Points  []CalibrationPoint `json:"points,omitempty"`
+	Returns []ReturnBin        `json:"returns"`
+}
+
+// CalibratedOutcome is the calibrated view of one pattern at one horizon.
+// Returns are in the pattern's direction: positive means the pattern worked.
+// CalibratedOutcome 为形态在某一周期的校准结果；收益按形态方向计，正值表示形态有效。
+type CalibratedOutcome struct {
+	Horizon        int     `json:"horizon"`
+	Table          string  `json:"table"` // Pattern type or "*" (所用校准表)
+	Samples        int     `json:"samples"`
+	HitProbability float64 `json:"hit_probability"`
+	ExpectedReturn float64 `json:"expected_return"`
+	ReturnLow      float64 `json:"return_low"`
+	ReturnHigh     float64 `json:"return_high"`
+}
+
+// LoadCalibration reads a calibration JSON file.
+// LoadCalibration 读取校准 JSON 文件。
+func LoadCalibration(path string) (*Calibration, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Calibration
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, err
+	}
+	for i, t := range c.Tables {
+		if len(t.Returns) == 0 || (t.Method == CalibrationIsotonic && len(t.Points) == 0) {
+			return nil, fmt.Errorf("tables[%d]: %s/%d has no calibration data", i, t.Pattern, t.Horizon)
+		}
+	}
+	return &c, nil
+}
+
+// Probability maps score to a calibrated hit probability.
+// Probability 将得分映射为校准后的命中概率。
+func (t CalibrationTable) Probability(score float64) float64 {
+	if t.Method == CalibrationPlatt {
+		return 1 / (1 + math.Exp(-(t.A*score + t.B)))
+	}
+	pts := t.Points
+	i := sort.Search(len(pts), func(i int) bool { return pts[i].Score >= score })
+	switch {
+	case i == 0:
+		return pts[0].Probability
+	case i == len(pts):
+		return pts[len(pts)-1].Probability
+	}
+	lo, hi := pts[i-1], pts[i]
+	return lo.Probability + (hi.Probability-lo.Probability)*(score-lo.Score)/(hi.Score-lo.Score)
+}
+
+// ReturnBin returns the bin score falls in; scores above the last bin use it.
+// ReturnBin 返回 score 所在分箱；高于最后一个分箱时使用最后一个。
+func (t CalibrationTable) ReturnBin(score float64) ReturnBin {
+	for _, b := range t.Returns {
+		if score <= b.MaxScore {
+			return b
+		}
+	}
+	return t.Returns[len(t.Returns)-1]
+}
+
+// Outcomes calibrates score for patternType at every horizon with a table,
+// falling back to the pooled table; it returns nil on a nil Calibration.
+// Outcomes 在各有校准表的周期上校准 patternType 的得分，缺少专属表时回退到汇总表；Calibration 为 nil 时返回 nil。
+func (c *Calibration) Outcomes(patternType string, score float64) []CalibratedOutcome {
+	if c == nil {
+		return nil
+	}
+	tables := make(map[int]CalibrationTable)
+	for _, t := range c.Tables {
+		if t.Pattern == patternType || (t.Pattern == CalibrationAllPatterns && tables[t.Horizon].Pattern != patternType) {
+			tables[t.Horizon] = t
+		}
+	}
+	if len(tables) == 0 {
+		return nil
+	}
+	horizons := make([]int, 0, len(tables))
+	for h := range tables {
+		horizons = append(horizons, h)
+	}
+	sort.Ints(horizons)
+	out := make([]CalibratedOutcome, 0, len(horizons))
+	for _, h := range horizons {
+		t := tables[h]
+		bin := t.ReturnBin(score)
+		out = append(out, CalibratedOutcome{
+			Horizon:        h,
+			Table:          t.Pattern,
+			Samples:        t.Samples,
+			HitProbability: t.Probability(score),
+			ExpectedReturn: bin.Mean,
+			ReturnLow:      bin.Low,
+			ReturnHigh:     bin.High,
+		})
+	}
+	return out
+}
@@ -0,0 +1,95 @@
+package signal
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+)
+
+func testCalibration() *Calibration {
+	bins := []ReturnBin{{MaxScore: 0.5, Count: 40, Mean: -0.2, Low: -2, High: 1.5}, {MaxScore: 1, Count: 40, Mean: 0.8, Low: -1, High: 3}}
+	return &Calibration{
+		Method: CalibrationIsotonic,
+		Tables: []CalibrationTable{
+			{Pattern: CalibrationAllPatterns, Horizon: 5, Method: CalibrationIsotonic, Samples: 80,
+				Points: []CalibrationPoint{{Score: 0.2, Probability: 0.3}, {Score: 0.6, Probability: 0.7}}, Returns: bins},
+			{Pattern: "Hammer", Horizon: 5, Method: CalibrationPlatt, Samples: 50, A: 4, B: -2, Returns: bins},
+			{Pattern: CalibrationAllPatterns, Horizon: 10, Method: CalibrationIsotonic, Samples: 80,
+				Points: []CalibrationPoint{{Score: 0.5, Probability: 0.55}}, Returns: bins},
+		},
+	}
+}
+
+func TestCalibrationOutcomes(t *testing.T) {
+	cal := testCalibration()
+	out := cal.Outcomes("Hammer", 0.5)
+	if len(out) != 2 || out[0].Horizon != 5 || out[0].Table != "Hammer" || out[1].Table != CalibrationAllPatterns {
+		t.Fatalf("expected the Hammer table at 5 bars and the pooled one at 10: %+v", out)
+	}
+	if math.Abs(out[0].HitProbability-0.5) > 1e-12 || out[0].ExpectedReturn != -0.2 || out[0].ReturnHigh != 1.5 {
+		t.Fatalf("unexpected Platt outcome: %+v", out[0])
+	}
+	other := cal.Outcomes("Shooting Star", 0.4)
+	if other[0].Table != CalibrationAllPatterns || math.Abs(other[0].HitProbability-0.5) > 1e-12 {
+		t.Fatalf("expected interpolation on the pooled table: %+v", other[0])
+	}
+	if p := cal.Tables[0].Probability(0.95); p != 0.7 {
+		t.Fatalf("scores past the last knot should clamp, got %.3f", p)
+	}
+	if b := cal.Tables[0].ReturnBin(1.2); b.Mean != 0.8 {
+		t.Fatalf("scores past the last bin should use it: %+v", b)
+	}
+	var none *Calibration
+	if none.Outcomes("Hammer", 0.5) != nil {
+		t.Fatal("nil calibration should give no outcomes")
+	}
+}
+
+func TestBuildReportWithCalibration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calibration.json")
+	data, err := json.Marshal(testCalibration())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cal, err := LoadCalibration(path)
+	if err != nil {
+		t.Fatalf("load calibration failed: %v", err)
+	}
+
+	base := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	candles := make([]*v1.Candlestick, 0, 40)
+	price := 100.0
+	for i := 0; i < 40; i++ {
+		open := price
+		price += 2 * math.Sin(float64(i)/4)
+		candles = append(candles, &v1.Candlestick{
+			Timestamp: base.AddDate(0, 0, i).Unix(),
+			Open:      open,
+			High:      math.Max(open, price) + 0.3,
+			Low:       math.Min(open, price) - 0.3 - float64(i%3)*0.4,
+			Close:     price,
+			Volume:    1000 + float64(i%4)*200,
+		})
+	}
+	report := BuildReportWithInputs("XSHE:300059", "2026-03-09T09:30:00Z", "test", candles, ReportInputs{Calibration: cal}, DefaultConfig())
+	if len(report.Patterns) == 0 {
+		t.Fatal("expected patterns")
+	}
+	for _, p := range report.Patterns {
+		if len(p.Calibrated) != 2 {
+			t.Fatalf("every pattern should be calibrated at 5 and 10 bars: %+v", p)
+		}
+	}
+	schemaPath := filepath.Join("..", "..", "docs", "signal.schema.json")
+	if err := ValidateReportSchema(report, schemaPath); err != nil {
+		t.Fatalf("schema validation failed: %v", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/LEVI-Tempest/Candle/pkg/cost"
 	"github.com/LEVI-Tempest/Candle/pkg/identify"
 )
 
@@ -29,10 +30,31 @@ type ScoreConfig struct {
 // Config is the top-level configuration for signal generation.
 // Config 是信号生成的顶层配置。
 type Config struct {
-	Trend      TrendConfig             `json:"trend"`
-	Score      ScoreConfig             `json:"score"`
-	Evidence   identify.EvidenceConfig `json:"evidence"`
-	LogCSVPath string                  `json:"log_csv_path"`
+	Trend      TrendConfig              `json:"trend"`
+	Score      ScoreConfig              `json:"score"`
+	Evidence   identify.EvidenceConfig  `json:"evidence"`
+	TrendLines identify.TrendLineConfig `json:"trendlines"`
+	Detector   identify.DetectorConfig  `json:"detector"`
+	Lifecycle  identify.LifecycleConfig `json:"lifecycle"`
+	// Indicators lists the technical indicators to compute; a non-empty list replaces the defaults.
+	// Indicators 列出需计算的技术指标；非空时整体替换默认列表。
+	Indicators []identify.IndicatorSpec `json:"indicators"`
+	Regime     identify.RegimeConfig    `json:"regime"`
+	// RegimeScore overrides score fields per regime (trend/range/high_volatility),
+	// by the regime at each pattern's bar; zero fields fall back to Score.
+	// RegimeScore 按市场状态覆盖评分配置，取每个形态所在K线的市场状态；为零的字段沿用 Score。
+	RegimeScore map[string]ScoreConfig `json:"regime_score"`
+	// HeikinAshi detects patterns on Heikin-Ashi bars and adds HA signals;
+	// prices, lifecycle levels, trend, regime, trendline breaks and forward
+	// returns still use the raw candles.
+	// HeikinAshi 基于平均K线识别形态并加入平均K线信号；价格、生命周期价位、趋势、市场状态、趋势线突破与前瞻收益仍使用原始K线。
+	HeikinAshi bool                  `json:"heikin_ashi"`
+	Analog     identify.AnalogConfig `json:"analog"`
+	// Cost adds net-of-cost forward returns when Cost.Market is set; fields
+	// left zero take that market's defaults.
+	// Cost 在设置 Cost.Market 时输出扣除成本后的前瞻收益；为零的字段取该市场默认值。
+	Cost       cost.Config `json:"cost"`
+	LogCSVPath string      `json:"log_csv_path"`
 }
 
 // DefaultConfig returns default values for local research workflow.
@@ -50,6 +72,12 @@ func DefaultConfig() Config {
 			MediumThreshold: 60,
 		},
 		Evidence:   identify.DefaultEvidenceConfig(),
+		TrendLines: identify.DefaultTrendLineConfig(),
+		Detector:   identify.DefaultDetectorConfig(),
+		Lifecycle:  identify.DefaultLifecycleConfig(),
+		Indicators: identify.DefaultIndicatorSpecs(),
+		Regime:     identify.DefaultRegimeConfig(),
+		Analog:     identify.DefaultAnalogConfig(),
 		LogCSVPath: filepath.Join("data", "signal_log.csv"),
 	}
 }
@@ -86,21 +114,7 @@ func mergeConfig(dst, src *Config) {
 		dst.Trend.Period = src.Trend.Period
 	}
 
-	if src.Score.PatternWeight > 0 {
-		dst.Score.PatternWeight = src.Score.PatternWeight
-	}
-	if src.Score.TrendWeight > 0 {
-		dst.Score.TrendWeight = src.Score.TrendWeight
-	}
-	if src.Score.VolumeWeight > 0 {
-		dst.Score.VolumeWeight = src.Score.VolumeWeight
-	}
-	if src.Score.StrongThreshold > 0 {
-		dst.Score.StrongThreshold = src.Score.StrongThreshold
-	}
-	if src.Score.MediumThreshold > 0 {
-		dst.Score.MediumThreshold = src.Score.MediumThreshold
-	}
+	mergeScoreConfig(&dst.Score, src.Score)
 
 	if src.Evidence.VolumeLookback > 0 {
 		dst.Evidence.VolumeLookback = src.Evidence.VolumeLookback
@@ -138,28 +152,330 @@ func mergeConfig(dst, src *Config) {
 	if src.Evidence.OBVDivergenceLookback > 0 {
 		dst.Evidence.OBVDivergenceLookback = src.Evidence.OBVDivergenceLookback
 	}
+	mergeLevelConfig(&dst.Evidence.Levels, src.Evidence.Levels)
+	mergeBeiliangConfig(&dst.Evidence.Beiliang, src.Evidence.Beiliang)
+	mergeVolumeProfileConfig(&dst.Evidence.Profile, src.Evidence.Profile)
+	mergeRelativeStrengthConfig(&dst.Evidence.RelativeStrength, src.Evidence.RelativeStrength)
+	mergeTrendLineConfig(&dst.TrendLines, src.TrendLines)
+	mergeDetectorConfig(&dst.Detector, src.Detector)
+	if src.Lifecycle.ConfirmBars > 0 {
+		dst.Lifecycle.ConfirmBars = src.Lifecycle.ConfirmBars
+	}
+	if len(src.Indicators) > 0 {
+		dst.Indicators = src.Indicators
+	}
+	mergeRegimeConfig(&dst.Regime, src.Regime)
+	if len(src.RegimeScore) > 0 {
+		dst.RegimeScore = src.RegimeScore
+	}
+	if src.HeikinAshi {
+		dst.HeikinAshi = true
+	}
+	mergeAnalogConfig(&dst.Analog, src.Analog)
+	mergeCostConfig(&dst.Cost, src.Cost)
 
 	if src.LogCSVPath != "" {
 		dst.LogCSVPath = src.LogCSVPath
 	}
 }
 
+func mergeScoreConfig(dst *ScoreConfig, src ScoreConfig) {
+	if src.PatternWeight > 0 {
+		dst.PatternWeight = src.PatternWeight
+	}
+	if src.TrendWeight > 0 {
+		dst.TrendWeight = src.TrendWeight
+	}
+	if src.VolumeWeight > 0 {
+		dst.VolumeWeight = src.VolumeWeight
+	}
+	if src.StrongThreshold > 0 {
+		dst.StrongThreshold = src.StrongThreshold
+	}
+	if src.MediumThreshold > 0 {
+		dst.MediumThreshold = src.MediumThreshold
+	}
+}
+
+func mergeRegimeConfig(dst *identify.RegimeConfig, src identify.RegimeConfig) {
+	if src.ADXPeriod > 0 {
+		dst.ADXPeriod = src.ADXPeriod
+	}
+	if src.TrendADX > 0 {
+		dst.TrendADX = src.TrendADX
+	}
+	if src.RangeADX > 0 {
+		dst.RangeADX = src.RangeADX
+	}
+	if src.VolatilityWindow > 0 {
+		dst.VolatilityWindow = src.VolatilityWindow
+	}
+	if src.VolatilityLookback > 0 {
+		dst.VolatilityLookback = src.VolatilityLookback
+	}
+	if src.HighVolPercentile > 0 {
+		dst.HighVolPercentile = src.HighVolPercentile
+	}
+	if src.HMM {
+		dst.HMM = true
+	}
+	if src.HMMStates > 0 {
+		dst.HMMStates = src.HMMStates
+	}
+	if src.HMMIterations > 0 {
+		dst.HMMIterations = src.HMMIterations
+	}
+}
+
+// ScoreFor returns Score with the overrides of regime applied.
+// ScoreFor 返回叠加了指定市场状态覆盖项的评分配置。
+func (cfg Config) ScoreFor(regime string) ScoreConfig {
+	score := cfg.Score
+	if override, ok := cfg.RegimeScore[regime]; ok {
+		mergeScoreConfig(&score, override)
+	}
+	return score
+}
+
+func mergeLevelConfig(dst *identify.LevelConfig, src identify.LevelConfig) {
+	if src.Lookback > 0 {
+		dst.Lookback = src.Lookback
+	}
+	if src.ClusterTolerance > 0 {
+		dst.ClusterTolerance = src.ClusterTolerance
+	}
+	if src.ProximityPercent > 0 {
+		dst.ProximityPercent = src.ProximityPercent
+	}
+	if src.VolumeBins > 0 {
+		dst.VolumeBins = src.VolumeBins
+	}
+	if src.VolumeNodes > 0 {
+		dst.VolumeNodes = src.VolumeNodes
+	}
+	if src.RecencyHalfLife > 0 {
+		dst.RecencyHalfLife = src.RecencyHalfLife
+	}
+	if src.MaxLevels > 0 {
+		dst.MaxLevels = src.MaxLevels
+	}
+	mergePivotConfig(&dst.Pivot, src.Pivot)
+}
+
+func mergeBeiliangConfig(dst *identify.BeiliangConfig, src identify.BeiliangConfig) {
+	if src.RangeWindow > 0 {
+		dst.RangeWindow = src.RangeWindow
+	}
+	if src.LowPosition > 0 {
+		dst.LowPosition = src.LowPosition
+	}
+	if src.HighPosition > 0 {
+		dst.HighPosition = src.HighPosition
+	}
+	if len(src.Horizons) > 0 {
+		dst.Horizons = src.Horizons
+	}
+	if src.PullbackVolumeRatio > 0 {
+		dst.PullbackVolumeRatio = src.PullbackVolumeRatio
+	}
+}
+
+func mergeVolumeProfileConfig(dst *identify.VolumeProfileConfig, src identify.VolumeProfileConfig) {
+	if src.Window > 0 {
+		dst.Window = src.Window
+	}
+	if src.Bins > 0 {
+		dst.Bins = src.Bins
+	}
+	if src.ValueArea > 0 {
+		dst.ValueArea = src.ValueArea
+	}
+	if src.ProximityPercent > 0 {
+		dst.ProximityPercent = src.ProximityPercent
+	}
+}
+
+func mergeRelativeStrengthConfig(dst *identify.RelativeStrengthConfig, src identify.RelativeStrengthConfig) {
+	if src.Lookback > 0 {
+		dst.Lookback = src.Lookback
+	}
+	if src.BetaWindow > 0 {
+		dst.BetaWindow = src.BetaWindow
+	}
+}
+
+func mergeAnalogConfig(dst *identify.AnalogConfig, src identify.AnalogConfig) {
+	if src.Window > 0 {
+		dst.Window = src.Window
+	}
+	if src.TopK > 0 {
+		dst.TopK = src.TopK
+	}
+	if src.Metric != "" {
+		dst.Metric = src.Metric
+	}
+	if src.VolumeWeight > 0 {
+		dst.VolumeWeight = src.VolumeWeight
+	}
+	if src.Band > 0 {
+		dst.Band = src.Band
+	}
+	if len(src.Horizons) > 0 {
+		dst.Horizons = src.Horizons
+	}
+}
+
+// mergeCostConfig starts from the defaults of src.Market, so a config naming
+// only the market gets its full fee schedule.
+func mergeCostConfig(dst *cost.Config, src cost.Config) {
+	if src.Market == "" {
+		return
+	}
+	if def, ok := cost.MarketConfig(src.Market); ok {
+		*dst = def
+	}
+	dst.Market = src.Market
+	merge := func(d *float64, v float64) {
+		if v > 0 {
+			*d = v
+		}
+	}
+	merge(&dst.CommissionRate, src.CommissionRate)
+	merge(&dst.MinCommission, src.MinCommission)
+	merge(&dst.StampDutyRate, src.StampDutyRate)
+	merge(&dst.TransferFeeRate, src.TransferFeeRate)
+	merge(&dst.ExchangeFeeRate, src.ExchangeFeeRate)
+	merge(&dst.SettlementFeeRate, src.SettlementFeeRate)
+	merge(&dst.MinSettlementFee, src.MinSettlementFee)
+	merge(&dst.MaxSettlementFee, src.MaxSettlementFee)
+	merge(&dst.SlippageBps, src.SlippageBps)
+	merge(&dst.LimitPct, src.LimitPct)
+	merge(&dst.Capital, src.Capital)
+	if src.StampDutyOnBuy {
+		dst.StampDutyOnBuy = true
+	}
+	if src.LotSize > 0 {
+		dst.LotSize = src.LotSize
+	}
+	if src.SettlementDays > 0 {
+		dst.SettlementDays = src.SettlementDays
+	}
+	if len(src.LimitPrefixes) > 0 {
+		dst.LimitPrefixes = src.LimitPrefixes
+	}
+	if src.AllowShort {
+		dst.AllowShort = true
+	}
+	if src.Timezone != "" {
+		dst.Timezone = src.Timezone
+	}
+}
+
+func mergeTrendLineConfig(dst *identify.TrendLineConfig, src identify.TrendLineConfig) {
+	if src.MinTouches > 0 {
+		dst.MinTouches = src.MinTouches
+	}
+	if src.TouchTolerance > 0 {
+		dst.TouchTolerance = src.TouchTolerance
+	}
+	if src.MaxViolation > 0 {
+		dst.MaxViolation = src.MaxViolation
+	}
+	if src.BreakPercent > 0 {
+		dst.BreakPercent = src.BreakPercent
+	}
+	if src.MaxPivots > 0 {
+		dst.MaxPivots = src.MaxPivots
+	}
+	if src.MaxLines > 0 {
+		dst.MaxLines = src.MaxLines
+	}
+	mergePivotConfig(&dst.Pivot, src.Pivot)
+}
+
+func mergeDetectorConfig(dst *identify.DetectorConfig, src identify.DetectorConfig) {
+	if src.BodyReference != "" {
+		dst.BodyReference = src.BodyReference
+	}
+	if src.ReferencePeriod > 0 {
+		dst.ReferencePeriod = src.ReferencePeriod
+	}
+	merge := func(d *float64, v float64) {
+		if v > 0 {
+			*d = v
+		}
+	}
+	merge(&dst.LongBodyMultiple, src.LongBodyMultiple)
+	merge(&dst.ShortBodyMultiple, src.ShortBodyMultiple)
+	merge(&dst.DojiBodyRatio, src.DojiBodyRatio)
+	merge(&dst.DojiShadowRatio, src.DojiShadowRatio)
+	merge(&dst.LongLeggedBodyRatio, src.LongLeggedBodyRatio)
+	merge(&dst.LongLeggedShadowRatio, src.LongLeggedShadowRatio)
+	merge(&dst.LongLeggedShadowDominant, src.LongLeggedShadowDominant)
+	merge(&dst.MarubozuShadowMultiple, src.MarubozuShadowMultiple)
+	merge(&dst.UmbrellaShadowMultiple, src.UmbrellaShadowMultiple)
+	merge(&dst.UmbrellaUpperMultiple, src.UmbrellaUpperMultiple)
+	merge(&dst.HammerShadowMultiple, src.HammerShadowMultiple)
+	merge(&dst.HammerOppositeMultiple, src.HammerOppositeMultiple)
+	merge(&dst.StarBodyRatio, src.StarBodyRatio)
+	merge(&dst.TweezerTolerance, src.TweezerTolerance)
+	merge(&dst.HaramiBodyMultiple, src.HaramiBodyMultiple)
+	merge(&dst.DragonflyBodyRatio, src.DragonflyBodyRatio)
+	merge(&dst.DragonflyOppositeRatio, src.DragonflyOppositeRatio)
+	merge(&dst.DragonflyShadowRatio, src.DragonflyShadowRatio)
+	merge(&dst.ThreeMethodsBodyRatio, src.ThreeMethodsBodyRatio)
+	merge(&dst.ThreeMethodsInnerMultiple, src.ThreeMethodsInnerMultiple)
+	merge(&dst.SpinningTopBodyRatio, src.SpinningTopBodyRatio)
+	merge(&dst.SpinningTopShadowRatio, src.SpinningTopShadowRatio)
+	merge(&dst.SpinningTopBalanceRatio, src.SpinningTopBalanceRatio)
+}
+
+func mergePivotConfig(dst *identify.PivotConfig, src identify.PivotConfig) {
+	if src.Method != "" {
+		dst.Method = src.Method
+	}
+	if src.FractalBars > 0 {
+		dst.FractalBars = src.FractalBars
+	}
+	if src.ZigZagPercent > 0 {
+		dst.ZigZagPercent = src.ZigZagPercent
+	}
+	if src.ATRPeriod > 0 {
+		dst.ATRPeriod = src.ATRPeriod
+	}
+	if src.ATRMultiplier > 0 {
+		dst.ATRMultiplier = src.ATRMultiplier
+	}
+}
+
+// Validate reports the first invalid setting in cfg.
+// Validate 返回 cfg 中第一个无效配置项的错误。
+func (cfg Config) Validate() error {
+	return validateConfig(cfg)
+}
+
 func validateConfig(cfg Config) error {
 	if cfg.Trend.Period < 2 {
 		return fmt.Errorf("trend.period must be >= 2")
 	}
-	totalWeight := cfg.Score.PatternWeight + cfg.Score.TrendWeight + cfg.Score.VolumeWeight
-	if math.Abs(totalWeight-100) > 1e-9 {
-		return fmt.Errorf("score weights must sum to 100, got %.2f", totalWeight)
+	if err := validateScoreConfig("score", cfg.Score); err != nil {
+		return err
 	}
-	if cfg.Score.StrongThreshold < cfg.Score.MediumThreshold {
-		return fmt.Errorf("strong_threshold must be >= medium_threshold")
+	for regime := range cfg.RegimeScore {
+		switch regime {
+		case identify.RegimeTrend, identify.RegimeRange, identify.RegimeHighVolatility:
+		default:
+			return fmt.Errorf("regime_score: unknown regime %q", regime)
+		}
+		if err := validateScoreConfig("regime_score."+regime, cfg.ScoreFor(regime)); err != nil {
+			return err
+		}
 	}
-	if cfg.Score.StrongThreshold > 100 || cfg.Score.StrongThreshold < 0 {
-		return fmt.Errorf("strong_threshold must be within [0,100]")
+	if cfg.Regime.HMMStates != 0 && cfg.Regime.HMMStates != 2 && cfg.Regime.HMMStates != 3 {
+		return fmt.Errorf("regime.hmm_states must be 2 or 3")
 	}
-	if cfg.Score.MediumThreshold > 100 || cfg.Score.MediumThreshold < 0 {
-		return fmt.Errorf("medium_threshold must be within [0,100]")
+	if cfg.Regime.HighVolPercentile < 0 || cfg.Regime.HighVolPercentile >= 1 {
+		return fmt.Errorf("regime.high_volatility_percentile must be within [0,1)")
 	}
 	if cfg.Evidence.BaseWeight+cfg.Evidence.ContextWeight+cfg.Evidence.VolumeWeight <= 0 {
 		return fmt.Errorf("evidence weights sum must be > 0")
@@ -167,5 +483,68 @@ func validateConfig(cfg Config) error {
 	if cfg.Evidence.BeiliangThreshold <= 0 {
 		return fmt.Errorf("evidence.beiliang_threshold must be > 0")
 	}
+	if b := cfg.Evidence.Beiliang; b.LowPosition >= b.HighPosition || b.HighPosition >= 1 {
+		return fmt.Errorf("evidence.beiliang positions must satisfy 0 < low_position < high_position < 1")
+	}
+	if cfg.Evidence.Profile.ValueArea > 1 {
+		return fmt.Errorf("evidence.profile.value_area must be within (0,1]")
+	}
+	switch cfg.Evidence.Levels.Pivot.Method {
+	case identify.PivotMethodFractal, identify.PivotMethodZigZag, identify.PivotMethodATR:
+	default:
+		return fmt.Errorf("evidence.levels.pivot.method must be fractal, zigzag or atr")
+	}
+	switch cfg.TrendLines.Pivot.Method {
+	case identify.PivotMethodFractal, identify.PivotMethodZigZag, identify.PivotMethodATR:
+	default:
+		return fmt.Errorf("trendlines.pivot.method must be fractal, zigzag or atr")
+	}
+	if cfg.TrendLines.MinTouches < 2 {
+		return fmt.Errorf("trendlines.min_touches must be >= 2")
+	}
+	switch cfg.Detector.BodyReference {
+	case identify.BodyReferenceRange, identify.BodyReferenceATR, identify.BodyReferenceMedianBody:
+	default:
+		return fmt.Errorf("detector.body_reference must be range, atr or median_body")
+	}
+	if cfg.Detector.ShortBodyMultiple >= cfg.Detector.LongBodyMultiple {
+		return fmt.Errorf("detector.short_body_multiple must be < long_body_multiple")
+	}
+	switch cfg.Analog.Metric {
+	case identify.AnalogEuclidean, identify.AnalogDTW:
+	default:
+		return fmt.Errorf("analog.metric must be euclidean or dtw")
+	}
+	if cfg.Analog.Window < 3 {
+		return fmt.Errorf("analog.window must be >= 3")
+	}
+	if cfg.Analog.VolumeWeight > 1 {
+		return fmt.Errorf("analog.volume_weight must be within [0,1]")
+	}
+	if err := cfg.Cost.Validate(); err != nil {
+		return fmt.Errorf("cost: %v", err)
+	}
+	for i, spec := range cfg.Indicators {
+		if err := identify.ValidateIndicatorSpec(spec); err != nil {
+			return fmt.Errorf("indicators[%d]: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func validateScoreConfig(name string, s ScoreConfig) error {
+	totalWeight := s.PatternWeight + s.TrendWeight + s.VolumeWeight
+	if math.Abs(totalWeight-100) > 1e-9 {
+		return fmt.Errorf("%s weights must sum to 100, got %.2f", name, totalWeight)
+	}
+	if s.StrongThreshold < s.MediumThreshold {
+		return fmt.Errorf("%s.strong_threshold must be >= medium_threshold", name)
+	}
+	if s.StrongThreshold > 100 || s.StrongThreshold < 0 {
+		return fmt.Errorf("%s.strong_threshold must be within [0,100]", name)
+	}
+	if s.MediumThreshold > 100 || s.MediumThreshold < 0 {
+		return fmt.Errorf("%s.medium_threshold must be within [0,100]", name)
+	}
 	return nil
 }
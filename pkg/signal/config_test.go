@@ -25,3 +25,135 @@ func TestLoadConfigValidation(t *testing.T) {
 		t.Fatal("expected validation error for invalid score weight sum")
 	}
 }
+
+func TestLoadConfigMergesTrendLines(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "trendlines.json")
+	raw := `{"trendlines": {"min_touches": 4, "pivot": {"method": "fractal"}}}`
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("load config failed: %v", err)
+	}
+	if cfg.TrendLines.MinTouches != 4 || cfg.TrendLines.Pivot.Method != "fractal" {
+		t.Fatalf("trendline overrides not merged: %+v", cfg.TrendLines)
+	}
+	if cfg.TrendLines.BreakPercent != DefaultConfig().TrendLines.BreakPercent {
+		t.Fatalf("unset fields should keep defaults: %+v", cfg.TrendLines)
+	}
+}
+
+func TestLoadConfigDetectorValidation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "detector.json")
+	raw := `{"detector": {"body_reference": "median_body", "doji_body_ratio": 0.12}}`
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("load config failed: %v", err)
+	}
+	if cfg.Detector.BodyReference != "median_body" || cfg.Detector.DojiBodyRatio != 0.12 {
+		t.Fatalf("detector overrides not merged: %+v", cfg.Detector)
+	}
+	if cfg.Detector.HammerShadowMultiple != 2 {
+		t.Fatalf("unset detector fields should keep defaults: %+v", cfg.Detector)
+	}
+
+	bad := filepath.Join(dir, "bad_detector.json")
+	if err := os.WriteFile(bad, []byte(`{"detector": {"body_reference": "atr_typo"}}`), 0o644); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+	if _, err := LoadConfig(bad); err == nil {
+		t.Fatal("expected validation error for unknown body_reference")
+	}
+}
+
+func TestLoadConfigIndicators(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "indicators.json")
+	raw := `{"indicators": [{"name": "ema", "params": [12]}, {"name": "macd"}]}`
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("load config failed: %v", err)
+	}
+	if len(cfg.Indicators) != 2 || cfg.Indicators[0].Name != "ema" {
+		t.Fatalf("indicator list should replace defaults: %+v", cfg.Indicators)
+	}
+
+	bad := filepath.Join(dir, "bad_indicators.json")
+	if err := os.WriteFile(bad, []byte(`{"indicators": [{"name": "macd", "params": [26, 12, 9]}]}`), 0o644); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+	if _, err := LoadConfig(bad); err == nil {
+		t.Fatal("expected validation error for macd fast >= slow")
+	}
+}
+
+func TestLoadConfigRegimeScore(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "regime.json")
+	raw := `{"regime": {"hmm": true, "trend_adx": 30}, "regime_score": {"range": {"pattern_weight": 80, "trend_weight": 10, "volume_weight": 10}}}`
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("load config failed: %v", err)
+	}
+	if !cfg.Regime.HMM || cfg.Regime.TrendADX != 30 || cfg.Regime.ADXPeriod != 14 {
+		t.Fatalf("regime config not merged: %+v", cfg.Regime)
+	}
+	score := cfg.ScoreFor("range")
+	if score.PatternWeight != 80 || score.StrongThreshold != cfg.Score.StrongThreshold {
+		t.Fatalf("regime score should override weights and keep thresholds: %+v", score)
+	}
+
+	for name, bad := range map[string]string{
+		"unknown regime": `{"regime_score": {"bull": {"pattern_weight": 60}}}`,
+		"weights":        `{"regime_score": {"trend": {"pattern_weight": 70}}}`,
+	} {
+		badPath := filepath.Join(dir, "bad_regime.json")
+		if err := os.WriteFile(badPath, []byte(bad), 0o644); err != nil {
+			t.Fatalf("write config failed: %v", err)
+		}
+		if _, err := LoadConfig(badPath); err == nil {
+			t.Fatalf("expected validation error for %s", name)
+		}
+	}
+}
+
+func TestLoadConfigMergesCost(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cost.json")
+	raw := `{"cost": {"market": "hk", "slippage_bps": 20, "lot_size": 500}}`
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("load config failed: %v", err)
+	}
+	if cfg.Cost.SlippageBps != 20 || cfg.Cost.LotSize != 500 {
+		t.Fatalf("cost overrides not merged: %+v", cfg.Cost)
+	}
+	if cfg.Cost.StampDutyRate != 0.001 || !cfg.Cost.StampDutyOnBuy || cfg.Cost.SettlementDays != 0 {
+		t.Fatalf("unset cost fields should take the hk defaults: %+v", cfg.Cost)
+	}
+	if DefaultConfig().Cost.Enabled() {
+		t.Fatal("cost modelling should be off by default")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"cost": {"market": "us"}}`), 0o644); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("expected validation error for an unknown cost market")
+	}
+}
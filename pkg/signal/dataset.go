@@ -0,0 +1,157 @@
+package signal
+
+import (
+	"time"
+
+	"github.com/LEVI-Tempest/Candle/pkg/identify"
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+)
+
+// Training feature names; factor hits are keyed "context:<name>" and "volume:<name>".
+// 训练特征名称；因子命中以 "context:<name>" 与 "volume:<name>" 为键。
+const (
+	FeatureBaseStrength   = "base_strength"
+	FeatureContextScore   = "context_score"
+	FeatureVolumeScore    = "volume_score"
+	FeatureTrendScore     = "trend_score"
+	FeatureVolumeState    = "volume_state_score"
+	FeatureContradictions = "contradictions"
+)
+
+// TrainingSample is one detected pattern with its evidence features and the
+// realized forward return, for learning weights offline.
+// TrainingSample 为一个已识别形态的证据特征及其实际前瞻收益，用于离线学习权重。
+type TrainingSample struct {
+	Symbol    string             `json:"symbol"`
+	Type      string             `json:"type"`
+	Direction string             `json:"direction"`
+	Position  int                `json:"position"`
+	Time      string             `json:"time"`
+	Features  map[string]float64 `json:"features"`
+	// ExitTime is the time of the bar the forward return is measured at.
+	// ExitTime 为计算前瞻收益所用K线的时间。
+	ExitTime string `json:"exit_time"`
+	// FinalScore is the evidence score the pattern was reported with.
+	// FinalScore 为形态输出时的证据总分。
+	FinalScore float64 `json:"final_score"`
+	// DecisionScore and DecisionLevel use cfg.Score and the trend at the pattern bar.
+	// DecisionScore 与 DecisionLevel 基于 cfg.Score 与形态所在K线的趋势计算。
+	DecisionScore float64 `json:"decision_score"`
+	DecisionLevel string  `json:"decision_level"`
+	// Return is the forward close return (percent) over the horizon, sign-flipped
+	// for bearish patterns so that a positive value means the pattern worked.
+	// Return 为持有周期内的收盘收益（百分比），看跌形态取反，正值表示形态有效。
+	Return float64 `json:"return"`
+	Hit    bool    `json:"hit"`
+	// NetReturn is set when cfg.Cost is on and the pattern could be traded in
+	// its direction: the net return of that trade, which exits at NetExitTime.
+	// NetReturn 在启用 cfg.Cost 且形态方向可交易时输出，为该笔交易的净收益，离场时间为 NetExitTime。
+	NetReturn   *float64 `json:"net_return,omitempty"`
+	NetExitTime string   `json:"net_exit_time,omitempty"`
+}
+
+// BuildTrainingSamples detects patterns over the whole of candles
+// (chronological) and returns one sample per directional pattern that has
+// horizon bars after it. The trend score and the regime that selects the
+// score config use only the bars up to the pattern.
+// BuildTrainingSamples 在整段 candles（按时间排列）上识别形态，为其后至少有 horizon 根K线的方向性形态各生成一个样本；
+// 趋势得分及决定评分配置的市场状态仅使用形态及之前的K线。
+func BuildTrainingSamples(symbol string, candles []*v1.Candlestick, cfg Config, horizon int) []TrainingSample {
+	out := make([]TrainingSample, 0)
+	if horizon < 1 || len(candles) == 0 {
+		return out
+	}
+	ek := detectPatterns(candles, nil, cfg)
+	data := ek.DetectionData()
+	evidence := identify.BuildPatternEvidenceWithIndicators(toPatternSignals(ek.Patterns), data, cfg.Evidence, ek.Indicators)
+	regimes := regimeSeries(ek.Data, cfg)
+
+	for _, ev := range evidence {
+		if ev.Direction != "bullish" && ev.Direction != "bearish" {
+			continue
+		}
+		ret := forwardReturn(candles, ev.Position, horizon)
+		if ret == nil {
+			continue
+		}
+		r := *ret
+		if ev.Direction == "bearish" {
+			r = -r
+		}
+		trend := determineTrendByMA(ek.Data[:ev.Position+1], cfg.Trend.Period)
+		state, _ := volumeStateAndReason(ev)
+		features := map[string]float64{
+			FeatureBaseStrength:   ev.BaseStrength,
+			FeatureContextScore:   ev.ContextScore,
+			FeatureVolumeScore:    ev.VolumeScore,
+			FeatureTrendScore:     trendMatchScore(ev.PatternType, trend),
+			FeatureVolumeState:    volumeStateScore(state),
+			FeatureContradictions: float64(len(ev.ContradictionFactors)),
+		}
+		for _, f := range ev.ContextFactors {
+			features["context:"+f.Name] = boolFeature(f.Passed)
+		}
+		for _, f := range ev.VolumeFactors {
+			features["volume:"+f.Name] = boolFeature(f.Passed)
+		}
+		scoreCfg := scoreConfigAt(regimes, ev.Position, cfg)
+		score := decisionScore(scoreCfg, ev.BaseStrength, features[FeatureTrendScore], features[FeatureVolumeState])
+		sample := TrainingSample{
+			Symbol:        symbol,
+			Type:          ev.PatternType,
+			Direction:     ev.Direction,
+			Position:      ev.Position,
+			Time:          ev.Time,
+			Features:      features,
+			ExitTime:      barTime(candles[ev.Position+horizon]),
+			FinalScore:    ev.FinalScore,
+			DecisionScore: score,
+			DecisionLevel: decisionLevel(score, scoreCfg.StrongThreshold, scoreCfg.MediumThreshold),
+			Return:        r,
+			Hit:           r > 0,
+		}
+		if cfg.Cost.Enabled() {
+			if t := cfg.Cost.Simulate(symbol, candles, ev.Position, horizon, ev.Direction == "bullish"); t.Blocked == "" {
+				net := t.NetReturn
+				sample.NetReturn = &net
+				sample.NetExitTime = barTime(candles[t.ExitIndex])
+			}
+		}
+		out = append(out, sample)
+	}
+	return out
+}
+
+// Detection is one pattern found by the configured detectors.
+// Detection 为按配置识别出的一个形态。
+type Detection struct {
+	Type      string `json:"type"`
+	Direction string `json:"direction"`
+	Position  int    `json:"position"` // Index of the pattern's last bar (形态末根K线序号)
+	Time      string `json:"time"`
+}
+
+// Detections returns every pattern cfg detects over candles (chronological),
+// as the signal report would see them, in detector order.
+// Detections 返回按 cfg 在 candles（按时间排列）上识别出的全部形态，与信号报告一致，按识别顺序排列。
+func Detections(candles []*v1.Candlestick, cfg Config) []Detection {
+	out := make([]Detection, 0)
+	if len(candles) == 0 {
+		return out
+	}
+	for _, p := range DetectionChart(candles, cfg).Patterns {
+		out = append(out, Detection{Type: p.Type, Direction: identify.PatternDirection(p.Type), Position: p.Position, Time: barTime(candles[p.Position])})
+	}
+	return out
+}
+
+func barTime(c *v1.Candlestick) string {
+	return time.Unix(c.Timestamp, 0).Format("2006-01-02 15:04:05")
+}
+
+func boolFeature(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -19,6 +19,39 @@ type PatternReport struct {
 	ForwardRet3   *float64 `json:"forward_ret_3,omitempty"`
 	ForwardRet5   *float64 `json:"forward_ret_5,omitempty"`
 	ForwardRet10  *float64 `json:"forward_ret_10,omitempty"`
+	// Calibrated is set only when a calibration was supplied.
+	// Calibrated 仅在提供校准表时输出。
+	Calibrated []CalibratedOutcome `json:"calibrated,omitempty"`
+	// NetForwardRet* are set only when cost modelling is on and the pattern is
+	// not neutral: the net return of a position in the pattern's direction,
+	// long when bullish and short when bearish, held over the horizon after
+	// fees, slippage, T+N and limit-locked exits; TradeBlocked says why the
+	// entry bar was untradable, "short" where the market forbids shorting.
+	// NetForwardRet* 仅在启用成本模型且形态非中性时输出：按形态方向（看涨做多、看跌做空）持仓至该周期，
+	// 扣除费用、滑点并考虑 T+N 与封板顺延后的净收益；TradeBlocked 说明入场K线无法成交的原因，市场不允许做空时为 "short"。
+	NetForwardRet3  *float64 `json:"net_forward_ret_3,omitempty"`
+	NetForwardRet5  *float64 `json:"net_forward_ret_5,omitempty"`
+	NetForwardRet10 *float64 `json:"net_forward_ret_10,omitempty"`
+	TradeBlocked    string   `json:"trade_blocked,omitempty"`
+	// Lifecycle fields are evaluated on the bars after the pattern.
+	// 生命周期字段基于形态之后的K线计算。
+	State             string  `json:"state"`
+	InvalidationPrice float64 `json:"invalidation_price"`
+	ConfirmationRule  string  `json:"confirmation_rule"`
+	ConfirmedAt       *int    `json:"confirmed_at,omitempty"`
+	InvalidatedAt     *int    `json:"invalidated_at,omitempty"`
+}
+
+// TrendlineBreakReport is a close through a validated trendline.
+// TrendlineBreakReport 是对已验证趋势线的收盘突破信号。
+type TrendlineBreakReport struct {
+	Direction string  `json:"direction"`
+	Position  int     `json:"position"`
+	Time      string  `json:"time"`
+	Price     float64 `json:"price"`
+	LinePrice float64 `json:"line_price"`
+	LineType  string  `json:"line_type"`
+	Touches   int     `json:"touches"`
 }
 
 // Report is the structured signal payload for upper-layer agents.
@@ -35,4 +68,30 @@ type Report struct {
 	Evidence        []identify.PatternEvidence `json:"evidence"`
 	CounterEvidence []string                   `json:"counter_evidence"`
 	InvalidIf       []string                   `json:"invalid_if"`
+	TrendlineBreaks []TrendlineBreakReport     `json:"trendline_breaks,omitempty"`
+	// Regime is omitted when there are too few bars to classify.
+	// Regime 在K线不足以分类时省略。
+	Regime *identify.RegimeResult `json:"regime,omitempty"`
+	// RelativeStrength is set only when a benchmark was supplied.
+	// RelativeStrength 仅在提供基准时输出。
+	RelativeStrength *RelativeStrengthReport `json:"relative_strength,omitempty"`
+	// Analogs is set only when a candle history was supplied.
+	// Analogs 仅在提供历史K线时输出。
+	Analogs *identify.AnalogResult `json:"analogs,omitempty"`
+	// Metadata records how the input was processed, e.g. transform=heikin_ashi.
+	// Metadata 记录输入的处理方式，如 transform=heikin_ashi。
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// RelativeStrengthReport compares the symbol with its benchmark and, when a
+// watchlist is given, ranks it by excess return among the watchlist.
+// RelativeStrengthReport 对比标的与基准；提供观察列表时按超额收益给出排名。
+type RelativeStrengthReport struct {
+	Benchmark string `json:"benchmark"`
+	identify.RelativeStrengthResult
+	Rank int `json:"rank,omitempty"` // 1 is the strongest (1 为最强)
+	// Percentile is set with Rank; 0 is the weakest of the watchlist.
+	// Percentile 与 Rank 同时输出；0 表示观察列表中最弱。
+	Percentile *float64 `json:"percentile,omitempty"`
+	Ranked     int      `json:"ranked,omitempty"` // Symbols ranked, including this one (参与排名的标的数)
 }
@@ -9,8 +9,10 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/LEVI-Tempest/Candle/pkg/charting"
+	"github.com/LEVI-Tempest/Candle/pkg/cost"
 	"github.com/LEVI-Tempest/Candle/pkg/identify"
 	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
 )
@@ -21,15 +23,64 @@ const (
 	VolumeContradict = "contradict"
 )
 
+// Benchmark is the index a report measures relative strength against, with
+// an optional watchlist (symbol -> candles) to rank the symbol within.
+// Benchmark 为计算相对强弱的基准指数，可附带观察列表（标的 -> K线）用于排名。
+type Benchmark struct {
+	Symbol    string
+	Candles   []*v1.Candlestick
+	Watchlist map[string][]*v1.Candlestick
+}
+
 // BuildReport generates structured signal output with trend filter and decision score.
 // BuildReport 生成包含趋势过滤和决策分的结构化信号输出。
 func BuildReport(symbol, asOf, source string, candles []*v1.Candlestick, cfg Config) Report {
-	ek := charting.NewEnhancedKline()
-	ek.LoadData(candles)
-	ek.AutoDetectPatterns()
+	return BuildReportWithBenchmark(symbol, asOf, source, candles, nil, cfg)
+}
+
+// ReportInputs carries the optional data a report can draw on besides the
+// symbol's own candles.
+// ReportInputs 为报告除标的自身K线外可使用的可选数据。
+type ReportInputs struct {
+	Benchmark *Benchmark
+	// History (symbol -> chronological candles) is searched for analogs of
+	// the latest window; the symbol's own candles are always searched.
+	// History（标的 -> 按时间排列的K线）用于检索最新窗口的历史相似形态；标的自身K线始终参与检索。
+	History map[string][]*v1.Candlestick
+	// Calibration adds calibrated hit probabilities and expected returns to patterns.
+	// Calibration 为形态加入校准后的命中概率与期望收益。
+	Calibration *Calibration
+}
+
+// BuildReportWithBenchmark is BuildReport plus relative strength against
+// bench; a nil bench gives the same report as BuildReport.
+// BuildReportWithBenchmark 在 BuildReport 基础上加入相对基准强弱；bench 为空时与 BuildReport 相同。
+func BuildReportWithBenchmark(symbol, asOf, source string, candles []*v1.Candlestick, bench *Benchmark, cfg Config) Report {
+	return BuildReportWithInputs(symbol, asOf, source, candles, ReportInputs{Benchmark: bench}, cfg)
+}
+
+// BuildReportWithInputs is BuildReport plus the sections enabled by in:
+// relative strength with a benchmark, analog search with a history and
+// calibrated outcomes with a calibration.
+// BuildReportWithInputs 在 BuildReport 基础上按 in 加入相应部分：提供基准时计算相对强弱，提供历史时检索相似形态，
+// 提供校准表时输出校准结果。
+func BuildReportWithInputs(symbol, asOf, source string, candles []*v1.Candlestick, in ReportInputs, cfg Config) Report {
+	bench := in.Benchmark
+	ek := detectPatterns(candles, bench, cfg)
+	var metadata map[string]string
+	if cfg.HeikinAshi {
+		metadata = map[string]string{"transform": "heikin_ashi"}
+	}
+	// Patterns and their evidence come from the detection bars, Heikin-Ashi
+	// in HA mode; prices, levels and outcomes come from the traded candles.
+	// 形态及其证据基于识别K线（HA 模式下为平均K线）；价格、价位与结果均基于真实成交的K线。
+	data := ek.DetectionData()
 
 	signals := toPatternSignals(ek.Patterns)
-	evidence := identify.BuildPatternEvidence(signals, ek.Data, cfg.Evidence)
+	for i := range signals {
+		signals[i].Price = candles[signals[i].Position].Close
+	}
+	evidence := identify.BuildPatternEvidenceWithIndicators(signals, data, cfg.Evidence, ek.Indicators)
 	sort.Slice(evidence, func(i, j int) bool {
 		if evidence[i].FinalScore == evidence[j].FinalScore {
 			return evidence[i].Position < evidence[j].Position
@@ -38,6 +89,17 @@ func BuildReport(symbol, asOf, source string, candles []*v1.Candlestick, cfg Con
 	})
 
 	trend := determineTrendByMA(ek.Data, cfg.Trend.Period)
+	var regime *identify.RegimeResult
+	scoreCfg := cfg.Score
+	regimes := regimeSeries(ek.Data, cfg)
+	if len(regimes) > 0 {
+		regime = regimes[len(regimes)-1]
+	} else if r, ok := identify.ClassifyRegime(ek.Data, cfg.Regime); ok {
+		regime = &r
+	}
+	if regime != nil {
+		scoreCfg = cfg.ScoreFor(regime.Regime)
+	}
 	patternReports := make([]PatternReport, 0, len(ek.Patterns))
 	evidenceByKey := make(map[string]identify.PatternEvidence, len(evidence))
 	for _, ev := range evidence {
@@ -50,17 +112,26 @@ func BuildReport(symbol, asOf, source string, candles []*v1.Candlestick, cfg Con
 			continue
 		}
 		volumeState, reason := volumeStateAndReason(ev)
-		score := decisionScore(cfg.Score, ev.BaseStrength, trendMatchScore(p.Type, trend), volumeStateScore(volumeState))
-		level := decisionLevel(score, cfg.Score.StrongThreshold, cfg.Score.MediumThreshold)
+		// Each pattern is scored under the regime of its own bar.
+		// 每个形态按其所在K线的市场状态评分。
+		patternCfg := scoreConfigAt(regimes, p.Position, cfg)
+		score := decisionScore(patternCfg, ev.BaseStrength, trendMatchScore(p.Type, trend), volumeStateScore(volumeState))
+		level := decisionLevel(score, patternCfg.StrongThreshold, patternCfg.MediumThreshold)
 		r3, r5, r10 := forwardReturns(candles, p.Position)
+		net, blocked := netForwardReturns(symbol, candles, p.Position, identify.PatternDirection(p.Type), cfg.Cost)
+		lc := identify.TrackPatternLifecycle(identify.PatternSignal{
+			Type:      p.Type,
+			Direction: identify.PatternDirection(p.Type),
+			Position:  p.Position,
+		}, ek.Data, cfg.Lifecycle)
 
 		patternReports = append(patternReports, PatternReport{
 			Type:          p.Type,
-			Direction:     patternDirection(p.Type),
+			Direction:     identify.PatternDirection(p.Type),
 			Position:      p.Position,
 			Strength:      p.Strength,
 			Risk:          p.Risk,
-			Price:         p.Price,
+			Price:         candles[p.Position].Close,
 			Time:          p.Time,
 			VolumeState:   volumeState,
 			DecisionScore: score,
@@ -69,6 +140,18 @@ func BuildReport(symbol, asOf, source string, candles []*v1.Candlestick, cfg Con
 			ForwardRet3:   r3,
 			ForwardRet5:   r5,
 			ForwardRet10:  r10,
+			Calibrated:    in.Calibration.Outcomes(p.Type, ev.FinalScore),
+
+			NetForwardRet3:  net[0],
+			NetForwardRet5:  net[1],
+			NetForwardRet10: net[2],
+			TradeBlocked:    blocked,
+
+			State:             lc.State,
+			InvalidationPrice: lc.InvalidationPrice,
+			ConfirmationRule:  lc.ConfirmationRule,
+			ConfirmedAt:       positionPtr(lc.ConfirmedAt),
+			InvalidatedAt:     positionPtr(lc.InvalidatedAt),
 		})
 	}
 
@@ -87,16 +170,152 @@ func BuildReport(symbol, asOf, source string, candles []*v1.Candlestick, cfg Con
 		Trend:           trend,
 		Score:           normalizedScore(patternReports),
 		DecisionScore:   topScore,
-		DecisionLevel:   decisionLevel(topScore, cfg.Score.StrongThreshold, cfg.Score.MediumThreshold),
+		DecisionLevel:   decisionLevel(topScore, scoreCfg.StrongThreshold, scoreCfg.MediumThreshold),
 		Patterns:        patternReports,
 		Evidence:        evidence,
 		CounterEvidence: collectCounterEvidence(evidence),
-		InvalidIf: []string{
+		InvalidIf: append(patternInvalidations(patternReports, 3),
 			"data source has missing/incorrect OHLCV records",
 			"next trading sessions show no volume confirmation",
 			"price breaks pattern invalidation level with high volatility",
-		},
+		),
+		TrendlineBreaks:  trendlineBreakReports(ek.Data, cfg.TrendLines),
+		Regime:           regime,
+		RelativeStrength: relativeStrengthReport(symbol, ek.Data, bench, cfg.Evidence.RelativeStrength),
+		Analogs:          analogReport(symbol, ek.Data, in.History, cfg.Analog),
+		Metadata:         metadata,
+	}
+}
+
+// detectPatterns runs pattern and indicator detection on candles as
+// configured by cfg, against bench when given.
+// detectPatterns 按 cfg 对 candles 执行形态与指标识别，提供 bench 时同时载入基准。
+func detectPatterns(candles []*v1.Candlestick, bench *Benchmark, cfg Config) *charting.EnhancedKline {
+	ek := charting.NewEnhancedKline()
+	ek.LoadData(candles)
+	if bench != nil {
+		ek.LoadBenchmark(bench.Candles)
+	}
+	if cfg.HeikinAshi {
+		ek.ChartConfig.HeikinAshi = charting.HeikinAshiDetect
+	}
+	ek.TrendLineConfig = cfg.TrendLines
+	ek.DetectorConfig = cfg.Detector
+	ek.EvidenceConfig = cfg.Evidence
+	ek.IndicatorSpecs = cfg.Indicators
+	ek.AutoDetectPatterns()
+	return ek
+}
+
+// DetectionChart returns the chart Detections reads its patterns from, with
+// candles loaded and patterns, evidence and indicators detected under cfg.
+// DetectionChart 返回 Detections 所依据的图表：已载入 candles，并按 cfg 完成形态、证据与指标识别。
+func DetectionChart(candles []*v1.Candlestick, cfg Config) *charting.EnhancedKline {
+	return detectPatterns(candles, nil, cfg)
+}
+
+// analogReport searches history for analogs of the raw candles cs; nil
+// without a history.
+// analogReport 在 history 中检索原始K线 cs 的相似形态；未提供历史时返回 nil。
+func analogReport(symbol string, cs []identify.CandlestickWrapper, history map[string][]*v1.Candlestick, cfg identify.AnalogConfig) *identify.AnalogResult {
+	if history == nil {
+		return nil
+	}
+	wrapped := make(map[string][]identify.CandlestickWrapper, len(history))
+	for s, candles := range history {
+		if s != symbol {
+			wrapped[s] = wrapCandles(candles)
+		}
+	}
+	res := identify.SearchAnalogs(symbol, cs, wrapped, cfg)
+	return &res
+}
+
+// relativeStrengthReport measures cs against the benchmark and ranks symbol
+// within the watchlist (the symbol itself always takes part).
+// relativeStrengthReport 计算 cs 相对基准的强弱，并在观察列表（始终包含标的本身）中排名。
+func relativeStrengthReport(symbol string, cs []identify.CandlestickWrapper, bench *Benchmark, cfg identify.RelativeStrengthConfig) *RelativeStrengthReport {
+	if bench == nil || len(bench.Candles) == 0 {
+		return nil
+	}
+	benchmark := wrapCandles(bench.Candles)
+	res, _, ok := identify.AnalyzeRelativeStrength(cs, benchmark, cfg)
+	if !ok {
+		return nil
+	}
+	out := &RelativeStrengthReport{Benchmark: bench.Symbol, RelativeStrengthResult: res}
+	if len(bench.Watchlist) == 0 {
+		return out
+	}
+	watchlist := make(map[string][]identify.CandlestickWrapper, len(bench.Watchlist)+1)
+	for s, candles := range bench.Watchlist {
+		watchlist[s] = wrapCandles(candles)
+	}
+	watchlist[symbol] = cs
+	ranks := identify.RankRelativeStrength(watchlist, benchmark, cfg)
+	for _, r := range ranks {
+		if r.Symbol == symbol {
+			pct := r.Percentile
+			out.Rank, out.Percentile, out.Ranked = r.Rank, &pct, len(ranks)
+		}
+	}
+	return out
+}
+
+func wrapCandles(candles []*v1.Candlestick) []identify.CandlestickWrapper {
+	out := make([]identify.CandlestickWrapper, len(candles))
+	for i, c := range candles {
+		out[i] = identify.NewCandlestickWrapper(c)
+	}
+	return out
+}
+
+// patternInvalidations turns the invalidation prices of the top live
+// (pending or confirmed) directional patterns into InvalidIf conditions.
+// patternInvalidations 将排名靠前且仍有效（待确认或已确认）的方向性形态的失效价转换为 InvalidIf 条件。
+func patternInvalidations(reports []PatternReport, limit int) []string {
+	out := make([]string, 0, limit)
+	for _, p := range reports {
+		if len(out) >= limit {
+			break
+		}
+		if p.State != identify.PatternPending && p.State != identify.PatternConfirmed {
+			continue
+		}
+		switch p.Direction {
+		case "bullish":
+			out = append(out, fmt.Sprintf("%s at %s: close below %.2f", p.Type, p.Time, p.InvalidationPrice))
+		case "bearish":
+			out = append(out, fmt.Sprintf("%s at %s: close above %.2f", p.Type, p.Time, p.InvalidationPrice))
+		}
+	}
+	return out
+}
+
+func positionPtr(i int) *int {
+	if i < 0 {
+		return nil
 	}
+	return &i
+}
+
+// trendlineBreakReports lists closes through fitted trendlines, oldest first.
+// trendlineBreakReports 按时间顺序列出对拟合趋势线的收盘突破。
+func trendlineBreakReports(cs []identify.CandlestickWrapper, cfg identify.TrendLineConfig) []TrendlineBreakReport {
+	breaks := identify.TrendLineBreaks(cs, identify.DetectTrendLines(cs, cfg))
+	out := make([]TrendlineBreakReport, 0, len(breaks))
+	for _, b := range breaks {
+		out = append(out, TrendlineBreakReport{
+			Direction: b.Direction,
+			Position:  b.Position,
+			Time:      time.Unix(cs[b.Position].Timestamp, 0).Format("2006-01-02 15:04:05"),
+			Price:     b.Price,
+			LinePrice: b.LinePrice,
+			LineType:  b.LineType,
+			Touches:   b.Touches,
+		})
+	}
+	return out
 }
 
 // AppendSignalLogCSV appends signal rows to a CSV file for personal replay.
@@ -154,12 +373,91 @@ func AppendSignalLogCSV(path string, report Report) error {
 	return w.Error()
 }
 
+// SignalLogRow is one pattern row of the signal log CSV.
+// SignalLogRow 为信号日志 CSV 中的一行形态记录。
+type SignalLogRow struct {
+	Time          string
+	Symbol        string
+	Pattern       string
+	Trend         string
+	VolumeState   string
+	DecisionScore float64
+	DecisionLevel string
+	ForwardRet3   *float64
+	ForwardRet5   *float64
+	ForwardRet10  *float64
+	Reason        string
+}
+
+// ForwardReturn returns the logged forward return for horizon 3, 5 or 10.
+// ForwardReturn 返回周期 3、5 或 10 的已记录前瞻收益。
+func (r SignalLogRow) ForwardReturn(horizon int) *float64 {
+	switch horizon {
+	case 3:
+		return r.ForwardRet3
+	case 5:
+		return r.ForwardRet5
+	case 10:
+		return r.ForwardRet10
+	default:
+		return nil
+	}
+}
+
+// LoadSignalLogCSV reads a log written by AppendSignalLogCSV.
+// LoadSignalLogCSV 读取 AppendSignalLogCSV 写入的日志。
+func LoadSignalLogCSV(path string) ([]SignalLogRow, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	out := make([]SignalLogRow, 0, len(records)-1)
+	for i, rec := range records[1:] {
+		if len(rec) != 11 {
+			return nil, fmt.Errorf("row %d: expected 11 fields, got %d", i+2, len(rec))
+		}
+		row := SignalLogRow{
+			Time:          rec[0],
+			Symbol:        rec[1],
+			Pattern:       rec[2],
+			Trend:         rec[3],
+			VolumeState:   rec[4],
+			DecisionLevel: rec[6],
+			Reason:        rec[10],
+		}
+		if row.DecisionScore, err = strconv.ParseFloat(rec[5], 64); err != nil {
+			return nil, fmt.Errorf("row %d: decision_score: %w", i+2, err)
+		}
+		for j, dst := range []**float64{&row.ForwardRet3, &row.ForwardRet5, &row.ForwardRet10} {
+			if rec[7+j] == "" {
+				continue
+			}
+			v, err := strconv.ParseFloat(rec[7+j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %s: %w", i+2, records[0][7+j], err)
+			}
+			*dst = &v
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
+
 func toPatternSignals(patterns []charting.Pattern) []identify.PatternSignal {
 	out := make([]identify.PatternSignal, 0, len(patterns))
 	for _, p := range patterns {
 		out = append(out, identify.PatternSignal{
 			Type:      p.Type,
-			Direction: patternDirection(p.Type),
+			Direction: identify.PatternDirection(p.Type),
 			Position:  p.Position,
 			Strength:  p.Strength,
 			Risk:      p.Risk,
@@ -211,7 +509,7 @@ func movingAverageClose(cs []identify.CandlestickWrapper, start, end int) float6
 }
 
 func trendMatchScore(patternType, trend string) float64 {
-	dir := patternDirection(patternType)
+	dir := identify.PatternDirection(patternType)
 	switch dir {
 	case "neutral":
 		return 0.5
@@ -278,6 +576,26 @@ func volumeStateScore(state string) float64 {
 	}
 }
 
+// regimeSeries classifies every bar of cs causally when cfg has regime score
+// overrides, and returns nil otherwise since no pattern needs it.
+// regimeSeries 在 cfg 含市场状态评分覆盖时逐根（仅用此前数据）判定市场状态，否则返回 nil。
+func regimeSeries(cs []identify.CandlestickWrapper, cfg Config) []*identify.RegimeResult {
+	if len(cfg.RegimeScore) == 0 {
+		return nil
+	}
+	return identify.RegimeSeries(cs, cfg.Regime)
+}
+
+// scoreConfigAt returns the score config for the regime of bar pos in
+// regimes; cfg.Score without overrides or enough bars.
+// scoreConfigAt 返回 regimes 中第 pos 根K线所处市场状态的评分配置；无覆盖项或数据不足时为 cfg.Score。
+func scoreConfigAt(regimes []*identify.RegimeResult, pos int, cfg Config) ScoreConfig {
+	if pos < len(regimes) && regimes[pos] != nil {
+		return cfg.ScoreFor(regimes[pos].Regime)
+	}
+	return cfg.Score
+}
+
 func decisionScore(cfg ScoreConfig, baseStrength, trendScore, volumeScore float64) float64 {
 	score := baseStrength*cfg.PatternWeight + trendScore*cfg.TrendWeight + volumeScore*cfg.VolumeWeight
 	if math.IsNaN(score) || math.IsInf(score, 0) {
@@ -360,35 +678,32 @@ func forwardReturn(candles []*v1.Candlestick, pos, horizon int) *float64 {
 	return &ret
 }
 
-func evidenceKey(patternType string, pos int) string {
-	return patternType + "#" + strconv.Itoa(pos)
+// netForwardReturns simulates trades at 3, 5 and 10 bars under c in the
+// pattern's direction, long when bullish and short when bearish; it returns
+// nils when costs are off or the pattern is neutral, and the entry block
+// reason, if any.
+func netForwardReturns(symbol string, candles []*v1.Candlestick, pos int, direction string, c cost.Config) ([3]*float64, string) {
+	var out [3]*float64
+	if !c.Enabled() || direction == "neutral" {
+		return out, ""
+	}
+	blocked := ""
+	for i, h := range []int{3, 5, 10} {
+		t := c.Simulate(symbol, candles, pos, h, direction == "bullish")
+		switch t.Blocked {
+		case "":
+			ret := t.NetReturn
+			out[i] = &ret
+		case cost.BlockedExit:
+		default:
+			blocked = t.Blocked
+		}
+	}
+	return out, blocked
 }
 
-func patternDirection(patternType string) string {
-	switch patternType {
-	case "Hammer",
-		"Inverted Hammer",
-		"Bullish Engulfing",
-		"Piercing Line",
-		"Morning Star",
-		"Three White Soldiers",
-		"Tweezer Bottoms",
-		"Rising Window",
-		"Rising Three Methods":
-		return "bullish"
-	case "Hanging Man",
-		"Shooting Star",
-		"Bearish Engulfing",
-		"Dark Cloud Cover",
-		"Evening Star",
-		"Three Black Crows",
-		"Tweezer Tops",
-		"Falling Window",
-		"Falling Three Methods":
-		return "bearish"
-	default:
-		return "neutral"
-	}
+func evidenceKey(patternType string, pos int) string {
+	return patternType + "#" + strconv.Itoa(pos)
 }
 
 func formatFloatPtr(v *float64) string {
@@ -2,12 +2,16 @@ package signal
 
 import (
 	"encoding/json"
+	"math"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/LEVI-Tempest/Candle/pkg/cost"
+	"github.com/LEVI-Tempest/Candle/pkg/identify"
 	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
 )
 
@@ -136,3 +140,396 @@ func TestBuildReportGoldenSummary(t *testing.T) {
 		t.Fatalf("golden mismatch\nwant:\n%s\n\ngot:\n%s", string(want), string(got))
 	}
 }
+
+func TestBuildReportRegimeScoreOverride(t *testing.T) {
+	base := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	candles := make([]*v1.Candlestick, 0, 150)
+	price := 100.0
+	for i := 0; i < 150; i++ {
+		open := price
+		price *= 1 + 0.01*math.Sin(float64(i)/3) + 0.004
+		candles = append(candles, &v1.Candlestick{
+			Timestamp: base.AddDate(0, 0, i).Unix(),
+			Open:      open,
+			High:      math.Max(open, price) * 1.01,
+			Low:       math.Min(open, price) * 0.99,
+			Close:     price,
+			Volume:    1000 + float64(i%7)*100,
+		})
+	}
+
+	plain := BuildReport("XSHE:300059", "2026-03-09T09:30:00Z", "test", candles, DefaultConfig())
+	if plain.Regime == nil || plain.Regime.Regime == "" {
+		t.Fatalf("expected a regime for 150 bars: %+v", plain.Regime)
+	}
+	if len(plain.Patterns) == 0 {
+		t.Fatal("expected patterns in the fixture")
+	}
+	schemaPath := filepath.Join("..", "..", "docs", "signal.schema.json")
+	if err := ValidateReportSchema(plain, schemaPath); err != nil {
+		t.Fatalf("schema validation failed: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.RegimeScore = map[string]ScoreConfig{
+		plain.Regime.Regime: {PatternWeight: 90, TrendWeight: 5, VolumeWeight: 5, StrongThreshold: 100, MediumThreshold: 100},
+	}
+	if err := validateConfig(cfg); err != nil {
+		t.Fatalf("override should be valid: %v", err)
+	}
+	report := BuildReport("XSHE:300059", "2026-03-09T09:30:00Z", "test", candles, cfg)
+	scoreCfg := cfg.ScoreFor(plain.Regime.Regime)
+	// Each pattern takes the override only when its own bar is in the regime.
+	// 仅当形态所在K线处于该市场状态时才采用覆盖项。
+	data := wrapCandles(candles)
+	plainLevels := make(map[string]string, len(plain.Patterns))
+	for _, p := range plain.Patterns {
+		plainLevels[evidenceKey(p.Type, p.Position)] = p.DecisionLevel
+	}
+	overridden, kept := 0, 0
+	for _, p := range report.Patterns {
+		r, ok := identify.ClassifyRegime(data[:p.Position+1], cfg.Regime)
+		if !ok || r.Regime != plain.Regime.Regime {
+			if p.DecisionLevel != plainLevels[evidenceKey(p.Type, p.Position)] {
+				t.Fatalf("pattern outside the regime should keep the default level: %+v", p)
+			}
+			kept++
+			continue
+		}
+		overridden++
+		if p.DecisionScore < 100 && p.DecisionLevel != "weak" {
+			t.Fatalf("regime thresholds not applied: %+v", p)
+		}
+	}
+	if overridden == 0 || kept == 0 {
+		t.Fatalf("expected patterns in and out of the %s regime: %d in, %d out", plain.Regime.Regime, overridden, kept)
+	}
+	if scoreCfg.PatternWeight != 90 || cfg.ScoreFor("unknown").PatternWeight != cfg.Score.PatternWeight {
+		t.Fatalf("unexpected score config: %+v", scoreCfg)
+	}
+}
+
+func TestBuildReportWithBenchmark(t *testing.T) {
+	base := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	series := func(n int, price func(i int) float64) []*v1.Candlestick {
+		out := make([]*v1.Candlestick, n)
+		for i := range out {
+			p := price(i)
+			out[i] = &v1.Candlestick{
+				Timestamp: base.AddDate(0, 0, i).Unix(),
+				Open:      p * 0.995, High: p * 1.01, Low: p * 0.985, Close: p, Volume: 1000 + float64(i%5)*100,
+			}
+		}
+		return out
+	}
+	index := series(60, func(i int) float64 { return 100 + math.Sin(float64(i)/4) })
+	candles := series(60, func(i int) float64 { return 50 + 0.2*float64(i) + math.Sin(float64(i)/3) })
+	bench := &Benchmark{
+		Symbol:  "CSI300",
+		Candles: index,
+		Watchlist: map[string][]*v1.Candlestick{
+			"LAGGARD": series(60, func(i int) float64 { return 80 - 0.1*float64(i) }),
+		},
+	}
+
+	report := BuildReportWithBenchmark("XSHE:300059", "2026-03-09T09:30:00Z", "test", candles, bench, DefaultConfig())
+	rs := report.RelativeStrength
+	if rs == nil || rs.Benchmark != "CSI300" || !rs.Outperforming {
+		t.Fatalf("expected outperformance vs CSI300: %+v", rs)
+	}
+	if rs.Rank != 1 || rs.Ranked != 2 || rs.Percentile == nil || *rs.Percentile != 1 {
+		t.Fatalf("expected first of two in the watchlist: %+v", rs)
+	}
+	schemaPath := filepath.Join("..", "..", "docs", "signal.schema.json")
+	if err := ValidateReportSchema(report, schemaPath); err != nil {
+		t.Fatalf("schema validation failed: %v", err)
+	}
+	// The weakest symbol keeps its percentile of 0 in the JSON output.
+	// 最弱标的的百分位 0 仍保留在 JSON 输出中。
+	laggard := BuildReportWithBenchmark("LAGGARD", "2026-03-09T09:30:00Z", "test", bench.Watchlist["LAGGARD"],
+		&Benchmark{Symbol: "CSI300", Candles: index, Watchlist: map[string][]*v1.Candlestick{"XSHE:300059": candles}}, DefaultConfig())
+	if raw, _ := json.Marshal(laggard.RelativeStrength); !strings.Contains(string(raw), `"percentile":0,`) {
+		t.Fatalf("expected percentile 0 for the weakest symbol: %s", raw)
+	}
+	if plain := BuildReport("XSHE:300059", "2026-03-09T09:30:00Z", "test", candles, DefaultConfig()); plain.RelativeStrength != nil {
+		t.Fatalf("report without benchmark should omit relative_strength: %+v", plain.RelativeStrength)
+	}
+}
+
+func TestBuildReportHeikinAshi(t *testing.T) {
+	base := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	candles := make([]*v1.Candlestick, 0, 40)
+	price := 100.0
+	for i := 0; i < 40; i++ {
+		open := price
+		price += 2 * math.Sin(float64(i)/4)
+		candles = append(candles, &v1.Candlestick{
+			Timestamp: base.AddDate(0, 0, i).Unix(),
+			Open:      open,
+			High:      math.Max(open, price) + 0.3,
+			Low:       math.Min(open, price) - 0.3,
+			Close:     price,
+			Volume:    1000 + float64(i%4)*200,
+		})
+	}
+	cfg := DefaultConfig()
+	cfg.HeikinAshi = true
+	report := BuildReport("XSHE:300059", "2026-03-09T09:30:00Z", "test", candles, cfg)
+	if report.Metadata["transform"] != "heikin_ashi" {
+		t.Fatalf("expected heikin_ashi transform in metadata: %+v", report.Metadata)
+	}
+	flips := 0
+	for _, p := range report.Patterns {
+		if p.Type == identify.SignalHABullishFlip || p.Type == identify.SignalHABearishFlip {
+			flips++
+			if p.Direction == "neutral" {
+				t.Fatalf("HA flip should be directional: %+v", p)
+			}
+		}
+	}
+	if flips == 0 {
+		t.Fatalf("expected HA flips in an oscillating series: %+v", report.Patterns)
+	}
+	schemaPath := filepath.Join("..", "..", "docs", "signal.schema.json")
+	if err := ValidateReportSchema(report, schemaPath); err != nil {
+		t.Fatalf("schema validation failed: %v", err)
+	}
+	plain := BuildReport("XSHE:300059", "2026-03-09T09:30:00Z", "test", candles, DefaultConfig())
+	if plain.Metadata != nil {
+		t.Fatalf("raw report should have no metadata: %+v", plain.Metadata)
+	}
+	// Prices, trend and trendline breaks come from the traded candles.
+	// 价格、趋势与趋势线突破均基于真实成交的K线。
+	for _, p := range report.Patterns {
+		if p.Price != candles[p.Position].Close {
+			t.Fatalf("pattern price should be the traded close: %+v", p)
+		}
+	}
+	for _, ev := range report.Evidence {
+		if ev.Price != candles[ev.Position].Close {
+			t.Fatalf("evidence price should be the traded close: %+v", ev)
+		}
+	}
+	if report.Trend != plain.Trend || !reflect.DeepEqual(report.TrendlineBreaks, plain.TrendlineBreaks) {
+		t.Fatalf("trend and trendline breaks should match the raw report: %s %+v vs %s %+v",
+			report.Trend, report.TrendlineBreaks, plain.Trend, plain.TrendlineBreaks)
+	}
+}
+
+func TestBuildReportWithHistoryAnalogs(t *testing.T) {
+	base := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	series := func(n int, price func(i int) float64) []*v1.Candlestick {
+		out := make([]*v1.Candlestick, n)
+		for i := range out {
+			p := price(i)
+			out[i] = &v1.Candlestick{
+				Timestamp: base.AddDate(0, 0, i).Unix(),
+				Open:      p * 0.995, High: p * 1.01, Low: p * 0.985, Close: p, Volume: 1000 + float64(i%5)*100,
+			}
+		}
+		return out
+	}
+	candles := series(60, func(i int) float64 { return 50 + 3*math.Sin(float64(i)/3) })
+	history := map[string][]*v1.Candlestick{
+		"PEER": series(80, func(i int) float64 { return 20 + math.Sin(float64(i)/3+1) }),
+	}
+
+	cfg := DefaultConfig()
+	cfg.Analog.Metric = identify.AnalogDTW
+	report := BuildReportWithInputs("XSHE:300059", "2026-03-09T09:30:00Z", "test", candles, ReportInputs{History: history}, cfg)
+	an := report.Analogs
+	if an == nil || an.Metric != identify.AnalogDTW || len(an.Matches) != cfg.Analog.TopK {
+		t.Fatalf("expected %d DTW analogs: %+v", cfg.Analog.TopK, an)
+	}
+	for i := 1; i < len(an.Matches); i++ {
+		if an.Matches[i].Distance < an.Matches[i-1].Distance {
+			t.Fatalf("matches should be sorted by distance: %+v", an.Matches)
+		}
+	}
+	if len(an.Outcomes) != 3 {
+		t.Fatalf("expected 3/5/10-bar outcomes: %+v", an.Outcomes)
+	}
+	schemaPath := filepath.Join("..", "..", "docs", "signal.schema.json")
+	if err := ValidateReportSchema(report, schemaPath); err != nil {
+		t.Fatalf("schema validation failed: %v", err)
+	}
+	if plain := BuildReport("XSHE:300059", "2026-03-09T09:30:00Z", "test", candles, DefaultConfig()); plain.Analogs != nil {
+		t.Fatalf("report without history should omit analogs: %+v", plain.Analogs)
+	}
+}
+
+func TestBuildTrainingSamples(t *testing.T) {
+	base := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	candles := make([]*v1.Candlestick, 0, 120)
+	price := 100.0
+	for i := 0; i < 120; i++ {
+		open := price
+		price += 2.5 * math.Sin(float64(i)/3)
+		candles = append(candles, &v1.Candlestick{
+			Timestamp: base.AddDate(0, 0, i).Unix(),
+			Open:      open,
+			High:      math.Max(open, price) + 0.2 + float64(i%3)*0.4,
+			Low:       math.Min(open, price) - 0.2 - float64(i%4)*0.3,
+			Close:     price,
+			Volume:    1000 + float64(i%5)*300,
+		})
+	}
+	samples := BuildTrainingSamples("XSHE:300059", candles, DefaultConfig(), 5)
+	if len(samples) == 0 {
+		t.Fatal("expected samples from an oscillating series")
+	}
+	for _, s := range samples {
+		if s.Position+5 >= len(candles) {
+			t.Fatalf("sample without a full horizon: %+v", s)
+		}
+		ret := *forwardReturn(candles, s.Position, 5)
+		if s.Direction == "bearish" {
+			ret = -ret
+		}
+		if s.Return != ret || s.Hit != (ret > 0) {
+			t.Fatalf("return should be direction-signed: %+v vs %.4f", s, ret)
+		}
+		if s.ExitTime <= s.Time || s.DecisionLevel == "" {
+			t.Fatalf("sample should carry its exit time and decision level: %+v", s)
+		}
+		for _, name := range []string{FeatureBaseStrength, FeatureContextScore, FeatureVolumeScore, FeatureTrendScore, FeatureVolumeState} {
+			if _, ok := s.Features[name]; !ok {
+				t.Fatalf("sample missing feature %s: %+v", name, s.Features)
+			}
+		}
+	}
+
+	// Regime overrides apply per sample, by the regime at its own bar.
+	// 市场状态覆盖项按每个样本所在K线的市场状态生效。
+	cfg := DefaultConfig()
+	override := ScoreConfig{PatternWeight: 90, TrendWeight: 5, VolumeWeight: 5, StrongThreshold: 100, MediumThreshold: 100}
+	cfg.RegimeScore = map[string]ScoreConfig{
+		identify.RegimeTrend: override, identify.RegimeRange: override, identify.RegimeHighVolatility: override,
+	}
+	data := wrapCandles(candles)
+	overridden := 0
+	for _, s := range BuildTrainingSamples("XSHE:300059", candles, cfg, 5) {
+		want := decisionScore(cfg.Score, s.Features[FeatureBaseStrength], s.Features[FeatureTrendScore], s.Features[FeatureVolumeState])
+		if _, ok := identify.ClassifyRegime(data[:s.Position+1], cfg.Regime); ok {
+			want = decisionScore(override, s.Features[FeatureBaseStrength], s.Features[FeatureTrendScore], s.Features[FeatureVolumeState])
+			if s.DecisionLevel != "weak" {
+				t.Fatalf("regime thresholds not applied: %+v", s)
+			}
+			overridden++
+		}
+		if s.DecisionScore != want {
+			t.Fatalf("decision score %.4f, want %.4f: %+v", s.DecisionScore, want, s)
+		}
+	}
+	if overridden == 0 {
+		t.Fatal("expected samples late enough to classify a regime")
+	}
+}
+
+func TestLoadSignalLogCSVRoundTrip(t *testing.T) {
+	ret5 := 1.25
+	report := Report{
+		Symbol: "XSHE:300059",
+		AsOf:   "2026-03-09T09:30:00Z",
+		Trend:  "uptrend",
+		Patterns: []PatternReport{
+			{Type: "Hammer", VolumeState: "confirmed", DecisionScore: 71.5, DecisionLevel: "strong", ForwardRet5: &ret5, Reason: []string{"a", "b"}},
+			{Type: "Doji", VolumeState: "neutral", DecisionScore: 40, DecisionLevel: "weak"},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "signal_log.csv")
+	if err := AppendSignalLogCSV(path, report); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := AppendSignalLogCSV(path, report); err != nil {
+		t.Fatalf("append again: %v", err)
+	}
+	rows, err := LoadSignalLogCSV(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("expected 4 rows without the header, got %d", len(rows))
+	}
+	hammer := rows[0]
+	if hammer.Symbol != report.Symbol || hammer.Pattern != "Hammer" || hammer.DecisionScore != 71.5 || hammer.Reason != "a | b" {
+		t.Fatalf("unexpected row: %+v", hammer)
+	}
+	if got := hammer.ForwardReturn(5); got == nil || *got != ret5 {
+		t.Fatalf("forward_ret_5 should round-trip, got %v", got)
+	}
+	if hammer.ForwardReturn(3) != nil || rows[1].ForwardReturn(5) != nil || hammer.ForwardReturn(7) != nil {
+		t.Fatal("missing forward returns and unknown horizons should be nil")
+	}
+}
+
+func TestBuildReportNetOfCost(t *testing.T) {
+	base := time.Date(2025, 1, 2, 15, 0, 0, 0, time.Local)
+	candles := make([]*v1.Candlestick, 0, 80)
+	price := 20.0
+	for i := 0; i < 80; i++ {
+		open := price
+		price += 0.5 * math.Sin(float64(i)/3)
+		candles = append(candles, &v1.Candlestick{
+			Timestamp: base.AddDate(0, 0, i).Unix(),
+			Open:      open,
+			High:      math.Max(open, price) + 0.05 + float64(i%3)*0.08,
+			Low:       math.Min(open, price) - 0.05 - float64(i%4)*0.06,
+			Close:     price,
+			Volume:    1000 + float64(i%5)*300,
+		})
+	}
+	cfg := DefaultConfig()
+	cfg.Cost = cost.DefaultCNAConfig()
+	report := BuildReport("XSHG:600000", "2026-03-09T09:30:00Z", "test", candles, cfg)
+	priced := 0
+	for _, p := range report.Patterns {
+		if p.ForwardRet5 == nil || p.Direction != "bullish" {
+			if p.NetForwardRet5 != nil {
+				t.Fatalf("net return only for tradable long patterns with a gross return: %+v", p)
+			}
+			if p.Direction == "bearish" && p.TradeBlocked != cost.BlockedShort {
+				t.Fatalf("A-shares cannot short a bearish pattern: %+v", p)
+			}
+			continue
+		}
+		if p.NetForwardRet5 == nil || *p.NetForwardRet5 >= *p.ForwardRet5 {
+			t.Fatalf("net return should be below the gross return: %+v", p)
+		}
+		priced++
+	}
+	if priced == 0 {
+		t.Fatal("expected patterns with forward returns")
+	}
+
+	// Where shorting is allowed, bearish patterns earn the falling price.
+	// 允许做空时，看跌形态按价格下跌获利。
+	hk := cfg
+	hk.Cost = cost.DefaultHKConfig()
+	hk.Cost.AllowShort = true
+	shorted := 0
+	for _, p := range BuildReport("XHKG:00700", "2026-03-09T09:30:00Z", "test", candles, hk).Patterns {
+		if p.Direction != "bearish" || p.ForwardRet5 == nil || p.NetForwardRet5 == nil {
+			continue
+		}
+		if *p.NetForwardRet5 >= -*p.ForwardRet5 {
+			t.Fatalf("short net return should be below the negated gross return: %+v", p)
+		}
+		shorted++
+	}
+	if shorted == 0 {
+		t.Fatal("expected bearish patterns with short net returns")
+	}
+	if err := ValidateReportSchema(report, filepath.Join("..", "..", "docs", "signal.schema.json")); err != nil {
+		t.Fatalf("schema validation failed: %v", err)
+	}
+
+	for _, s := range BuildTrainingSamples("XSHG:600000", candles, cfg, 5) {
+		if (s.NetReturn != nil) != (s.Direction == "bullish") {
+			t.Fatalf("only bullish samples are tradable without shorting: %+v", s)
+		}
+		if s.NetReturn != nil && (*s.NetReturn >= s.Return || s.NetExitTime != s.ExitTime) {
+			t.Fatalf("unexpected net sample: %+v", s)
+		}
+	}
+}
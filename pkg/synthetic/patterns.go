@@ -0,0 +1,139 @@
+package synthetic
+
+import "sort"
+
+// templateBar is one bar of a pattern, in volatility units from the previous close.
+type templateBar struct{ open, high, low, close float64 }
+
+// template is the bar shape of a pattern; context is the trend (-1 falling,
+// +1 rising, 0 any) pushed into the bars before it. direction follows
+// identify's grading, so doji and harami reversals stay neutral.
+type template struct {
+	direction string
+	context   int
+	bars      []templateBar
+}
+
+// Each template satisfies its detector with the default thresholds. Long
+// bodies are three volatility units and short bodies a fraction of one, so
+// atr/median_body references almost always accept them too.
+// 每个模板在默认阈值下均满足对应识别器；长实体为三个波动单位、短实体不足一个，atr/median_body 参照下也几乎总能识别。
+var bullishTemplates = map[string]template{
+	"Hammer":          {"bullish", -1, []templateBar{{0, 0.31, -1, 0.3}}},
+	"Inverted Hammer": {"bullish", -1, []templateBar{{0, 1.3, -0.01, 0.3}}},
+	"Dragonfly Doji":  {"neutral", -1, []templateBar{{0, 0.03, -1.2, 0.02}}},
+	"White Marubozu":  {"neutral", 0, []templateBar{{0, 3, 0, 3}}},
+	"Bullish Engulfing": {"bullish", -1, []templateBar{
+		{0, 0.2, -1.2, -1},
+		{-1.2, 0.5, -1.3, 0.4},
+	}},
+	"Piercing Line": {"bullish", -1, []templateBar{
+		{0, 0.1, -3.1, -3},
+		{-3.3, -1.3, -3.4, -1.4},
+	}},
+	"Tweezer Bottoms": {"bullish", -1, []templateBar{
+		{0, 0.1, -1.3, -1},
+		{-0.9, 0, -1.3, -0.1},
+	}},
+	"Rising Window": {"bullish", 1, []templateBar{
+		{0, 1.1, -0.1, 1},
+		{1.4, 2.3, 1.3, 2.2},
+	}},
+	"Bullish Harami": {"neutral", -1, []templateBar{
+		{0, 0.1, -3.1, -3},
+		{-2, -1.1, -2.1, -1.3},
+	}},
+	"Morning Star": {"bullish", -1, []templateBar{
+		{0, 0.1, -3.1, -3},
+		{-3.4, -3.2, -3.8, -3.25},
+		{-3.2, -1.1, -3.3, -1.2},
+	}},
+	"Morning Doji Star": {"neutral", -1, []templateBar{
+		{0, 0.1, -3.1, -3},
+		{-3.4, -3.2, -3.7, -3.38},
+		{-3.2, -1.1, -3.3, -1.2},
+	}},
+	"Three White Soldiers": {"bullish", -1, []templateBar{
+		{0, 3.1, -0.1, 3},
+		{2.2, 5.3, 2.1, 5.2},
+		{4.4, 7.5, 4.3, 7.4},
+	}},
+	"Rising Three Methods": {"bullish", 1, []templateBar{
+		{0, 3.1, -0.1, 3},
+		{2.8, 2.9, 2.1, 2.2},
+		{2.3, 2.4, 1.6, 1.7},
+		{1.8, 1.9, 1.1, 1.2},
+		{1.3, 4.4, 1.2, 4.3},
+	}},
+}
+
+// mirrorNames pairs each bullish template with its bearish counterpart.
+var mirrorNames = map[string]string{
+	"Hammer":               "Hanging Man",
+	"Inverted Hammer":      "Shooting Star",
+	"Dragonfly Doji":       "Gravestone Doji",
+	"White Marubozu":       "Black Marubozu",
+	"Bullish Engulfing":    "Bearish Engulfing",
+	"Piercing Line":        "Dark Cloud Cover",
+	"Tweezer Bottoms":      "Tweezer Tops",
+	"Rising Window":        "Falling Window",
+	"Bullish Harami":       "Bearish Harami",
+	"Morning Star":         "Evening Star",
+	"Morning Doji Star":    "Evening Doji Star",
+	"Three White Soldiers": "Three Black Crows",
+	"Rising Three Methods": "Falling Three Methods",
+}
+
+// neutralTemplates are the patterns without a bullish/bearish pair.
+var neutralTemplates = map[string]template{
+	"Doji":             {"neutral", 0, []templateBar{{0, 0.4, -1, 0.05}}},
+	"Long-Legged Doji": {"neutral", 0, []templateBar{{0, 1.2, -1.2, 0.05}}},
+	"Spinning Top":     {"neutral", 0, []templateBar{{0, 0.8, -0.7, 0.2}}},
+	"Umbrella":         {"neutral", 0, []templateBar{{0, 0.3, -1, 0.3}}},
+}
+
+// templates is every injectable pattern by detector name.
+var templates = buildTemplates()
+
+func buildTemplates() map[string]template {
+	out := make(map[string]template, 2*len(bullishTemplates)+len(neutralTemplates))
+	for name, t := range bullishTemplates {
+		out[name] = t
+		out[mirrorNames[name]] = mirror(t)
+	}
+	for name, t := range neutralTemplates {
+		out[name] = t
+	}
+	// The pair of a Hammer shape in an uptrend is the Hanging Man, which keeps
+	// the Hammer's lower shadow: mirror only the body color and the context.
+	// 锤头线在上升趋势中即吊颈线，保留下影线，只翻转实体颜色与趋势背景。
+	out["Hanging Man"] = template{"bearish", 1, []templateBar{{0.3, 0.31, -1, 0}}}
+	out["Shooting Star"] = template{"bearish", 1, []templateBar{{0.3, 1.3, -0.01, 0}}}
+	return out
+}
+
+// mirror flips a template upside down: bullish becomes bearish.
+func mirror(t template) template {
+	m := template{direction: t.direction, context: -t.context, bars: make([]templateBar, len(t.bars))}
+	switch t.direction {
+	case "bullish":
+		m.direction = "bearish"
+	case "bearish":
+		m.direction = "bullish"
+	}
+	for i, b := range t.bars {
+		m.bars[i] = templateBar{open: -b.open, high: -b.low, low: -b.high, close: -b.close}
+	}
+	return m
+}
+
+// Patterns returns the names of the injectable patterns, sorted.
+// Patterns 返回可注入形态的名称（已排序）。
+func Patterns() []string {
+	out := make([]string, 0, len(templates))
+	for name := range templates {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
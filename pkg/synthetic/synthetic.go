@@ -0,0 +1,407 @@
+// Package synthetic generates OHLCV series with GBM or GARCH returns, regime
+// switching and injected candlestick patterns with known ground truth.
+// 合成数据包 - 以 GBM 或 GARCH 收益、状态切换生成K线，并注入已知真值的蜡烛形态。
+package synthetic
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+)
+
+// Return models
+// 收益模型
+const (
+	ModelGBM   = "gbm"   // Constant volatility per regime (各状态内波动率恒定)
+	ModelGARCH = "garch" // GARCH(1,1) volatility clustering around each regime's level (围绕各状态波动水平的 GARCH(1,1) 波动聚集)
+)
+
+// Regime is one state of the regime-switching chain. Drift and Volatility
+// are per bar, in log-return units.
+// Regime 为状态切换链中的一个状态；Drift 与 Volatility 均为单根K线的对数收益。
+type Regime struct {
+	Name       string  `json:"name"`
+	Drift      float64 `json:"drift"`
+	Volatility float64 `json:"volatility"`
+	// VolumeMultiple scales the base volume while the regime is active (default 1).
+	// VolumeMultiple 为该状态下基础成交量的倍数（默认 1）。
+	VolumeMultiple float64 `json:"volume_multiple"`
+}
+
+// Injection plants Pattern so that its last bar is at Position, and/or
+// multiplies that bar's volume by VolumeMultiple.
+// Injection 在 Position 处注入以该K线结束的 Pattern，和/或将该K线成交量乘以 VolumeMultiple。
+type Injection struct {
+	Position int    `json:"position"`
+	Pattern  string `json:"pattern"` // Empty for a volume spike only (为空时仅注入放量)
+	// VolumeMultiple > 1 makes the last bar a volume spike.
+	// VolumeMultiple 大于 1 时最后一根K线为放量。
+	VolumeMultiple float64 `json:"volume_multiple"`
+	// ContextBars is how many bars before the pattern are pushed in the trend
+	// the pattern reverses or continues (default 5, negative for none).
+	// ContextBars 为形态之前按其反转或延续的趋势推动的K线数量（默认 5，负数表示不推动）。
+	ContextBars int `json:"context_bars"`
+}
+
+// Config controls series generation.
+// Config 控制序列生成。
+type Config struct {
+	Bars  int     `json:"bars"`  // default 250
+	Price float64 `json:"price"` // Starting price (起始价格，默认 100)
+	// Start is the first bar's date in UTC (default 2024-01-02), so a seed gives
+	// the same timestamps on every host; daily bars skip weekends.
+	// Start 为首根K线的 UTC 日期（默认 2024-01-02），相同种子在任何主机上生成相同时间戳；日线跳过周末。
+	Start string `json:"start"`
+	// IntervalMinutes > 0 makes intraday bars of that length instead of daily bars.
+	// IntervalMinutes 大于 0 时生成该分钟数的日内K线，而非日线。
+	IntervalMinutes int    `json:"interval_minutes"`
+	Model           string `json:"model"` // gbm/garch
+	// Regimes default to a single regime with 0.03% drift and 1.5% volatility.
+	// Regimes 默认为单一状态：漂移 0.03%，波动率 1.5%。
+	Regimes []Regime `json:"regimes"`
+	// Transition[i][j] is the chance of moving from regime i to j per bar; when
+	// empty each regime persists with StayProbability and otherwise switches
+	// uniformly.
+	// Transition[i][j] 为每根K线由状态 i 转至 j 的概率；为空时各状态以 StayProbability 保持，否则均匀切换。
+	Transition      [][]float64 `json:"transition"`
+	StayProbability float64     `json:"stay_probability"` // default 0.98
+	// GARCHAlpha and GARCHBeta are the shock and persistence weights (default 0.08/0.9).
+	// GARCHAlpha 与 GARCHBeta 为冲击与持续性权重（默认 0.08/0.9）。
+	GARCHAlpha float64 `json:"garch_alpha"`
+	GARCHBeta  float64 `json:"garch_beta"`
+	Volume     float64 `json:"volume"` // Base volume (基础成交量，默认 1e6)
+	// VolumeNoise is the log-normal volume noise; VolumeBeta raises volume with
+	// the size of the bar's move (defaults 0.3/0.5).
+	// VolumeNoise 为成交量对数正态噪声；VolumeBeta 使成交量随K线涨跌幅增大（默认 0.3/0.5）。
+	VolumeNoise float64     `json:"volume_noise"`
+	VolumeBeta  float64     `json:"volume_beta"`
+	Seed        int64       `json:"seed"`
+	Injections  []Injection `json:"injections"`
+}
+
+// DefaultConfig returns 250 daily GBM bars from 100 with no injections.
+// DefaultConfig 返回自 100 起 250 根日线 GBM、无注入的默认配置。
+func DefaultConfig() Config {
+	return Config{
+		Bars:            250,
+		Price:           100,
+		Start:           "2024-01-02",
+		Model:           ModelGBM,
+		Regimes:         []Regime{{Name: "base", Drift: 0.0003, Volatility: 0.015, VolumeMultiple: 1}},
+		StayProbability: 0.98,
+		GARCHAlpha:      0.08,
+		GARCHBeta:       0.9,
+		Volume:          1e6,
+		VolumeNoise:     0.3,
+		VolumeBeta:      0.5,
+		Seed:            1,
+	}
+}
+
+func normalizeConfig(cfg Config) Config {
+	def := DefaultConfig()
+	if cfg.Bars < 1 {
+		cfg.Bars = def.Bars
+	}
+	if cfg.Price <= 0 {
+		cfg.Price = def.Price
+	}
+	if cfg.Start == "" {
+		cfg.Start = def.Start
+	}
+	if cfg.Model != ModelGARCH {
+		cfg.Model = ModelGBM
+	}
+	if len(cfg.Regimes) == 0 {
+		cfg.Regimes = def.Regimes
+	}
+	cfg.Regimes = append([]Regime(nil), cfg.Regimes...)
+	for i := range cfg.Regimes {
+		if cfg.Regimes[i].Volatility <= 0 {
+			cfg.Regimes[i].Volatility = def.Regimes[0].Volatility
+		}
+		if cfg.Regimes[i].VolumeMultiple <= 0 {
+			cfg.Regimes[i].VolumeMultiple = 1
+		}
+		if cfg.Regimes[i].Name == "" {
+			cfg.Regimes[i].Name = fmt.Sprintf("regime_%d", i)
+		}
+	}
+	if cfg.StayProbability <= 0 || cfg.StayProbability > 1 {
+		cfg.StayProbability = def.StayProbability
+	}
+	if cfg.GARCHAlpha <= 0 || cfg.GARCHBeta <= 0 || cfg.GARCHAlpha+cfg.GARCHBeta >= 1 {
+		cfg.GARCHAlpha, cfg.GARCHBeta = def.GARCHAlpha, def.GARCHBeta
+	}
+	if cfg.Volume <= 0 {
+		cfg.Volume = def.Volume
+	}
+	if cfg.VolumeNoise < 0 {
+		cfg.VolumeNoise = def.VolumeNoise
+	}
+	if cfg.VolumeBeta < 0 {
+		cfg.VolumeBeta = def.VolumeBeta
+	}
+	return cfg
+}
+
+// Truth is the ground truth of one injection.
+// Truth 为一次注入的真值。
+type Truth struct {
+	Pattern   string `json:"pattern,omitempty"`
+	Direction string `json:"direction,omitempty"`
+	Start     int    `json:"start"`    // First bar of the pattern (形态首根K线)
+	Position  int    `json:"position"` // Last bar, as detectors report it (末根K线，与识别结果一致)
+	Time      string `json:"time"`
+	// VolumeSpike is set when the last bar's volume was multiplied.
+	// VolumeSpike 表示末根K线成交量被放大。
+	VolumeSpike bool `json:"volume_spike"`
+}
+
+// Series is a generated candle series with its regime path and ground truth.
+// Series 为生成的K线序列及其状态路径与真值。
+type Series struct {
+	Candles []*v1.Candlestick `json:"candles"`
+	Regimes []string          `json:"regimes"` // Regime name per bar (每根K线的状态)
+	Truth   []Truth           `json:"truth"`
+}
+
+// Generate builds one series from cfg; the same Seed gives the same series.
+// Generate 按 cfg 生成一条序列；相同 Seed 生成相同序列。
+func Generate(cfg Config) (Series, error) {
+	cfg = normalizeConfig(cfg)
+	start, err := time.Parse("2006-01-02", cfg.Start)
+	if err != nil {
+		return Series{}, fmt.Errorf("start: %w", err)
+	}
+	if err := validateTransition(cfg); err != nil {
+		return Series{}, err
+	}
+	plan, err := planInjections(cfg)
+	if err != nil {
+		return Series{}, err
+	}
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	out := Series{
+		Candles: make([]*v1.Candlestick, cfg.Bars),
+		Regimes: make([]string, cfg.Bars),
+		Truth:   make([]Truth, 0, len(cfg.Injections)),
+	}
+	ts := start
+	if cfg.IntervalMinutes <= 0 {
+		ts = nextWeekday(ts)
+	}
+	state := 0
+	prev := cfg.Price
+	variance := cfg.Regimes[0].Volatility * cfg.Regimes[0].Volatility
+	lastShock := 0.0
+	anchor, unit := 0.0, 0.0
+
+	for i := 0; i < cfg.Bars; i++ {
+		if i > 0 {
+			state = nextRegime(rng, cfg, state)
+			if cfg.IntervalMinutes > 0 {
+				ts = ts.Add(time.Duration(cfg.IntervalMinutes) * time.Minute)
+			} else {
+				ts = nextWeekday(ts.AddDate(0, 0, 1))
+			}
+		}
+		reg := cfg.Regimes[state]
+		sigma := reg.Volatility
+		if cfg.Model == ModelGARCH {
+			target := reg.Volatility * reg.Volatility
+			variance = target*(1-cfg.GARCHAlpha-cfg.GARCHBeta) + cfg.GARCHAlpha*lastShock*lastShock + cfg.GARCHBeta*variance
+			sigma = math.Sqrt(variance)
+		}
+		out.Regimes[i] = reg.Name
+
+		var bar *v1.Candlestick
+		move := 0.0
+		if p, ok := plan.bars[i]; ok {
+			// Template bars are in volatility units around the close before the pattern.
+			// 模板K线以波动率为单位、围绕形态之前的收盘价构造。
+			if p.offset == 0 {
+				anchor, unit = prev, prev*sigma
+			}
+			b := p.template.bars[p.offset]
+			bar = &v1.Candlestick{
+				Open:  anchor + b.open*unit,
+				High:  anchor + b.high*unit,
+				Low:   anchor + b.low*unit,
+				Close: anchor + b.close*unit,
+			}
+			move = math.Log(bar.Close / prev)
+			lastShock = move - reg.Drift
+		} else {
+			drift := reg.Drift + plan.context[i]*sigma
+			shock := sigma * rng.NormFloat64()
+			lastShock = shock
+			move = drift - sigma*sigma/2 + shock
+			bar = randomBar(rng, prev, move, sigma)
+		}
+		bar.Timestamp = ts.Unix()
+		bar.Volume = math.Round(cfg.Volume * reg.VolumeMultiple * math.Exp(cfg.VolumeNoise*rng.NormFloat64()) * (1 + cfg.VolumeBeta*math.Abs(move)/sigma))
+		if m := plan.volume[i]; m > 0 {
+			bar.Volume = math.Round(bar.Volume * m)
+		}
+		out.Candles[i] = bar
+		prev = bar.Close
+	}
+
+	for _, inj := range plan.sorted {
+		t := Truth{Pattern: inj.Pattern, Position: inj.Position, Start: inj.Position, VolumeSpike: inj.VolumeMultiple > 1}
+		if tpl, ok := templates[inj.Pattern]; ok {
+			t.Direction = tpl.direction
+			t.Start = inj.Position - len(tpl.bars) + 1
+		}
+		t.Time = time.Unix(out.Candles[inj.Position].Timestamp, 0).Format("2006-01-02 15:04:05")
+		out.Truth = append(out.Truth, t)
+	}
+	return out, nil
+}
+
+// GenerateMany builds n series with seeds Seed, Seed+1, ..., for Monte Carlo runs.
+// GenerateMany 以 Seed、Seed+1……生成 n 条序列，用于蒙特卡洛模拟。
+func GenerateMany(cfg Config, n int) ([]Series, error) {
+	out := make([]Series, 0, n)
+	for k := 0; k < n; k++ {
+		c := cfg
+		c.Seed = cfg.Seed + int64(k)
+		s, err := Generate(c)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// randomBar draws open, high and low around a close-to-close log move.
+func randomBar(rng *rand.Rand, prev, move, sigma float64) *v1.Candlestick {
+	open := prev * math.Exp(0.2*sigma*rng.NormFloat64())
+	closePrice := prev * math.Exp(move)
+	high := math.Max(open, closePrice) * math.Exp(0.5*sigma*math.Abs(rng.NormFloat64()))
+	low := math.Min(open, closePrice) * math.Exp(-0.5*sigma*math.Abs(rng.NormFloat64()))
+	return &v1.Candlestick{Open: open, High: high, Low: low, Close: closePrice}
+}
+
+func nextRegime(rng *rand.Rand, cfg Config, state int) int {
+	k := len(cfg.Regimes)
+	if k == 1 {
+		return 0
+	}
+	u := rng.Float64()
+	if len(cfg.Transition) == 0 {
+		if u < cfg.StayProbability {
+			return state
+		}
+		next := rng.Intn(k - 1)
+		if next >= state {
+			next++
+		}
+		return next
+	}
+	for j, p := range cfg.Transition[state] {
+		if u < p {
+			return j
+		}
+		u -= p
+	}
+	return k - 1
+}
+
+func validateTransition(cfg Config) error {
+	if len(cfg.Transition) == 0 {
+		return nil
+	}
+	if len(cfg.Transition) != len(cfg.Regimes) {
+		return fmt.Errorf("transition must have one row per regime")
+	}
+	for i, row := range cfg.Transition {
+		if len(row) != len(cfg.Regimes) {
+			return fmt.Errorf("transition[%d] must have one entry per regime", i)
+		}
+		sum := 0.0
+		for _, p := range row {
+			if p < 0 {
+				return fmt.Errorf("transition[%d] has a negative probability", i)
+			}
+			sum += p
+		}
+		if math.Abs(sum-1) > 1e-6 {
+			return fmt.Errorf("transition[%d] must sum to 1", i)
+		}
+	}
+	return nil
+}
+
+type plannedBar struct {
+	template template
+	offset   int
+}
+
+type injectionPlan struct {
+	bars    map[int]plannedBar
+	context map[int]float64 // Extra drift, in volatility units (额外漂移，以波动率为单位)
+	volume  map[int]float64
+	sorted  []Injection
+}
+
+// planInjections maps every injected bar to its template and rejects
+// unknown patterns and overlapping or out-of-range injections.
+func planInjections(cfg Config) (injectionPlan, error) {
+	plan := injectionPlan{
+		bars:    make(map[int]plannedBar),
+		context: make(map[int]float64),
+		volume:  make(map[int]float64),
+		sorted:  append([]Injection(nil), cfg.Injections...),
+	}
+	sort.SliceStable(plan.sorted, func(i, j int) bool { return plan.sorted[i].Position < plan.sorted[j].Position })
+	for _, inj := range plan.sorted {
+		if inj.Position < 1 || inj.Position >= cfg.Bars {
+			return plan, fmt.Errorf("injection at %d is outside bars 1..%d", inj.Position, cfg.Bars-1)
+		}
+		if inj.VolumeMultiple > 0 {
+			plan.volume[inj.Position] = inj.VolumeMultiple
+		}
+		if inj.Pattern == "" {
+			continue
+		}
+		tpl, ok := templates[inj.Pattern]
+		if !ok {
+			return plan, fmt.Errorf("unknown pattern %q", inj.Pattern)
+		}
+		first := inj.Position - len(tpl.bars) + 1
+		if first < 1 {
+			return plan, fmt.Errorf("%s at %d needs %d earlier bars", inj.Pattern, inj.Position, len(tpl.bars))
+		}
+		for k := range tpl.bars {
+			if _, taken := plan.bars[first+k]; taken {
+				return plan, fmt.Errorf("%s at %d overlaps another injection", inj.Pattern, inj.Position)
+			}
+			plan.bars[first+k] = plannedBar{template: tpl, offset: k}
+		}
+		bars := inj.ContextBars
+		if bars == 0 {
+			bars = 5
+		}
+		for k := first - bars; k < first; k++ {
+			if k >= 0 {
+				plan.context[k] = 0.8 * float64(tpl.context)
+			}
+		}
+	}
+	return plan, nil
+}
+
+func nextWeekday(t time.Time) time.Time {
+	for t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
@@ -0,0 +1,182 @@
+package synthetic
+
+import (
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/LEVI-Tempest/Candle/pkg/identify"
+	v1 "github.com/LEVI-Tempest/Candle/pkg/proto"
+)
+
+func TestGenerateIsValidAndDeterministic(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Model = ModelGARCH
+	a, err := Generate(cfg)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	b, _ := Generate(cfg)
+	if len(a.Candles) != 250 || len(a.Regimes) != 250 {
+		t.Fatalf("expected 250 bars, got %d", len(a.Candles))
+	}
+	if first := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC).Unix(); a.Candles[0].Timestamp != first {
+		t.Fatalf("first bar should start at UTC midnight of the start date: %d, want %d", a.Candles[0].Timestamp, first)
+	}
+	for i, c := range a.Candles {
+		if o := b.Candles[i]; c.Timestamp != o.Timestamp || c.Open != o.Open || c.High != o.High || c.Low != o.Low || c.Close != o.Close || c.Volume != o.Volume {
+			t.Fatalf("bar %d differs between runs with the same seed", i)
+		}
+		if c.Low <= 0 || c.High < math.Max(c.Open, c.Close) || c.Low > math.Min(c.Open, c.Close) || c.Volume <= 0 {
+			t.Fatalf("invalid bar %d: %+v", i, c)
+		}
+		ts := time.Unix(c.Timestamp, 0).UTC()
+		if ts.Weekday() == time.Saturday || ts.Weekday() == time.Sunday {
+			t.Fatalf("daily bar %d falls on a weekend: %s", i, ts)
+		}
+		if i > 0 && c.Timestamp <= a.Candles[i-1].Timestamp {
+			t.Fatalf("timestamps must increase at %d", i)
+		}
+	}
+	cfg.Seed = 2
+	if c, _ := Generate(cfg); c.Candles[10].Close == a.Candles[10].Close {
+		t.Fatal("a different seed should give a different path")
+	}
+}
+
+func wrap(cs []*v1.Candlestick) []identify.CandlestickWrapper {
+	out := make([]identify.CandlestickWrapper, len(cs))
+	for i, c := range cs {
+		out[i] = identify.NewCandlestickWrapper(c)
+	}
+	return out
+}
+
+func TestInjectedPatternsAreDetected(t *testing.T) {
+	patterns := Patterns()
+	if len(patterns) != 30 {
+		t.Fatalf("expected every directional and neutral template, got %d: %v", len(patterns), patterns)
+	}
+	d := identify.NewDetector(identify.DefaultDetectorConfig())
+	ps := identify.NewPatternScorer(identify.DefaultPatternConfig())
+	for seed := int64(1); seed <= 5; seed++ {
+		cfg := DefaultConfig()
+		cfg.Seed = seed
+		cfg.Bars = 20*len(patterns) + 20
+		cfg.VolumeNoise = 0.1
+		for k, p := range patterns {
+			cfg.Injections = append(cfg.Injections, Injection{Position: 15 + 20*k, Pattern: p, VolumeMultiple: 4})
+		}
+		s, err := Generate(cfg)
+		if err != nil {
+			t.Fatalf("generate: %v", err)
+		}
+		cs := wrap(s.Candles)
+		volumes := make([]float64, len(s.Candles))
+		for i, c := range s.Candles {
+			volumes[i] = c.Volume
+		}
+		sort.Float64s(volumes)
+		median := volumes[len(volumes)/2]
+		for _, tr := range s.Truth {
+			found := false
+			for _, sig := range identify.ScanPatternsAt(cs, tr.Position, d, ps) {
+				if sig.Type == tr.Pattern {
+					found = sig.Direction == tr.Direction
+				}
+			}
+			if !found {
+				t.Fatalf("seed %d: %s not detected in its direction at %d: %+v", seed, tr.Pattern, tr.Position, s.Candles[tr.Start:tr.Position+1])
+			}
+			if !tr.VolumeSpike || s.Candles[tr.Position].Volume < 2*median {
+				t.Fatalf("seed %d: %s should end on a volume spike", seed, tr.Pattern)
+			}
+		}
+	}
+}
+
+// squaredReturnAutocorr is the lag-1 autocorrelation of squared log returns.
+func squaredReturnAutocorr(cs []*v1.Candlestick) float64 {
+	sq := make([]float64, 0, len(cs))
+	for i := 1; i < len(cs); i++ {
+		r := math.Log(cs[i].Close / cs[i-1].Close)
+		sq = append(sq, r*r)
+	}
+	mean := 0.0
+	for _, v := range sq {
+		mean += v / float64(len(sq))
+	}
+	num, den := 0.0, 0.0
+	for i, v := range sq {
+		den += (v - mean) * (v - mean)
+		if i > 0 {
+			num += (v - mean) * (sq[i-1] - mean)
+		}
+	}
+	return num / den
+}
+
+func TestGARCHClustersVolatility(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Bars = 5000
+	cfg.GARCHAlpha, cfg.GARCHBeta = 0.15, 0.8
+	gbm, _ := Generate(cfg)
+	cfg.Model = ModelGARCH
+	garch, _ := Generate(cfg)
+	g, c := squaredReturnAutocorr(gbm.Candles), squaredReturnAutocorr(garch.Candles)
+	if math.Abs(g) > 0.05 || c < 0.1 {
+		t.Fatalf("expected clustering only under GARCH: gbm %.3f garch %.3f", g, c)
+	}
+}
+
+func TestRegimeSwitching(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Bars = 3000
+	cfg.Regimes = []Regime{
+		{Name: "calm", Drift: 0.0005, Volatility: 0.01},
+		{Name: "stress", Drift: -0.001, Volatility: 0.04, VolumeMultiple: 2},
+	}
+	cfg.Transition = [][]float64{{0.98, 0.02}, {0.05, 0.95}}
+	s, err := Generate(cfg)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	sum := map[string]float64{}
+	n := map[string]float64{}
+	for i := 1; i < len(s.Candles); i++ {
+		r := math.Log(s.Candles[i].Close / s.Candles[i-1].Close)
+		sum[s.Regimes[i]] += r * r
+		n[s.Regimes[i]]++
+	}
+	if n["calm"] == 0 || n["stress"] == 0 {
+		t.Fatalf("both regimes should occur: %v", n)
+	}
+	calm, stress := math.Sqrt(sum["calm"]/n["calm"]), math.Sqrt(sum["stress"]/n["stress"])
+	if stress < 2.5*calm {
+		t.Fatalf("stress volatility %.4f should be well above calm %.4f", stress, calm)
+	}
+	if share := n["calm"] / float64(len(s.Candles)-1); share < 0.6 || share > 0.85 {
+		t.Fatalf("calm share %.2f should be near the stationary 0.71", share)
+	}
+
+	cfg.Transition = [][]float64{{0.5, 0.4}, {0.5, 0.5}}
+	if _, err := Generate(cfg); err == nil {
+		t.Fatal("expected an error for a transition row not summing to 1")
+	}
+}
+
+func TestInjectionErrors(t *testing.T) {
+	for name, inj := range map[string][]Injection{
+		"unknown":  {{Position: 10, Pattern: "Head and Shoulders"}},
+		"overlap":  {{Position: 10, Pattern: "Morning Star"}, {Position: 9, Pattern: "Doji"}},
+		"too-late": {{Position: 250, Pattern: "Doji"}},
+		"too-soon": {{Position: 2, Pattern: "Rising Three Methods"}},
+	} {
+		cfg := DefaultConfig()
+		cfg.Injections = inj
+		if _, err := Generate(cfg); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}